@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -14,10 +15,10 @@ type (
 	}
 
 	database interface {
-		Query(query string, args ...interface{}) (rows, error)
-		QueryRow(query string, args ...interface{}) row
-		Exec(query string, args ...interface{}) (sql.Result, error)
-		Begin() (transaction, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) row
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		BeginTx(ctx context.Context) (transaction, error)
 	}
 	row interface {
 		Scan(dest ...interface{}) error
@@ -28,7 +29,7 @@ type (
 		row // Scan method
 	}
 	transaction interface {
-		Exec(query string, args ...interface{}) (sql.Result, error)
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		Commit() error
 		Rollback() error
 	}
@@ -58,21 +59,21 @@ func newSQLDatabase(driverName, dataSourceName string) (*sqlDB, error) {
 	return &d, nil
 }
 
-func (s sqlDatabase) Query(query string, args ...interface{}) (rows, error) {
-	return s.db.Query(query, args...)
+func (s sqlDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (rows, error) {
+	return s.db.QueryContext(ctx, query, args...)
 }
-func (s sqlDatabase) QueryRow(query string, args ...interface{}) row {
-	return s.db.QueryRow(query, args...)
+func (s sqlDatabase) QueryRowContext(ctx context.Context, query string, args ...interface{}) row {
+	return s.db.QueryRowContext(ctx, query, args...)
 }
-func (s sqlDatabase) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return s.db.Exec(query, args...)
+func (s sqlDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
 }
-func (s sqlDatabase) Begin() (transaction, error) {
-	return s.db.Begin()
+func (s sqlDatabase) BeginTx(ctx context.Context) (transaction, error) {
+	return s.db.BeginTx(ctx, nil)
 }
 
-func (d *sqlDB) begin() (dbTX, error) {
-	tx, err := d.db.Begin()
+func (d *sqlDB) begin(ctx context.Context) (dbTX, error) {
+	tx, err := d.db.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
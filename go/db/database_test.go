@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -68,16 +69,16 @@ type (
 	}
 )
 
-func (m mockDatabase) Query(query string, args ...interface{}) (rows, error) {
+func (m mockDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (rows, error) {
 	return m.QueryFunc(query, args...)
 }
-func (m mockDatabase) QueryRow(query string, args ...interface{}) row {
+func (m mockDatabase) QueryRowContext(ctx context.Context, query string, args ...interface{}) row {
 	return m.QueryRowFunc(query, args...)
 }
-func (m mockDatabase) Exec(query string, args ...interface{}) (sql.Result, error) {
+func (m mockDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return m.ExecFunc(query, args...)
 }
-func (m mockDatabase) Begin() (transaction, error) {
+func (m mockDatabase) BeginTx(ctx context.Context) (transaction, error) {
 	return m.BeginFunc()
 }
 func (m mockRow) Scan(dest ...interface{}) error {
@@ -92,7 +93,7 @@ func (m mockRows) Next() bool {
 func (m mockRows) Scan(dest ...interface{}) error {
 	return m.ScanFunc(dest...)
 }
-func (m mockTransaction) Exec(query string, args ...interface{}) (sql.Result, error) {
+func (m mockTransaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return m.ExecFunc(query, args...)
 }
 func (m mockTransaction) Commit() error {
@@ -204,6 +205,12 @@ func mockScan(dest, src interface{}) error {
 			*d = s
 			return nil
 		}
+	case time.Time:
+		switch d := dest.(type) {
+		case *time.Time:
+			*d = s
+			return nil
+		}
 	case *[]byte:
 		switch d := dest.(type) {
 		case **[]byte:
@@ -360,6 +367,7 @@ func TestSQLDatabaseMethods(t *testing.T) {
 	if d == nil {
 		t.Fatal("expected database to not be nil after Init() called, but was")
 	}
+	ctx := context.Background()
 	methodTests := []struct {
 		methodName string
 		count      *int
@@ -368,22 +376,22 @@ func TestSQLDatabaseMethods(t *testing.T) {
 		{
 			methodName: "queryCalled",
 			count:      &queryCalled,
-			method:     func() { d.db.Query("query_sql") },
+			method:     func() { d.db.QueryContext(ctx, "query_sql") },
 		},
 		{
 			methodName: "queryRowCalled",
 			count:      &queryRowCalled,
-			method:     func() { d.db.QueryRow("query_row_sql") },
+			method:     func() { d.db.QueryRowContext(ctx, "query_row_sql") },
 		},
 		{
 			methodName: "execCalled",
 			count:      &execCalled,
-			method:     func() { d.db.Exec("exec_sql") },
+			method:     func() { d.db.ExecContext(ctx, "exec_sql") },
 		},
 		{
 			methodName: "beginTransactionCalled",
 			count:      &beginTransactionCalled,
-			method:     func() { d.db.Begin() },
+			method:     func() { d.db.BeginTx(ctx) },
 		},
 	}
 	for _, call := range methodTests {
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/fs"
@@ -43,54 +44,65 @@ type (
 	}
 
 	db interface {
-		begin() (dbTX, error) // returning the dbTX interface is smelly
-		GetSportTypes() (SportTypeMap, error)
-		GetPlayerTypes() (PlayerTypeMap, error)
-		GetYears(st SportType) ([]Year, error)
-		GetStat(st SportType) (*Stat, error)
-		SetStat(stat Stat) error
-		ClrStat(st SportType) error
-		GetFriends(st SportType) ([]Friend, error)
-		GetPlayers(st SportType) ([]Player, error)
-		GetUserPassword(username string) (string, error)
-		SetUserPassword(username, hashedPassword string) error
-		AddUser(username, hashedPassword string) error
+		begin(ctx context.Context) (dbTX, error) // returning the dbTX interface is smelly
+		GetSportTypes(ctx context.Context) (SportTypeMap, error)
+		GetPlayerTypes(ctx context.Context) (PlayerTypeMap, error)
+		GetYears(ctx context.Context, st SportType) ([]Year, error)
+		GetStat(ctx context.Context, st SportType) (*Stat, error)
+		GetYearStat(ctx context.Context, st SportType, year int) (*Stat, error)
+		SetStat(ctx context.Context, stat Stat) error
+		ClrStat(ctx context.Context, st SportType) error
+		GetFriends(ctx context.Context, st SportType) ([]Friend, error)
+		GetPlayers(ctx context.Context, st SportType) ([]Player, error)
+		GetPlayerInfos(ctx context.Context, st SportType) ([]PlayerInfo, error)
+		GetSubscriptions(ctx context.Context, st SportType) ([]Subscription, error)
+		GetUserPassword(ctx context.Context, username string) (string, error)
+		SetUserPassword(ctx context.Context, username, hashedPassword string) error
+		AddUser(ctx context.Context, username, hashedPassword string) error
 		// IsNotExist is used by the datastore to determine if a query failed because data does not exist.
 		IsNotExist(err error) bool
 	}
 
 	dbTX interface {
-		execute() error
+		execute(ctx context.Context) error
 		AddYear(st SportType, year int)
 		DelYear(st SportType, year int)
 		SetYearActive(st SportType, year int)
 		ClrYearActive(st SportType)
+		SetStatFrozen(st SportType, year int)
+		ClrStatFrozen(st SportType, year int)
+		AddRosters(st SportType, fromYear, toYear int, keepers bool)
 		AddFriend(st SportType, displayOrder int, name string)
 		SetFriend(st SportType, id ID, displayOrder int, name string)
 		DelFriend(st SportType, id ID)
-		AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID)
-		SetPlayer(st SportType, id ID, displayOrder int)
+		AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID, addDate, dropDate *time.Time)
+		AddFriendPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendName string)
+		SetPlayer(st SportType, id ID, displayOrder int, addDate, dropDate *time.Time)
 		DelPlayer(st SportType, id ID)
+		SetPlayerInfo(st SportType, playerInfo PlayerInfo)
+		SetSubscription(st SportType, subscription Subscription)
+		DelSubscription(st SportType, friendID ID)
 	}
 )
 
 // NewDatastore creates a new sqlDatastore
-func NewDatastore(dataSourceName string, log *log.Logger, fs fs.ReadFileFS) (*Datastore, error) {
+// The context is used to connect to the database and load the SportTypes and PlayerTypes.
+func NewDatastore(ctx context.Context, dataSourceName string, log *log.Logger, fs fs.ReadFileFS) (*Datastore, error) {
 	cfg := datastoreConfig{
 		dataSourceName: dataSourceName,
 		ph:             bcryptPasswordHasher{},
 		log:            log,
 		fs:             fs,
 	}
-	d, err := cfg.newDatabase()
+	d, err := cfg.newDatabase(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return cfg.newDatastore(d)
+	return cfg.newDatastore(ctx, d)
 }
 
 // newDatabase creates a database from the dataSourceName in the config
-func (cfg datastoreConfig) newDatabase() (db, error) {
+func (cfg datastoreConfig) newDatabase(ctx context.Context) (db, error) {
 	url, err := url.Parse(cfg.dataSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("parsing data source: %w", err)
@@ -101,7 +113,7 @@ func (cfg datastoreConfig) newDatabase() (db, error) {
 		d, err = newSQLDatabase(url.Scheme, cfg.dataSourceName)
 	case "firestore":
 		projectID := url.Host
-		d, err = newFirestoreDB(projectID)
+		d, err = newFirestoreDB(ctx, projectID)
 	}
 	if err != nil {
 		return nil, err
@@ -110,7 +122,7 @@ func (cfg datastoreConfig) newDatabase() (db, error) {
 }
 
 // tewDataStore creates a datastore using the database
-func (cfg datastoreConfig) newDatastore(db db) (*Datastore, error) {
+func (cfg datastoreConfig) newDatastore(ctx context.Context, db db) (*Datastore, error) {
 
 	ds := Datastore{
 		db:  db,
@@ -119,18 +131,18 @@ func (cfg datastoreConfig) newDatastore(db db) (*Datastore, error) {
 	}
 
 	if d, ok := db.(*sqlDB); ok {
-		if err := d.SetupTablesAndFunctions(cfg.fs); err != nil {
+		if err := d.SetupTablesAndFunctions(ctx, cfg.fs); err != nil {
 			return nil, err
 		}
 	}
 
-	sportTypes, err := ds.GetSportTypes()
+	sportTypes, err := ds.GetSportTypes(ctx)
 	if err != nil {
 		return nil, err
 	}
 	ds.sportTypes = sportTypes
 
-	playerTypes, err := ds.GetPlayerTypes()
+	playerTypes, err := ds.GetPlayerTypes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +166,11 @@ func (ds Datastore) PlayerTypes() PlayerTypeMap {
 	return ds.playerTypes
 }
 
-func (t *sqlTX) execute() error {
+func (t *sqlTX) execute(ctx context.Context) error {
 	var result sql.Result
 	var err error
 	for _, sqlFunction := range t.queries {
-		result, err = t.tx.Exec(sqlFunction.sql(), sqlFunction.args...)
+		result, err = t.tx.ExecContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 		if err == nil {
 			err = expectSingleRowAffected(result)
 		}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
@@ -116,7 +117,7 @@ func TestSqlTXExecute(t *testing.T) {
 			tx:      tx,
 			queries: test.queries,
 		}
-		gotErr := sTX.execute()
+		gotErr := sTX.execute(context.Background())
 		switch {
 		case gotErr == nil:
 			if test.execErr != nil || test.commitErr != nil {
@@ -335,7 +336,7 @@ func TestNewSQLDatastore(t *testing.T) {
 		case err != nil:
 			t.Fatalf("Test %v: unwanted error creating database: %v", i, err)
 		}
-		ds, err := cfg.newDatastore(db)
+		ds, err := cfg.newDatastore(context.Background(), db)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -351,7 +352,7 @@ func TestNewSQLDatastore(t *testing.T) {
 				1: {Name: "st_1_name", URL: "st_1_url", DisplayOrder: 0},
 				2: {Name: "st_2_name", URL: "st_2_url", DisplayOrder: 1},
 			}
-			gotSportTypes, err := ds.GetSportTypes()
+			gotSportTypes, err := ds.GetSportTypes(context.Background())
 			switch {
 			case err != nil:
 				t.Errorf("Test %v: %v", i, err)
@@ -366,7 +367,7 @@ func TestNewSQLDatastore(t *testing.T) {
 				5: {SportType: 2, Name: "pt_5_name", Description: "pt_5_description", ScoreType: "pt_5_score_type", DisplayOrder: 4},
 				6: {SportType: 2, Name: "pt_6_name", Description: "pt_6_description", ScoreType: "pt_6_score_type", DisplayOrder: 5},
 			}
-			gotPlayerTypes, err := ds.GetPlayerTypes()
+			gotPlayerTypes, err := ds.GetPlayerTypes(context.Background())
 			switch {
 			case err != nil:
 				t.Errorf("Test %v: %v", i, err)
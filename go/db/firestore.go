@@ -30,6 +30,7 @@ type (
 		doc   *firestore.DocumentRef
 		data  map[string]interface{}
 		fc    *firestoreFriendChange
+		rc    *firestoreRosterCopy
 	}
 	firestoreTransactionOperationClass int
 	firestoreFriendChange              struct {
@@ -39,8 +40,20 @@ type (
 		newFriendID ID
 	}
 	firestoreFriendChangeClass int
-	firestoreTransactionReads  struct {
+	// firestoreRosterCopy copies the friends, and optionally the players that were not dropped, from a year to the year of the operation
+	firestoreRosterCopy struct {
+		sportType SportType
+		fromYear  int
+		keepers   bool
+	}
+	firestoreRosterSnaps struct {
+		friends       []*firestore.DocumentSnapshot
+		players       []*firestore.DocumentSnapshot
+		subscriptions []*firestore.DocumentSnapshot
+	}
+	firestoreTransactionReads struct {
 		sportTypePlayerDocs map[SportType][]*firestore.DocumentRef
+		rosterSnaps         map[firestoreRosterCopy]firestoreRosterSnaps
 	}
 
 	firestoreFriend struct {
@@ -49,11 +62,27 @@ type (
 	firestorePlayer struct {
 		DisplayOrder int        `firestore:"display_order"`
 		PlayerType   PlayerType `firestore:"player_type"`
+		SourceID     SourceID   `firestore:"source_id"`
 		FriendID     ID         `firestore:"friend_id"`
+		AddDate      *time.Time `firestore:"add_date"`
+		DropDate     *time.Time `firestore:"drop_date"`
+	}
+	firestorePlayerInfo struct {
+		PlayerType  PlayerType `firestore:"player_type"`
+		SourceID    SourceID   `firestore:"source_id"`
+		Name        string     `firestore:"name"`
+		Position    string     `firestore:"position"`
+		Team        string     `firestore:"team"`
+		RefreshTime *time.Time `firestore:"refresh_time"`
+	}
+	firestoreSubscription struct {
+		Email string `firestore:"email"`
 	}
 	firestoreStat struct {
-		EtlJSON      string     `firestore:"etl_json"`
-		EtlTimestamp *time.Time `firestore:"etl_timestamp"`
+		EtlJSON       string     `firestore:"etl_json"`
+		EtlStatusJSON string     `firestore:"etl_status_json"`
+		EtlTimestamp  *time.Time `firestore:"etl_timestamp"`
+		EtlFrozen     bool       `firestore:"etl_frozen"`
 	}
 	firestoreAdminUser struct {
 		HashedPassword string `firestore:"admin_password"`
@@ -64,20 +93,31 @@ const (
 	add firestoreTransactionOperationClass = iota + 1
 	set
 	del
+	replace
+	copyRosters
 	delPlayers firestoreFriendChangeClass = iota + 1
 	setPlayers
-	adminUsername              = "admin"
-	firestoreContextTimeout    = 5 * time.Second
-	firestoreFieldDisplayOrder = "display_order"
-	firestoreFieldPlayerType   = "player_type"
-	firestoreFieldFriendID     = "friend_id"
-	firestoreFieldEtlTimestamp = "etl_timestamp"
-	firestoreFieldEtlJSON      = "etl_json"
-	firestoreFieldPassword     = "admin_password"
+	adminUsername               = "admin"
+	firestoreContextTimeout     = 5 * time.Second
+	firestoreFieldDisplayOrder  = "display_order"
+	firestoreFieldPlayerType    = "player_type"
+	firestoreFieldFriendID      = "friend_id"
+	firestoreFieldAddDate       = "add_date"
+	firestoreFieldDropDate      = "drop_date"
+	firestoreFieldSourceID      = "source_id"
+	firestoreFieldName          = "name"
+	firestoreFieldPosition      = "position"
+	firestoreFieldTeam          = "team"
+	firestoreFieldRefreshTime   = "refresh_time"
+	firestoreFieldEmail         = "email"
+	firestoreFieldEtlTimestamp  = "etl_timestamp"
+	firestoreFieldEtlJSON       = "etl_json"
+	firestoreFieldEtlStatusJSON = "etl_status_json"
+	firestoreFieldEtlFrozen     = "etl_frozen"
+	firestoreFieldPassword      = "admin_password"
 )
 
-func newFirestoreDB(projectID string) (*firestoreDB, error) {
-	ctx := context.Background()
+func newFirestoreDB(ctx context.Context, projectID string) (*firestoreDB, error) {
 	client, err := firestore.NewClient(ctx, projectID) // do not timeout context - the client is used by the backend
 	if err != nil {
 		return nil, fmt.Errorf("creating firestore client: %w", err)
@@ -88,15 +128,15 @@ func newFirestoreDB(projectID string) (*firestoreDB, error) {
 	return &d, nil
 }
 
-func (d *firestoreDB) begin() (dbTX, error) {
+func (d *firestoreDB) begin(ctx context.Context) (dbTX, error) {
 	t := firestoreTX{
 		db: d,
 	}
 	return &t, nil
 }
 
-func (t *firestoreTX) execute() error {
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+func (t *firestoreTX) execute(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		return t.db.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
 			reads, err := t.makeReads(tx)
 			if err != nil {
@@ -133,6 +173,10 @@ func (op firestoreTransactionOperation) execute(ctx context.Context, tx *firesto
 		if err := tx.Update(op.doc, updates); err != nil {
 			return err
 		}
+	case replace:
+		if err := tx.Set(op.doc, op.data); err != nil {
+			return err
+		}
 	case del:
 		if op.data != nil {
 			return fmt.Errorf("cannot delete document data, can only delete the whole document")
@@ -140,6 +184,13 @@ func (op firestoreTransactionOperation) execute(ctx context.Context, tx *firesto
 		if err := tx.Delete(op.doc); err != nil {
 			return err
 		}
+	case copyRosters:
+		if op.rc == nil {
+			return fmt.Errorf("missing rosters to copy")
+		}
+		if err := op.rc.addRosters(tx, op.doc, reads); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unknown firestoreTransactionOperationClass: %v", op.class)
 	}
@@ -187,11 +238,95 @@ func (fc *firestoreFriendChange) updatePlayers(ctx context.Context, tx *firestor
 	return nil
 }
 
+// addRosters creates the friends, subscriptions, and keepers of the year of the roster copy in the year document.
+func (rc firestoreRosterCopy) addRosters(tx *firestore.Transaction, yearDoc *firestore.DocumentRef, reads firestoreTransactionReads) error {
+	snaps := reads.rosterSnaps[rc]
+	friendsCollection := yearDoc.Collection("friends")
+	for _, snap := range snaps.friends {
+		var f firestoreFriend
+		if err := snap.DataTo(&f); err != nil {
+			return err
+		}
+		data := map[string]interface{}{
+			firestoreFieldDisplayOrder: f.DisplayOrder,
+		}
+		if err := tx.Create(friendsCollection.Doc(snap.Ref.ID), data); err != nil {
+			return err
+		}
+	}
+	subscriptionsCollection := yearDoc.Collection("subscriptions")
+	for _, snap := range snaps.subscriptions {
+		if err := tx.Create(subscriptionsCollection.Doc(snap.Ref.ID), snap.Data()); err != nil {
+			return err
+		}
+	}
+	if !rc.keepers {
+		return nil
+	}
+	playersCollection := yearDoc.Collection("players")
+	for _, snap := range snaps.players {
+		var p firestorePlayer
+		if err := snap.DataTo(&p); err != nil {
+			return err
+		}
+		if p.DropDate != nil {
+			continue
+		}
+		keeper, err := p.keeper(snap.Ref)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(playersCollection.Doc(snap.Ref.ID), keeper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keeper creates the player as it is kept in a new year, without the dates it was added and dropped.
+// The source id is copied because the ids of players are not their source ids.
+func (p firestorePlayer) keeper(doc *firestore.DocumentRef) (*firestorePlayer, error) {
+	sourceID, err := p.sourceID(doc)
+	if err != nil {
+		return nil, err
+	}
+	keeper := firestorePlayer{
+		DisplayOrder: p.DisplayOrder,
+		PlayerType:   p.PlayerType,
+		SourceID:     sourceID,
+		FriendID:     p.FriendID,
+	}
+	return &keeper, nil
+}
+
 func (t firestoreTX) makeReads(tx *firestore.Transaction) (*firestoreTransactionReads, error) {
 	reads := firestoreTransactionReads{
 		sportTypePlayerDocs: make(map[SportType][]*firestore.DocumentRef),
+		rosterSnaps:         make(map[firestoreRosterCopy]firestoreRosterSnaps),
 	}
 	for _, op := range t.ops {
+		if op.rc != nil {
+			if _, ok := reads.rosterSnaps[*op.rc]; !ok {
+				doc := t.db.yearDoc(op.rc.sportType, op.rc.fromYear)
+				friends, err := tx.Documents(doc.Collection("friends")).GetAll()
+				if err != nil {
+					return nil, err
+				}
+				players, err := tx.Documents(doc.Collection("players")).GetAll()
+				if err != nil {
+					return nil, err
+				}
+				subscriptions, err := tx.Documents(doc.Collection("subscriptions")).GetAll()
+				if err != nil {
+					return nil, err
+				}
+				reads.rosterSnaps[*op.rc] = firestoreRosterSnaps{
+					friends:       friends,
+					players:       players,
+					subscriptions: subscriptions,
+				}
+			}
+		}
 		if op.fc != nil {
 			if _, ok := reads.sportTypePlayerDocs[op.fc.sportType]; !ok {
 				c, ok := t.db.playersCollection(op.fc.sportType)
@@ -209,10 +344,14 @@ func (t firestoreTX) makeReads(tx *firestore.Transaction) (*firestoreTransaction
 	return &reads, nil
 }
 
-func withFirestoreTimeoutContext(f func(ctx context.Context) error) error {
-	ctx := context.Background()
-	ctx, cancelFunc := context.WithTimeout(ctx, firestoreContextTimeout)
-	defer cancelFunc()
+// withFirestoreTimeoutContext calls the function with the context.
+// If the context has no deadline, the function is called with a context that times out after the firestoreContextTimeout.
+func withFirestoreTimeoutContext(ctx context.Context, f func(ctx context.Context) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancelFunc context.CancelFunc
+		ctx, cancelFunc = context.WithTimeout(ctx, firestoreContextTimeout)
+		defer cancelFunc()
+	}
 	return f(ctx)
 }
 
@@ -239,13 +378,22 @@ func (d *firestoreDB) yearsCollection(st SportType) *firestore.CollectionRef {
 	return d.statsCollection().Doc(sportTypeName).Collection("years")
 }
 
+func (d *firestoreDB) playerInfosCollection(st SportType) *firestore.CollectionRef {
+	sportTypeName := d.sportTypeMap[st].Name
+	return d.statsCollection().Doc(sportTypeName).Collection("player-infos")
+}
+
+func (d *firestoreDB) yearDoc(st SportType, year int) *firestore.DocumentRef {
+	y := strconv.Itoa(year)
+	return d.yearsCollection(st).Doc(y)
+}
+
 func (d *firestoreDB) activeYearDoc(st SportType) (_ *firestore.DocumentRef, ok bool) {
 	activeYear, ok := d.activeYears[st]
 	if !ok {
 		return nil, false
 	}
-	year := strconv.Itoa(activeYear)
-	return d.yearsCollection(st).Doc(year), true
+	return d.yearDoc(st, activeYear), true
 }
 
 func (d *firestoreDB) friendsCollection(st SportType) (_ *firestore.CollectionRef, ok bool) {
@@ -264,15 +412,24 @@ func (d *firestoreDB) playersCollection(st SportType) (_ *firestore.CollectionRe
 	return doc.Collection("players"), true
 }
 
+// subscriptionsCollection has the subscriptions of the friends of the active year, keyed by the ids of the friends.
+func (d *firestoreDB) subscriptionsCollection(st SportType) (_ *firestore.CollectionRef, ok bool) {
+	doc, ok := d.activeYearDoc(st)
+	if !ok {
+		return nil, false
+	}
+	return doc.Collection("subscriptions"), true
+}
+
 // ----- BEGIN QUERY/ SINGLE-EXEC FUNCTIONS -----
 
-func (d *firestoreDB) GetSportTypes() (SportTypeMap, error) {
+func (d *firestoreDB) GetSportTypes(ctx context.Context) (SportTypeMap, error) {
 	d.sportTypeMap = d.getSportTypes()
 	sportTypesByName, err := d.loadSportTypesByName()
 	if err != nil {
 		return nil, err
 	}
-	if err := d.loadActiveYears(sportTypesByName); err != nil {
+	if err := d.loadActiveYears(ctx, sportTypesByName); err != nil {
 		return nil, err
 	}
 	return d.sportTypeMap, nil
@@ -302,9 +459,9 @@ func (d firestoreDB) loadSportTypesByName() (map[string]SportType, error) {
 	return sportTypesByName, nil
 }
 
-func (d *firestoreDB) loadActiveYears(sportTypesByName map[string]SportType) error {
+func (d *firestoreDB) loadActiveYears(ctx context.Context, sportTypesByName map[string]SportType) error {
 	doc := d.activeYearsDocument()
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snap, err := doc.Get(ctx)
 		if err != nil {
 			if d.IsNotExist(err) {
@@ -357,7 +514,7 @@ func (d *firestoreDB) initActiveYears(ctx context.Context, doc *firestore.Docume
 	return nil
 }
 
-func (d *firestoreDB) GetPlayerTypes() (PlayerTypeMap, error) {
+func (d *firestoreDB) GetPlayerTypes(ctx context.Context) (PlayerTypeMap, error) {
 	m := PlayerTypeMap{
 		PlayerTypeMlbTeam: PlayerTypeInfo{
 			SportType:    SportTypeMlb,
@@ -405,10 +562,10 @@ func (d *firestoreDB) GetPlayerTypes() (PlayerTypeMap, error) {
 	return m, nil
 }
 
-func (d *firestoreDB) GetYears(st SportType) ([]Year, error) {
+func (d *firestoreDB) GetYears(ctx context.Context, st SportType) ([]Year, error) {
 	c := d.yearsCollection(st)
 	var years []Year
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snaps, err := c.Documents(ctx).GetAll()
 		if err != nil {
 			if d.IsNotExist(err) {
@@ -447,13 +604,13 @@ func (d firestoreDB) getYears(snaps []*firestore.DocumentSnapshot, st SportType)
 	return years, nil
 }
 
-func (d *firestoreDB) GetFriends(st SportType) ([]Friend, error) {
+func (d *firestoreDB) GetFriends(ctx context.Context, st SportType) ([]Friend, error) {
 	c, ok := d.friendsCollection(st)
 	if !ok {
 		return nil, nil
 	}
 	var friends []Friend
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snaps, err := c.Documents(ctx).GetAll()
 		if err != nil {
 			return err
@@ -490,13 +647,13 @@ func (firestoreDB) getFriends(snaps []*firestore.DocumentSnapshot) ([]Friend, er
 	return friends, nil
 }
 
-func (d *firestoreDB) GetPlayers(st SportType) ([]Player, error) {
+func (d *firestoreDB) GetPlayers(ctx context.Context, st SportType) ([]Player, error) {
 	c, ok := d.playersCollection(st)
 	if !ok {
 		return nil, nil
 	}
 	var players []Player
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snaps, err := c.Documents(ctx).GetAll()
 		if err != nil {
 			return err
@@ -522,6 +679,18 @@ func (d *firestoreDB) GetPlayers(st SportType) ([]Player, error) {
 	return players, nil
 }
 
+// sourceID is the SourceID of the player.  Players that were added before the SourceID was saved are identified by it.
+func (p firestorePlayer) sourceID(doc *firestore.DocumentRef) (SourceID, error) {
+	if p.SourceID != 0 {
+		return p.SourceID, nil
+	}
+	sourceID, err := strconv.Atoi(doc.ID)
+	if err != nil {
+		return 0, err
+	}
+	return SourceID(sourceID), nil
+}
+
 func (firestoreDB) getPlayers(snaps []*firestore.DocumentSnapshot) ([]Player, error) {
 	var players []Player
 	for _, snap := range snaps {
@@ -529,7 +698,7 @@ func (firestoreDB) getPlayers(snaps []*firestore.DocumentSnapshot) ([]Player, er
 		if err := snap.DataTo(&fp); err != nil {
 			return nil, err
 		}
-		sourceID, err := strconv.Atoi(snap.Ref.ID)
+		sourceID, err := fp.sourceID(snap.Ref)
 		if err != nil {
 			return nil, err
 		}
@@ -538,20 +707,123 @@ func (firestoreDB) getPlayers(snaps []*firestore.DocumentSnapshot) ([]Player, er
 			PlayerType:   PlayerType(fp.PlayerType),
 			FriendID:     fp.FriendID,
 			DisplayOrder: fp.DisplayOrder,
-			SourceID:     SourceID(sourceID),
+			SourceID:     sourceID,
+			AddDate:      fp.AddDate,
+			DropDate:     fp.DropDate,
 		}
 		players = append(players, p)
 	}
 	return players, nil
 }
 
-func (d *firestoreDB) GetStat(st SportType) (*Stat, error) {
+func (d *firestoreDB) GetPlayerInfos(ctx context.Context, st SportType) ([]PlayerInfo, error) {
+	c := d.playerInfosCollection(st)
+	var playerInfos []PlayerInfo
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
+		snaps, err := c.Documents(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		playerInfos2, err := d.getPlayerInfos(snaps)
+		if err != nil {
+			return err
+		}
+		playerInfos = playerInfos2
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("get player infos: %w", err)
+	}
+	sort.Slice(playerInfos, func(i, j int) bool {
+		if playerInfos[i].PlayerType != playerInfos[j].PlayerType {
+			return playerInfos[i].PlayerType < playerInfos[j].PlayerType
+		}
+		return playerInfos[i].SourceID < playerInfos[j].SourceID
+	})
+	return playerInfos, nil
+}
+
+func (firestoreDB) getPlayerInfos(snaps []*firestore.DocumentSnapshot) ([]PlayerInfo, error) {
+	var playerInfos []PlayerInfo
+	for _, snap := range snaps {
+		var fpi firestorePlayerInfo
+		if err := snap.DataTo(&fpi); err != nil {
+			return nil, err
+		}
+		pi := PlayerInfo{
+			PlayerType:  fpi.PlayerType,
+			SourceID:    fpi.SourceID,
+			Name:        fpi.Name,
+			Position:    fpi.Position,
+			Team:        fpi.Team,
+			RefreshTime: fpi.RefreshTime,
+		}
+		playerInfos = append(playerInfos, pi)
+	}
+	return playerInfos, nil
+}
+
+func (d *firestoreDB) GetSubscriptions(ctx context.Context, st SportType) ([]Subscription, error) {
+	c, ok := d.subscriptionsCollection(st)
+	if !ok {
+		return nil, nil
+	}
+	var subscriptions []Subscription
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
+		snaps, err := c.Documents(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		subscriptions2, err := d.getSubscriptions(snaps)
+		if err != nil {
+			return err
+		}
+		subscriptions = subscriptions2
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("get subscriptions: %w", err)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].FriendID < subscriptions[j].FriendID
+	})
+	return subscriptions, nil
+}
+
+func (firestoreDB) getSubscriptions(snaps []*firestore.DocumentSnapshot) ([]Subscription, error) {
+	var subscriptions []Subscription
+	for _, snap := range snaps {
+		var fs firestoreSubscription
+		if err := snap.DataTo(&fs); err != nil {
+			return nil, err
+		}
+		s := Subscription{
+			FriendID: ID(snap.Ref.ID),
+			Email:    fs.Email,
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+func (d *firestoreDB) GetStat(ctx context.Context, st SportType) (*Stat, error) {
 	doc, ok := d.activeYearDoc(st)
 	if !ok {
 		return nil, nil
 	}
+	return d.getStat(ctx, st, doc)
+}
+
+func (d *firestoreDB) GetYearStat(ctx context.Context, st SportType, year int) (*Stat, error) {
+	doc := d.yearDoc(st, year)
+	stat, err := d.getStat(ctx, st, doc)
+	if err != nil && d.IsNotExist(err) {
+		return nil, nil
+	}
+	return stat, err
+}
+
+func (d *firestoreDB) getStat(ctx context.Context, st SportType, doc *firestore.DocumentRef) (*Stat, error) {
 	var stat Stat
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snap, err := doc.Get(ctx)
 		if err != nil {
 			return err
@@ -567,7 +839,9 @@ func (d *firestoreDB) GetStat(st SportType) (*Stat, error) {
 		stat.Year = y
 		stat.SportType = st
 		stat.EtlJSON = fs.EtlJSON
+		stat.EtlStatusJSON = fs.EtlStatusJSON
 		stat.EtlTimestamp = fs.EtlTimestamp
+		stat.Frozen = fs.EtlFrozen
 		return nil
 	}); err != nil {
 		return nil, fmt.Errorf("get stat: % w", err)
@@ -575,16 +849,18 @@ func (d *firestoreDB) GetStat(st SportType) (*Stat, error) {
 	return &stat, nil
 }
 
-func (d *firestoreDB) SetStat(stat Stat) error {
+func (d *firestoreDB) SetStat(ctx context.Context, stat Stat) error {
 	doc, ok := d.activeYearDoc(stat.SportType)
 	if !ok {
 		return fmt.Errorf("no active year to set stat for")
 	}
 	m := map[string]interface{}{
-		firestoreFieldEtlJSON:      stat.EtlJSON,
-		firestoreFieldEtlTimestamp: stat.EtlTimestamp,
+		firestoreFieldEtlJSON:       stat.EtlJSON,
+		firestoreFieldEtlStatusJSON: stat.EtlStatusJSON,
+		firestoreFieldEtlTimestamp:  stat.EtlTimestamp,
+		firestoreFieldEtlFrozen:     stat.Frozen,
 	}
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		if _, err := doc.Set(ctx, m); err != nil {
 			return err
 		}
@@ -595,23 +871,33 @@ func (d *firestoreDB) SetStat(stat Stat) error {
 	return nil
 }
 
-func (d *firestoreDB) ClrStat(st SportType) error {
-	stat := Stat{
-		SportType: st,
+func (d *firestoreDB) ClrStat(ctx context.Context, st SportType) error {
+	doc, ok := d.activeYearDoc(st)
+	if !ok {
+		return fmt.Errorf("no active year to clear stat for")
+	}
+	updates := []firestore.Update{
+		{Path: firestoreFieldEtlTimestamp, Value: nil},
+		{Path: firestoreFieldEtlFrozen, Value: false},
 	}
-	if err := d.SetStat(stat); err != nil {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
+		if _, err := doc.Update(ctx, updates); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
 		return fmt.Errorf("clear stat: %w", err)
 	}
 	return nil
 }
 
-func (d *firestoreDB) GetUserPassword(username string) (string, error) {
+func (d *firestoreDB) GetUserPassword(ctx context.Context, username string) (string, error) {
 	if username != adminUsername {
 		return "", fmt.Errorf("cannot get username for %q", username)
 	}
 	doc := d.rootDocument()
 	var fu firestoreAdminUser
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		snap, err := doc.Get(ctx)
 		if err != nil {
 			return err
@@ -626,7 +912,7 @@ func (d *firestoreDB) GetUserPassword(username string) (string, error) {
 	return fu.HashedPassword, nil
 }
 
-func (d *firestoreDB) SetUserPassword(username, hashedPassword string) error {
+func (d *firestoreDB) SetUserPassword(ctx context.Context, username, hashedPassword string) error {
 	if username != adminUsername {
 		return fmt.Errorf("cannot set username for %q", username)
 	}
@@ -634,7 +920,7 @@ func (d *firestoreDB) SetUserPassword(username, hashedPassword string) error {
 		firestoreFieldPassword: hashedPassword,
 	}
 	doc := d.rootDocument()
-	if err := withFirestoreTimeoutContext(func(ctx context.Context) error {
+	if err := withFirestoreTimeoutContext(ctx, func(ctx context.Context) error {
 		if _, err := doc.Set(ctx, m); err != nil {
 			return err
 		}
@@ -645,8 +931,8 @@ func (d *firestoreDB) SetUserPassword(username, hashedPassword string) error {
 	return nil
 }
 
-func (d *firestoreDB) AddUser(username, hashedPassword string) error {
-	if err := d.SetUserPassword(username, hashedPassword); err != nil {
+func (d *firestoreDB) AddUser(ctx context.Context, username, hashedPassword string) error {
+	if err := d.SetUserPassword(ctx, username, hashedPassword); err != nil {
 		return fmt.Errorf("add user: %w", err)
 	}
 	return nil
@@ -715,6 +1001,49 @@ func (t *firestoreTX) ClrYearActive(st SportType) {
 	t.ops = append(t.ops, op)
 }
 
+func (t *firestoreTX) SetStatFrozen(st SportType, year int) {
+	doc := t.db.yearDoc(st, year)
+	data := map[string]interface{}{
+		firestoreFieldEtlFrozen: true,
+	}
+	op := firestoreTransactionOperation{
+		name:  "set stat frozen",
+		class: set,
+		doc:   doc,
+		data:  data,
+	}
+	t.ops = append(t.ops, op)
+}
+
+func (t *firestoreTX) ClrStatFrozen(st SportType, year int) {
+	doc := t.db.yearDoc(st, year)
+	data := map[string]interface{}{
+		firestoreFieldEtlFrozen: false,
+	}
+	op := firestoreTransactionOperation{
+		name:  "clear stat frozen",
+		class: set,
+		doc:   doc,
+		data:  data,
+	}
+	t.ops = append(t.ops, op)
+}
+
+func (t *firestoreTX) AddRosters(st SportType, fromYear, toYear int, keepers bool) {
+	doc := t.db.yearDoc(st, toYear)
+	op := firestoreTransactionOperation{
+		name:  "add rosters",
+		class: copyRosters,
+		doc:   doc,
+		rc: &firestoreRosterCopy{
+			sportType: st,
+			fromYear:  fromYear,
+			keepers:   keepers,
+		},
+	}
+	t.ops = append(t.ops, op)
+}
+
 func (t *firestoreTX) AddFriend(st SportType, displayOrder int, name string) {
 	c, ok := t.db.friendsCollection(st)
 	if !ok {
@@ -773,6 +1102,8 @@ func (t *firestoreTX) SetFriend(st SportType, id ID, displayOrder int, name stri
 			},
 		}
 		t.ops = append(t.ops, op1, op2)
+		// subscriptions are keyed by the old name, so renamed friends must be subscribed again
+		t.DelSubscription(st, id)
 	}
 }
 
@@ -795,19 +1126,22 @@ func (t *firestoreTX) DelFriend(st SportType, id ID) {
 		},
 	}
 	t.ops = append(t.ops, op)
+	t.DelSubscription(st, id)
 }
 
-func (t *firestoreTX) AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID) {
+func (t *firestoreTX) AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID, addDate, dropDate *time.Time) {
 	c, ok := t.db.playersCollection(st)
 	if !ok {
 		return
 	}
-	path := strconv.Itoa(int(sourceID))
-	doc := c.Doc(path)
+	doc := c.NewDoc() // players that were dropped can be added again
 	data := map[string]interface{}{
 		firestoreFieldDisplayOrder: displayOrder,
 		firestoreFieldPlayerType:   pt,
+		firestoreFieldSourceID:     sourceID,
 		firestoreFieldFriendID:     friendID,
+		firestoreFieldAddDate:      addDate,
+		firestoreFieldDropDate:     dropDate,
 	}
 	op := firestoreTransactionOperation{
 		name:  "add player",
@@ -818,7 +1152,11 @@ func (t *firestoreTX) AddPlayer(st SportType, displayOrder int, pt PlayerType, s
 	t.ops = append(t.ops, op)
 }
 
-func (t *firestoreTX) SetPlayer(st SportType, id ID, displayOrder int) {
+func (t *firestoreTX) AddFriendPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendName string) {
+	t.AddPlayer(st, displayOrder, pt, sourceID, ID(friendName), nil, nil) // friends are identified by their names
+}
+
+func (t *firestoreTX) SetPlayer(st SportType, id ID, displayOrder int, addDate, dropDate *time.Time) {
 	c, ok := t.db.playersCollection(st)
 	if !ok {
 		return
@@ -827,6 +1165,8 @@ func (t *firestoreTX) SetPlayer(st SportType, id ID, displayOrder int) {
 	doc := c.Doc(path)
 	data := map[string]interface{}{
 		firestoreFieldDisplayOrder: displayOrder,
+		firestoreFieldAddDate:      addDate,
+		firestoreFieldDropDate:     dropDate,
 	}
 	op := firestoreTransactionOperation{
 		name:  "set player",
@@ -851,3 +1191,58 @@ func (t *firestoreTX) DelPlayer(st SportType, id ID) {
 	}
 	t.ops = append(t.ops, op)
 }
+
+func (t *firestoreTX) SetPlayerInfo(st SportType, playerInfo PlayerInfo) {
+	c := t.db.playerInfosCollection(st)
+	path := fmt.Sprintf("%d-%d", playerInfo.PlayerType, playerInfo.SourceID)
+	doc := c.Doc(path)
+	data := map[string]interface{}{
+		firestoreFieldPlayerType:  playerInfo.PlayerType,
+		firestoreFieldSourceID:    playerInfo.SourceID,
+		firestoreFieldName:        playerInfo.Name,
+		firestoreFieldPosition:    playerInfo.Position,
+		firestoreFieldTeam:        playerInfo.Team,
+		firestoreFieldRefreshTime: firestore.ServerTimestamp,
+	}
+	op := firestoreTransactionOperation{
+		name:  "set player info",
+		class: replace,
+		doc:   doc,
+		data:  data,
+	}
+	t.ops = append(t.ops, op)
+}
+
+func (t *firestoreTX) SetSubscription(st SportType, subscription Subscription) {
+	c, ok := t.db.subscriptionsCollection(st)
+	if !ok {
+		return
+	}
+	path := string(subscription.FriendID)
+	doc := c.Doc(path)
+	data := map[string]interface{}{
+		firestoreFieldEmail: subscription.Email,
+	}
+	op := firestoreTransactionOperation{
+		name:  "set subscription",
+		class: replace,
+		doc:   doc,
+		data:  data,
+	}
+	t.ops = append(t.ops, op)
+}
+
+func (t *firestoreTX) DelSubscription(st SportType, friendID ID) {
+	c, ok := t.db.subscriptionsCollection(st)
+	if !ok {
+		return
+	}
+	path := string(friendID)
+	doc := c.Doc(path)
+	op := firestoreTransactionOperation{
+		name:  "delete subscription",
+		class: del,
+		doc:   doc,
+	}
+	t.ops = append(t.ops, op)
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 )
@@ -15,13 +16,13 @@ type Friend struct {
 var friendNameRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`) // duplicated in friends.html
 
 // GetFriends gets the friends for the active year for a SportType
-func (ds Datastore) GetFriends(st SportType) ([]Friend, error) {
-	return ds.db.GetFriends(st)
+func (ds Datastore) GetFriends(ctx context.Context, st SportType) ([]Friend, error) {
+	return ds.db.GetFriends(ctx, st)
 }
 
-func (d sqlDB) GetFriends(st SportType) ([]Friend, error) {
+func (d sqlDB) GetFriends(ctx context.Context, st SportType) ([]Friend, error) {
 	sqlFunction := newReadSQLFunction("get_friends", []string{"id", "display_order", "name"}, st)
-	rs, err := d.db.Query(sqlFunction.sql(), sqlFunction.args...)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return nil, fmt.Errorf("reading friends: %w", err)
 	}
@@ -41,8 +42,8 @@ func (d sqlDB) GetFriends(st SportType) ([]Friend, error) {
 }
 
 // SaveFriends saves the specified friends for the active year for a SportType
-func (ds Datastore) SaveFriends(st SportType, futureFriends []Friend) error {
-	friends, err := ds.GetFriends(st)
+func (ds Datastore) SaveFriends(ctx context.Context, st SportType, futureFriends []Friend) error {
+	friends, err := ds.GetFriends(ctx, st)
 	if err != nil {
 		return err
 	}
@@ -65,14 +66,13 @@ func (ds Datastore) SaveFriends(st SportType, futureFriends []Friend) error {
 		delete(previousFriends, friend.ID)
 	}
 
-	t, err := ds.db.begin()
+	t, err := ds.db.begin(ctx)
 	if err != nil {
 		return err
 	}
 	for _, f := range append(updateFriends, insertFriends...) {
-		if !friendNameRE.MatchString(f.Name) {
-			return fmt.Errorf("invalid friend name '%v'"+
-				"- can only contain, digits, hyphens, or underscores", f.Name)
+		if err := f.validate(); err != nil {
+			return err
 		}
 	}
 	for deleteFriendID := range previousFriends {
@@ -84,7 +84,16 @@ func (ds Datastore) SaveFriends(st SportType, futureFriends []Friend) error {
 	for _, updateFriend := range updateFriends {
 		t.SetFriend(st, updateFriend.ID, updateFriend.DisplayOrder, updateFriend.Name)
 	}
-	return t.execute()
+	return t.execute(ctx)
+}
+
+// validate checks the name of the friend.
+func (f Friend) validate() error {
+	if !friendNameRE.MatchString(f.Name) {
+		return fmt.Errorf("invalid friend name '%v'"+
+			"- can only contain, digits, hyphens, or underscores", f.Name)
+	}
+	return nil
 }
 
 func (t *sqlTX) DelFriend(st SportType, id ID) {
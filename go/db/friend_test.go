@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -101,7 +102,7 @@ func TestGetFriends(t *testing.T) {
 				},
 			}},
 		}
-		gotSlice, gotErr := ds.GetFriends(test.requestSportType)
+		gotSlice, gotErr := ds.GetFriends(context.Background(), test.requestSportType)
 		switch {
 		case test.wantErr:
 			if gotErr == nil {
@@ -236,7 +237,7 @@ func TestSaveFriends(t *testing.T) {
 			}},
 		}
 		wantErr := test.getFriendsErr != nil || test.executeInTransactionErr != nil || test.wantValidationError
-		gotErr := ds.SaveFriends(test.st, test.futureFriends)
+		gotErr := ds.SaveFriends(context.Background(), test.st, test.futureFriends)
 		hadErr := gotErr != nil
 		if wantErr != hadErr {
 			t.Errorf("Test %v: wanted error %v, got: %v", i, wantErr, gotErr)
@@ -1,31 +1,64 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
+// DateFormat is the yyyy-mm-dd layout of the AddDates and DropDates of Players
+const DateFormat = "2006-01-02"
+
 type (
 	// Player maps a player (of a a specific PlayerType) to a Friend.
+	// The AddDate and DropDate are the effective dates of the transactions that put the player on and took the player off of the Friend's roster.
+	// A nil AddDate means the player was on the roster from the start of the season.  A nil DropDate means the player is still on the roster.
+	// The Name and InfoRefreshTime are from the PlayerInfo of the player.  They are empty if the PlayerInfo has not been saved.
 	Player struct {
-		ID           ID
-		PlayerType   PlayerType
-		SourceID     SourceID
-		FriendID     ID
-		DisplayOrder int
+		ID              ID
+		PlayerType      PlayerType
+		SourceID        SourceID
+		FriendID        ID
+		DisplayOrder    int
+		AddDate         *time.Time
+		DropDate        *time.Time
+		Name            string
+		InfoRefreshTime *time.Time
 	}
 
 	// SourceID is the id used to retrieve information about the player from external sources
 	SourceID int
+
+	// ImportedPlayer is a player added to the roster of the friend with the FriendName.
+	ImportedPlayer struct {
+		PlayerType   PlayerType
+		SourceID     SourceID
+		FriendName   string
+		DisplayOrder int
+	}
 )
 
-// GetPlayers gets the players for the active year for a SportType
-func (ds Datastore) GetPlayers(st SportType) ([]Player, error) {
-	return ds.db.GetPlayers(st)
+// GetPlayers gets the players for the active year for a SportType, with the names from their PlayerInfos.
+func (ds Datastore) GetPlayers(ctx context.Context, st SportType) ([]Player, error) {
+	players, err := ds.db.GetPlayers(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	playerInfos, err := ds.getPlayerInfos(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	for i, player := range players {
+		playerInfo := playerInfos[player.PlayerType][player.SourceID]
+		players[i].Name = playerInfo.Name
+		players[i].InfoRefreshTime = playerInfo.RefreshTime
+	}
+	return players, nil
 }
 
-func (d sqlDB) GetPlayers(st SportType) ([]Player, error) {
-	sqlFunction := newReadSQLFunction("get_players", []string{"id", "player_type_id", "source_id", "friend_id", "display_order"}, st)
-	rs, err := d.db.Query(sqlFunction.sql(), sqlFunction.args...)
+func (d sqlDB) GetPlayers(ctx context.Context, st SportType) ([]Player, error) {
+	sqlFunction := newReadSQLFunction("get_players", []string{"id", "player_type_id", "source_id", "friend_id", "display_order", "add_date", "drop_date"}, st)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return nil, fmt.Errorf("reading players: %w", err)
 	}
@@ -35,7 +68,7 @@ func (d sqlDB) GetPlayers(st SportType) ([]Player, error) {
 	i := 0
 	for rs.Next() {
 		players = append(players, Player{})
-		err = rs.Scan(&players[i].ID, &players[i].PlayerType, &players[i].SourceID, &players[i].FriendID, &players[i].DisplayOrder)
+		err = rs.Scan(&players[i].ID, &players[i].PlayerType, &players[i].SourceID, &players[i].FriendID, &players[i].DisplayOrder, &players[i].AddDate, &players[i].DropDate)
 		if err != nil {
 			return nil, fmt.Errorf("reading player: %w", err)
 		}
@@ -44,9 +77,12 @@ func (d sqlDB) GetPlayers(st SportType) ([]Player, error) {
 	return players, nil
 }
 
-// SavePlayers saves the specified players for the active year for a SportType
-func (ds Datastore) SavePlayers(st SportType, futurePlayers []Player) error {
-	players, err := ds.GetPlayers(st)
+// SavePlayers saves the specified players for the active year for a SportType with the infos of the players in one transaction
+func (ds Datastore) SavePlayers(ctx context.Context, st SportType, futurePlayers []Player, playerInfos []PlayerInfo) error {
+	if err := ds.validatePlayerInfos(st, playerInfos); err != nil {
+		return err
+	}
+	players, err := ds.db.GetPlayers(ctx, st)
 	if err != nil {
 		return err
 	}
@@ -57,7 +93,23 @@ func (ds Datastore) SavePlayers(st SportType, futurePlayers []Player) error {
 
 	insertPlayers := make([]Player, 0, len(futurePlayers))
 	updatePlayers := make([]Player, 0, len(futurePlayers))
+	type rosterKey struct {
+		pt       PlayerType
+		sourceID SourceID
+		friendID ID
+	}
+	rosteredPlayers := make(map[rosterKey]bool, len(futurePlayers))
 	for _, player := range futurePlayers {
+		if err := player.validateDates(); err != nil {
+			return err
+		}
+		if player.DropDate == nil {
+			k := rosterKey{player.PlayerType, player.SourceID, player.FriendID}
+			if rosteredPlayers[k] {
+				return fmt.Errorf("player %v is on the roster of friend %v more than once without being dropped", player.SourceID, player.FriendID)
+			}
+			rosteredPlayers[k] = true
+		}
 		previousPlayer, ok := previousPlayers[player.ID]
 		switch {
 		case !ok:
@@ -66,36 +118,92 @@ func (ds Datastore) SavePlayers(st SportType, futurePlayers []Player) error {
 				return fmt.Errorf("cannot add Player with PlayerType of %v when saving Players of SportType %v: it has a SportType of %v", player.PlayerType, st, ptInfo.SportType)
 			}
 			insertPlayers = append(insertPlayers, player)
-		case player.DisplayOrder != previousPlayer.DisplayOrder: // can only update display order
+		case player.DisplayOrder != previousPlayer.DisplayOrder, // can only update display order and roster dates
+			!sameDate(player.AddDate, previousPlayer.AddDate),
+			!sameDate(player.DropDate, previousPlayer.DropDate):
 			updatePlayers = append(updatePlayers, player)
 		}
 		delete(previousPlayers, player.ID)
 	}
 
-	t, err := ds.db.begin()
+	t, err := ds.db.begin(ctx)
 	if err != nil {
 		return err
 	}
 	for deleteID := range previousPlayers {
 		t.DelPlayer(st, deleteID)
 	}
+	// update before inserting to drop players before they are added again
+	for _, updatePlayer := range updatePlayers {
+		t.SetPlayer(st, updatePlayer.ID, updatePlayer.DisplayOrder, updatePlayer.AddDate, updatePlayer.DropDate)
+	}
 	for _, insertPlayer := range insertPlayers {
-		t.AddPlayer(st, insertPlayer.DisplayOrder, insertPlayer.PlayerType, insertPlayer.SourceID, insertPlayer.FriendID)
+		t.AddPlayer(st, insertPlayer.DisplayOrder, insertPlayer.PlayerType, insertPlayer.SourceID, insertPlayer.FriendID, insertPlayer.AddDate, insertPlayer.DropDate)
 	}
-	for _, updatePlayer := range updatePlayers {
-		t.SetPlayer(st, updatePlayer.ID, updatePlayer.DisplayOrder)
+	ds.setPlayerInfos(t, st, playerInfos)
+	return t.execute(ctx)
+}
+
+// ImportRosters adds the new friends, the imported players, and the infos of the players for the active year of a SportType in one transaction.
+// The imported players can be added to the rosters of the new friends.
+func (ds Datastore) ImportRosters(ctx context.Context, st SportType, newFriends []Friend, importedPlayers []ImportedPlayer, playerInfos []PlayerInfo) error {
+	for _, f := range newFriends {
+		if err := f.validate(); err != nil {
+			return err
+		}
+	}
+	for _, player := range importedPlayers {
+		if ptInfo := ds.playerTypes[player.PlayerType]; ptInfo.SportType != st {
+			return fmt.Errorf("cannot import Player with PlayerType of %v when importing Players of SportType %v: it has a SportType of %v", player.PlayerType, st, ptInfo.SportType)
+		}
+	}
+	if err := ds.validatePlayerInfos(st, playerInfos); err != nil {
+		return err
+	}
+	t, err := ds.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range newFriends {
+		t.AddFriend(st, f.DisplayOrder, f.Name)
+	}
+	for _, player := range importedPlayers {
+		t.AddFriendPlayer(st, player.DisplayOrder, player.PlayerType, player.SourceID, player.FriendName)
+	}
+	ds.setPlayerInfos(t, st, playerInfos)
+	return t.execute(ctx)
+}
+
+func (p Player) validateDates() error {
+	if p.AddDate != nil && p.DropDate != nil && p.DropDate.Before(*p.AddDate) {
+		return fmt.Errorf("player %v cannot be dropped (%v) before being added (%v)", p.ID, p.DropDate.Format(DateFormat), p.AddDate.Format(DateFormat))
+	}
+	return nil
+}
+
+// sameDate determines whether the optional dates are both nil or on the same day
+func sameDate(a, b *time.Time) bool {
+	switch {
+	case a == nil || b == nil:
+		return a == b
+	default:
+		return a.Format(DateFormat) == b.Format(DateFormat)
 	}
-	return t.execute()
 }
 
 func (t *sqlTX) DelPlayer(st SportType, id ID) {
 	t.queries = append(t.queries, newWriteSQLFunction("del_player", id, st))
 }
 
-func (t *sqlTX) AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID) {
-	t.queries = append(t.queries, newWriteSQLFunction("add_player", displayOrder, pt, sourceID, friendID, st))
+func (t *sqlTX) AddPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendID ID, addDate, dropDate *time.Time) {
+	t.queries = append(t.queries, newWriteSQLFunction("add_player", displayOrder, pt, sourceID, friendID, addDate, dropDate, st))
+}
+
+func (t *sqlTX) AddFriendPlayer(st SportType, displayOrder int, pt PlayerType, sourceID SourceID, friendName string) {
+	// the friend is found by name so it can be added in the same transaction
+	t.queries = append(t.queries, newWriteSQLFunction("add_friend_player", displayOrder, pt, sourceID, friendName, st))
 }
 
-func (t *sqlTX) SetPlayer(st SportType, id ID, displayOrder int) {
-	t.queries = append(t.queries, newWriteSQLFunction("set_player", displayOrder, id, st))
+func (t *sqlTX) SetPlayer(st SportType, id ID, displayOrder int, addDate, dropDate *time.Time) {
+	t.queries = append(t.queries, newWriteSQLFunction("set_player", displayOrder, addDate, dropDate, id, st))
 }
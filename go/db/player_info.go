@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	// PlayerInfo describes a player from an external source.
+	// It is saved when the player is added, or when the name of the player is first requested for the stats of players added before infos were saved,
+	// so the name of the player is known without requesting it.
+	// The RefreshTime is when the info was last saved.  It is nil for infos saved before refresh times were kept.
+	PlayerInfo struct {
+		PlayerType  PlayerType
+		SourceID    SourceID
+		Name        string
+		Position    string
+		Team        string
+		RefreshTime *time.Time
+	}
+)
+
+// SavePlayerInfos saves the infos of players for a SportType, replacing previous infos for the same players.
+func (ds Datastore) SavePlayerInfos(ctx context.Context, st SportType, playerInfos []PlayerInfo) error {
+	if err := ds.validatePlayerInfos(st, playerInfos); err != nil {
+		return err
+	}
+	t, err := ds.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	ds.setPlayerInfos(t, st, playerInfos)
+	return t.execute(ctx)
+}
+
+func (ds Datastore) validatePlayerInfos(st SportType, playerInfos []PlayerInfo) error {
+	for _, playerInfo := range playerInfos {
+		if ptInfo := ds.playerTypes[playerInfo.PlayerType]; ptInfo.SportType != st {
+			return fmt.Errorf("cannot save PlayerInfo with PlayerType of %v when saving PlayerInfos of SportType %v: it has a SportType of %v", playerInfo.PlayerType, st, ptInfo.SportType)
+		}
+		if len(playerInfo.Name) == 0 {
+			return fmt.Errorf("name required to save PlayerInfo for player %v", playerInfo.SourceID)
+		}
+	}
+	return nil
+}
+
+// setPlayerInfos adds the infos to the transaction.  The databases set the refresh times of the infos when they are saved.
+func (ds Datastore) setPlayerInfos(t dbTX, st SportType, playerInfos []PlayerInfo) {
+	for _, playerInfo := range playerInfos {
+		t.SetPlayerInfo(st, playerInfo)
+	}
+}
+
+// getPlayerInfos gets the infos of players for a SportType, keyed by PlayerType and SourceID.
+func (ds Datastore) getPlayerInfos(ctx context.Context, st SportType) (map[PlayerType]map[SourceID]PlayerInfo, error) {
+	playerInfos, err := ds.db.GetPlayerInfos(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[PlayerType]map[SourceID]PlayerInfo)
+	for _, playerInfo := range playerInfos {
+		if _, ok := m[playerInfo.PlayerType]; !ok {
+			m[playerInfo.PlayerType] = make(map[SourceID]PlayerInfo)
+		}
+		m[playerInfo.PlayerType][playerInfo.SourceID] = playerInfo
+	}
+	return m, nil
+}
+
+func (d sqlDB) GetPlayerInfos(ctx context.Context, st SportType) ([]PlayerInfo, error) {
+	sqlFunction := newReadSQLFunction("get_player_infos", []string{"player_type_id", "source_id", "name", "position", "team", "refresh_time"}, st)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading player infos: %w", err)
+	}
+	defer rs.Close()
+
+	var playerInfos []PlayerInfo
+	i := 0
+	for rs.Next() {
+		playerInfos = append(playerInfos, PlayerInfo{})
+		err = rs.Scan(&playerInfos[i].PlayerType, &playerInfos[i].SourceID, &playerInfos[i].Name, &playerInfos[i].Position, &playerInfos[i].Team, &playerInfos[i].RefreshTime)
+		if err != nil {
+			return nil, fmt.Errorf("reading player info: %w", err)
+		}
+		i++
+	}
+	return playerInfos, nil
+}
+
+func (t *sqlTX) SetPlayerInfo(st SportType, playerInfo PlayerInfo) {
+	t.queries = append(t.queries, newWriteSQLFunction("set_player_info", playerInfo.PlayerType, playerInfo.SourceID, playerInfo.Name, playerInfo.Position, playerInfo.Team, st))
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSavePlayerInfos(t *testing.T) {
+	savePlayerInfosTests := []struct {
+		st                      SportType
+		playerInfos             []PlayerInfo
+		executeInTransactionErr error
+		wantErr                 bool
+		wantQueryArgs           [][]interface{}
+	}{
+		{},
+		{ // happy path
+			st: 1,
+			playerInfos: []PlayerInfo{
+				{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper", Position: "RF", Team: "PHI"},
+			},
+			wantQueryArgs: [][]interface{}{
+				{PlayerType(2), SourceID(547180), "Bryce Harper", "RF", "PHI", SportType(1)},
+			},
+		},
+		{ // playerType is for wrong SportType
+			st: 1,
+			playerInfos: []PlayerInfo{
+				{PlayerType: 5, SourceID: 2532975, Name: "Russell Wilson"},
+			},
+			wantErr: true,
+		},
+		{ // no name
+			st: 1,
+			playerInfos: []PlayerInfo{
+				{PlayerType: 2, SourceID: 547180},
+			},
+			wantErr: true,
+		},
+		{
+			st: 1,
+			playerInfos: []PlayerInfo{
+				{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper"},
+			},
+			executeInTransactionErr: errors.New("executeInTransaction error"),
+			wantErr:                 true,
+		},
+	}
+	playerTypes := PlayerTypeMap{
+		PlayerType(2): PlayerTypeInfo{SportType: SportType(1)},
+		PlayerType(5): PlayerTypeInfo{SportType: SportType(2)},
+	}
+	for i, test := range savePlayerInfosTests {
+		executeInTransactionFunc := func(queries []writeSQLFunction) {
+			if len(test.wantQueryArgs) != len(queries) {
+				t.Errorf("Test %v: wanted %v queries, got %v", i, len(test.wantQueryArgs), len(queries))
+			}
+			for j, wantQueryArgs := range test.wantQueryArgs {
+				queryArgs := queries[j].args
+				if !reflect.DeepEqual(wantQueryArgs, queryArgs) {
+					t.Errorf("Test %v: query %v args: wanted %v, got %v", i, j, wantQueryArgs, queryArgs)
+				}
+			}
+		}
+		ds := Datastore{
+			db: &sqlDB{db: mockDatabase{
+				BeginFunc: newMockBeginFunc(test.executeInTransactionErr, executeInTransactionFunc),
+			}},
+			playerTypes: playerTypes,
+		}
+		gotErr := ds.SavePlayerInfos(context.Background(), test.st, test.playerInfos)
+		switch {
+		case test.wantErr:
+			if gotErr == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case gotErr != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, gotErr)
+		}
+	}
+}
+
+func TestGetPlayerInfos(t *testing.T) {
+	refreshTime := time.Date(2019, time.June, 2, 6, 0, 0, 0, time.UTC)
+	want := []PlayerInfo{
+		{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper", Position: "RF", Team: "PHI", RefreshTime: &refreshTime},
+	}
+	playerInfoRows := []interface{}{want[0]}
+	d := sqlDB{db: mockDatabase{
+		QueryFunc: func(query string, args ...interface{}) (rows, error) {
+			return newMockRows(playerInfoRows), nil
+		},
+	}}
+	got, err := d.GetPlayerInfos(context.Background(), 1)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
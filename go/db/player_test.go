@@ -1,18 +1,35 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
+// playerRow is a Player as it is read from the database, without the Name from its PlayerInfo
+type playerRow struct {
+	ID           ID
+	PlayerType   PlayerType
+	SourceID     SourceID
+	FriendID     ID
+	DisplayOrder int
+	AddDate      *time.Time
+	DropDate     *time.Time
+}
+
 func TestGetPlayers(t *testing.T) {
+	infoRefreshTime := time.Date(2019, time.June, 2, 6, 0, 0, 0, time.UTC)
 	getPlayersTests := []struct {
 		requestSportType SportType
 		rowsSportType    SportType
 		queryErr         error
 		rows             []interface{}
+		playerInfoRows   []interface{}
+		playerInfosErr   error
 		wantSlice        []Player
 		wantErr          bool
 	}{
@@ -25,7 +42,7 @@ func TestGetPlayers(t *testing.T) {
 			requestSportType: 1,
 			rowsSportType:    2,
 			rows: []interface{}{
-				Player{
+				playerRow{
 					ID:           "1",
 					PlayerType:   1,
 					SourceID:     1,
@@ -37,22 +54,26 @@ func TestGetPlayers(t *testing.T) {
 		{ // happy path
 			requestSportType: 3,
 			rowsSportType:    3,
+			playerInfoRows: []interface{}{
+				PlayerInfo{PlayerType: 3, SourceID: 6, Name: "Bryce Harper", RefreshTime: &infoRefreshTime},
+				PlayerInfo{PlayerType: 2, SourceID: 1, Name: "not for PlayerType 1"},
+			},
 			rows: []interface{}{
-				Player{
+				playerRow{
 					ID:           "1",
 					PlayerType:   1,
 					SourceID:     1,
 					FriendID:     "1",
 					DisplayOrder: 1,
 				},
-				Player{
+				playerRow{
 					ID:           "17",
 					PlayerType:   3,
 					SourceID:     6,
 					FriendID:     "2",
 					DisplayOrder: 3,
 				},
-				Player{
+				playerRow{
 					ID:           "34",
 					PlayerType:   3,
 					SourceID:     4000,
@@ -69,11 +90,13 @@ func TestGetPlayers(t *testing.T) {
 					DisplayOrder: 1,
 				},
 				{
-					ID:           "17",
-					PlayerType:   3,
-					SourceID:     6,
-					FriendID:     "2",
-					DisplayOrder: 3,
+					ID:              "17",
+					PlayerType:      3,
+					SourceID:        6,
+					FriendID:        "2",
+					DisplayOrder:    3,
+					Name:            "Bryce Harper",
+					InfoRefreshTime: &infoRefreshTime,
 				},
 				{
 					ID:           "34",
@@ -84,6 +107,15 @@ func TestGetPlayers(t *testing.T) {
 				},
 			},
 		},
+		{ // player infos error
+			requestSportType: 1,
+			rowsSportType:    1,
+			rows: []interface{}{
+				playerRow{ID: "1", PlayerType: 1, SourceID: 1, FriendID: "1", DisplayOrder: 1},
+			},
+			playerInfosErr: fmt.Errorf("query error"),
+			wantErr:        true,
+		},
 		{ // scan error
 			requestSportType: 1,
 			rowsSportType:    1,
@@ -109,6 +141,9 @@ func TestGetPlayers(t *testing.T) {
 		ds := Datastore{
 			db: &sqlDB{db: mockDatabase{
 				QueryFunc: func(query string, args ...interface{}) (rows, error) {
+					if strings.Contains(query, "get_player_infos") {
+						return newMockRows(test.playerInfoRows), test.playerInfosErr
+					}
 					if test.queryErr != nil {
 						return nil, test.queryErr
 					}
@@ -119,7 +154,7 @@ func TestGetPlayers(t *testing.T) {
 				},
 			}},
 		}
-		gotSlice, gotErr := ds.GetPlayers(test.requestSportType)
+		gotSlice, gotErr := ds.GetPlayers(context.Background(), test.requestSportType)
 		switch {
 		case test.wantErr:
 			if gotErr == nil {
@@ -146,9 +181,14 @@ func TestGetPlayers(t *testing.T) {
 }
 
 func TestSavePlayers(t *testing.T) {
+	var noDate *time.Time
+	june1 := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+	july1 := time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)
+	july1Evening := time.Date(2019, time.July, 1, 19, 5, 0, 0, time.UTC)
 	savePlayersTests := []struct {
 		st                      SportType
 		futurePlayers           []Player
+		playerInfos             []PlayerInfo
 		previousPlayers         []interface{}
 		getPlayersErr           error
 		executeInTransactionErr error
@@ -189,28 +229,28 @@ func TestSavePlayers(t *testing.T) {
 				},
 			},
 			previousPlayers: []interface{}{
-				Player{
+				playerRow{
 					ID:           "29",
 					PlayerType:   1,
 					SourceID:     9,
 					FriendID:     "7",
 					DisplayOrder: 1,
 				},
-				Player{
+				playerRow{
 					ID:           "97",
 					PlayerType:   1,
 					SourceID:     81,
 					FriendID:     "7",
 					DisplayOrder: 2,
 				},
-				Player{
+				playerRow{
 					ID:           "14",
 					PlayerType:   1,
 					SourceID:     13,
 					FriendID:     "4",
 					DisplayOrder: 1,
 				},
-				Player{
+				playerRow{
 					ID:           "63",
 					PlayerType:   3,
 					SourceID:     13,
@@ -220,10 +260,130 @@ func TestSavePlayers(t *testing.T) {
 			},
 			wantQueryArgs: [][]interface{}{
 				{ID("14"), SportType(3)},
-				{1, PlayerType(3), SourceID(477), ID("4"), SportType(3)},
-				{2, ID("29"), SportType(3)},
-				{1, ID("97"), SportType(3)},
+				{2, noDate, noDate, ID("29"), SportType(3)},
+				{1, noDate, noDate, ID("97"), SportType(3)},
+				{1, PlayerType(3), SourceID(477), ID("4"), noDate, noDate, SportType(3)},
+			},
+		},
+		{ // roster dates
+			st: 3,
+			futurePlayers: []Player{
+				{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+					DropDate:     &july1,
+				},
+				{ // same date, different time
+					ID:           "97",
+					PlayerType:   1,
+					SourceID:     81,
+					FriendID:     "7",
+					DisplayOrder: 2,
+					AddDate:      &july1Evening,
+				},
+				{
+					ID:           "98",
+					PlayerType:   1,
+					SourceID:     82,
+					FriendID:     "7",
+					DisplayOrder: 3,
+					AddDate:      &july1,
+				},
+			},
+			previousPlayers: []interface{}{
+				playerRow{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+				},
+				playerRow{
+					ID:           "97",
+					PlayerType:   1,
+					SourceID:     81,
+					FriendID:     "7",
+					DisplayOrder: 2,
+					AddDate:      &july1,
+				},
+			},
+			wantQueryArgs: [][]interface{}{
+				{1, noDate, &july1, ID("29"), SportType(3)},
+				{3, PlayerType(1), SourceID(82), ID("7"), &july1, noDate, SportType(3)},
+			},
+		},
+		{ // dropped player added again
+			st: 3,
+			futurePlayers: []Player{
+				{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+					DropDate:     &june1,
+				},
+				{
+					ID:           "30",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 2,
+					AddDate:      &july1,
+				},
+			},
+			previousPlayers: []interface{}{
+				playerRow{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+				},
+			},
+			wantQueryArgs: [][]interface{}{
+				{1, noDate, &june1, ID("29"), SportType(3)},
+				{2, PlayerType(1), SourceID(9), ID("7"), &july1, noDate, SportType(3)},
+			},
+		},
+		{ // added again without being dropped
+			st: 3,
+			futurePlayers: []Player{
+				{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+				},
+				{
+					ID:           "30",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 2,
+					AddDate:      &july1,
+				},
 			},
+			wantErr: true,
+		},
+		{ // dropped before added
+			st: 3,
+			futurePlayers: []Player{
+				{
+					ID:           "29",
+					PlayerType:   1,
+					SourceID:     9,
+					FriendID:     "7",
+					DisplayOrder: 1,
+					AddDate:      &july1,
+					DropDate:     &june1,
+				},
+			},
+			wantErr: true,
 		},
 		{
 			getPlayersErr: errors.New("getPlayers error"),
@@ -231,6 +391,32 @@ func TestSavePlayers(t *testing.T) {
 		{
 			executeInTransactionErr: errors.New("executeInTransaction error"),
 		},
+		{ // with player infos
+			st: 3,
+			futurePlayers: []Player{
+				{
+					ID:           "66",
+					PlayerType:   3,
+					SourceID:     477,
+					FriendID:     "4",
+					DisplayOrder: 1,
+				},
+			},
+			playerInfos: []PlayerInfo{
+				{PlayerType: 3, SourceID: 477, Name: "Josh Allen", Position: "QB", Team: "BUF"},
+			},
+			wantQueryArgs: [][]interface{}{
+				{1, PlayerType(3), SourceID(477), ID("4"), noDate, noDate, SportType(3)},
+				{PlayerType(3), SourceID(477), "Josh Allen", "QB", "BUF", SportType(3)},
+			},
+		},
+		{ // player info without name
+			st: 3,
+			playerInfos: []PlayerInfo{
+				{PlayerType: 3, SourceID: 477},
+			},
+			wantErr: true,
+		},
 		{ // playerType is for wrong SportType
 			st: 3,
 			futurePlayers: []Player{
@@ -242,7 +428,7 @@ func TestSavePlayers(t *testing.T) {
 				},
 			},
 			wantQueryArgs: [][]interface{}{
-				{1, PlayerType(8), SourceID(87), ID("3")},
+				{1, PlayerType(8), SourceID(87), ID("3"), noDate, noDate},
 			},
 			wantErr: true,
 		},
@@ -254,7 +440,7 @@ func TestSavePlayers(t *testing.T) {
 	}
 	for i, test := range savePlayersTests {
 		executeInTransactionFunc := func(queries []writeSQLFunction) {
-			// delete playerIds, insert players {displayOrder, playerType, sourceID, friendID}, update players {displayOrder, id}
+			// delete playerIds, update players {displayOrder, addDate, dropDate, id}, insert players {displayOrder, playerType, sourceID, friendID, addDate, dropDate}
 			if len(test.wantQueryArgs) != len(queries) {
 				t.Errorf("Test %v: wanted %v queries, got %v", i, len(test.wantQueryArgs), len(queries))
 			}
@@ -278,7 +464,7 @@ func TestSavePlayers(t *testing.T) {
 			playerTypes: playerTypes,
 		}
 		wantErr := test.wantErr || test.getPlayersErr != nil || test.executeInTransactionErr != nil
-		gotErr := ds.SavePlayers(test.st, test.futurePlayers)
+		gotErr := ds.SavePlayers(context.Background(), test.st, test.futurePlayers, test.playerInfos)
 		hadErr := gotErr != nil
 		if wantErr != hadErr {
 			t.Errorf("Test %v: wanted error %v, got: %v", i, wantErr, gotErr)
@@ -291,3 +477,86 @@ func TestSavePlayers(t *testing.T) {
 		}
 	}
 }
+
+func TestImportRosters(t *testing.T) {
+	importRostersTests := []struct {
+		newFriends              []Friend
+		importedPlayers         []ImportedPlayer
+		playerInfos             []PlayerInfo
+		executeInTransactionErr error
+		wantErr                 bool
+		wantQueryNames          []string
+		wantQueryArgs           [][]interface{}
+	}{
+		{},
+		{ // happy path
+			newFriends: []Friend{{DisplayOrder: 2, Name: "Bob"}},
+			importedPlayers: []ImportedPlayer{
+				{PlayerType: 2, SourceID: 547180, FriendName: "Alice", DisplayOrder: 4},
+				{PlayerType: 2, SourceID: 545361, FriendName: "Bob", DisplayOrder: 1},
+			},
+			playerInfos: []PlayerInfo{
+				{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper"},
+			},
+			wantQueryNames: []string{"add_friend", "add_friend_player", "add_friend_player", "set_player_info"},
+			wantQueryArgs: [][]interface{}{
+				{2, "Bob", SportType(1)},
+				{4, PlayerType(2), SourceID(547180), "Alice", SportType(1)},
+				{1, PlayerType(2), SourceID(545361), "Bob", SportType(1)},
+				{PlayerType(2), SourceID(547180), "Bryce Harper", "", "", SportType(1)},
+			},
+		},
+		{ // invalid friend name
+			newFriends: []Friend{{Name: "Bob Jones"}},
+			wantErr:    true,
+		},
+		{ // playerType is for wrong SportType
+			importedPlayers: []ImportedPlayer{{PlayerType: 5, SourceID: 2532975, FriendName: "Alice"}},
+			wantErr:         true,
+		},
+		{ // no name
+			playerInfos: []PlayerInfo{{PlayerType: 2, SourceID: 547180}},
+			wantErr:     true,
+		},
+		{
+			newFriends:              []Friend{{Name: "Bob"}},
+			executeInTransactionErr: errors.New("executeInTransaction error"),
+			wantErr:                 true,
+		},
+	}
+	playerTypes := PlayerTypeMap{
+		PlayerType(2): PlayerTypeInfo{SportType: SportType(1)},
+		PlayerType(5): PlayerTypeInfo{SportType: SportType(2)},
+	}
+	for i, test := range importRostersTests {
+		executeInTransactionFunc := func(queries []writeSQLFunction) {
+			if len(test.wantQueryArgs) != len(queries) {
+				t.Errorf("Test %v: wanted %v queries, got %v", i, len(test.wantQueryArgs), len(queries))
+				return
+			}
+			for j, wantQueryArgs := range test.wantQueryArgs {
+				switch {
+				case !strings.HasPrefix(queries[j].name, "SELECT "+test.wantQueryNames[j]+"("):
+					t.Errorf("Test %v: query %v: wanted %v, got %v", i, j, test.wantQueryNames[j], queries[j].name)
+				case !reflect.DeepEqual(wantQueryArgs, queries[j].args):
+					t.Errorf("Test %v: query %v args: wanted %v, got %v", i, j, wantQueryArgs, queries[j].args)
+				}
+			}
+		}
+		ds := Datastore{
+			db: &sqlDB{db: mockDatabase{
+				BeginFunc: newMockBeginFunc(test.executeInTransactionErr, executeInTransactionFunc),
+			}},
+			playerTypes: playerTypes,
+		}
+		gotErr := ds.ImportRosters(context.Background(), 1, test.newFriends, test.importedPlayers, test.playerInfos)
+		switch {
+		case test.wantErr:
+			if gotErr == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case gotErr != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, gotErr)
+		}
+	}
+}
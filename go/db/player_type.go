@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -31,9 +32,19 @@ const (
 	PlayerTypeNflMisc    PlayerType = 6
 )
 
+// RosterDates determines whether players of the PlayerType can have AddDates and DropDates.
+// Nfl stats are only requested for whole seasons, so nfl players are always on the roster for the whole season.
+func (pt PlayerType) RosterDates() bool {
+	switch pt {
+	case PlayerTypeNflTeam, PlayerTypeNflQB, PlayerTypeNflMisc:
+		return false
+	}
+	return true
+}
+
 // GetPlayerTypes loads the PlayerTypes from the database
-func (ds Datastore) GetPlayerTypes() (PlayerTypeMap, error) {
-	playerTypes, err := ds.db.GetPlayerTypes()
+func (ds Datastore) GetPlayerTypes(ctx context.Context) (PlayerTypeMap, error) {
+	playerTypes, err := ds.db.GetPlayerTypes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -43,9 +54,9 @@ func (ds Datastore) GetPlayerTypes() (PlayerTypeMap, error) {
 	return playerTypes, nil
 }
 
-func (d sqlDB) GetPlayerTypes() (PlayerTypeMap, error) {
+func (d sqlDB) GetPlayerTypes(ctx context.Context) (PlayerTypeMap, error) {
 	sqlFunction := newReadSQLFunction("get_player_types", []string{"id", "sport_type_id", "name", "description", "score_type"})
-	rs, err := d.db.Query(sqlFunction.sql(), sqlFunction.args...)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return nil, fmt.Errorf("reading playerTypes: %w", err)
 	}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -78,7 +79,7 @@ func TestGetPlayerTypes(t *testing.T) {
 				},
 			}},
 		}
-		gotPlayerTypes, gotErr := ds.GetPlayerTypes()
+		gotPlayerTypes, gotErr := ds.GetPlayerTypes(context.Background())
 		switch {
 		case test.wantErr:
 			if gotErr == nil {
@@ -92,3 +93,19 @@ func TestGetPlayerTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestPlayerTypeRosterDates(t *testing.T) {
+	rosterDatesTests := map[PlayerType]bool{
+		PlayerTypeMlbTeam:    true,
+		PlayerTypeMlbHitter:  true,
+		PlayerTypeMlbPitcher: true,
+		PlayerTypeNflTeam:    false,
+		PlayerTypeNflQB:      false,
+		PlayerTypeNflMisc:    false,
+	}
+	for pt, want := range rosterDatesTests {
+		if got := pt.RosterDates(); want != got {
+			t.Errorf("Test %v: wanted %v, got %v", pt, want, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"strconv"
@@ -10,7 +11,7 @@ import (
 func (d sqlDB) getSetupTableQueries(fsys fs.ReadFileFS) ([]string, error) {
 	var queries []string
 	// order of setup files matters - some queries reference others
-	setupFileNames := []string{"users", "sport_types", "stats", "friends", "player_types", "players"}
+	setupFileNames := []string{"users", "sport_types", "stats", "friends", "player_types", "players", "player_infos", "subscriptions"}
 	for _, setupFileName := range setupFileNames {
 		b, err := fsys.ReadFile(fmt.Sprintf("sql/setup/%s.pgsql", setupFileName))
 		if err != nil {
@@ -45,7 +46,7 @@ func (d sqlDB) getSetupFunctionQueries(fsys fs.ReadFileFS) ([]string, error) {
 }
 
 // SetupTablesAndFunctions runs setup scripts to ensure tables are initialized, populated, and re-adds all functions to access/change saved data
-func (d sqlDB) SetupTablesAndFunctions(fsys fs.ReadFileFS) error {
+func (d sqlDB) SetupTablesAndFunctions(ctx context.Context, fsys fs.ReadFileFS) error {
 	setupTableQueries, err := d.getSetupTableQueries(fsys)
 	if err != nil {
 		return err
@@ -55,12 +56,12 @@ func (d sqlDB) SetupTablesAndFunctions(fsys fs.ReadFileFS) error {
 		return err
 	}
 	queries := concat(setupTableQueries, setupFunctionQueries)
-	tx, err := d.db.Begin()
+	tx, err := d.db.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("starting database setup: %w", err)
 	}
 	for _, sql := range queries {
-		if _, err2 := tx.Exec(sql); err2 != nil {
+		if _, err2 := tx.ExecContext(ctx, sql); err2 != nil {
 			err2 = fmt.Errorf("setting: %w\nquery: %v", err2, strings.TrimSpace(sql))
 			rollbackErr := tx.Rollback()
 			if rollbackErr != nil {
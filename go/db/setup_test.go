@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"io/fs"
@@ -16,7 +17,9 @@ var mockValidFS = fstest.MapFS{
 	"sql/setup/friends.pgsql":       &fstest.MapFile{Data: []byte("d")},
 	"sql/setup/player_types.pgsql":  &fstest.MapFile{Data: []byte("e")},
 	"sql/setup/players.pgsql":       &fstest.MapFile{Data: []byte("f")},
-	"sql/functions/add/DUMMY.pgsql": &fstest.MapFile{Data: []byte("g")},
+	"sql/setup/player_infos.pgsql":  &fstest.MapFile{Data: []byte("g")},
+	"sql/setup/subscriptions.pgsql": &fstest.MapFile{Data: []byte("h")},
+	"sql/functions/add/DUMMY.pgsql": &fstest.MapFile{Data: []byte("i")},
 }
 
 func TestSetupTablesAndFunctions(t *testing.T) {
@@ -39,12 +42,14 @@ func TestSetupTablesAndFunctions(t *testing.T) {
 		},
 		{ //  getSetupFunctionQueries error
 			fs: fstest.MapFS{
-				"sql/setup/users.pgsql":        &fstest.MapFile{Data: []byte("a")},
-				"sql/setup/sport_types.pgsql":  &fstest.MapFile{Data: []byte("b")},
-				"sql/setup/stats.pgsql":        &fstest.MapFile{Data: []byte("c")},
-				"sql/setup/friends.pgsql":      &fstest.MapFile{Data: []byte("d")},
-				"sql/setup/player_types.pgsql": &fstest.MapFile{Data: []byte("e")},
-				"sql/setup/players.pgsql":      &fstest.MapFile{Data: []byte("f")},
+				"sql/setup/users.pgsql":         &fstest.MapFile{Data: []byte("a")},
+				"sql/setup/sport_types.pgsql":   &fstest.MapFile{Data: []byte("b")},
+				"sql/setup/stats.pgsql":         &fstest.MapFile{Data: []byte("c")},
+				"sql/setup/friends.pgsql":       &fstest.MapFile{Data: []byte("d")},
+				"sql/setup/player_types.pgsql":  &fstest.MapFile{Data: []byte("e")},
+				"sql/setup/players.pgsql":       &fstest.MapFile{Data: []byte("f")},
+				"sql/setup/player_infos.pgsql":  &fstest.MapFile{Data: []byte("g")},
+				"sql/setup/subscriptions.pgsql": &fstest.MapFile{Data: []byte("h")},
 			},
 		},
 		{
@@ -98,7 +103,7 @@ func TestSetupTablesAndFunctions(t *testing.T) {
 				return tx, nil
 			},
 		}}
-		gotErr := d.SetupTablesAndFunctions(test.fs)
+		gotErr := d.SetupTablesAndFunctions(context.Background(), test.fs)
 		switch {
 		case !test.wantOk:
 			if gotErr == nil {
@@ -113,9 +118,9 @@ func TestSetupTablesAndFunctions(t *testing.T) {
 			if rollbackCalled {
 				t.Errorf("Test %v: rollback called", i)
 			}
-			// 6 setup files, (a-f)
-			// 1 function file (g)
-			wantFuncQueries := "abcdefg"
+			// 8 setup files, (a-h)
+			// 1 function file (i)
+			wantFuncQueries := "abcdefghi"
 			if wantFuncQueries != execFuncQueries { // this will need to be updated every time additional setup query types are added
 				t.Errorf("Test %v: wanted %v queries, got %v", i, wantFuncQueries, execFuncQueries)
 			}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -26,8 +27,8 @@ const (
 )
 
 // GetSportTypes returns the SportTypes from the database
-func (ds Datastore) GetSportTypes() (SportTypeMap, error) {
-	sportTypes, err := ds.db.GetSportTypes()
+func (ds Datastore) GetSportTypes(ctx context.Context) (SportTypeMap, error) {
+	sportTypes, err := ds.db.GetSportTypes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -37,9 +38,9 @@ func (ds Datastore) GetSportTypes() (SportTypeMap, error) {
 	return sportTypes, nil
 }
 
-func (d sqlDB) GetSportTypes() (SportTypeMap, error) {
+func (d sqlDB) GetSportTypes(ctx context.Context) (SportTypeMap, error) {
 	sqlFunction := newReadSQLFunction("get_sport_types", []string{"id", "name", "url"})
-	rs, err := d.db.Query(sqlFunction.sql(), sqlFunction.args...)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return nil, fmt.Errorf("reading sportTypes: %w", err)
 	}
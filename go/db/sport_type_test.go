@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -80,7 +81,7 @@ func TestGetSportTypes(t *testing.T) {
 				},
 			}},
 		}
-		gotSportTypes, gotErr := ds.GetSportTypes()
+		gotSportTypes, gotErr := ds.GetSportTypes(context.Background())
 		switch {
 		case test.wantErr:
 			if gotErr == nil {
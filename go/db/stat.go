@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -9,25 +10,29 @@ import (
 type (
 	// Stat is a wrapper for EtlJSON
 	// It is for a particular year and SportType.  It has an etl timestamp.
+	// The EtlStatusJSON describes when each category in the EtlJSON was last fetched.
+	// Frozen stats are the final stats of a year that is no longer active, so they are not refreshed.
 	Stat struct {
-		SportType    SportType
-		Year         int
-		EtlTimestamp *time.Time
-		EtlJSON      string
+		SportType     SportType
+		Year          int
+		EtlTimestamp  *time.Time
+		EtlJSON       string
+		EtlStatusJSON string
+		Frozen        bool
 	}
 )
 
 // GetStat gets the Stat for the active year, nil if there is not active stat
-func (ds Datastore) GetStat(st SportType) (*Stat, error) {
-	return ds.db.GetStat(st)
+func (ds Datastore) GetStat(ctx context.Context, st SportType) (*Stat, error) {
+	return ds.db.GetStat(ctx, st)
 }
 
-func (d sqlDB) GetStat(st SportType) (*Stat, error) {
+func (d sqlDB) GetStat(ctx context.Context, st SportType) (*Stat, error) {
 	stat := Stat{SportType: st}
-	sqlFunction := newReadSQLFunction("get_stat", []string{"year", "etl_timestamp", "etl_json"}, st)
-	r := d.db.QueryRow(sqlFunction.sql(), sqlFunction.args...)
-	var etlJSON sql.NullString
-	err := r.Scan(&stat.Year, &stat.EtlTimestamp, &etlJSON)
+	sqlFunction := newReadSQLFunction("get_stat", []string{"year", "etl_timestamp", "etl_json", "etl_status_json", "etl_frozen"}, st)
+	r := d.db.QueryRowContext(ctx, sqlFunction.sql(), sqlFunction.args...)
+	var etlJSON, etlStatusJSON sql.NullString
+	err := r.Scan(&stat.Year, &stat.EtlTimestamp, &etlJSON, &etlStatusJSON, &stat.Frozen)
 	if err != nil {
 		if d.IsNotExist(err) {
 			return nil, nil
@@ -37,31 +42,61 @@ func (d sqlDB) GetStat(st SportType) (*Stat, error) {
 	if etlJSON.Valid {
 		stat.EtlJSON = etlJSON.String
 	}
+	if etlStatusJSON.Valid {
+		stat.EtlStatusJSON = etlStatusJSON.String
+	}
+	return &stat, nil
+}
+
+// GetYearStat gets the Stat for the year, nil if the year has not been saved
+func (ds Datastore) GetYearStat(ctx context.Context, st SportType, year int) (*Stat, error) {
+	return ds.db.GetYearStat(ctx, st, year)
+}
+
+func (d sqlDB) GetYearStat(ctx context.Context, st SportType, year int) (*Stat, error) {
+	stat := Stat{SportType: st, Year: year}
+	sqlFunction := newReadSQLFunction("get_year_stat", []string{"etl_timestamp", "etl_json", "etl_status_json", "etl_frozen"}, st, year)
+	r := d.db.QueryRowContext(ctx, sqlFunction.sql(), sqlFunction.args...)
+	var etlJSON, etlStatusJSON sql.NullString
+	err := r.Scan(&stat.EtlTimestamp, &etlJSON, &etlStatusJSON, &stat.Frozen)
+	if err != nil {
+		if d.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting stats for %v: %w", year, err)
+	}
+	if etlJSON.Valid {
+		stat.EtlJSON = etlJSON.String
+	}
+	if etlStatusJSON.Valid {
+		stat.EtlStatusJSON = etlStatusJSON.String
+	}
 	return &stat, nil
 }
 
-// SetStat sets the etl timestamp and json for the year (which must be active)
-func (ds Datastore) SetStat(stat Stat) error {
-	return ds.db.SetStat(stat)
+// SetStat sets the etl timestamp, json, and status json for the year (which must be active)
+func (ds Datastore) SetStat(ctx context.Context, stat Stat) error {
+	return ds.db.SetStat(ctx, stat)
 }
 
-func (d *sqlDB) SetStat(stat Stat) error {
-	sqlFunction := newWriteSQLFunction("set_stat", stat.EtlTimestamp, stat.EtlJSON, stat.SportType, stat.Year)
-	result, err := d.db.Exec(sqlFunction.sql(), sqlFunction.args...)
+func (d *sqlDB) SetStat(ctx context.Context, stat Stat) error {
+	sqlFunction := newWriteSQLFunction("set_stat", stat.EtlTimestamp, stat.EtlJSON, stat.EtlStatusJSON, stat.SportType, stat.Year)
+	result, err := d.db.ExecContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return fmt.Errorf("saving stats: %w", err)
 	}
 	return expectSingleRowAffected(result)
 }
 
-// ClearStat clears the stats for the active year
-func (ds Datastore) ClearStat(st SportType) error {
-	return ds.db.ClrStat(st)
+// ClearStat marks the stats for the active year as stale, unfreezing them so they are refreshed.
+// The last stats are kept so categories that cannot be refreshed can still be shown.
+func (ds Datastore) ClearStat(ctx context.Context, st SportType) error {
+	return ds.db.ClrStat(ctx, st)
 }
 
-func (d *sqlDB) ClrStat(st SportType) error {
+func (d *sqlDB) ClrStat(ctx context.Context, st SportType) error {
 	sqlFunction := newWriteSQLFunction("clr_stat", st)
-	_, err := d.db.Exec(sqlFunction.sql(), sqlFunction.args...)
+	_, err := d.db.ExecContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return fmt.Errorf("clearing saved stats: %w", err)
 	}
@@ -1,8 +1,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -30,9 +32,11 @@ var (
 			requestSportType: 8,
 			rowSportType:     8,
 			row: struct {
-				Year         int
-				EtlTimestamp *time.Time
-				EtlJSON      string
+				Year          int
+				EtlTimestamp  *time.Time
+				EtlJSON       string
+				EtlStatusJSON string
+				EtlFrozen     bool
 			}{
 				Year:         2019,
 				EtlTimestamp: &testTime,
@@ -48,9 +52,11 @@ var (
 			requestSportType: 8,
 			rowSportType:     8,
 			row: struct {
-				Year         int
-				EtlTimestamp *time.Time
-				EtlJSON      *sql.NullString
+				Year          int
+				EtlTimestamp  *time.Time
+				EtlJSON       *sql.NullString
+				EtlStatusJSON *sql.NullString
+				EtlFrozen     bool
 			}{
 				Year:         2019,
 				EtlTimestamp: &testTime,
@@ -66,9 +72,11 @@ var (
 			requestSportType: 8,
 			rowSportType:     8,
 			row: struct {
-				Year         int
-				EtlTimestamp *time.Time
-				EtlJSON      string
+				Year          int
+				EtlTimestamp  *time.Time
+				EtlJSON       string
+				EtlStatusJSON string
+				EtlFrozen     bool
 			}{
 				Year:         2019,
 				EtlTimestamp: &testTime,
@@ -85,9 +93,11 @@ var (
 			requestSportType: 8,
 			rowSportType:     8,
 			row: struct {
-				Year         int
-				EtlTimestamp *time.Time
-				EtlJSON      *sql.NullString
+				Year          int
+				EtlTimestamp  *time.Time
+				EtlJSON       *sql.NullString
+				EtlStatusJSON *sql.NullString
+				EtlFrozen     bool
 			}{
 				Year:         2019,
 				EtlTimestamp: &testTime,
@@ -95,12 +105,19 @@ var (
 					String: "[42]",
 					Valid:  true,
 				},
+				EtlStatusJSON: &sql.NullString{
+					String: "[43]",
+					Valid:  true,
+				},
+				EtlFrozen: true,
 			},
 			wantStat: &Stat{
-				SportType:    8,
-				Year:         2019,
-				EtlTimestamp: &testTime,
-				EtlJSON:      "[42]",
+				SportType:     8,
+				Year:          2019,
+				EtlTimestamp:  &testTime,
+				EtlJSON:       "[42]",
+				EtlStatusJSON: "[43]",
+				Frozen:        true,
 			},
 		},
 	}
@@ -127,7 +144,7 @@ func TestGetStat(t *testing.T) {
 				},
 			},
 		}}
-		gotStat, gotErr := ds.GetStat(test.requestSportType)
+		gotStat, gotErr := ds.GetStat(context.Background(), test.requestSportType)
 		switch {
 		case test.wantErr:
 			switch {
@@ -157,6 +174,78 @@ func TestGetStat(t *testing.T) {
 	}
 }
 
+func TestGetYearStat(t *testing.T) {
+	getYearStatTests := []struct {
+		queryRowErr error
+		row         interface{}
+		wantStat    *Stat
+		wantErr     bool
+	}{
+		{ // year not saved
+		},
+		{
+			queryRowErr: errors.New("queryRow error"),
+			wantErr:     true,
+		},
+		{
+			row: struct {
+				EtlTimestamp  *time.Time
+				EtlJSON       *sql.NullString
+				EtlStatusJSON *sql.NullString
+				EtlFrozen     bool
+			}{
+				EtlTimestamp: &testTime,
+				EtlJSON: &sql.NullString{
+					String: "[42]",
+					Valid:  true,
+				},
+				EtlFrozen: true,
+			},
+			wantStat: &Stat{
+				SportType:    3,
+				Year:         2017,
+				EtlTimestamp: &testTime,
+				EtlJSON:      "[42]",
+				Frozen:       true,
+			},
+		},
+	}
+	for i, test := range getYearStatTests {
+		ds := Datastore{db: &sqlDB{
+			db: mockDatabase{
+				QueryRowFunc: func(query string, args ...interface{}) row {
+					if want := []interface{}{SportType(3), 2017}; !reflect.DeepEqual(want, args) {
+						t.Errorf("Test %v: wanted args %v, got %v", i, want, args)
+					}
+					return mockRow{
+						ScanFunc: func(dest ...interface{}) error {
+							switch {
+							case test.queryRowErr != nil:
+								return test.queryRowErr
+							case test.row == nil:
+								return sql.ErrNoRows
+							default:
+								return mockRowScanFunc(test.row, dest...)
+							}
+						},
+					}
+				},
+			},
+		}}
+		gotStat, gotErr := ds.GetYearStat(context.Background(), 3, 2017)
+		switch {
+		case test.wantErr:
+			if !errors.Is(gotErr, test.queryRowErr) {
+				t.Errorf("Test %v, wanted error with %v, but got %v", i, test.queryRowErr, gotErr)
+			}
+		case gotErr != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, gotErr)
+		case !reflect.DeepEqual(test.wantStat, gotStat):
+			t.Errorf("Test %v: stats not equal:\nwanted: %v\ngot:    %v", i, test.wantStat, gotStat)
+		}
+	}
+}
+
 func TestSetStat(t *testing.T) {
 	setStatTests := []struct {
 		stat         Stat
@@ -191,7 +280,7 @@ func TestSetStat(t *testing.T) {
 				},
 			},
 		}}
-		gotErr := ds.SetStat(test.stat)
+		gotErr := ds.SetStat(context.Background(), test.stat)
 		switch {
 		case test.wantErr:
 			switch {
@@ -226,7 +315,7 @@ func TestClearStat(t *testing.T) {
 				},
 			},
 		}}
-		gotErr := ds.ClearStat(test.st)
+		gotErr := ds.ClearStat(context.Background(), test.st)
 		switch {
 		case test.wantErr:
 			switch {
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+)
+
+// Subscription is the email address a friend of the active year is sent digests of the standings at.
+type Subscription struct {
+	FriendID ID
+	Email    string
+}
+
+// GetSubscriptions gets the subscriptions of the friends of the active year for a SportType
+func (ds Datastore) GetSubscriptions(ctx context.Context, st SportType) ([]Subscription, error) {
+	return ds.db.GetSubscriptions(ctx, st)
+}
+
+func (d sqlDB) GetSubscriptions(ctx context.Context, st SportType) ([]Subscription, error) {
+	sqlFunction := newReadSQLFunction("get_subscriptions", []string{"friend_id", "email"}, st)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading subscriptions: %w", err)
+	}
+	defer rs.Close()
+
+	var subscriptions []Subscription
+	i := 0
+	for rs.Next() {
+		subscriptions = append(subscriptions, Subscription{})
+		err = rs.Scan(&subscriptions[i].FriendID, &subscriptions[i].Email)
+		if err != nil {
+			return nil, fmt.Errorf("reading subscription: %w", err)
+		}
+		i++
+	}
+	return subscriptions, nil
+}
+
+// SaveSubscriptions saves the subscriptions of the friends of the active year for a SportType.
+// Friends that do not have future subscriptions are unsubscribed.
+func (ds Datastore) SaveSubscriptions(ctx context.Context, st SportType, futureSubscriptions []Subscription) error {
+	subscriptions, err := ds.GetSubscriptions(ctx, st)
+	if err != nil {
+		return err
+	}
+	previousSubscriptions := make(map[ID]Subscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		previousSubscriptions[subscription.FriendID] = subscription
+	}
+
+	setSubscriptions := make([]Subscription, 0, len(futureSubscriptions))
+	for _, subscription := range futureSubscriptions {
+		if err := subscription.validate(); err != nil {
+			return err
+		}
+		previousSubscription, ok := previousSubscriptions[subscription.FriendID]
+		if !ok || subscription.Email != previousSubscription.Email {
+			setSubscriptions = append(setSubscriptions, subscription)
+		}
+		delete(previousSubscriptions, subscription.FriendID)
+	}
+
+	t, err := ds.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	for deleteFriendID := range previousSubscriptions {
+		t.DelSubscription(st, deleteFriendID)
+	}
+	for _, setSubscription := range setSubscriptions {
+		t.SetSubscription(st, setSubscription)
+	}
+	return t.execute(ctx)
+}
+
+// validate checks the friend and email address of the subscription.
+func (s Subscription) validate() error {
+	if len(s.FriendID) == 0 {
+		return fmt.Errorf("friend required for subscription of %v", s.Email)
+	}
+	a, err := mail.ParseAddress(s.Email)
+	if err != nil || a.Address != s.Email {
+		return fmt.Errorf("invalid email address '%v' for friend %v", s.Email, s.FriendID)
+	}
+	return nil
+}
+
+func (t *sqlTX) SetSubscription(st SportType, subscription Subscription) {
+	t.queries = append(t.queries, newWriteSQLFunction("set_subscription", subscription.FriendID, subscription.Email, st))
+}
+
+func (t *sqlTX) DelSubscription(st SportType, friendID ID) {
+	t.queries = append(t.queries, newWriteSQLFunction("del_subscription", friendID, st))
+}
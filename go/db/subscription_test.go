@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetSubscriptions(t *testing.T) {
+	getSubscriptionsTests := []struct {
+		queryErr error
+		rows     []interface{}
+		want     []Subscription
+		wantErr  bool
+	}{
+		{},
+		{
+			queryErr: errors.New("query error"),
+			wantErr:  true,
+		},
+		{ // happy path
+			rows: []interface{}{
+				Subscription{FriendID: "1", Email: "alfred@example.com"},
+				Subscription{FriendID: "4", Email: "earl@example.com"},
+			},
+			want: []Subscription{
+				{FriendID: "1", Email: "alfred@example.com"},
+				{FriendID: "4", Email: "earl@example.com"},
+			},
+		},
+	}
+	for i, test := range getSubscriptionsTests {
+		d := sqlDB{db: mockDatabase{
+			QueryFunc: func(query string, args ...interface{}) (rows, error) {
+				if len(args) != 1 || !reflect.DeepEqual(SportType(2), args[0]) {
+					t.Errorf("Test %v: wanted to get subscriptions for SportType 2, but got %v", i, args)
+				}
+				return newMockRows(test.rows), test.queryErr
+			},
+		}}
+		got, err := d.GetSubscriptions(context.Background(), 2)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestSaveSubscriptions(t *testing.T) {
+	saveSubscriptionsTests := []struct {
+		futureSubscriptions     []Subscription
+		previousSubscriptions   []interface{}
+		getSubscriptionsErr     error
+		executeInTransactionErr error
+		wantQueryArgs           [][]interface{}
+		wantErr                 bool
+	}{
+		{},
+		{ // happy path
+			futureSubscriptions: []Subscription{
+				{FriendID: "8", Email: "bobby@example.com"},
+				{FriendID: "7", Email: "curt@example.com"},
+				{FriendID: "5", Email: "jeb@example.com"},
+			},
+			previousSubscriptions: []interface{}{
+				Subscription{FriendID: "1", Email: "alfred@example.com"},
+				Subscription{FriendID: "8", Email: "bob@example.com"},
+				Subscription{FriendID: "5", Email: "jeb@example.com"},
+			},
+			wantQueryArgs: [][]interface{}{
+				{ID("1"), SportType(9)}, // alfred
+				{ID("8"), "bobby@example.com", SportType(9)},
+				{ID("7"), "curt@example.com", SportType(9)},
+			},
+		},
+		{ // named addresses are not allowed
+			futureSubscriptions: []Subscription{{FriendID: "8", Email: "Bob <bob@example.com>"}},
+			wantErr:             true,
+		},
+		{
+			futureSubscriptions: []Subscription{{FriendID: "8", Email: "bob"}},
+			wantErr:             true,
+		},
+		{
+			futureSubscriptions: []Subscription{{Email: "bob@example.com"}},
+			wantErr:             true,
+		},
+		{
+			getSubscriptionsErr: errors.New("getSubscriptions error"),
+			wantErr:             true,
+		},
+		{
+			executeInTransactionErr: errors.New("executeInTransaction error"),
+			wantErr:                 true,
+		},
+	}
+	for i, test := range saveSubscriptionsTests {
+		executeInTransactionFunc := func(queries []writeSQLFunction) {
+			// delete subscriptions {friendID}, set subscriptions {friendID, email}
+			if len(test.wantQueryArgs) != len(queries) {
+				t.Errorf("Test %v: wanted %v queries, got %v", i, len(test.wantQueryArgs), len(queries))
+			}
+			for j, wantQueryArgs := range test.wantQueryArgs {
+				queryArgs := queries[j].args
+				if !reflect.DeepEqual(wantQueryArgs, queryArgs) {
+					t.Errorf("Test %v: query %v args: wanted %v, got %v", i, j, wantQueryArgs, queryArgs)
+				}
+			}
+		}
+		ds := Datastore{
+			db: &sqlDB{db: mockDatabase{
+				QueryFunc: func(query string, args ...interface{}) (rows, error) {
+					return newMockRows(test.previousSubscriptions), test.getSubscriptionsErr
+				},
+				BeginFunc: newMockBeginFunc(test.executeInTransactionErr, executeInTransactionFunc),
+			}},
+		}
+		gotErr := ds.SaveSubscriptions(context.Background(), 9, test.futureSubscriptions)
+		switch {
+		case test.wantErr:
+			if gotErr == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case gotErr != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, gotErr)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -22,13 +23,13 @@ type (
 var whitespaceRE = regexp.MustCompile(`\s`)
 
 // getUserPassword gets the password for the specified user
-func (ds Datastore) getUserPassword(username string) (string, error) {
-	return ds.db.GetUserPassword(username)
+func (ds Datastore) getUserPassword(ctx context.Context, username string) (string, error) {
+	return ds.db.GetUserPassword(ctx, username)
 }
 
-func (d sqlDB) GetUserPassword(username string) (string, error) {
+func (d sqlDB) GetUserPassword(ctx context.Context, username string) (string, error) {
 	sqlFunction := newReadSQLFunction("get_user_password", []string{"password"}, username)
-	r := d.db.QueryRow(sqlFunction.sql(), sqlFunction.args...)
+	r := d.db.QueryRowContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	var password string
 	err := r.Scan(&password)
 	if err != nil {
@@ -38,7 +39,7 @@ func (d sqlDB) GetUserPassword(username string) (string, error) {
 }
 
 // SetUserPassword gets the password for the specified user
-func (ds Datastore) SetUserPassword(username string, p Password) error {
+func (ds Datastore) SetUserPassword(ctx context.Context, username string, p Password) error {
 	if err := p.validate(); err != nil {
 		return err
 	}
@@ -46,12 +47,12 @@ func (ds Datastore) SetUserPassword(username string, p Password) error {
 	if err != nil {
 		return err
 	}
-	return ds.db.SetUserPassword(username, hashedPassword)
+	return ds.db.SetUserPassword(ctx, username, hashedPassword)
 }
 
-func (d *sqlDB) SetUserPassword(username, hashedPassword string) error {
+func (d *sqlDB) SetUserPassword(ctx context.Context, username, hashedPassword string) error {
 	sqlFunction := newWriteSQLFunction("set_user_password", username, hashedPassword)
-	result, err := d.db.Exec(sqlFunction.sql(), sqlFunction.args...)
+	result, err := d.db.ExecContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return fmt.Errorf("setting user password: %w", err)
 	}
@@ -59,7 +60,7 @@ func (d *sqlDB) SetUserPassword(username, hashedPassword string) error {
 }
 
 // AddUser creates a user with the specified username and password
-func (ds Datastore) AddUser(username string, p Password) error {
+func (ds Datastore) AddUser(ctx context.Context, username string, p Password) error {
 	if err := p.validate(); err != nil {
 		return err
 	}
@@ -67,12 +68,12 @@ func (ds Datastore) AddUser(username string, p Password) error {
 	if err != nil {
 		return err
 	}
-	return ds.db.AddUser(username, hashedPassword)
+	return ds.db.AddUser(ctx, username, hashedPassword)
 }
 
-func (d *sqlDB) AddUser(username, hashedPassword string) error {
+func (d *sqlDB) AddUser(ctx context.Context, username, hashedPassword string) error {
 	sqlFunction := newWriteSQLFunction("add_user", username, hashedPassword)
-	result, err := d.db.Exec(sqlFunction.sql(), sqlFunction.args...)
+	result, err := d.db.ExecContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return fmt.Errorf("adding user: %w", err)
 	}
@@ -80,8 +81,8 @@ func (d *sqlDB) AddUser(username, hashedPassword string) error {
 }
 
 // IsCorrectUserPassword determines whether the password for the user is correct
-func (ds Datastore) IsCorrectUserPassword(username string, p Password) (bool, error) {
-	hashedPassword, err := ds.getUserPassword(username)
+func (ds Datastore) IsCorrectUserPassword(ctx context.Context, username string, p Password) (bool, error) {
+	hashedPassword, err := ds.getUserPassword(ctx, username)
 	if err != nil {
 		return false, err
 	}
@@ -90,14 +91,14 @@ func (ds Datastore) IsCorrectUserPassword(username string, p Password) (bool, er
 
 // SetAdminPassword sets the admin password
 // If the admin user does not exist, it is created.
-func (ds Datastore) SetAdminPassword(p Password) error {
+func (ds Datastore) SetAdminPassword(ctx context.Context, p Password) error {
 	username := "admin"
-	_, err := ds.getUserPassword(username)
+	_, err := ds.getUserPassword(ctx, username)
 	switch {
 	case err == nil: // user exists
-		return ds.SetUserPassword(username, p)
+		return ds.SetUserPassword(ctx, username, p)
 	case ds.db.IsNotExist(err):
-		return ds.AddUser(username, p)
+		return ds.AddUser(ctx, username, p)
 	default: // problem checking if user exists
 		return err
 	}
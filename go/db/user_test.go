@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -56,7 +57,7 @@ func TestGetUserPassword(t *testing.T) {
 				},
 			}},
 		}
-		gotPassword, gotErr := ds.getUserPassword(test.username)
+		gotPassword, gotErr := ds.getUserPassword(context.Background(), test.username)
 		switch {
 		case gotErr != nil:
 			if !errors.Is(gotErr, test.queryRowErr) {
@@ -77,7 +78,7 @@ func TestAddUser(t *testing.T) {
 	userExecuteHelperTest(t, Datastore.AddUser)
 }
 
-func userExecuteHelperTest(t *testing.T, testFunc func(Datastore, string, Password) error) {
+func userExecuteHelperTest(t *testing.T, testFunc func(Datastore, context.Context, string, Password) error) {
 	userExecuteTests := []struct {
 		username     string
 		p            Password
@@ -132,7 +133,7 @@ func userExecuteHelperTest(t *testing.T, testFunc func(Datastore, string, Passwo
 				},
 			},
 		}
-		gotErr := testFunc(ds, test.username, test.p)
+		gotErr := testFunc(ds, context.Background(), test.username, test.p)
 		switch {
 		case test.wantErr:
 			switch {
@@ -196,7 +197,7 @@ func TestIsCorrectUserPassword(t *testing.T) {
 				},
 			},
 		}
-		gotBool, gotErr := ds.IsCorrectUserPassword(test.username, test.p)
+		gotBool, gotErr := ds.IsCorrectUserPassword(context.Background(), test.username, test.p)
 		switch {
 		case test.wantErr:
 			switch {
@@ -283,7 +284,7 @@ func TestSetAdminPassword(t *testing.T) {
 				},
 			},
 		}
-		gotErr := ds.SetAdminPassword(test.p)
+		gotErr := ds.SetAdminPassword(context.Background(), test.p)
 		switch {
 		case test.getUserPasswordFuncErr == nil:
 			if !errors.Is(gotErr, test.setUserPasswordFuncErr) {
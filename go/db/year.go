@@ -1,23 +1,34 @@
 package db
 
 import (
+	"context"
 	"fmt"
 )
 
-// Year contains a year that has been set for stats and whether it is active
-type Year struct {
-	Value  int
-	Active bool
-}
+type (
+	// Year contains a year that has been set for stats and whether it is active
+	Year struct {
+		Value  int
+		Active bool
+	}
+
+	// YearRollover copies the friends of a previous year to a year that is being added.
+	// If Keepers is true, the players that were not dropped from the rosters of the friends are also copied, without their roster dates.
+	YearRollover struct {
+		FromYear int
+		ToYear   int
+		Keepers  bool
+	}
+)
 
 // GetYears gets years for a SportType
-func (ds Datastore) GetYears(st SportType) ([]Year, error) {
-	return ds.db.GetYears(st)
+func (ds Datastore) GetYears(ctx context.Context, st SportType) ([]Year, error) {
+	return ds.db.GetYears(ctx, st)
 }
 
-func (d sqlDB) GetYears(st SportType) ([]Year, error) {
+func (d sqlDB) GetYears(ctx context.Context, st SportType) ([]Year, error) {
 	sqlFunction := newReadSQLFunction("get_years", []string{"year", "active"}, st)
-	rs, err := d.db.Query(sqlFunction.sql(), sqlFunction.args...)
+	rs, err := d.db.QueryContext(ctx, sqlFunction.sql(), sqlFunction.args...)
 	if err != nil {
 		return nil, fmt.Errorf("reading years: %w", err)
 	}
@@ -46,34 +57,47 @@ func (d sqlDB) GetYears(st SportType) ([]Year, error) {
 }
 
 // SaveYears saves the specified years and sets the active year for a SportType
-func (ds Datastore) SaveYears(st SportType, futureYears []Year) error {
-	previousYears, err := ds.GetYears(st)
+// The rosters of new years are copied from previous years by the rollovers.
+// The stats of the previous active year are frozen if it is kept but is no longer active.
+// The stats of a kept year that is made active again are unfrozen.
+func (ds Datastore) SaveYears(ctx context.Context, st SportType, futureYears []Year, rollovers ...YearRollover) error {
+	previousYears, err := ds.GetYears(ctx, st)
 	if err != nil {
 		return err
 	}
 	previousYearsMap := make(map[int]bool, len(previousYears))
+	var previousActiveYear int
 	for _, year := range previousYears {
 		previousYearsMap[year.Value] = true
+		if year.Active {
+			previousActiveYear = year.Value
+		}
 	}
 
 	insertYears := make([]int, 0, len(futureYears))
 	var activeYear int
 	activeYearPresent := false
+	unfreezeActiveYear := false
 	for _, year := range futureYears {
+		_, previousYear := previousYearsMap[year.Value]
 		if year.Active {
 			if activeYearPresent {
 				return fmt.Errorf("multiple active years present in %v", futureYears)
 			}
 			activeYear = year.Value
 			activeYearPresent = true
+			unfreezeActiveYear = previousYear && year.Value != previousActiveYear
 		}
-		if _, ok := previousYearsMap[year.Value]; !ok {
+		if !previousYear {
 			insertYears = append(insertYears, year.Value)
 		}
 		delete(previousYearsMap, year.Value)
 	}
+	if err := validateYearRollovers(rollovers, previousYears, previousYearsMap, insertYears); err != nil {
+		return err
+	}
 
-	t, err := ds.db.begin()
+	t, err := ds.db.begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -82,13 +106,46 @@ func (ds Datastore) SaveYears(st SportType, futureYears []Year) error {
 	for deleteYear := range previousYearsMap {
 		t.DelYear(st, deleteYear)
 	}
+	if previousActiveYear != 0 && !previousYearsMap[previousActiveYear] && (!activeYearPresent || activeYear != previousActiveYear) {
+		t.SetStatFrozen(st, previousActiveYear)
+	}
 	for _, insertYear := range insertYears {
 		t.AddYear(st, insertYear)
 	}
+	for _, rollover := range rollovers {
+		t.AddRosters(st, rollover.FromYear, rollover.ToYear, rollover.Keepers)
+	}
 	if activeYearPresent {
 		t.SetYearActive(st, activeYear)
 	}
-	return t.execute()
+	if unfreezeActiveYear {
+		t.ClrStatFrozen(st, activeYear)
+	}
+	return t.execute(ctx)
+}
+
+// validateYearRollovers ensures the rosters of each year that is being added are copied from at most one year that is being kept.
+func validateYearRollovers(rollovers []YearRollover, previousYears []Year, deleteYears map[int]bool, insertYears []int) error {
+	fromYears := make(map[int]bool, len(previousYears))
+	for _, year := range previousYears {
+		if !deleteYears[year.Value] {
+			fromYears[year.Value] = true
+		}
+	}
+	toYears := make(map[int]bool, len(insertYears))
+	for _, year := range insertYears {
+		toYears[year] = true
+	}
+	for _, rollover := range rollovers {
+		switch {
+		case !fromYears[rollover.FromYear]:
+			return fmt.Errorf("cannot roll over rosters from %v: it is not a saved year", rollover.FromYear)
+		case !toYears[rollover.ToYear]:
+			return fmt.Errorf("cannot roll over rosters to %v: it is not a new year", rollover.ToYear)
+		}
+		delete(toYears, rollover.ToYear)
+	}
+	return nil
 }
 
 func (t *sqlTX) ClrYearActive(st SportType) {
@@ -106,3 +163,15 @@ func (t *sqlTX) AddYear(st SportType, insertYear int) {
 func (t *sqlTX) SetYearActive(st SportType, activeYear int) {
 	t.queries = append(t.queries, newWriteSQLFunction("set_year_active", st, activeYear))
 }
+
+func (t *sqlTX) SetStatFrozen(st SportType, year int) {
+	t.queries = append(t.queries, newWriteSQLFunction("set_stat_frozen", st, year))
+}
+
+func (t *sqlTX) ClrStatFrozen(st SportType, year int) {
+	t.queries = append(t.queries, newWriteSQLFunction("clr_stat_frozen", st, year))
+}
+
+func (t *sqlTX) AddRosters(st SportType, fromYear, toYear int, keepers bool) {
+	t.queries = append(t.queries, newWriteSQLFunction("add_rosters", fromYear, toYear, keepers, st))
+}
@@ -1,10 +1,14 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
 )
 
 var getYearsTests = []struct {
@@ -108,7 +112,7 @@ func TestGetYears(t *testing.T) {
 				},
 			},
 		}}
-		gotSlice, gotErr := ds.GetYears(test.requestSportType)
+		gotSlice, gotErr := ds.GetYears(context.Background(), test.requestSportType)
 		switch {
 		case test.wantErr:
 			if gotErr == nil {
@@ -137,6 +141,7 @@ func TestGetYears(t *testing.T) {
 var saveYearsTests = []struct {
 	st                      SportType
 	futureYears             []Year
+	rollovers               []YearRollover
 	previousYears           []interface{}
 	getYearsErr             error
 	executeInTransactionErr error
@@ -169,7 +174,7 @@ var saveYearsTests = []struct {
 				Active: true,
 			},
 		},
-		wantQueryYears: []int{2017, 2019, 2020, 2019},
+		wantQueryYears: []int{2017, 2018, 2019, 2020, 2019}, // 2018 is frozen because it is no longer active
 	},
 	{
 		getYearsErr: errors.New("getYears error"),
@@ -190,6 +195,160 @@ var saveYearsTests = []struct {
 		},
 		wantErr: true,
 	},
+	{ // rollover rosters of the active year to a new year
+		futureYears: []Year{
+			{
+				Value: 2018,
+			},
+			{
+				Value:  2019,
+				Active: true,
+			},
+		},
+		rollovers: []YearRollover{
+			{
+				FromYear: 2018,
+				ToYear:   2019,
+				Keepers:  true,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value:  2018,
+				Active: true,
+			},
+		},
+		wantQueryYears: []int{2018, 2019, 2019, 2019},
+	},
+	{ // active year not changed: not frozen
+		futureYears: []Year{
+			{
+				Value:  2018,
+				Active: true,
+			},
+			{
+				Value: 2019,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value:  2018,
+				Active: true,
+			},
+		},
+		wantQueryYears: []int{2019, 2018},
+	},
+	{ // previous year made active again: unfrozen
+		futureYears: []Year{
+			{
+				Value:  2017,
+				Active: true,
+			},
+			{
+				Value: 2018,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value: 2017,
+			},
+			Year{
+				Value:  2018,
+				Active: true,
+			},
+		},
+		wantQueryYears: []int{2018, 2017, 2017}, // 2018 is frozen, 2017 is made active and unfrozen
+	},
+	{ // active year deleted: not frozen
+		futureYears: []Year{
+			{
+				Value: 2019,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value:  2018,
+				Active: true,
+			},
+		},
+		wantQueryYears: []int{2018, 2019},
+	},
+	{ // rollover from deleted year
+		futureYears: []Year{
+			{
+				Value: 2019,
+			},
+		},
+		rollovers: []YearRollover{
+			{
+				FromYear: 2018,
+				ToYear:   2019,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value: 2018,
+			},
+		},
+		wantErr: true,
+	},
+	{ // rollover to existing year
+		futureYears: []Year{
+			{
+				Value: 2018,
+			},
+			{
+				Value: 2019,
+			},
+		},
+		rollovers: []YearRollover{
+			{
+				FromYear: 2018,
+				ToYear:   2019,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value: 2018,
+			},
+			Year{
+				Value: 2019,
+			},
+		},
+		wantErr: true,
+	},
+	{ // multiple rollovers to the same year
+		futureYears: []Year{
+			{
+				Value: 2017,
+			},
+			{
+				Value: 2018,
+			},
+			{
+				Value: 2019,
+			},
+		},
+		rollovers: []YearRollover{
+			{
+				FromYear: 2017,
+				ToYear:   2019,
+			},
+			{
+				FromYear: 2018,
+				ToYear:   2019,
+			},
+		},
+		previousYears: []interface{}{
+			Year{
+				Value: 2017,
+			},
+			Year{
+				Value: 2018,
+			},
+		},
+		wantErr: true,
+	},
 }
 
 func TestSaveYears(t *testing.T) {
@@ -223,7 +382,7 @@ func TestSaveYears(t *testing.T) {
 			},
 		}}
 		wantErr := test.wantErr || test.getYearsErr != nil || test.executeInTransactionErr != nil
-		gotErr := ds.SaveYears(test.st, test.futureYears)
+		gotErr := ds.SaveYears(context.Background(), test.st, test.futureYears, test.rollovers...)
 		hadErr := gotErr != nil
 		if wantErr != hadErr {
 			t.Errorf("Test %v: wanted error %v, got: %v", i, wantErr, gotErr)
@@ -236,3 +395,48 @@ func TestSaveYears(t *testing.T) {
 		}
 	}
 }
+
+func TestFirestorePlayerKeeper(t *testing.T) {
+	addDate := time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)
+	keeperTests := []struct {
+		player  firestorePlayer
+		docID   string
+		want    firestorePlayer
+		wantErr bool
+	}{
+		{
+			player: firestorePlayer{DisplayOrder: 2, PlayerType: 3, SourceID: 545361, FriendID: "f1", AddDate: &addDate, DropDate: &addDate},
+			docID:  "8Vw0XmQh2kTzR1bYc9pL", // players have random ids
+			want:   firestorePlayer{DisplayOrder: 2, PlayerType: 3, SourceID: 545361, FriendID: "f1"},
+		},
+		{
+			player: firestorePlayer{DisplayOrder: 1, PlayerType: 3, FriendID: "f2"},
+			docID:  "547180", // players used to be keyed by their source ids
+			want:   firestorePlayer{DisplayOrder: 1, PlayerType: 3, SourceID: 547180, FriendID: "f2"},
+		},
+		{
+			player:  firestorePlayer{PlayerType: 3},
+			docID:   "8Vw0XmQh2kTzR1bYc9pL",
+			wantErr: true,
+		},
+	}
+	for i, test := range keeperTests {
+		got, err := test.player.keeper(&firestore.DocumentRef{ID: test.docID})
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, *got):
+			t.Errorf("Test %v: keepers not equal:\nwanted: %v\ngot:    %v", i, test.want, *got)
+		default:
+			// the copied keeper has the same id as the player it was copied from
+			sourceID, err := got.sourceID(&firestore.DocumentRef{ID: test.docID})
+			if err != nil || sourceID != test.want.SourceID {
+				t.Errorf("Test %v: wanted to read source id %v of copied keeper, got %v (error: %v)", i, test.want.SourceID, sourceID, err)
+			}
+		}
+	}
+}
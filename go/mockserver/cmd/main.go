@@ -0,0 +1,42 @@
+// Package main runs the mock server so the server can request data from it by setting its UPSTREAM_URL.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/mockserver"
+)
+
+func main() {
+	port := flag.String("p", "8001", "The port number to run the mock server on.")
+	scenarioPath := flag.String("s", "", "The json file of the scenario to respond with.  The default scenario is used if it is empty.")
+	smtpPort := flag.String("sp", "", "The port number to run a stand-in mail server on, which logs the messages sent to it.  The mail server is not run if it is empty.")
+	flag.Parse()
+
+	scenario := mockserver.DefaultScenario()
+	if len(*scenarioPath) != 0 {
+		s, err := mockserver.ReadScenario(*scenarioPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		scenario = *s
+	}
+	if len(*smtpPort) != 0 {
+		smtpServer, err := mockserver.NewSMTPServer(":" + *smtpPort)
+		if err != nil {
+			log.Fatal(err)
+		}
+		smtpServer.OnMessage = func(m mockserver.SMTPMessage) {
+			log.Printf("mail from %v to %v:\n%v", m.From, m.To, m.Data)
+		}
+		log.Printf("mock mail server running at localhost:%s", *smtpPort)
+		go func() {
+			log.Fatal(smtpServer.Serve())
+		}()
+	}
+	addr := ":" + *port
+	log.Printf("mock server running at http://localhost%s", addr)
+	log.Fatal(http.ListenAndServe(addr, mockserver.New(scenario)))
+}
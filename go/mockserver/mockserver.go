@@ -0,0 +1,556 @@
+// Package mockserver serves fake responses of the external sources for data so the server can be tested end to end without network access.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Scenario is the data the mock server responds with.
+	Scenario struct {
+		MlbTeams    []MlbTeam    `json:"mlbTeams"`
+		MlbPlayers  []MlbPlayer  `json:"mlbPlayers"`
+		NflTeams    []NflTeam    `json:"nflTeams"`
+		NflPlayers  []NflPlayer  `json:"nflPlayers"`
+		Deployments []Deployment `json:"deployments"`
+		// FailPaths are the paths of requests that fail with server errors, such as "/api/v1/standings/regularSeason".
+		FailPaths []string `json:"failPaths"`
+	}
+
+	// MlbTeam is a team in the mlb standings.  The wins and losses are for the whole season.
+	// Standings on a date have the games of the season up to the date, as if the games were played evenly from the start to the end of the season.
+	MlbTeam struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Wins   int    `json:"wins"`
+		Losses int    `json:"losses"`
+	}
+
+	// MlbPlayer is an mlb hitter or pitcher.  Pitchers have a position of "P".
+	// The HomeRuns and Wins are for the whole season.  Stats for a range of dates are accrued evenly like the games of MlbTeams.
+	MlbPlayer struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		Position     string `json:"position"`
+		Team         string `json:"team"`
+		BirthCountry string `json:"birthCountry"`
+		// BirthDate is formatted as yyyy-mm-dd.  It is empty if it is not known.
+		BirthDate string `json:"birthDate"`
+		Active    bool   `json:"active"`
+		HomeRuns  int    `json:"homeRuns"`
+		Wins      int    `json:"wins"`
+	}
+
+	// NflTeam is a team in the nfl schedule.  The EspnID is its id in the espn standings.
+	NflTeam struct {
+		ID     int    `json:"id"`
+		EspnID int    `json:"espnId"`
+		Name   string `json:"name"`
+		Wins   int    `json:"wins"`
+		Losses int    `json:"losses"`
+		Ties   int    `json:"ties"`
+	}
+
+	// NflPlayer is an nfl player with touchdown stats.  The EspnID is its id in the espn athletes.
+	NflPlayer struct {
+		ID          int    `json:"id"`
+		EspnID      int    `json:"espnId"`
+		Name        string `json:"name"`
+		Position    string `json:"position"`
+		Team        string `json:"team"`
+		PassingTD   int    `json:"passingTD"`
+		RushingTD   int    `json:"rushingTD"`
+		ReceivingTD int    `json:"receivingTD"`
+		ReturnTD    int    `json:"returnTD"`
+	}
+
+	// Deployment is a deployment of the application on github.
+	Deployment struct {
+		Ref         string    `json:"ref"`
+		Environment string    `json:"environment"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+	}
+)
+
+// The first and last months and days of the mlb regular season that games are played evenly between.
+const (
+	mlbSeasonStartMonth, mlbSeasonStartDay = time.March, 28
+	mlbSeasonEndMonth, mlbSeasonEndDay     = time.September, 29
+	dateFormat                             = "2006-01-02"
+)
+
+// DefaultScenario is a small season of data for each sport.
+func DefaultScenario() Scenario {
+	return Scenario{
+		MlbTeams: []MlbTeam{
+			{ID: 143, Name: "Philadelphia Phillies", Wins: 81, Losses: 81},
+			{ID: 120, Name: "Washington Nationals", Wins: 93, Losses: 69},
+			{ID: 108, Name: "Los Angeles Angels", Wins: 72, Losses: 90},
+		},
+		MlbPlayers: []MlbPlayer{
+			{ID: 547180, Name: "Bryce Harper", Position: "RF", Team: "PHI", BirthCountry: "USA", BirthDate: "1992-10-16", Active: true, HomeRuns: 35},
+			{ID: 545361, Name: "Mike Trout", Position: "CF", Team: "LAA", BirthCountry: "USA", BirthDate: "1991-08-07", Active: true, HomeRuns: 45},
+			{ID: 453286, Name: "Max Scherzer", Position: "P", Team: "WSH", BirthCountry: "USA", BirthDate: "1984-07-27", Active: true, Wins: 11},
+			{ID: 116539, Name: "Bryce Florie", Position: "P", Team: "BOS", BirthCountry: "USA", BirthDate: "1970-05-21", Wins: 4},
+		},
+		NflTeams: []NflTeam{
+			{ID: 26, EspnID: 26, Name: "Seattle Seahawks", Wins: 11, Losses: 5},
+			{ID: 9, EspnID: 9, Name: "Green Bay Packers", Wins: 13, Losses: 3},
+		},
+		NflPlayers: []NflPlayer{
+			{ID: 2532975, EspnID: 14881, Name: "Russell Wilson", Position: "QB", Team: "SEA", PassingTD: 31, RushingTD: 3},
+			{ID: 2495455, EspnID: 8439, Name: "Aaron Rodgers", Position: "QB", Team: "GB", PassingTD: 26, RushingTD: 1},
+			{ID: 2540204, EspnID: 16725, Name: "Tyler Lockett", Position: "WR", Team: "SEA", ReceivingTD: 8},
+		},
+		Deployments: []Deployment{
+			{Ref: "0123456789abcdef", Environment: "nate-mlb", UpdatedAt: time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+// ReadScenario reads the Scenario in the json file.
+func ReadScenario(path string) (*Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario: %w", err)
+	}
+	var s Scenario
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("decoding scenario: %w", err)
+	}
+	return &s, nil
+}
+
+// New creates a handler that responds to requests for data like the external sources, using the data in the Scenario.
+// All sources are served from the same host, so it can be used as the upstream url of the server.
+func New(s Scenario) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/standings/regularSeason", s.handleMlbStandings)
+	mux.HandleFunc("GET /api/v1/people", s.handleMlbPlayerNames)
+	mux.HandleFunc("GET /api/v1/people/{id}/stats", s.handleMlbPlayerStats)
+	mux.HandleFunc("GET /api/v1/people/search", s.handleMlbPeopleSearch)
+	mux.HandleFunc("GET /json/named.search_player_all.bam", s.handleMlbPlayerSearch)
+	mux.HandleFunc("GET /v2/nfl/schedule", s.handleNflSchedule)
+	mux.HandleFunc("GET /v2/batchservices", s.handleNflPlayerDetails)
+	mux.HandleFunc("GET /v2/players/autocomplete", s.handleNflPlayerSearch)
+	mux.HandleFunc("GET /apis/v2/sports/football/nfl/standings", s.handleEspnStandings)
+	mux.HandleFunc("GET /v3/sports/football/nfl/athletes", s.handleEspnAthletes)
+	mux.HandleFunc("GET /v2/sports/football/leagues/nfl/seasons/{season}/types/2/athletes/{id}/statistics", s.handleEspnAthleteStatistics)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/deployments", s.handleDeployments)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, failPath := range s.FailPaths {
+			if r.URL.Path == failPath {
+				http.Error(w, "scenario failure", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// handleMlbStandings responds with the standings of the teams.  If the date query param is present, the standings on the date are responded with.
+func (s Scenario) handleMlbStandings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	played := func(games int) int { return games }
+	if date := q.Get("date"); len(date) != 0 {
+		season, dates, err := mlbSeasonDates(q.Get("season"), date)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		played = func(games int) int { return gamesPlayed(games, season, dates[0]) }
+	}
+	teamRecords := make([]interface{}, len(s.MlbTeams))
+	for i, team := range s.MlbTeams {
+		teamRecords[i] = map[string]interface{}{
+			"team": map[string]interface{}{
+				"id":   team.ID,
+				"name": team.Name,
+			},
+			"wins":   played(team.Wins),
+			"losses": played(team.Losses),
+		}
+	}
+	standings := map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"teamRecords": teamRecords},
+		},
+	}
+	writeJSON(w, standings)
+}
+
+func (s Scenario) handleMlbPlayerNames(w http.ResponseWriter, r *http.Request) {
+	ids := make(map[int]bool)
+	for _, id := range strings.Split(r.URL.Query().Get("personIds"), ",") {
+		if idI, err := strconv.Atoi(id); err == nil {
+			ids[idI] = true
+		}
+	}
+	people := []interface{}{}
+	for _, player := range s.MlbPlayers {
+		if ids[player.ID] {
+			people = append(people, map[string]interface{}{
+				"id":       player.ID,
+				"fullName": player.Name,
+			})
+		}
+	}
+	writeJSON(w, map[string]interface{}{"people": people})
+}
+
+func (s Scenario) handleMlbPlayerStats(w http.ResponseWriter, r *http.Request) {
+	player, ok := s.mlbPlayer(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	accrued := func(total int) int { return total }
+	if q := r.URL.Query(); q.Get("stats") == "byDateRange" {
+		season, dates, err := mlbSeasonDates(q.Get("season"), q.Get("startDate"), q.Get("endDate"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		start, end := dates[0], dates[1]
+		accrued = func(total int) int {
+			if end.Before(start) {
+				return 0
+			}
+			return gamesPlayed(total, season, end) - gamesPlayed(total, season, start.AddDate(0, 0, -1))
+		}
+	}
+	group, stat := "hitting", map[string]interface{}{"homeRuns": accrued(player.HomeRuns)}
+	if player.Position == "P" {
+		group, stat = "pitching", map[string]interface{}{"wins": accrued(player.Wins)}
+	}
+	stats := map[string]interface{}{
+		"stats": []interface{}{
+			map[string]interface{}{
+				"group":  map[string]interface{}{"displayName": group},
+				"splits": []interface{}{map[string]interface{}{"stat": stat}},
+			},
+		},
+	}
+	writeJSON(w, stats)
+}
+
+// mlbSeasonDates parses the season year and the yyyy-mm-dd dates.
+func mlbSeasonDates(season string, dates ...string) (int, []time.Time, error) {
+	year, err := strconv.Atoi(season)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid season: %w", err)
+	}
+	times := make([]time.Time, len(dates))
+	for i, date := range dates {
+		times[i], err = time.Parse(dateFormat, date)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid date: %w", err)
+		}
+	}
+	return year, times, nil
+}
+
+// gamesPlayed is the part of the games of the season that were played by the end of the date.
+// The games are played evenly from the start to the end of the regular season.
+func gamesPlayed(games, season int, date time.Time) int {
+	start := time.Date(season, mlbSeasonStartMonth, mlbSeasonStartDay, 0, 0, 0, 0, time.UTC)
+	end := time.Date(season, mlbSeasonEndMonth, mlbSeasonEndDay, 0, 0, 0, 0, time.UTC)
+	switch {
+	case date.Before(start):
+		return 0
+	case !date.Before(end):
+		return games
+	}
+	seasonDays := end.Sub(start).Hours()/24 + 1
+	days := date.Sub(start).Hours()/24 + 1
+	return int(float64(games) * days / seasonDays)
+}
+
+func (s Scenario) mlbPlayer(id string) (MlbPlayer, bool) {
+	for _, player := range s.MlbPlayers {
+		if strconv.Itoa(player.ID) == id {
+			return player, true
+		}
+	}
+	return MlbPlayer{}, false
+}
+
+// handleMlbPeopleSearch responds with players with names that start with the names query param.
+func (s Scenario) handleMlbPeopleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	names := strings.ToLower(q.Get("names"))
+	activeOnly := q.Get("active") == "true"
+	people := []interface{}{}
+	for _, player := range s.MlbPlayers {
+		if (activeOnly && !player.Active) || !namePrefixMatches(player.Name, names) {
+			continue
+		}
+		person := map[string]interface{}{
+			"id":              player.ID,
+			"fullName":        player.Name,
+			"birthCountry":    player.BirthCountry,
+			"active":          player.Active,
+			"primaryPosition": map[string]interface{}{"abbreviation": player.Position},
+		}
+		if len(player.BirthDate) != 0 {
+			person["birthDate"] = player.BirthDate
+		}
+		if len(player.Team) != 0 {
+			person["currentTeam"] = map[string]interface{}{"abbreviation": player.Team}
+		}
+		people = append(people, person)
+	}
+	writeJSON(w, map[string]interface{}{"people": people})
+}
+
+// handleMlbPlayerSearch responds with players with names that start with the quoted name_part, which ends with a percent sign.
+// Like the external source, a single player is not in an array.
+func (s Scenario) handleMlbPlayerSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	namePart := strings.Trim(q.Get("name_part"), "'")
+	namePart = strings.ToLower(strings.TrimSuffix(namePart, "%"))
+	activeOnly := strings.Trim(q.Get("active_sw"), "'") == "Y"
+	var rows []interface{}
+	for _, player := range s.MlbPlayers {
+		if (activeOnly && !player.Active) || !namePrefixMatches(player.Name, namePart) {
+			continue
+		}
+		birthDate := ""
+		if len(player.BirthDate) != 0 {
+			birthDate = player.BirthDate + "T00:00:00"
+		}
+		rows = append(rows, map[string]interface{}{
+			"player_id":               strconv.Itoa(player.ID),
+			"name_display_first_last": player.Name,
+			"position":                player.Position,
+			"team_abbrev":             player.Team,
+			"birth_country":           player.BirthCountry,
+			"birth_date":              birthDate,
+		})
+	}
+	queryResults := map[string]interface{}{
+		"totalSize": strconv.Itoa(len(rows)),
+	}
+	switch len(rows) {
+	case 0:
+	case 1:
+		queryResults["row"] = rows[0]
+	default:
+		queryResults["row"] = rows
+	}
+	search := map[string]interface{}{
+		"search_player_all": map[string]interface{}{
+			"queryResults": queryResults,
+		},
+	}
+	writeJSON(w, search)
+}
+
+// namePrefixMatches determines if the name or any of its parts start with the lowercase prefix.
+func namePrefixMatches(name, prefix string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, prefix) {
+		return true
+	}
+	for _, part := range strings.Fields(name) {
+		if strings.HasPrefix(part, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Scenario) handleNflSchedule(w http.ResponseWriter, r *http.Request) {
+	nflTeams := make(map[string]interface{}, len(s.NflTeams))
+	for _, team := range s.NflTeams {
+		nflTeams[strconv.Itoa(team.ID)] = map[string]interface{}{
+			"fullName": team.Name,
+			"record":   fmt.Sprintf("%d-%d-%d", team.Wins, team.Losses, team.Ties),
+		}
+	}
+	writeJSON(w, map[string]interface{}{"nflTeams": nflTeams})
+}
+
+// handleNflPlayerDetails responds with the season stats of the players in the playerDetails services.
+func (s Scenario) handleNflPlayerDetails(w http.ResponseWriter, r *http.Request) {
+	var services []map[string]string
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("services")), &services); err != nil {
+		http.Error(w, fmt.Sprintf("invalid services: %v", err), http.StatusBadRequest)
+		return
+	}
+	season := ""
+	ids := make(map[int]bool, len(services))
+	for _, service := range services {
+		details, err := url.ParseQuery(service["playerDetails"])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid playerDetails: %v", err), http.StatusBadRequest)
+			return
+		}
+		season = details.Get("season")
+		if id, err := strconv.Atoi(details.Get("playerId")); err == nil {
+			ids[id] = true
+		}
+	}
+	players := make(map[string]interface{}, len(ids))
+	for _, player := range s.NflPlayers {
+		if ids[player.ID] {
+			p := player.json()
+			p["stats"] = map[string]interface{}{
+				"season": map[string]interface{}{
+					season: map[string]string{
+						"6":  strconv.Itoa(player.PassingTD),
+						"15": strconv.Itoa(player.RushingTD),
+						"22": strconv.Itoa(player.ReceivingTD),
+						"28": strconv.Itoa(player.ReturnTD),
+					},
+				},
+			}
+			players[strconv.Itoa(player.ID)] = p
+		}
+	}
+	writeNflGame(w, season, players)
+}
+
+// handleNflPlayerSearch responds with the players with names that contain the query.
+func (s Scenario) handleNflPlayerSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	players := make(map[string]interface{})
+	for _, player := range s.NflPlayers {
+		if strings.Contains(strings.ToLower(player.Name), query) {
+			players[strconv.Itoa(player.ID)] = player.json()
+		}
+	}
+	writeNflGame(w, "", players)
+}
+
+func (player NflPlayer) json() map[string]interface{} {
+	return map[string]interface{}{
+		"playerId":    strconv.Itoa(player.ID),
+		"name":        player.Name,
+		"position":    player.Position,
+		"nflTeamAbbr": player.Team,
+	}
+}
+
+func writeNflGame(w http.ResponseWriter, season string, players map[string]interface{}) {
+	game := map[string]interface{}{
+		"players": players,
+	}
+	if len(season) != 0 {
+		game["season"] = season
+	}
+	writeJSON(w, map[string]interface{}{
+		"games": map[string]interface{}{"102019": game},
+	})
+}
+
+// handleEspnStandings responds with all teams in a single group.
+func (s Scenario) handleEspnStandings(w http.ResponseWriter, r *http.Request) {
+	entries := make([]interface{}, len(s.NflTeams))
+	for i, team := range s.NflTeams {
+		entries[i] = map[string]interface{}{
+			"team": map[string]interface{}{
+				"id":          strconv.Itoa(team.EspnID),
+				"displayName": team.Name,
+			},
+			"stats": []interface{}{
+				espnStat("wins", team.Wins),
+				espnStat("losses", team.Losses),
+				espnStat("ties", team.Ties),
+			},
+		}
+	}
+	standings := map[string]interface{}{
+		"children": []interface{}{
+			map[string]interface{}{
+				"standings": map[string]interface{}{"entries": entries},
+			},
+		},
+	}
+	writeJSON(w, standings)
+}
+
+// handleEspnAthletes responds with all nfl players.
+func (s Scenario) handleEspnAthletes(w http.ResponseWriter, r *http.Request) {
+	items := make([]interface{}, len(s.NflPlayers))
+	for i, player := range s.NflPlayers {
+		items[i] = map[string]interface{}{
+			"id":       strconv.Itoa(player.EspnID),
+			"fullName": player.Name,
+			"position": map[string]interface{}{"abbreviation": player.Position},
+			"team":     map[string]interface{}{"abbreviation": player.Team},
+		}
+	}
+	writeJSON(w, map[string]interface{}{"items": items})
+}
+
+// handleEspnAthleteStatistics responds with the touchdown stats of the player.
+func (s Scenario) handleEspnAthleteStatistics(w http.ResponseWriter, r *http.Request) {
+	var player *NflPlayer
+	for i := range s.NflPlayers {
+		if strconv.Itoa(s.NflPlayers[i].EspnID) == r.PathValue("id") {
+			player = &s.NflPlayers[i]
+		}
+	}
+	if player == nil {
+		http.NotFound(w, r)
+		return
+	}
+	categories := []interface{}{
+		espnStatCategory("passing", espnStat("passingTouchdowns", player.PassingTD)),
+		espnStatCategory("rushing", espnStat("rushingTouchdowns", player.RushingTD)),
+		espnStatCategory("receiving", espnStat("receivingTouchdowns", player.ReceivingTD)),
+		espnStatCategory("returning", espnStat("kickReturnTouchdowns", player.ReturnTD), espnStat("puntReturnTouchdowns", 0)),
+	}
+	writeJSON(w, map[string]interface{}{
+		"splits": map[string]interface{}{"categories": categories},
+	})
+}
+
+func espnStatCategory(name string, stats ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"stats": stats,
+	}
+}
+
+// espnStat is a stat with a value that is a float, like those of espn.
+func espnStat(name string, value int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"value": float64(value),
+	}
+}
+
+// handleDeployments responds with the deployments, most recent first.
+func (s Scenario) handleDeployments(w http.ResponseWriter, r *http.Request) {
+	deployments := make([]Deployment, len(s.Deployments))
+	copy(deployments, s.Deployments)
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].UpdatedAt.After(deployments[j].UpdatedAt)
+	})
+	githubDeployments := make([]interface{}, len(deployments))
+	for i, d := range deployments {
+		githubDeployments[i] = map[string]interface{}{
+			"ref":         d.Ref,
+			"environment": d.Environment,
+			"updated_at":  d.UpdatedAt,
+		}
+	}
+	writeJSON(w, githubDeployments)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,300 @@
+package mockserver
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+func newTestRequesters(t *testing.T, s Scenario) (map[db.PlayerType]request.ScoreCategorizer, map[db.PlayerType]request.Searcher, request.AboutRequester) {
+	return newTestNflProviderRequesters(t, s, request.NflProviderFantasy, request.NflProviderEspn)
+}
+
+func newTestNflProviderRequesters(t *testing.T, s Scenario, nflProviders ...string) (map[db.PlayerType]request.ScoreCategorizer, map[db.PlayerType]request.Searcher, request.AboutRequester) {
+	server := httptest.NewServer(New(s))
+	t.Cleanup(server.Close)
+	log := log.New(io.Discard, "test", log.LstdFlags)
+	return request.NewRequesters(server.Client(), nil, "test_key", nflProviders, "nate-mlb", false, request.NewRetryConfig(0), request.NewHostLimits(0, 0), request.NewBaseURLs(server.URL), log)
+}
+
+func TestScoreCategories(t *testing.T) {
+	scoreCategorizers, _, _ := newTestRequesters(t, DefaultScenario())
+	friends := []db.Friend{{ID: "1", DisplayOrder: 1, Name: "Bobby"}}
+	scoreCategoriesTests := []struct {
+		pt         db.PlayerType
+		players    []db.Player
+		wantScores map[string]int
+	}{
+		{
+			pt:         db.PlayerTypeMlbTeam,
+			players:    []db.Player{{ID: "1", SourceID: 143, FriendID: "1"}, {ID: "2", SourceID: 120, FriendID: "1"}},
+			wantScores: map[string]int{"Philadelphia Phillies": 81, "Washington Nationals": 93},
+		},
+		{
+			pt:         db.PlayerTypeMlbHitter,
+			players:    []db.Player{{ID: "1", SourceID: 547180, FriendID: "1"}, {ID: "2", SourceID: 545361, FriendID: "1"}},
+			wantScores: map[string]int{"Bryce Harper": 35, "Mike Trout": 45},
+		},
+		{
+			pt:         db.PlayerTypeMlbPitcher,
+			players:    []db.Player{{ID: "1", SourceID: 453286, FriendID: "1"}},
+			wantScores: map[string]int{"Max Scherzer": 11},
+		},
+		{
+			pt:         db.PlayerTypeNflTeam,
+			players:    []db.Player{{ID: "1", SourceID: 26, FriendID: "1"}},
+			wantScores: map[string]int{"Seattle Seahawks": 11},
+		},
+		{
+			pt:         db.PlayerTypeNflQB,
+			players:    []db.Player{{ID: "1", SourceID: 2532975, FriendID: "1"}},
+			wantScores: map[string]int{"Russell Wilson": 34},
+		},
+		{
+			pt:         db.PlayerTypeNflMisc,
+			players:    []db.Player{{ID: "1", SourceID: 2540204, FriendID: "1"}},
+			wantScores: map[string]int{"Tyler Lockett": 8},
+		},
+	}
+	for i, test := range scoreCategoriesTests {
+		sc, err := scoreCategorizers[test.pt].RequestScoreCategory(context.Background(), test.pt, db.PlayerTypeInfo{}, 2019, friends, test.players)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		gotScores := make(map[string]int)
+		for _, fs := range sc.FriendScores {
+			for _, ps := range fs.PlayerScores {
+				gotScores[ps.Name] = ps.Score
+			}
+		}
+		if !reflect.DeepEqual(test.wantScores, gotScores) {
+			t.Errorf("Test %v: scores not equal:\nwanted: %v\ngot:    %v", i, test.wantScores, gotScores)
+		}
+	}
+}
+
+func TestScoreCategories_rosterDates(t *testing.T) {
+	scoreCategorizers, _, _ := newTestRequesters(t, DefaultScenario())
+	friends := []db.Friend{{ID: "1", DisplayOrder: 1, Name: "Bobby"}, {ID: "2", DisplayOrder: 2, Name: "Charles"}}
+	july1 := time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)
+	scoreCategoriesRosterDatesTests := []struct {
+		pt         db.PlayerType
+		sourceID   db.SourceID
+		wantScores map[db.ID]int
+	}{
+		{
+			pt:         db.PlayerTypeMlbTeam,
+			sourceID:   143,                             // Philadelphia Phillies, 81 wins
+			wantScores: map[db.ID]int{"1": 41, "2": 40}, // 95 of 186 days of the season were before July
+		},
+		{
+			pt:         db.PlayerTypeMlbHitter,
+			sourceID:   547180, // Bryce Harper, 35 home runs
+			wantScores: map[db.ID]int{"1": 17, "2": 18},
+		},
+	}
+	for i, test := range scoreCategoriesRosterDatesTests {
+		players := []db.Player{
+			{ID: "1", PlayerType: test.pt, SourceID: test.sourceID, FriendID: "1", DropDate: &july1},
+			{ID: "2", PlayerType: test.pt, SourceID: test.sourceID, FriendID: "2", AddDate: &july1},
+		}
+		sc, err := scoreCategorizers[test.pt].RequestScoreCategory(context.Background(), test.pt, db.PlayerTypeInfo{}, 2019, friends, players)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		gotScores := make(map[db.ID]int)
+		for _, fs := range sc.FriendScores {
+			for _, ps := range fs.PlayerScores {
+				gotScores[ps.ID] = ps.Score
+			}
+		}
+		if !reflect.DeepEqual(test.wantScores, gotScores) {
+			t.Errorf("Test %v: scores not equal:\nwanted: %v\ngot:    %v", i, test.wantScores, gotScores)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, searchers, _ := newTestRequesters(t, DefaultScenario())
+	searchTests := []struct {
+		pt                db.PlayerType
+		query             string
+		activePlayersOnly bool
+		wantSourceIDs     []db.SourceID
+	}{
+		{pt: db.PlayerTypeMlbTeam, query: "phil", wantSourceIDs: []db.SourceID{143, 120, 108}}, // other teams are listed after the matched team
+		{pt: db.PlayerTypeMlbHitter, query: "bryce", wantSourceIDs: []db.SourceID{547180}},
+		{pt: db.PlayerTypeMlbPitcher, query: "bryce", wantSourceIDs: []db.SourceID{116539}},
+		{pt: db.PlayerTypeMlbPitcher, query: "bryce", activePlayersOnly: true},
+		{pt: db.PlayerTypeMlbHitter, query: "trout", wantSourceIDs: []db.SourceID{545361}},
+		{pt: db.PlayerTypeNflTeam, query: "seattle", wantSourceIDs: []db.SourceID{26, 9}},
+		{pt: db.PlayerTypeNflQB, query: "russell", wantSourceIDs: []db.SourceID{2532975}},
+		{pt: db.PlayerTypeNflMisc, query: "lockett", wantSourceIDs: []db.SourceID{2540204}},
+	}
+	for i, test := range searchTests {
+		results, err := searchers[test.pt].Search(context.Background(), test.pt, 2019, test.query, test.activePlayersOnly)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		var gotSourceIDs []db.SourceID
+		for _, result := range results {
+			gotSourceIDs = append(gotSourceIDs, result.SourceID)
+		}
+		if !reflect.DeepEqual(test.wantSourceIDs, gotSourceIDs) {
+			t.Errorf("Test %v: search results for %q not equal:\nwanted: %v\ngot:    %v", i, test.query, test.wantSourceIDs, gotSourceIDs)
+		}
+	}
+}
+
+func TestSearch_fallback(t *testing.T) {
+	s := DefaultScenario()
+	s.FailPaths = []string{"/api/v1/people/search"}
+	_, searchers, _ := newTestRequesters(t, s)
+	results, err := searchers[db.PlayerTypeMlbHitter].Search(context.Background(), db.PlayerTypeMlbHitter, 2019, "trout", true)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(results) != 1 || results[0].SourceID != 545361 || results[0].Team != "LAA":
+		t.Errorf("wanted search to fall back to lookup service, got %v", results)
+	}
+	s.FailPaths = append(s.FailPaths, "/json/named.search_player_all.bam")
+	_, searchers, _ = newTestRequesters(t, s)
+	if _, err := searchers[db.PlayerTypeMlbHitter].Search(context.Background(), db.PlayerTypeMlbHitter, 2019, "trout", true); err == nil {
+		t.Error("wanted error when all searches fail")
+	}
+}
+
+func TestScoreCategories_nflFallback(t *testing.T) {
+	s := DefaultScenario()
+	s.FailPaths = []string{"/v2/nfl/schedule", "/v2/batchservices"}
+	scoreCategorizers, _, _ := newTestRequesters(t, s)
+	friends := []db.Friend{{ID: "1", DisplayOrder: 1, Name: "Bobby"}}
+	scoreCategoriesTests := []struct {
+		pt        db.PlayerType
+		player    db.Player
+		wantScore int
+	}{
+		{pt: db.PlayerTypeNflTeam, player: db.Player{ID: "1", SourceID: 26, Name: "Seattle Seahawks", FriendID: "1"}, wantScore: 11},
+		{pt: db.PlayerTypeNflQB, player: db.Player{ID: "1", SourceID: 2532975, Name: "Russell Wilson", FriendID: "1"}, wantScore: 34},
+		{pt: db.PlayerTypeNflMisc, player: db.Player{ID: "1", SourceID: 2540204, Name: "Tyler Lockett", FriendID: "1"}, wantScore: 8},
+	}
+	for i, test := range scoreCategoriesTests {
+		sc, err := scoreCategorizers[test.pt].RequestScoreCategory(context.Background(), test.pt, db.PlayerTypeInfo{}, 2019, friends, []db.Player{test.player})
+		switch {
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case len(sc.FriendScores) != 1 || len(sc.FriendScores[0].PlayerScores) != 1:
+			t.Errorf("Test %v: wanted a single player score, got %v", i, sc)
+		case sc.FriendScores[0].PlayerScores[0].SourceID != test.player.SourceID:
+			t.Errorf("Test %v: wanted score for the SourceID of the primary provider, got %v", i, sc.FriendScores[0].PlayerScores[0])
+		case sc.FriendScores[0].PlayerScores[0].Score != test.wantScore:
+			t.Errorf("Test %v: wanted score %v from fallback provider, got %v", i, test.wantScore, sc.FriendScores[0].PlayerScores[0].Score)
+		}
+	}
+}
+
+func TestSearch_espn(t *testing.T) {
+	_, searchers, _ := newTestNflProviderRequesters(t, DefaultScenario(), request.NflProviderEspn)
+	searchTests := []struct {
+		pt            db.PlayerType
+		query         string
+		wantSourceIDs []db.SourceID
+	}{
+		{pt: db.PlayerTypeNflTeam, query: "seattle", wantSourceIDs: []db.SourceID{26, 9}},
+		{pt: db.PlayerTypeNflQB, query: "russell", wantSourceIDs: []db.SourceID{14881}},
+		{pt: db.PlayerTypeNflMisc, query: "lockett", wantSourceIDs: []db.SourceID{16725}},
+	}
+	for i, test := range searchTests {
+		results, err := searchers[test.pt].Search(context.Background(), test.pt, 2019, test.query, true)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		var gotSourceIDs []db.SourceID
+		for _, result := range results {
+			gotSourceIDs = append(gotSourceIDs, result.SourceID)
+		}
+		if !reflect.DeepEqual(test.wantSourceIDs, gotSourceIDs) {
+			t.Errorf("Test %v: search results for %q not equal:\nwanted: %v\ngot:    %v", i, test.query, test.wantSourceIDs, gotSourceIDs)
+		}
+	}
+}
+
+func TestPreviousDeployment(t *testing.T) {
+	_, _, aboutRequester := newTestRequesters(t, DefaultScenario())
+	deployment, err := aboutRequester.PreviousDeployment(context.Background())
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case deployment == nil || deployment.Version != "0123456":
+		t.Errorf("wanted deployment of short ref, got %v", deployment)
+	}
+}
+
+func TestFailPaths(t *testing.T) {
+	s := DefaultScenario()
+	s.FailPaths = []string{"/api/v1/standings/regularSeason"}
+	scoreCategorizers, _, _ := newTestRequesters(t, s)
+	if _, err := scoreCategorizers[db.PlayerTypeMlbTeam].RequestScoreCategory(context.Background(), db.PlayerTypeMlbTeam, db.PlayerTypeInfo{}, 2019, nil, nil); err == nil {
+		t.Error("wanted error requesting from failing path")
+	}
+	if _, err := scoreCategorizers[db.PlayerTypeNflTeam].RequestScoreCategory(context.Background(), db.PlayerTypeNflTeam, db.PlayerTypeInfo{}, 2019, nil, nil); err != nil {
+		t.Errorf("unexpected error requesting from other path: %v", err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	server := httptest.NewServer(New(DefaultScenario()))
+	defer server.Close()
+	for _, path := range []string{"/api/v1/people/1/stats", "/unknown"} {
+		response, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if want, got := http.StatusNotFound, response.StatusCode; want != got {
+			t.Errorf("wanted status %v for %v, got %v", want, path, got)
+		}
+	}
+}
+
+func TestReadScenario(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scenario.json")
+	if err := os.WriteFile(path, []byte(`{"mlbTeams":[{"id":143,"name":"Philadelphia Phillies","wins":81}],"failPaths":["/v2/nfl/schedule"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	want := Scenario{
+		MlbTeams:  []MlbTeam{{ID: 143, Name: "Philadelphia Phillies", Wins: 81}},
+		FailPaths: []string{"/v2/nfl/schedule"},
+	}
+	got, err := ReadScenario(path)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, *got):
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, *got)
+	}
+	for _, path := range []string{invalidPath, filepath.Join(dir, "missing.json")} {
+		if _, err := ReadScenario(path); err == nil {
+			t.Errorf("wanted error reading %v", path)
+		}
+	}
+}
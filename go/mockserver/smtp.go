@@ -0,0 +1,139 @@
+package mockserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+type (
+	// SMTPServer is a stand-in for a mail server that keeps the messages sent to it instead of delivering them.
+	// Any credentials are accepted.
+	SMTPServer struct {
+		// OnMessage is called with each message that is received if it is set.
+		OnMessage func(m SMTPMessage)
+		listener  net.Listener
+		mu        sync.Mutex
+		messages  []SMTPMessage
+	}
+
+	// SMTPMessage is a message that was sent to the SMTPServer.  The Data has the headers and body of the message.
+	SMTPMessage struct {
+		From string
+		To   []string
+		Data string
+	}
+)
+
+// NewSMTPServer creates an SMTPServer that listens at the address, such as "localhost:2525".
+// A port is chosen if the port of the address is 0.
+func NewSMTPServer(addr string) (*SMTPServer, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for smtp connections: %w", err)
+	}
+	s := SMTPServer{
+		listener: l,
+	}
+	return &s, nil
+}
+
+// Addr is the address the SMTPServer listens at.
+func (s *SMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve handles connections until the SMTPServer is closed.
+func (s *SMTPServer) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops listening for connections.
+func (s *SMTPServer) Close() error {
+	return s.listener.Close()
+}
+
+// Messages gets a copy of the messages that have been received.
+func (s *SMTPServer) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.messages...)
+}
+
+// handle responds to the commands of the connection until it quits.
+func (s *SMTPServer) handle(conn net.Conn) {
+	tc := textproto.NewConn(conn)
+	defer tc.Close()
+	var m SMTPMessage
+	tc.PrintfLine("220 mockserver ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tc.PrintfLine("250-mockserver")
+			tc.PrintfLine("250 AUTH PLAIN LOGIN")
+		case "HELO", "NOOP":
+			tc.PrintfLine("250 OK")
+		case "AUTH":
+			tc.PrintfLine("235 Authentication successful")
+		case "RSET":
+			m = SMTPMessage{}
+			tc.PrintfLine("250 OK")
+		case "MAIL":
+			m = SMTPMessage{From: smtpPath(arg)}
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			m.To = append(m.To, smtpPath(arg))
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tc.DotReader())
+			if err != nil {
+				return
+			}
+			m.Data = string(data)
+			s.receive(m)
+			m = SMTPMessage{}
+			tc.PrintfLine("250 OK")
+		case "QUIT":
+			tc.PrintfLine("221 Bye")
+			return
+		default:
+			tc.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// receive keeps the message.
+func (s *SMTPServer) receive(m SMTPMessage) {
+	s.mu.Lock()
+	s.messages = append(s.messages, m)
+	s.mu.Unlock()
+	if s.OnMessage != nil {
+		s.OnMessage(m)
+	}
+}
+
+// smtpPath gets the address from the argument of a MAIL or RCPT command, such as "FROM:<a@example.com>".
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ") // remove parameters such as BODY=8BITMIME
+	return strings.Trim(path, "<>")
+}
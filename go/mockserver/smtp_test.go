@@ -0,0 +1,64 @@
+package mockserver
+
+import (
+	"net/smtp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestSMTPServer(t *testing.T) *SMTPServer {
+	s, err := NewSMTPServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSMTPServer(t *testing.T) {
+	s := newTestSMTPServer(t)
+	received := make(chan SMTPMessage, 1)
+	s.OnMessage = func(m SMTPMessage) {
+		received <- m
+	}
+	host, _, _ := strings.Cut(s.Addr(), ":")
+	auth := smtp.PlainAuth("", "user", "secret", host)
+	to := []string{"alfred@example.com", "bert@example.com"}
+	msg := "Subject: standings\r\n\r\nAlfred is first.\r\n.leading dot\r\n"
+	if err := smtp.SendMail(s.Addr(), auth, "stats@example.com", to, []byte(msg)); err != nil {
+		t.Fatalf("unexpected error sending mail: %v", err)
+	}
+	want := []SMTPMessage{
+		{
+			From: "stats@example.com",
+			To:   to,
+			Data: "Subject: standings\n\nAlfred is first.\n.leading dot\n",
+		},
+	}
+	got := s.Messages()
+	switch {
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("messages not equal:\nwanted: %q\ngot:    %q", want, got)
+	case !reflect.DeepEqual(want[0], <-received):
+		t.Error("wanted OnMessage to be called with the message")
+	}
+}
+
+func TestSMTPServer_close(t *testing.T) {
+	s, err := NewSMTPServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.Serve()
+	}()
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("wanted serving to stop without error when closed, got %v", err)
+	}
+}
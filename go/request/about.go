@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -10,6 +11,7 @@ type (
 	AboutRequester struct {
 		environment string
 		requester   requester
+		baseURL     string
 	}
 
 	// GithubRepoDeployment is used to unmarshal information about a github repository
@@ -29,12 +31,12 @@ type (
 )
 
 // PreviousDeployment returns some information about the most recent deployment
-func (r AboutRequester) PreviousDeployment() (*Deployment, error) {
+func (r AboutRequester) PreviousDeployment(ctx context.Context) (*Deployment, error) {
 	owner := "jacobpatterson1549"
 	repo := "nate-mlb"
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/deployments", owner, repo)
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments", r.baseURL, owner, repo)
 	var s githubRepoDeployments
-	err := r.requester.structPointerFromURI(uri, &s)
+	err := r.requester.structPointerFromURI(ctx, uri, &s)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -75,7 +76,7 @@ func TestPreviousDeployment_RequesterError(t *testing.T) {
 		},
 	}
 	about := AboutRequester{requester: &m}
-	_, err := about.PreviousDeployment()
+	_, err := about.PreviousDeployment(context.Background())
 	if err == nil {
 		t.Error("expected request to fail, but did not")
 	}
@@ -91,7 +92,7 @@ func TestPreviousDeployment_ok(t *testing.T) {
 	}
 	r := newMockHTTPRequester(jsonFunc)
 	about := AboutRequester{environment: "foo", requester: r}
-	got, err := about.PreviousDeployment()
+	got, err := about.PreviousDeployment(context.Background())
 	switch {
 	case err != nil:
 		t.Errorf("request failed: %v", err)
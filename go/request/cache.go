@@ -1,61 +1,312 @@
 package request
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"sync"
+	"time"
 )
 
-// Cache keeps track of recent requests.
-// A queue is used to track recent requests.  The stored recent request is returned as long as the request uri is in the cache.
-type Cache struct {
-	requestValues map[string][]byte
-	requestURIs   []string
-	index         int
-	mutex         *sync.Mutex
+type (
+	// Cache keeps the responses of recent requests so they can be reused until they expire.
+	// Expired responses are kept so they can be revalidated with conditional requests using their ETag or Last-Modified time.
+	// When the cache is full, the response that expires first is removed to make room for new responses.
+	Cache struct {
+		config    CacheConfig
+		now       func() time.Time
+		saveDelay time.Duration
+		mu        sync.Mutex
+		entries   map[string]cacheEntry
+		stats     CacheStats
+		saveTimer *time.Timer
+		saveMu    sync.Mutex
+	}
+
+	// CacheConfig configures how many responses are cached, how long they are fresh, and where they are saved.
+	CacheConfig struct {
+		// MaxEntries is the most responses that are kept.  Zero disables the cache.
+		MaxEntries int
+		// TTLs are how long responses to uris are fresh.  The first TTL with a pattern in the uri is used.
+		TTLs []CacheTTL
+		// DefaultTTL is how long responses to uris that do not match any TTLs are fresh.
+		DefaultTTL time.Duration
+		// Path is the file the cache is saved to so it is kept when the server restarts.  The cache is not saved if the path is empty.
+		Path string
+	}
+
+	// CacheTTL is how long responses to uris containing the pattern are fresh.
+	CacheTTL struct {
+		Pattern string
+		TTL     time.Duration
+	}
+
+	// CacheStats describe how the cache has been used since the server started.
+	CacheStats struct {
+		Entries int
+		// Hits are requests that used fresh cached responses.
+		Hits int
+		// Misses are requests that were made because they were not cached or their cached responses expired.
+		Misses int
+		// Revalidations are misses where the host reported the expired cached response was not modified.
+		Revalidations int
+		// SaveError is the error from the last time the cache was saved, if it failed.
+		SaveError string
+	}
+
+	cacheEntry struct {
+		Body         []byte    `json:"body"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified string    `json:"lastModified,omitempty"`
+		Expires      time.Time `json:"expires"`
+	}
+)
+
+// defaultCacheSaveDelay is how long changes to the cache are collected before it is saved.
+const defaultCacheSaveDelay = 10 * time.Second
+
+// defaultCacheTTLs keep responses that rarely change, such as player names, longer than stats and standings.
+var defaultCacheTTLs = []CacheTTL{
+	{Pattern: "/api/v1/people?", TTL: 7 * 24 * time.Hour}, // player names
+	{Pattern: "/api/v1/people/search?", TTL: time.Hour},
+	{Pattern: "named.search_player_all", TTL: time.Hour},
+	{Pattern: "/players/autocomplete", TTL: time.Hour},
+	{Pattern: "/nfl/athletes?", TTL: time.Hour},
+	{Pattern: "/deployments", TTL: time.Hour},
+	{Pattern: "/standings/", TTL: 5 * time.Minute},
+}
+
+// NewCacheConfig creates a CacheConfig that keeps the most responses, saving them to the path if it is not empty.
+// Player names are fresh for a week, searches for an hour, and stats and standings for five minutes.
+func NewCacheConfig(maxEntries int, path string) CacheConfig {
+	return CacheConfig{
+		MaxEntries: maxEntries,
+		TTLs:       defaultCacheTTLs,
+		DefaultTTL: 5 * time.Minute,
+		Path:       path,
+	}
+}
+
+// NewCache creates a Cache from the config
+func NewCache(cfg CacheConfig) *Cache {
+	if cfg.MaxEntries < 0 {
+		panic(fmt.Sprintf("cache size must be positive - got %v", cfg.MaxEntries))
+	}
+	return &Cache{
+		config:    cfg,
+		now:       time.Now,
+		saveDelay: defaultCacheSaveDelay,
+		entries:   make(map[string]cacheEntry, cfg.MaxEntries),
+	}
+}
+
+// ttl is how long the response of the uri is fresh.
+func (cfg CacheConfig) ttl(uri string) time.Duration {
+	for _, t := range cfg.TTLs {
+		if strings.Contains(uri, t.Pattern) {
+			return t.TTL
+		}
+	}
+	return cfg.DefaultTTL
+}
+
+// get returns the cached response for the uri and whether it is fresh.
+// Expired responses are returned so they can be revalidated.  A nil Cache does not have any responses.
+func (c *Cache) get(uri string) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[cacheKey(uri)]
+	if !ok || !c.now().Before(e.Expires) {
+		c.stats.Misses++
+		return e, false
+	}
+	c.stats.Hits++
+	return e, true
 }
 
-// NewCache creates a Cache with a specified queue size
-func NewCache(queueSize int) Cache {
-	if queueSize < 0 {
-		panic(fmt.Sprintf("cache size must be positive - got %v", queueSize))
+// add caches the response of the uri until its ttl expires.
+func (c *Cache) add(uri string, e cacheEntry) {
+	if c == nil || c.config.MaxEntries == 0 {
+		return
 	}
-	return Cache{
-		requestValues: make(map[string][]byte, queueSize),
-		requestURIs:   make([]string, queueSize),
-		index:         0,
-		mutex:         &sync.Mutex{},
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cacheKey(uri)
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.config.MaxEntries {
+		c.evict()
 	}
+	e.Expires = c.now().Add(c.config.ttl(key))
+	c.entries[key] = e
+	c.changed()
 }
 
-func (c *Cache) add(uri string, value []byte) {
-	if c.index >= len(c.requestURIs) {
+// revalidate makes the expired cached response of the uri fresh again after the host reported it was not modified.
+func (c *Cache) revalidate(uri string) {
+	if c == nil {
 		return
 	}
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	delete(c.requestValues, c.requestURIs[c.index])
-	c.requestValues[uri] = value
-	c.requestURIs[c.index] = uri
-	c.index++
-	if c.index == len(c.requestURIs) {
-		c.index = 0
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Revalidations++
+	key := cacheKey(uri)
+	e, ok := c.entries[key]
+	if !ok {
+		return
 	}
+	e.Expires = c.now().Add(c.config.ttl(key))
+	c.entries[key] = e
+	c.changed()
 }
 
-func (c *Cache) get(uri string) ([]byte, bool) {
-	b, ok := c.requestValues[uri]
-	return b, ok
+// cacheKey is the uri with its secret query params scrubbed so they are never saved with the cache.
+// Uris that cannot be parsed are used as their own keys.
+func cacheKey(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	return scrubURI(u)
+}
+
+// scrubbedQueryParams are removed from uris before they are saved so secrets such as the nfl app key are not saved.
+var scrubbedQueryParams = []string{"appKey"}
+
+// scrubURI removes secret query params from the url.  The remaining query params are sorted so equivalent uris are saved the same.
+func scrubURI(u *url.URL) string {
+	scrubbed := *u
+	q := scrubbed.Query()
+	for _, param := range scrubbedQueryParams {
+		q.Del(param)
+	}
+	scrubbed.RawQuery = q.Encode()
+	scrubbed.Fragment = ""
+	return scrubbed.String()
+}
+
+// evict removes the entry that expires first.  The mutex must be locked.
+func (c *Cache) evict() {
+	var evictURI string
+	var evictExpires time.Time
+	for uri, e := range c.entries {
+		if len(evictURI) == 0 || e.Expires.Before(evictExpires) {
+			evictURI, evictExpires = uri, e.Expires
+		}
+	}
+	delete(c.entries, evictURI)
 }
 
 // Clear removes stored responses for all the URIs is the Cache
 func (c *Cache) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	for k := range c.requestValues {
-		delete(c.requestValues, k)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		delete(c.entries, k)
+	}
+	c.changed()
+}
+
+// Stats describes how the Cache has been used.
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// changed schedules the Cache to be saved if it has a path.  The mutex must be locked.
+func (c *Cache) changed() {
+	if len(c.config.Path) == 0 || c.saveTimer != nil {
+		return
+	}
+	c.saveTimer = time.AfterFunc(c.saveDelay, func() {
+		c.Save() // the error is recorded in the stats
+	})
+}
+
+// Save writes the responses in the Cache to its path.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	if c.saveTimer != nil {
+		c.saveTimer.Stop()
+		c.saveTimer = nil
+	}
+	b, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err == nil {
+		err = c.write(b)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.SaveError = ""
+	if err != nil {
+		err = fmt.Errorf("saving cache: %w", err)
+		c.stats.SaveError = err.Error()
+	}
+	return err
+}
+
+// write replaces the file at the path of the Cache with the data.
+// The data is written to a temporary file first so a partially written cache is never loaded.
+func (c *Cache) write(b []byte) error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	tempPath := c.config.Path + ".tmp"
+	if err := os.WriteFile(tempPath, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, c.config.Path)
+}
+
+// Load reads the responses saved at the path of the Cache.
+// It is not an error for the file to not exist.
+func (c *Cache) Load() error {
+	if len(c.config.Path) == 0 {
+		return nil
+	}
+	b, err := os.ReadFile(c.config.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("loading cache: %w", err)
+	}
+	var entries map[string]cacheEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return fmt.Errorf("reading saved cache: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uri, e := range entries {
+		c.entries[cacheKey(uri)] = e // caches saved before secrets were scrubbed are scrubbed when loaded
+	}
+	for len(c.entries) > c.config.MaxEntries {
+		c.evict()
+	}
+	return nil
+}
+
+// conditionalHeader is the header to request the uri of the response only if it has been modified.
+func (e cacheEntry) conditionalHeader() http.Header {
+	header := make(http.Header)
+	if len(e.ETag) != 0 {
+		header.Set("If-None-Match", e.ETag)
 	}
-	for i := range c.requestURIs {
-		c.requestURIs[i] = ""
+	if len(e.LastModified) != 0 {
+		header.Set("If-Modified-Since", e.LastModified)
 	}
-	c.index = 0
+	return header
 }
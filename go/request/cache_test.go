@@ -1,9 +1,16 @@
 package request
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewCacheTooSmall(t *testing.T) {
@@ -13,11 +20,11 @@ func TestNewCacheTooSmall(t *testing.T) {
 			t.Errorf("wanted panic when trying to create cache with size=%d, but did not get one", cacheSize)
 		}
 	}()
-	NewCache(cacheSize)
+	NewCache(CacheConfig{MaxEntries: cacheSize})
 }
 
 func TestContainsNo(t *testing.T) {
-	cache := NewCache(5)
+	cache := NewCache(NewCacheConfig(5, ""))
 	uri := "uri"
 	_, got := cache.get(uri)
 	want := false
@@ -27,9 +34,21 @@ func TestContainsNo(t *testing.T) {
 }
 
 func TestContainsZero(t *testing.T) {
-	cache := NewCache(0)
+	cache := NewCache(NewCacheConfig(0, ""))
 	uri := "uri"
-	cache.add(uri, nil)
+	cache.add(uri, cacheEntry{})
+	_, got := cache.get(uri)
+	want := false
+	if want != got {
+		t.Errorf("wanted %v to not be in the cache, but it was", uri)
+	}
+}
+
+func TestContainsNil(t *testing.T) {
+	var cache *Cache
+	uri := "uri"
+	cache.add(uri, cacheEntry{})
+	cache.revalidate(uri)
 	_, got := cache.get(uri)
 	want := false
 	if want != got {
@@ -38,9 +57,9 @@ func TestContainsZero(t *testing.T) {
 }
 
 func TestContainsYes(t *testing.T) {
-	cache := NewCache(5)
+	cache := NewCache(NewCacheConfig(5, ""))
 	uri := "uri"
-	cache.add(uri, nil)
+	cache.add(uri, cacheEntry{})
 	_, got := cache.get(uri)
 	want := true
 	if want != got {
@@ -50,26 +69,48 @@ func TestContainsYes(t *testing.T) {
 
 func TestContainsNoAfterManyOtherAdds(t *testing.T) {
 	cacheSize := 10
-	cache := NewCache(cacheSize)
+	cache := NewCache(NewCacheConfig(cacheSize, ""))
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
 	uri := "uri"
-	cache.add(uri, nil)
+	cache.add(uri, cacheEntry{})
 	for i := 0; i < cacheSize; i++ {
+		now = now.Add(time.Second)
 		j := strconv.Itoa(i)
-		cache.add(j, []byte(j))
+		cache.add(j, cacheEntry{Body: []byte(j)})
 	}
 	_, got := cache.get(uri)
 	want := false
 	if want != got {
 		t.Errorf("wanted %v to not be in the cache after it should be forgotten, but it was", uri)
 	}
+	if want, got := cacheSize, cache.Stats().Entries; want != got {
+		t.Errorf("wanted cache to have %v entries, got %v", want, got)
+	}
+}
+
+func TestContainsLongTTLAfterManyOtherAdds(t *testing.T) {
+	cfg := NewCacheConfig(2, "")
+	cfg.TTLs = []CacheTTL{{Pattern: "names", TTL: time.Hour}}
+	cfg.DefaultTTL = time.Minute
+	cache := NewCache(cfg)
+	uri := "names"
+	cache.add(uri, cacheEntry{})
+	for i := 0; i < 3; i++ {
+		cache.add(strconv.Itoa(i), cacheEntry{})
+	}
+	if _, ok := cache.get(uri); !ok {
+		t.Errorf("wanted entries that expire first to be forgotten before %v", uri)
+	}
 }
 
 func TestGet(t *testing.T) {
-	cache := NewCache(10)
+	cache := NewCache(NewCacheConfig(10, ""))
 	uri := "uri"
 	value := []byte("abc")
-	cache.add(uri, value)
-	got, ok := cache.get(uri)
+	cache.add(uri, cacheEntry{Body: value})
+	e, ok := cache.get(uri)
+	got := e.Body
 	want := value
 	switch {
 	case !ok:
@@ -79,10 +120,62 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetExpired(t *testing.T) {
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	cfg := NewCacheConfig(10, "")
+	cfg.TTLs = []CacheTTL{{Pattern: "/standings/", TTL: time.Minute}}
+	cfg.DefaultTTL = time.Hour
+	cache := NewCache(cfg)
+	cache.now = func() time.Time { return now }
+	standingsURI := "http://statsapi.mlb.com/api/v1/standings/regularSeason"
+	otherURI := "http://statsapi.mlb.com/api/v1/teams"
+	cache.add(standingsURI, cacheEntry{Body: []byte("standings"), ETag: "v1"})
+	cache.add(otherURI, cacheEntry{Body: []byte("teams")})
+	now = now.Add(time.Minute)
+	e, ok := cache.get(standingsURI)
+	switch {
+	case ok:
+		t.Errorf("wanted %v to expire after its ttl", standingsURI)
+	case string(e.Body) != "standings" || e.ETag != "v1":
+		t.Errorf("wanted expired entry to be returned to revalidate, got %v", e)
+	}
+	if _, ok := cache.get(otherURI); !ok {
+		t.Errorf("wanted %v to use the default ttl", otherURI)
+	}
+	cache.revalidate(standingsURI)
+	if _, ok := cache.get(standingsURI); !ok {
+		t.Errorf("wanted %v to be fresh after it is revalidated", standingsURI)
+	}
+	want := CacheStats{Entries: 2, Hits: 2, Misses: 1, Revalidations: 1}
+	if got := cache.Stats(); want != got {
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestCacheConfigTTL(t *testing.T) {
+	cfg := NewCacheConfig(1, "")
+	cacheConfigTTLTests := []struct {
+		uri  string
+		want time.Duration
+	}{
+		{uri: "http://statsapi.mlb.com/api/v1/people?personIds=547180&fields=people,id,fullName", want: 7 * 24 * time.Hour},
+		{uri: "http://statsapi.mlb.com/api/v1/people/547180/stats?&season=2019&stats=season", want: 5 * time.Minute},
+		{uri: "http://statsapi.mlb.com/api/v1/standings/regularSeason?leagueId=103,104&season=2019", want: 5 * time.Minute},
+		{uri: "https://api.fantasy.nfl.com/v2/players/autocomplete?positionIds=1&query=tom&appKey=key", want: time.Hour},
+		{uri: "https://api.github.com/repos/owner/repo/deployments", want: time.Hour},
+	}
+	for i, test := range cacheConfigTTLTests {
+		got := cfg.ttl(test.uri)
+		if test.want != got {
+			t.Errorf("Test %v: wanted ttl of %v for %v, got %v", i, test.want, test.uri, got)
+		}
+	}
+}
+
 func TestClear(t *testing.T) {
-	cache := NewCache(1)
+	cache := NewCache(NewCacheConfig(1, ""))
 	uri := "uri"
-	cache.add(uri, nil)
+	cache.add(uri, cacheEntry{})
 	cache.Clear()
 	_, got := cache.get(uri)
 	want := false
@@ -90,3 +183,160 @@ func TestClear(t *testing.T) {
 		t.Errorf("wanted cache to not contain uri %v after clearing, but it was present", uri)
 	}
 }
+
+func TestCacheSaveLoad(t *testing.T) {
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewCache(NewCacheConfig(10, path))
+	cache.now = func() time.Time { return now }
+	cache.saveDelay = time.Hour
+	cache.add("uri", cacheEntry{Body: []byte("abc"), ETag: "v1"})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("unexpected error saving cache: %v", err)
+	}
+	loadedCache := NewCache(NewCacheConfig(10, path))
+	loadedCache.now = func() time.Time { return now }
+	if err := loadedCache.Load(); err != nil {
+		t.Fatalf("unexpected error loading cache: %v", err)
+	}
+	want := cacheEntry{Body: []byte("abc"), ETag: "v1", Expires: now.Add(5 * time.Minute)}
+	got, ok := loadedCache.get("uri")
+	switch {
+	case !ok:
+		t.Error("wanted saved entry to be loaded")
+	case !reflect.DeepEqual(want.Body, got.Body) || want.ETag != got.ETag || !want.Expires.Equal(got.Expires):
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestCacheSave_scrubsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewCache(NewCacheConfig(10, path))
+	cache.saveDelay = time.Hour
+	uri := "https://api.fantasy.nfl.com/v2/players/autocomplete?query=tom&appKey=s3cr3t"
+	cache.add(uri, cacheEntry{Body: []byte("abc")})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("unexpected error saving cache: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading saved cache: %v", err)
+	}
+	if strings.Contains(string(b), "s3cr3t") {
+		t.Errorf("wanted app key to be scrubbed from saved cache: %s", b)
+	}
+	loadedCache := NewCache(NewCacheConfig(10, path))
+	if err := loadedCache.Load(); err != nil {
+		t.Fatalf("unexpected error loading cache: %v", err)
+	}
+	if got, ok := loadedCache.get(uri); !ok || string(got.Body) != "abc" {
+		t.Errorf("wanted saved response to be used for request with app key, got %q", got.Body)
+	}
+}
+
+func TestCacheLoad(t *testing.T) {
+	dir := t.TempDir()
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cacheLoadTests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{}, // not saved
+		{path: filepath.Join(dir, "missing.json")},
+		{path: invalidPath, wantErr: true},
+	}
+	for i, test := range cacheLoadTests {
+		cache := NewCache(NewCacheConfig(10, test.path))
+		err := cache.Load()
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestCacheSave_error(t *testing.T) {
+	cache := NewCache(NewCacheConfig(10, filepath.Join(t.TempDir(), "missing", "cache.json")))
+	if err := cache.Save(); err == nil {
+		t.Error("wanted error saving cache to missing directory")
+	}
+	if len(cache.Stats().SaveError) == 0 {
+		t.Error("wanted save error in stats")
+	}
+}
+
+func TestCacheChanged_saves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewCache(NewCacheConfig(10, path))
+	cache.saveDelay = 0
+	cache.add("uri", cacheEntry{Body: []byte("abc")})
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("wanted cache to be saved after it changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHTTPRequesterCachedBytes_revalidate(t *testing.T) {
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	var requestHeaders []http.Header
+	r := httpRequester{
+		cache: NewCache(NewCacheConfig(10, "")),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				requestHeaders = append(requestHeaders, r.Header)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					return &http.Response{
+						StatusCode: http.StatusNotModified,
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil
+				}
+				response := http.Response{
+					StatusCode: http.StatusOK,
+					Header: http.Header{
+						"Etag":          {`"v1"`},
+						"Last-Modified": {"Wed, 21 Aug 2019 10:00:00 GMT"},
+					},
+					Body: io.NopCloser(strings.NewReader("7")),
+				}
+				return &response, nil
+			},
+		},
+	}
+	r.cache.now = func() time.Time { return now }
+	uri := "http://statsapi.mlb.com/api/v1/teams"
+	for i := 0; i < 3; i++ {
+		var got int
+		if err := r.structPointerFromURI(context.Background(), uri, &got); err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		}
+		if want := 7; want != got {
+			t.Errorf("Test %v: wanted %v, got %v", i, want, got)
+		}
+		now = now.Add(3 * time.Minute)
+	}
+	switch {
+	case len(requestHeaders) != 2:
+		t.Errorf("wanted fresh response to be used instead of requesting again, got %v requests", len(requestHeaders))
+	case len(requestHeaders[0].Get("If-None-Match")) != 0:
+		t.Errorf("wanted first request to not be conditional, got %v", requestHeaders[0])
+	case requestHeaders[1].Get("If-Modified-Since") != "Wed, 21 Aug 2019 10:00:00 GMT":
+		t.Errorf("wanted expired response to be requested if modified since it was cached, got %v", requestHeaders[1])
+	}
+	want := CacheStats{Entries: 1, Hits: 1, Misses: 2, Revalidations: 1}
+	if got := r.cache.Stats(); want != got {
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
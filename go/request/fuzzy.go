@@ -0,0 +1,273 @@
+package request
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+// The relevance of matches of a query to a name, from most to least relevant.
+// Fuzzy matches are less relevant for each typo.
+const (
+	matchExact         = 100
+	matchAlias         = 95
+	matchPrefix        = 90
+	matchTokenPrefixes = 80
+	matchContains      = 70
+	matchFuzzy         = 60
+	matchTypo          = 10
+)
+
+// foldedRunes are accented letters and the letters they are folded to
+var foldedRunes = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r",
+	'ś': "s", 'ş': "s", 'š': "s", 'ß': "ss",
+	'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'æ': "ae", 'œ': "oe",
+}
+
+// foldName lowercases the name, removes accents and punctuation, and separates its words with single spaces.
+// Hyphens separate words, so "D-backs" is folded to "d backs".
+func foldName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch folded, ok := foldedRunes[r]; {
+		case ok:
+			sb.WriteString(folded)
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			sb.WriteRune(r)
+		case unicode.IsSpace(r), r == '-', r == '_':
+			sb.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// matchScore is the relevance of the best match of the query to the name or one of its aliases, such as an abbreviation.
+// Zero means the query does not match.
+func matchScore(query, name string, aliases ...string) int {
+	q := foldName(query)
+	if len(q) == 0 {
+		return 0
+	}
+	best := nameMatchScore(q, foldName(name))
+	for _, alias := range aliases {
+		if score := min(nameMatchScore(q, foldName(alias)), matchAlias); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// nameMatchScore is the relevance of the folded query to the folded name.
+func nameMatchScore(q, name string) int {
+	switch {
+	case len(name) == 0:
+		return 0
+	case q == name:
+		return matchExact
+	case strings.HasPrefix(name, q):
+		return matchPrefix
+	}
+	qTokens, nameTokens := strings.Fields(q), strings.Fields(name)
+	if allTokensArePrefixes(qTokens, nameTokens) {
+		return matchTokenPrefixes
+	}
+	if strings.Contains(name, q) {
+		return matchContains
+	}
+	typos := 0
+	for _, qToken := range qTokens {
+		tokenTypos := -1
+		for _, nameToken := range nameTokens {
+			if d := tokenDistance(qToken, nameToken); tokenTypos < 0 || d < tokenTypos {
+				tokenTypos = d
+			}
+		}
+		if tokenTypos < 0 || tokenTypos > maxTypos(qToken) {
+			return 0
+		}
+		typos += tokenTypos
+	}
+	return max(matchFuzzy-typos*matchTypo, 1)
+}
+
+// allTokensArePrefixes determines if each of the query tokens is the start of one of the name tokens.
+func allTokensArePrefixes(qTokens, nameTokens []string) bool {
+	for _, qToken := range qTokens {
+		found := false
+		for _, nameToken := range nameTokens {
+			if strings.HasPrefix(nameToken, qToken) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// maxTypos is the most typos a query token can have to fuzzily match a name token.  Short tokens must not have typos.
+func maxTypos(qToken string) int {
+	switch n := len([]rune(qToken)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// tokenDistance is the fewest typos to change the query token into the name token or one of its prefixes about as long as the query token,
+// so partially typed names match.
+func tokenDistance(qToken, nameToken string) int {
+	q, name := []rune(qToken), []rune(nameToken)
+	best := editDistance(q, name)
+	for n := len(q) - 1; n <= len(q)+1; n++ {
+		if n > 0 && n < len(name) {
+			best = min(best, editDistance(q, name[:n]))
+		}
+	}
+	return best
+}
+
+// editDistance is the fewest insertions, deletions, substitutions, and transpositions of adjacent runes to change a into b.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// rankSearchResults sorts the results that match the query by relevance, keeping the order of equally relevant results.
+// Teams also match their aliases.  The results that do not match the query are returned separately, in their original order.
+func rankSearchResults(pt db.PlayerType, query string, results []PlayerSearchResult) (matched, unmatched []PlayerSearchResult) {
+	aliases := teamAliases(pt)
+	scores := make(map[int]int, len(results))
+	indexes := make([]int, 0, len(results))
+	for i, result := range results {
+		if score := matchScore(query, result.Name, aliases[foldName(result.Name)]...); score > 0 {
+			scores[i] = score
+			indexes = append(indexes, i)
+		} else {
+			unmatched = append(unmatched, result)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+	for _, i := range indexes {
+		matched = append(matched, results[i])
+	}
+	return matched, unmatched
+}
+
+// BestMatch is the result the query clearly names: the only result, or the result that matches the query better than all others by at least the prefixes of its words.
+// Teams also match their aliases.  False is returned if the query is ambiguous, such as when two players have the same name.
+func BestMatch(pt db.PlayerType, query string, results []PlayerSearchResult) (PlayerSearchResult, bool) {
+	if len(results) == 1 {
+		return results[0], true
+	}
+	aliases := teamAliases(pt)
+	best, bestScore, runnerUpScore := -1, 0, 0
+	for i, result := range results {
+		switch score := matchScore(query, result.Name, aliases[foldName(result.Name)]...); {
+		case score > bestScore:
+			best, bestScore, runnerUpScore = i, score, bestScore
+		case score > runnerUpScore:
+			runnerUpScore = score
+		}
+	}
+	if bestScore < matchTokenPrefixes || bestScore == runnerUpScore {
+		return PlayerSearchResult{}, false
+	}
+	return results[best], true
+}
+
+// broadenQuery is the start of the longest word of the query, used to search again when the query has typos that prevent sources from finding any names.
+// The last of equally long words is used because it is usually the last name.  The query is not broadened if it is too short.
+func broadenQuery(query string) (string, bool) {
+	const broadQueryLength = 3
+	longest := ""
+	for _, token := range strings.Fields(foldName(query)) {
+		if len(token) >= len(longest) {
+			longest = token
+		}
+	}
+	if len([]rune(longest)) <= broadQueryLength {
+		return "", false
+	}
+	return string([]rune(longest)[:broadQueryLength]), true
+}
+
+// FilterSearchResults removes the results that are not on the team or do not play the position.
+// The team can be an abbreviation or another alias of a team in the sport of the PlayerType, such as "Yankees" or "NYY".  Empty filters are not applied.
+func FilterSearchResults(pt db.PlayerType, results []PlayerSearchResult, team, position string) []PlayerSearchResult {
+	team, position = strings.TrimSpace(team), strings.TrimSpace(position)
+	if len(team) == 0 && len(position) == 0 {
+		return results
+	}
+	filtered := make([]PlayerSearchResult, 0, len(results))
+	for _, result := range results {
+		if (len(position) == 0 || strings.EqualFold(result.Position, position)) &&
+			(len(team) == 0 || teamMatches(pt, result.Team, team)) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// teamMatches determines if the team abbreviation of a player is the filter or an alias of the team the filter names.
+func teamMatches(pt db.PlayerType, abbreviation, filter string) bool {
+	if len(abbreviation) == 0 {
+		return false
+	}
+	if strings.EqualFold(abbreviation, filter) {
+		return true
+	}
+	for name, aliases := range teamAliases(pt) {
+		if matchScore(filter, name, aliases...) < matchTokenPrefixes {
+			continue
+		}
+		for _, alias := range aliases {
+			if strings.EqualFold(abbreviation, alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
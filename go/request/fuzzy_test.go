@@ -0,0 +1,186 @@
+package request
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+func TestFoldName(t *testing.T) {
+	foldNameTests := map[string]string{
+		"Ronald Acuña Jr.":     "ronald acuna jr",
+		"  José   Ramírez ":    "jose ramirez",
+		"Arizona D-backs":      "arizona d backs",
+		"Travis d'Arnaud":      "travis darnaud",
+		"A.J. Brown":           "aj brown",
+		"Søren Großkopf-Ødegå": "soren grosskopf odega",
+		"":                     "",
+	}
+	for name, want := range foldNameTests {
+		if got := foldName(name); want != got {
+			t.Errorf("wanted %q to be folded to %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	matchScoreTests := []struct {
+		query   string
+		name    string
+		aliases []string
+		want    int
+	}{
+		{query: "Mike Trout", name: "Mike Trout", want: matchExact},
+		{query: "acuna", name: "Ronald Acuña Jr.", want: matchTokenPrefixes},
+		{query: "NYY", name: "New York Yankees", aliases: []string{"NYY", "Yankees"}, want: matchAlias},
+		{query: "yankees", name: "New York Yankees", aliases: []string{"NYY", "Yankees"}, want: matchAlias},
+		{query: "new york", name: "New York Yankees", want: matchPrefix},
+		{query: "mi tr", name: "Mike Trout", want: matchTokenPrefixes},
+		{query: "ke tr", name: "Mike Trout", want: matchContains},
+		{query: "mike trot", name: "Mike Trout", want: matchFuzzy - matchTypo},
+		{query: "trotu", name: "Mike Trout", want: matchFuzzy - matchTypo}, // transposition
+		{query: "cristian yelich", name: "Christian Yelich", want: matchFuzzy - matchTypo},
+		{query: "mkie trot", name: "Mike Trout", want: matchFuzzy - 2*matchTypo},
+		{query: "tout", name: "Mike Trout", want: matchFuzzy - matchTypo},
+		{query: "trt", name: "Mike Trout"},   // too short to have typos
+		{query: "tarot", name: "Mike Trout"}, // deletion and insertion
+		{query: "trxxt", name: "Mike Trout"}, // too many typos
+		{query: "betts", name: "Mike Trout"},
+		{query: "...", name: "Mike Trout"},
+	}
+	for i, test := range matchScoreTests {
+		if got := matchScore(test.query, test.name, test.aliases...); test.want != got {
+			t.Errorf("Test %v: wanted score of %q for %q to be %v, got %v", i, test.query, test.name, test.want, got)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	editDistanceTests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "trout", b: "", want: 5},
+		{a: "trout", b: "trout", want: 0},
+		{a: "trotu", b: "trout", want: 1},
+		{a: "trot", b: "trout", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "peña", b: "pena", want: 1},
+	}
+	for i, test := range editDistanceTests {
+		if got := editDistance([]rune(test.a), []rune(test.b)); test.want != got {
+			t.Errorf("Test %v: wanted edit distance from %q to %q to be %v, got %v", i, test.a, test.b, test.want, got)
+		}
+	}
+}
+
+func TestRankSearchResults(t *testing.T) {
+	results := []PlayerSearchResult{
+		{Name: "Mike Trout", SourceID: 1},
+		{Name: "Mookie Betts", SourceID: 2},
+		{Name: "Mike Trotter", SourceID: 3},
+		{Name: "Trout Mikeson", SourceID: 4},
+	}
+	wantMatched := []PlayerSearchResult{
+		{Name: "Mike Trout", SourceID: 1},
+		{Name: "Trout Mikeson", SourceID: 4},
+		{Name: "Mike Trotter", SourceID: 3},
+	}
+	wantUnmatched := []PlayerSearchResult{
+		{Name: "Mookie Betts", SourceID: 2},
+	}
+	gotMatched, gotUnmatched := rankSearchResults(db.PlayerTypeMlbHitter, "mike trout", results)
+	if !reflect.DeepEqual(wantMatched, gotMatched) {
+		t.Errorf("matched results not equal:\nwanted: %v\ngot:    %v", wantMatched, gotMatched)
+	}
+	if !reflect.DeepEqual(wantUnmatched, gotUnmatched) {
+		t.Errorf("unmatched results not equal:\nwanted: %v\ngot:    %v", wantUnmatched, gotUnmatched)
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	bestMatchTests := []struct {
+		pt      db.PlayerType
+		query   string
+		results []string
+		want    string
+		wantOk  bool
+	}{
+		{pt: db.PlayerTypeMlbHitter, query: "mike trot", results: []string{"Mike Trout"}, want: "Mike Trout", wantOk: true},
+		{pt: db.PlayerTypeMlbHitter, query: "juan soto", results: []string{"Juan Sotomayor", "Juan Soto"}, want: "Juan Soto", wantOk: true},
+		{pt: db.PlayerTypeMlbHitter, query: "acuna", results: []string{"Ronald Acuña Jr.", "Mike Trout"}, want: "Ronald Acuña Jr.", wantOk: true},
+		{pt: db.PlayerTypeMlbHitter, query: "will smith", results: []string{"Will Smith", "Will Smith"}},
+		{pt: db.PlayerTypeMlbHitter, query: "smith", results: []string{"Will Smith", "Dominic Smith"}},
+		{pt: db.PlayerTypeMlbHitter, query: "mkie trout", results: []string{"Mike Trout", "Mike Trotter"}}, // typos are not clear enough
+		{pt: db.PlayerTypeMlbTeam, query: "Yankees", results: []string{"New York Mets", "New York Yankees"}, want: "New York Yankees", wantOk: true},
+		{pt: db.PlayerTypeMlbHitter, query: "trout"},
+	}
+	for i, test := range bestMatchTests {
+		results := make([]PlayerSearchResult, len(test.results))
+		for j, name := range test.results {
+			results[j] = PlayerSearchResult{Name: name, SourceID: db.SourceID(j + 1)}
+		}
+		got, gotOk := BestMatch(test.pt, test.query, results)
+		if test.want != got.Name || test.wantOk != gotOk {
+			t.Errorf("Test %v: wanted best match of %q to be %q (%v), got %q (%v)", i, test.query, test.want, test.wantOk, got.Name, gotOk)
+		}
+	}
+}
+
+func TestBroadenQuery(t *testing.T) {
+	broadenQueryTests := []struct {
+		query  string
+		want   string
+		wantOk bool
+	}{
+		{query: "trot", want: "tro", wantOk: true},
+		{query: "mike trot", want: "tro", wantOk: true},
+		{query: "Acuñaa Ronald", want: "ron", wantOk: true},
+		{query: "ohtani s", want: "oht", wantOk: true},
+		{query: "tro"},
+		{query: "al x"},
+		{query: ""},
+	}
+	for i, test := range broadenQueryTests {
+		got, gotOk := broadenQuery(test.query)
+		if test.want != got || test.wantOk != gotOk {
+			t.Errorf("Test %v: wanted %q to be broadened to %q (%v), got %q (%v)", i, test.query, test.want, test.wantOk, got, gotOk)
+		}
+	}
+}
+
+func TestFilterSearchResults(t *testing.T) {
+	results := []PlayerSearchResult{
+		{Name: "Aaron Judge", Position: "RF", Team: "NYY"},
+		{Name: "Gerrit Cole", Position: "P", Team: "NYY"},
+		{Name: "Pete Alonso", Position: "1B", Team: "NYM"},
+		{Name: "Ketel Marte", Position: "2B", Team: "AZ"},
+		{Name: "Shohei Ohtani", Position: "TWP"},
+	}
+	filterSearchResultsTests := []struct {
+		pt       db.PlayerType
+		team     string
+		position string
+		want     []string
+	}{
+		{pt: db.PlayerTypeMlbHitter, want: []string{"Aaron Judge", "Gerrit Cole", "Pete Alonso", "Ketel Marte", "Shohei Ohtani"}},
+		{pt: db.PlayerTypeMlbHitter, team: "nyy", want: []string{"Aaron Judge", "Gerrit Cole"}},
+		{pt: db.PlayerTypeMlbHitter, team: "Yankees", want: []string{"Aaron Judge", "Gerrit Cole"}},
+		{pt: db.PlayerTypeMlbHitter, team: "new york", want: []string{"Aaron Judge", "Gerrit Cole", "Pete Alonso"}},
+		{pt: db.PlayerTypeMlbHitter, team: "ARI", want: []string{"Ketel Marte"}},
+		{pt: db.PlayerTypeMlbHitter, team: "Cardinals"}, // not the Arizona Cardinals of the nfl
+		{pt: db.PlayerTypeMlbHitter, team: "NYY", position: "p", want: []string{"Gerrit Cole"}},
+		{pt: db.PlayerTypeMlbHitter, position: "TWP", want: []string{"Shohei Ohtani"}},
+	}
+	for i, test := range filterSearchResultsTests {
+		var got []string
+		for _, result := range FilterSearchResults(test.pt, results, test.team, test.position) {
+			got = append(got, result.Name)
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("Test %v: filtered results not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
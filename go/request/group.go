@@ -0,0 +1,60 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// requestGroup runs requests concurrently, canceling the outstanding requests when any of them fails.
+type requestGroup struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errs   []error
+}
+
+func newRequestGroup(ctx context.Context) *requestGroup {
+	groupCtx, cancel := context.WithCancel(ctx)
+	return &requestGroup{
+		parent: ctx,
+		ctx:    groupCtx,
+		cancel: cancel,
+	}
+}
+
+// do calls the function in a new goroutine with the context of the group.
+func (g *requestGroup) do(f func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// fail records the error and cancels the other requests.
+// Errors caused by the cancellation are not recorded.
+func (g *requestGroup) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if errors.Is(err, context.Canceled) && g.ctx.Err() != nil {
+		return
+	}
+	g.errs = append(g.errs, err)
+	g.cancel()
+}
+
+// wait blocks until all functions have returned.
+// It returns the errors of all failed requests, or the error of the parent context if it is done.
+func (g *requestGroup) wait() error {
+	g.wg.Wait()
+	g.cancel()
+	if err := g.parent.Err(); err != nil {
+		return err
+	}
+	return errors.Join(g.errs...)
+}
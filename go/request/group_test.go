@@ -0,0 +1,101 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkGoroutines fails the test if the number of goroutines does not fall back to the number running before the test.
+func checkGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := runtime.NumGoroutine()
+		if got <= before {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("wanted all goroutines to exit, but %v are still running", got-before)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRequestGroupWait(t *testing.T) {
+	requestGroupWaitTests := []struct {
+		funcs    []func(ctx context.Context) error
+		wantErrs []string
+	}{
+		{}, // no requests
+		{ // happy path
+			funcs: []func(ctx context.Context) error{
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil },
+			},
+		},
+		{ // all failures are returned, outstanding requests are canceled
+			funcs: []func(ctx context.Context) error{
+				func(ctx context.Context) error { return errors.New("error 1") },
+				func(ctx context.Context) error { return errors.New("error 2") },
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			wantErrs: []string{"error 1", "error 2"},
+		},
+	}
+	for i, test := range requestGroupWaitTests {
+		before := runtime.NumGoroutine()
+		g := newRequestGroup(context.Background())
+		for _, f := range test.funcs {
+			g.do(f)
+		}
+		err := g.wait()
+		switch {
+		case len(test.wantErrs) == 0:
+			if err != nil {
+				t.Errorf("Test %v: unexpected error: %v", i, err)
+			}
+		case err == nil:
+			t.Errorf("Test %v: wanted error but did not get one", i)
+		default:
+			for _, wantErr := range test.wantErrs {
+				if !strings.Contains(err.Error(), wantErr) {
+					t.Errorf("Test %v: wanted error to contain %q, got %v", i, wantErr, err)
+				}
+			}
+			if errors.Is(err, context.Canceled) {
+				t.Errorf("Test %v: wanted errors of canceled requests to be ignored, got %v", i, err)
+			}
+		}
+		checkGoroutines(t, before)
+	}
+}
+
+func TestRequestGroupWait_parentCanceled(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	g := newRequestGroup(ctx)
+	started := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		g.do(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	cancelFunc()
+	if err := g.wait(); err != context.Canceled {
+		t.Errorf("wanted %v, got %v", context.Canceled, err)
+	}
+	checkGoroutines(t, before)
+}
@@ -0,0 +1,105 @@
+package request
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// HostLimits restrict how requests are made to each external host so the hosts do not throttle or ban the server.
+	HostLimits struct {
+		// MaxConcurrentRequests is the most requests that can be made to a host at once.  Zero allows any number of requests.
+		MaxConcurrentRequests int
+		// RequestsPerSecond is the most requests that can be started to a host each second.  Zero allows any rate.
+		RequestsPerSecond float64
+	}
+
+	// hostLimiters make requests to hosts wait until they are within the HostLimits.
+	hostLimiters struct {
+		limits HostLimits
+		mu     sync.Mutex
+		hosts  map[string]*hostLimiter
+		now    func() time.Time
+		sleep  func(ctx context.Context, d time.Duration) error
+	}
+
+	hostLimiter struct {
+		slots chan struct{}
+		next  time.Time
+	}
+)
+
+// NewHostLimits creates HostLimits with the maximum number of concurrent requests and requests per second for each host.
+func NewHostLimits(maxConcurrentRequests int, requestsPerSecond float64) HostLimits {
+	return HostLimits{
+		MaxConcurrentRequests: maxConcurrentRequests,
+		RequestsPerSecond:     requestsPerSecond,
+	}
+}
+
+func newHostLimiters(limits HostLimits) *hostLimiters {
+	return &hostLimiters{
+		limits: limits,
+		hosts:  make(map[string]*hostLimiter),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// wait blocks until a request to the host can be made within the limits or the context is done.
+// The returned func must be called after the request is done.  A nil hostLimiters does not limit requests.
+func (hls *hostLimiters) wait(ctx context.Context, host string) (done func(), err error) {
+	done = func() {}
+	if hls == nil {
+		return done, nil
+	}
+	hl := hls.hostLimiter(host)
+	if hl.slots != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case hl.slots <- struct{}{}:
+		}
+		done = func() { <-hl.slots }
+	}
+	if d := hls.reserve(hl); d > 0 {
+		if err := hls.sleep(ctx, d); err != nil {
+			done()
+			return nil, err
+		}
+	}
+	return done, nil
+}
+
+func (hls *hostLimiters) hostLimiter(host string) *hostLimiter {
+	hls.mu.Lock()
+	defer hls.mu.Unlock()
+	hl, ok := hls.hosts[host]
+	if !ok {
+		hl = new(hostLimiter)
+		if hls.limits.MaxConcurrentRequests > 0 {
+			hl.slots = make(chan struct{}, hls.limits.MaxConcurrentRequests)
+		}
+		hls.hosts[host] = hl
+	}
+	return hl
+}
+
+// reserve schedules the next request to the host, returning how long to wait before making it.
+// Requests are spaced evenly so no more than RequestsPerSecond are started in any second.
+func (hls *hostLimiters) reserve(hl *hostLimiter) time.Duration {
+	if hls.limits.RequestsPerSecond <= 0 {
+		return 0
+	}
+	interval := time.Duration(float64(time.Second) / hls.limits.RequestsPerSecond)
+	hls.mu.Lock()
+	defer hls.mu.Unlock()
+	now := hls.now()
+	start := hl.next
+	if start.Before(now) {
+		start = now
+	}
+	hl.next = start.Add(interval)
+	return start.Sub(now)
+}
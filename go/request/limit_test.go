@@ -0,0 +1,169 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostLimitersWait_rate(t *testing.T) {
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	hls := newHostLimiters(NewHostLimits(0, 4))
+	hls.now = func() time.Time { return now }
+	hls.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	hostLimitersWaitTests := []struct {
+		host     string
+		advance  time.Duration
+		wantWait time.Duration
+	}{
+		{host: "a"},
+		{host: "a", wantWait: 250 * time.Millisecond},
+		{host: "a", wantWait: 500 * time.Millisecond},
+		{host: "b"}, // other hosts are not affected
+		{host: "a", advance: 200 * time.Millisecond, wantWait: 550 * time.Millisecond},
+		{host: "a", advance: 2 * time.Second}, // waits do not accumulate while idle
+		{host: "a", wantWait: 250 * time.Millisecond},
+	}
+	for i, test := range hostLimitersWaitTests {
+		now = now.Add(test.advance)
+		waits = nil
+		done, err := hls.wait(context.Background(), test.host)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		done()
+		var gotWait time.Duration
+		if len(waits) != 0 {
+			gotWait = waits[0]
+		}
+		if test.wantWait != gotWait {
+			t.Errorf("Test %v: wanted wait of %v, got %v", i, test.wantWait, gotWait)
+		}
+	}
+}
+
+func TestHostLimitersWait_concurrency(t *testing.T) {
+	hls := newHostLimiters(NewHostLimits(2, 0))
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	started := make(chan struct{}, 6)
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := hls.wait(context.Background(), "a")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			defer done()
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			started <- struct{}{}
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Errorf("wanted requests to wait while the most concurrent requests are running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	otherDone := make(chan struct{})
+	go func() {
+		if done, err := hls.wait(context.Background(), "b"); err == nil {
+			done()
+		}
+		close(otherDone)
+	}()
+	select {
+	case <-otherDone:
+	case <-time.After(time.Second):
+		t.Errorf("wanted requests to other hosts to not wait for busy host")
+	}
+	close(release)
+	wg.Wait()
+	if want := 2; want != maxRunning {
+		t.Errorf("wanted at most %v concurrent requests, got %v", want, maxRunning)
+	}
+}
+
+func TestHostLimitersWait_nil(t *testing.T) {
+	var hls *hostLimiters
+	done, err := hls.wait(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done()
+}
+
+func TestHostLimitersWait_canceled(t *testing.T) {
+	hls := newHostLimiters(NewHostLimits(1, 0))
+	done, err := hls.wait(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	if _, err := hls.wait(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted canceled request to stop waiting for busy host, got %v", err)
+	}
+	done()
+	done, err = hls.wait(context.Background(), "a")
+	if err != nil {
+		t.Errorf("wanted request to be made after busy host is done, got %v", err)
+	} else {
+		done()
+	}
+}
+
+func TestHTTPRequesterBytes_hostLimits(t *testing.T) {
+	var waits []time.Duration
+	r := httpRequester{
+		cache: NewCache(CacheConfig{}),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				response := http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("7")),
+				}
+				return &response, nil
+			},
+		},
+		limiters: newHostLimiters(NewHostLimits(1, 1)),
+	}
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	r.limiters.now = func() time.Time { return now }
+	r.limiters.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := r.fetch(context.Background(), "http://statsapi.mlb.com/api/v1/teams", nil); err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		}
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(want) != len(waits) || want[0] != waits[0] || want[1] != waits[1] {
+		t.Errorf("wanted waits of %v, got %v", want, waits)
+	}
+}
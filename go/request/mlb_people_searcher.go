@@ -0,0 +1,91 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// mlbPeopleSearcher implements the Searcher interface with the statsapi people search
+	mlbPeopleSearcher struct {
+		requester requester
+		baseURL   string
+	}
+
+	// MlbPeopleSearch is used to unmarshal a request for information about players by name
+	MlbPeopleSearch struct {
+		People []MlbPerson `json:"people"`
+	}
+
+	// MlbPerson contains the results of a people search for a single player
+	MlbPerson struct {
+		ID              db.SourceID    `json:"id"`
+		FullName        string         `json:"fullName"`
+		BirthDate       string         `json:"birthDate"`
+		BirthCountry    string         `json:"birthCountry"`
+		Active          bool           `json:"active"`
+		PrimaryPosition MlbPosition    `json:"primaryPosition"`
+		CurrentTeam     MlbCurrentTeam `json:"currentTeam"`
+	}
+
+	// MlbPosition contains the abbreviation of a position, such as "P" or "CF"
+	MlbPosition struct {
+		Abbreviation string `json:"abbreviation"`
+	}
+
+	// MlbCurrentTeam contains the abbreviation of the team a player is on.  It is empty for players who are not on a team.
+	MlbCurrentTeam struct {
+		Abbreviation string `json:"abbreviation"`
+	}
+)
+
+// Search implements the Searcher interface
+func (s *mlbPeopleSearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+	uri := fmt.Sprintf("%s/api/v1/people/search?names=%s&sportIds=1&hydrate=currentTeam", s.baseURL, url.QueryEscape(playerNamePrefix))
+	if activePlayersOnly {
+		uri += "&active=true"
+	}
+	var mlbPeopleSearch MlbPeopleSearch
+	if err := s.requester.structPointerFromURI(ctx, uri, &mlbPeopleSearch); err != nil {
+		return nil, err
+	}
+	var playerSearchResults []PlayerSearchResult
+	for _, person := range mlbPeopleSearch.People {
+		if (activePlayersOnly && !person.Active) || !person.matches(pt) {
+			continue
+		}
+		playerSearchResults = append(playerSearchResults, person.toPlayerSearchResult())
+	}
+	return playerSearchResults, nil
+}
+
+// matches determines if the person plays the position of the PlayerType.  Two-way players are both hitters and pitchers.
+func (person MlbPerson) matches(pt db.PlayerType) bool {
+	switch person.PrimaryPosition.Abbreviation {
+	case "TWP":
+		return pt == db.PlayerTypeMlbHitter || pt == db.PlayerTypeMlbPitcher
+	case "P":
+		return pt == db.PlayerTypeMlbPitcher
+	default:
+		return pt == db.PlayerTypeMlbHitter
+	}
+}
+
+func (person MlbPerson) toPlayerSearchResult() PlayerSearchResult {
+	birthDate := person.BirthDate
+	if len(birthDate) == 0 {
+		birthDate = "?"
+	}
+	position := person.PrimaryPosition.Abbreviation
+	team := person.CurrentTeam.Abbreviation
+	return PlayerSearchResult{
+		Name:     person.FullName,
+		Details:  fmt.Sprintf("team:%s, position:%s, born:%s,%s", team, position, person.BirthCountry, birthDate),
+		SourceID: person.ID,
+		Position: position,
+		Team:     team,
+	}
+}
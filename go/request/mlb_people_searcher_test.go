@@ -0,0 +1,89 @@
+package request
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+func TestMlbPeopleSearch(t *testing.T) {
+	mlbPeopleSearchTests := []struct {
+		pt                db.PlayerType
+		playerNamePrefix  string
+		activePlayersOnly bool
+		peopleJSON        string
+		wantErr           bool
+		want              []PlayerSearchResult
+	}{
+		{
+			pt:                db.PlayerTypeMlbPitcher,
+			playerNamePrefix:  "Hader",
+			activePlayersOnly: true,
+			peopleJSON: `{"people":[{
+				"id": 623352,
+				"fullName": "Josh Hader",
+				"birthDate": "1994-04-07",
+				"birthCountry": "USA",
+				"active": true,
+				"primaryPosition": {"abbreviation": "P"},
+				"currentTeam": {"id": 158, "abbreviation": "MIL"}}]}`,
+			want: []PlayerSearchResult{{Name: "Josh Hader", Details: "team:MIL, position:P, born:USA,1994-04-07", SourceID: 623352, Position: "P", Team: "MIL"}},
+		},
+		{
+			pt:               db.PlayerTypeMlbHitter,
+			playerNamePrefix: "alonso",
+			peopleJSON: `{"people":[
+				{"id": 624413, "fullName": "Pete Alonso", "birthDate": "1994-12-07", "birthCountry": "USA", "active": true, "primaryPosition": {"abbreviation": "1B"}, "currentTeam": {"abbreviation": "NYM"}},
+				{"id": 475174, "fullName": "Yonder Alonso", "birthCountry": "Cuba", "primaryPosition": {"abbreviation": "1B"}},
+				{"id": 112526, "fullName": "Mark Alonso", "primaryPosition": {"abbreviation": "P"}}]}`,
+			want: []PlayerSearchResult{
+				{Name: "Pete Alonso", Details: "team:NYM, position:1B, born:USA,1994-12-07", SourceID: 624413, Position: "1B", Team: "NYM"},
+				{Name: "Yonder Alonso", Details: "team:, position:1B, born:Cuba,?", SourceID: 475174, Position: "1B"},
+			},
+		},
+		{
+			pt:                db.PlayerTypeMlbHitter,
+			playerNamePrefix:  "alonso",
+			activePlayersOnly: true,
+			peopleJSON:        `{"people":[{"id": 475174, "fullName": "Yonder Alonso", "active": false, "primaryPosition": {"abbreviation": "1B"}}]}`,
+		},
+		{
+			pt:               db.PlayerTypeMlbPitcher,
+			playerNamePrefix: "ohtani",
+			peopleJSON:       `{"people":[{"id": 660271, "fullName": "Shohei Ohtani", "primaryPosition": {"abbreviation": "TWP"}}]}`,
+			want:             []PlayerSearchResult{{Name: "Shohei Ohtani", Details: "team:, position:TWP, born:,?", SourceID: 660271, Position: "TWP"}},
+		},
+		{
+			pt:               db.PlayerTypeMlbHitter,
+			playerNamePrefix: "felix",
+			wantErr:          true, // no json
+		},
+	}
+	for i, test := range mlbPeopleSearchTests {
+		jsonFunc := func(uri string) string {
+			if want := "names=" + test.playerNamePrefix; !strings.Contains(uri, want) {
+				t.Errorf("Test %v: wanted uri to contain %v: %v", i, want, uri)
+			}
+			if test.activePlayersOnly != strings.Contains(uri, "active=true") {
+				t.Errorf("Test %v: wanted uri to contain flag for activePlayersOnly (%v): %v", i, test.activePlayersOnly, uri)
+			}
+			return test.peopleJSON
+		}
+		r := newMockHTTPRequester(jsonFunc)
+		mlbPeopleS := mlbPeopleSearcher{requester: r}
+		got, err := mlbPeopleS.Search(context.Background(), test.pt, 2019, test.playerNamePrefix, test.activePlayersOnly)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error but did not get one", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: Not equal:\nWanted: %v\nGot:    %v", i, test.want, got)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package request
 
 import (
+	"context"
 	"fmt"
-	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
@@ -11,7 +11,7 @@ import (
 type (
 	// mlbPlayerRequester contains information about requests for hitter/pitcher names/stats
 	mlbPlayerRequester struct {
-		requester requester
+		provider MlbProvider
 	}
 
 	// MlbPlayerNames is used to unmarshal a request for player names
@@ -53,106 +53,85 @@ type (
 	}
 )
 
-// RequestScoreCategory implements the ScoreCategorizer interface
-func (r *mlbPlayerRequester) RequestScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error) {
-	sourceIDs := make(map[db.SourceID]bool, len(players))
+// RequestScoreCategory implements the ScoreCategorizer interface.
+// The stats and the names of players without saved names are requested concurrently.  If any request fails, the others are canceled and the errors of all failed requests are returned.
+func (r *mlbPlayerRequester) RequestScoreCategory(ctx context.Context, pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error) {
+	playerNames := make(map[db.SourceID]string, len(players))
+	statRanges := make(map[playerStatRange]bool, len(players))
 	for _, player := range players {
-		sourceIDs[player.SourceID] = true
+		if len(player.Name) != 0 {
+			playerNames[player.SourceID] = player.Name
+		}
+		statRanges[newPlayerStatRange(player)] = true
+	}
+	unnamedSourceIDs := make(map[db.SourceID]bool, len(players))
+	for _, player := range players {
+		if _, ok := playerNames[player.SourceID]; !ok {
+			unnamedSourceIDs[player.SourceID] = true
+		}
 	}
-
-	playerNames := make(map[db.SourceID]string, len(sourceIDs))
-	playerStats := make(map[db.SourceID]int, len(sourceIDs))
-	playerNamesCh := make(chan playerName, len(sourceIDs))
-	playerStatsCh := make(chan playerStat, len(sourceIDs))
-	quit := make(chan error)
 
 	var scoreCategory ScoreCategory
-	if len(sourceIDs) > 0 {
-		go r.requestPlayerNames(sourceIDs, playerNamesCh, quit)
-		go r.requestPlayerStats(pt, year, sourceIDs, playerStatsCh, quit)
-		i := 0
-		for {
-			select {
-			case err := <-quit:
-				return scoreCategory, err
-			case pn := <-playerNamesCh:
-				playerNames[pn.sourceID] = pn.name
-			case ps := <-playerStatsCh:
-				playerStats[ps.sourceID] = ps.stat
-			}
-			i++
-			if i == len(sourceIDs)*2 {
-				break
-			}
+	var requestedPlayerNames map[db.SourceID]string
+	playerStats := make(map[playerStatRange]int, len(statRanges))
+	if len(players) > 0 {
+		var mu sync.Mutex
+		g := newRequestGroup(ctx)
+		if len(unnamedSourceIDs) > 0 { // only players without saved names are requested
+			g.do(func(ctx context.Context) error {
+				var err error
+				requestedPlayerNames, err = r.requestPlayerNames(ctx, unnamedSourceIDs)
+				return err
+			})
+		}
+		for statRange := range statRanges {
+			g.do(func(ctx context.Context) error {
+				stat, err := r.requestPlayerStat(ctx, pt, statRange, year)
+				if err != nil {
+					return fmt.Errorf("requesting stats for mlb player %v: %w", statRange.sourceID, err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				playerStats[statRange] = stat
+				return nil
+			})
 		}
+		if err := g.wait(); err != nil {
+			return scoreCategory, err
+		}
+	}
+	for sourceID, name := range requestedPlayerNames {
+		playerNames[sourceID] = name
 	}
 	playerNameScores := playerNameScoresFromFieldMaps(players, playerNames, playerStats)
 	return newScoreCategory(pt, ptInfo, friends, players, playerNameScores, true), nil
 }
 
-func (r *mlbPlayerRequester) requestPlayerNames(sourceIDs map[db.SourceID]bool, playerNames chan<- playerName, quit chan<- error) {
-	sourceIDStrings := make([]string, len(sourceIDs))
-	i := 0
+func (r *mlbPlayerRequester) requestPlayerNames(ctx context.Context, sourceIDs map[db.SourceID]bool) (map[db.SourceID]string, error) {
+	requestedSourceIDs := make([]db.SourceID, 0, len(sourceIDs))
 	for sourceID := range sourceIDs {
-		sourceIDStrings[i] = strconv.Itoa(int(sourceID))
-		i++
-	}
-	playerNamesURI := strings.ReplaceAll(
-		fmt.Sprintf(
-			"http://statsapi.mlb.com/api/v1/people?personIds=%s&fields=people,id,fullName",
-			strings.Join(sourceIDStrings, ",")),
-		",",
-		"%2C")
-	var mlbPlayerNames MlbPlayerNames
-	err := r.requester.structPointerFromURI(playerNamesURI, &mlbPlayerNames)
+		requestedSourceIDs = append(requestedSourceIDs, sourceID)
+	}
+	mlbPlayerNames, err := r.provider.RequestPlayerNames(ctx, requestedSourceIDs)
 	if err != nil {
-		quit <- err
-		return
+		return nil, fmt.Errorf("requesting mlb player names: %w", err)
 	}
 
-	i = 0
+	playerNames := make(map[db.SourceID]string, len(sourceIDs))
 	for _, person := range mlbPlayerNames.People {
 		if _, ok := sourceIDs[person.ID]; ok {
-			playerNames <- playerName{
-				sourceID: person.ID,
-				name:     person.FullName,
-			}
-			i++
+			playerNames[person.ID] = person.FullName
 		}
 	}
-	if i != len(sourceIDs) {
-		quit <- fmt.Errorf("expected to receive %d mlb player names, but only got %d", len(sourceIDs), i)
-	}
-}
-
-func (r mlbPlayerRequester) requestPlayerStats(pt db.PlayerType, year int, sourceIDs map[db.SourceID]bool, playerStats chan<- playerStat, quit chan<- error) {
-	for sourceID := range sourceIDs {
-		go r.getPlayerStat(pt, sourceID, year, playerStats, quit)
-	}
-}
-
-func (r mlbPlayerRequester) getPlayerStat(pt db.PlayerType, sourceID db.SourceID, year int, playerStats chan<- playerStat, quit chan<- error) {
-	stat, err := r.requestPlayerStat(pt, sourceID, year)
-	if err != nil {
-		quit <- err
-		return
-	}
-	playerStats <- playerStat{
-		sourceID: sourceID,
-		stat:     stat,
+	if len(playerNames) != len(sourceIDs) {
+		return nil, fmt.Errorf("expected to receive %d mlb player names, but only got %d", len(sourceIDs), len(playerNames))
 	}
+	return playerNames, nil
 }
 
-func (r mlbPlayerRequester) requestPlayerStat(pt db.PlayerType, sourceID db.SourceID, year int) (int, error) {
-	mlbPlayerStatsURI := strings.ReplaceAll(
-		fmt.Sprintf(
-			"http://statsapi.mlb.com/api/v1/people/%d/stats?&season=%d&stats=season&fields=stats,group,displayName,splits,stat,homeRuns,wins",
-			sourceID,
-			year),
-		",",
-		"%2C")
-	var mlbPlayerStats MlbPlayerStats
-	err := r.requester.structPointerFromURI(mlbPlayerStatsURI, &mlbPlayerStats)
+// requestPlayerStat requests the stat of the player, only accrued while the player was on the roster if the range is bounded.
+func (r mlbPlayerRequester) requestPlayerStat(ctx context.Context, pt db.PlayerType, statRange playerStatRange, year int) (int, error) {
+	mlbPlayerStats, err := r.provider.RequestPlayerStats(ctx, year, statRange.sourceID, statRange.startDate, statRange.endDate)
 	if err != nil {
 		return -1, err
 	}
@@ -1,11 +1,18 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
@@ -197,8 +204,8 @@ func TestMlbPlayerRequestScoreCategory(t *testing.T) {
 			return "" // will cause json unmarshal error
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		mlbPlayerR := mlbPlayerRequester{requester: r}
-		got, err := mlbPlayerR.RequestScoreCategory(test.pt, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
+		mlbPlayerR := mlbPlayerRequester{provider: statsMlbProvider{requester: r}}
+		got, err := mlbPlayerR.RequestScoreCategory(context.Background(), test.pt, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -211,3 +218,145 @@ func TestMlbPlayerRequestScoreCategory(t *testing.T) {
 		}
 	}
 }
+
+func TestMlbPlayerRequestScoreCategory_rosterDates(t *testing.T) {
+	july1 := time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)
+	friends := []db.Friend{
+		{ID: "1", DisplayOrder: 1, Name: "Bobby"},
+		{ID: "2", DisplayOrder: 2, Name: "Charles"},
+	}
+	players := []db.Player{
+		{ID: "1", SourceID: 547180, FriendID: "1", DisplayOrder: 1, DropDate: &july1}, // Bryce Harper
+		{ID: "2", SourceID: 547180, FriendID: "2", DisplayOrder: 1, AddDate: &july1},  // Bryce Harper
+	}
+	jsonFunc := func(uri string) string {
+		switch {
+		case strings.Contains(uri, "/people?"):
+			return `{"People":[{"id":547180,"fullName":"Bryce Harper"}]}`
+		case strings.Contains(uri, "stats=byDateRange&startDate=2019-01-01&endDate=2019-06-30&"):
+			return `{"stats":[{"group":{"displayName":"hitting"},"splits":[{"stat":{"homeRuns":17}}]}]}`
+		case strings.Contains(uri, "stats=byDateRange&startDate=2019-07-01&endDate=2019-12-31&"):
+			return `{"stats":[{"group":{"displayName":"hitting"},"splits":[{"stat":{"homeRuns":18}}]}]}`
+		}
+		return "" // will cause json unmarshal error
+	}
+	want := ScoreCategory{
+		PlayerType: db.PlayerTypeMlbHitter,
+		FriendScores: []FriendScore{
+			{
+				DisplayOrder: 1, ID: "1", Name: "Bobby", Score: 17,
+				PlayerScores: []PlayerScore{
+					{ID: "1", Name: "Bryce Harper", Score: 17, DisplayOrder: 1, SourceID: 547180, DropDate: &july1}},
+			},
+			{
+				DisplayOrder: 2, ID: "2", Name: "Charles", Score: 18,
+				PlayerScores: []PlayerScore{
+					{ID: "2", Name: "Bryce Harper", Score: 18, DisplayOrder: 1, SourceID: 547180, AddDate: &july1}},
+			},
+		},
+	}
+	r := newMockHTTPRequester(jsonFunc)
+	mlbPlayerR := mlbPlayerRequester{provider: statsMlbProvider{requester: r}}
+	got, err := mlbPlayerR.RequestScoreCategory(context.Background(), db.PlayerTypeMlbHitter, db.PlayerTypeInfo{}, 2019, friends, players)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("Not equal:\nWanted: %v\nGot:    %v", want, got)
+	}
+}
+
+func TestMlbPlayerRequestScoreCategory_savedNames(t *testing.T) {
+	friends := []db.Friend{{ID: "1", DisplayOrder: 1, Name: "Bobby"}}
+	mlbPlayerRequestScoreCategorySavedNamesTests := []struct {
+		players        []db.Player
+		wantPeopleURIs []string
+	}{
+		{
+			players: []db.Player{
+				{ID: "1", SourceID: 547180, FriendID: "1", DisplayOrder: 1, Name: "Bryce Harper"},
+			},
+		},
+		{
+			players: []db.Player{
+				{ID: "1", SourceID: 547180, FriendID: "1", DisplayOrder: 1, Name: "Bryce Harper"},
+				{ID: "2", SourceID: 545361, FriendID: "1", DisplayOrder: 2},
+			},
+			wantPeopleURIs: []string{"/api/v1/people?personIds=545361&fields=people%2Cid%2CfullName"},
+		},
+	}
+	for i, test := range mlbPlayerRequestScoreCategorySavedNamesTests {
+		var mu sync.Mutex
+		var gotPeopleURIs []string
+		jsonFunc := func(uri string) string {
+			if strings.Contains(uri, "/people?") {
+				mu.Lock()
+				defer mu.Unlock()
+				gotPeopleURIs = append(gotPeopleURIs, uri)
+				return `{"People":[{"id":545361,"fullName":"Mike Trout"}]}`
+			}
+			return `{"stats":[{"group":{"displayName":"hitting"},"splits":[{"stat":{"homeRuns":17}}]}]}`
+		}
+		r := newMockHTTPRequester(jsonFunc)
+		mlbPlayerR := mlbPlayerRequester{provider: statsMlbProvider{requester: r}}
+		got, err := mlbPlayerR.RequestScoreCategory(context.Background(), db.PlayerTypeMlbHitter, db.PlayerTypeInfo{}, 2019, friends, test.players)
+		switch {
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.wantPeopleURIs, gotPeopleURIs):
+			t.Errorf("Test %v: wanted only names of players without saved names to be requested: wanted %v, got %v", i, test.wantPeopleURIs, gotPeopleURIs)
+		case got.FriendScores[0].PlayerScores[0].Name != "Bryce Harper":
+			t.Errorf("Test %v: wanted saved name to be used, got %v", i, got.FriendScores[0].PlayerScores[0])
+		}
+	}
+}
+
+func TestMlbPlayerRequestScoreCategory_requestErrors(t *testing.T) {
+	friends := []db.Friend{{ID: "1", Name: "Bobby"}}
+	players := []db.Player{
+		{ID: "1", SourceID: 1, FriendID: "1"},
+		{ID: "2", SourceID: 2, FriendID: "1"},
+		{ID: "3", SourceID: 3, FriendID: "1"},
+	}
+	r := httpRequester{
+		cache: NewCache(CacheConfig{}),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				body := `{"people":[{"id":1},{"id":2},{"id":3}]}`
+				switch {
+				case strings.Contains(r.URL.Path, "/3/stats"):
+					<-r.Context().Done() // should be canceled when other requests fail
+					return nil, r.Context().Err()
+				case strings.Contains(r.URL.Path, "/stats"):
+					body = "" // will cause json unmarshal error
+				}
+				response := http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}
+				return &response, nil
+			},
+		},
+	}
+	mlbPlayerR := mlbPlayerRequester{provider: statsMlbProvider{requester: &r}}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc()
+	before := runtime.NumGoroutine()
+	_, err := mlbPlayerR.RequestScoreCategory(ctx, db.PlayerTypeMlbHitter, db.PlayerTypeInfo{}, 2019, friends, players)
+	switch {
+	case err == nil:
+		t.Error("wanted error but did not get one")
+	case errors.Is(err, context.DeadlineExceeded):
+		t.Errorf("wanted outstanding requests to be canceled when others fail, got %v", err)
+	default:
+		for _, want := range []string{"mlb player 1", "mlb player 2"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("wanted error to contain %q, got %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "mlb player 3") {
+			t.Errorf("wanted error of canceled request to be ignored, got %v", err)
+		}
+	}
+	checkGoroutines(t, before)
+}
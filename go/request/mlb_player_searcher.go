@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -11,9 +12,11 @@ import (
 )
 
 type (
-	// mlbPlayerSearcher implements the Searcher interface
+	// mlbPlayerSearcher implements the Searcher interface with the legacy lookup service.
+	// It is used when the people search of the statsapi fails.
 	mlbPlayerSearcher struct {
 		requester requester
+		baseURL   string
 	}
 
 	// MlbPlayerSearch is used to unmarshal a request for information about players by name
@@ -50,15 +53,15 @@ type (
 )
 
 // Search implements the Searcher interface
-func (s *mlbPlayerSearcher) Search(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+func (s *mlbPlayerSearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
 	activePlayers := "N"
 	if activePlayersOnly {
 		activePlayers = "Y"
 	}
 	playerNamePrefix = url.QueryEscape(playerNamePrefix)
-	uri := strings.ReplaceAll(fmt.Sprintf("http://lookup-service-prod.mlb.com/json/named.search_player_all.bam?name_part='%s%%25'&active_sw='%s'&sport_code='mlb'&search_player_all.col_in=player_id&search_player_all.col_in=name_display_first_last&search_player_all.col_in=position&search_player_all.col_in=team_abbrev&search_player_all.col_in=team_abbrev&search_player_all.col_in=birth_country&search_player_all.col_in=birth_date", playerNamePrefix, activePlayers), "'", "%27")
+	uri := strings.ReplaceAll(fmt.Sprintf("%s/json/named.search_player_all.bam?name_part='%s%%25'&active_sw='%s'&sport_code='mlb'&search_player_all.col_in=player_id&search_player_all.col_in=name_display_first_last&search_player_all.col_in=position&search_player_all.col_in=team_abbrev&search_player_all.col_in=team_abbrev&search_player_all.col_in=birth_country&search_player_all.col_in=birth_date", s.baseURL, playerNamePrefix, activePlayers), "'", "%27")
 	var mlbPlayerSearchQueryResult MlbPlayerSearch
-	err := s.requester.structPointerFromURI(uri, &mlbPlayerSearchQueryResult)
+	err := s.requester.structPointerFromURI(ctx, uri, &mlbPlayerSearchQueryResult)
 	if err != nil {
 		return []PlayerSearchResult{}, err
 	}
@@ -105,6 +108,8 @@ func (mlbPlayerBio MlbPlayerBio) toPlayerSearchResult() PlayerSearchResult {
 		Name:     mlbPlayerBio.PlayerName,
 		Details:  fmt.Sprintf("team:%s, position:%s, born:%s,%s", mlbPlayerBio.TeamAbbrev, mlbPlayerBio.Position, mlbPlayerBio.BirthCountry, mlbPlayerBio.BirthDate),
 		SourceID: mlbPlayerBio.PlayerID,
+		Position: mlbPlayerBio.Position,
+		Team:     mlbPlayerBio.TeamAbbrev,
 	}
 }
 
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"strings"
@@ -31,7 +32,7 @@ func TestMlbPlayerSearchResults(t *testing.T) {
 					"team_abbrev": "MIL",
 					"name_display_first_last": "Josh Hader",
 					"player_id": "623352"}}}}`,
-			want: []PlayerSearchResult{{Name: "Josh Hader", Details: "team:MIL, position:P, born:USA,1994-04-07", SourceID: 623352}},
+			want: []PlayerSearchResult{{Name: "Josh Hader", Details: "team:MIL, position:P, born:USA,1994-04-07", SourceID: 623352, Position: "P", Team: "MIL"}},
 		},
 		{
 			pt:                db.PlayerTypeMlbHitter,
@@ -55,7 +56,7 @@ func TestMlbPlayerSearchResults(t *testing.T) {
 					"name_display_first_last": "Jose Martinez",
 					"player_id": "118370"
 					}]}}}`, // do not include player 500874 - he is inactive in 2019
-			want: []PlayerSearchResult{{Name: "Jose Martinez", Details: "team:PIT, position:2B, born:Cuba,1942-07-26", SourceID: 118370}},
+			want: []PlayerSearchResult{{Name: "Jose Martinez", Details: "team:PIT, position:2B, born:Cuba,1942-07-26", SourceID: 118370, Position: "2B", Team: "PIT"}},
 		},
 		{
 			pt:               db.PlayerTypeMlbHitter,
@@ -80,7 +81,7 @@ func TestMlbPlayerSearchResults(t *testing.T) {
 		}
 		r := newMockHTTPRequester(jsonFunc)
 		mlbPlayerS := mlbPlayerSearcher{requester: r}
-		got, err := mlbPlayerS.Search(test.pt, 2019, test.playerNamePrefix, test.activePlayersOnly)
+		got, err := mlbPlayerS.Search(context.Background(), test.pt, 2019, test.playerNamePrefix, test.activePlayersOnly)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -116,7 +117,7 @@ func TestGetMlbPlayerSearchResults(t *testing.T) {
 			searchResultJSON: `{"search_player_all":{"queryResults":{"totalSize":"1","row":{"position":"CF","birth_country":"USA","birth_date":"1991-08-07T00:00:00","team_abbrev":"LAA","name_display_first_last":"Mike Trout","player_id":"545361"}}}}`,
 			playerType:       db.PlayerTypeMlbHitter,
 			want: []PlayerSearchResult{
-				{Name: "Mike Trout", Details: "team:LAA, position:CF, born:USA,1991-08-07", SourceID: 545361},
+				{Name: "Mike Trout", Details: "team:LAA, position:CF, born:USA,1991-08-07", SourceID: 545361, Position: "CF", Team: "LAA"},
 			},
 		},
 		{
@@ -124,8 +125,8 @@ func TestGetMlbPlayerSearchResults(t *testing.T) {
 			searchResultJSON: `{"search_player_all":{"queryResults":{"totalSize":"2","row":[{"position":"1B","birth_country":"USA","birth_date":"1994-12-07T00:00:00","team_abbrev":"NYM","name_display_first_last":"Pete Alonso","player_id":"624413"},{"position":"1B","birth_country":"Cuba","birth_date":"1987-04-08T00:00:00","team_abbrev":"COL","name_display_first_last":"Yonder Alonso","player_id":"475174"}]}}}`,
 			playerType:       db.PlayerTypeMlbHitter,
 			want: []PlayerSearchResult{
-				{Name: "Pete Alonso", Details: "team:NYM, position:1B, born:USA,1994-12-07", SourceID: 624413},
-				{Name: "Yonder Alonso", Details: "team:COL, position:1B, born:Cuba,1987-04-08", SourceID: 475174},
+				{Name: "Pete Alonso", Details: "team:NYM, position:1B, born:USA,1994-12-07", SourceID: 624413, Position: "1B", Team: "NYM"},
+				{Name: "Yonder Alonso", Details: "team:COL, position:1B, born:Cuba,1987-04-08", SourceID: 475174, Position: "1B", Team: "COL"},
 			},
 		},
 		{
@@ -145,7 +146,7 @@ func TestGetMlbPlayerSearchResults(t *testing.T) {
 			searchResultJSON: `{"search_player_all":{"queryResults":{"totalSize":"1","row":{"position":"P","birth_country":"USA","birth_date":"","team_abbrev":"CHC","name_display_first_last":"Abe Johnson","player_id":"116556"}}}}`,
 			playerType:       db.PlayerTypeMlbPitcher,
 			want: []PlayerSearchResult{
-				{Name: "Abe Johnson", Details: "team:CHC, position:P, born:USA,?", SourceID: 116556},
+				{Name: "Abe Johnson", Details: "team:CHC, position:P, born:USA,?", SourceID: 116556, Position: "P", Team: "CHC"},
 			},
 		},
 		{
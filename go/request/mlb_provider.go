@@ -0,0 +1,90 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// MlbProvider requests mlb standings, player stats, and player names from an external source.
+	// The SourceIDs of mlb teams and players are those of statsapi.mlb.com, so other providers would have to map them to their own SourceIDs.
+	MlbProvider interface {
+		// RequestTeams requests the standings of all teams for the year.  If the date is not empty, the standings on that date are requested.
+		RequestTeams(ctx context.Context, year int, date string) (MlbTeams, error)
+		// RequestPlayerStats requests the stats of the player for the year.  Only the stats accrued between the dates are requested if either is not empty.
+		RequestPlayerStats(ctx context.Context, year int, sourceID db.SourceID, startDate, endDate string) (MlbPlayerStats, error)
+		// RequestPlayerNames requests the full names of the players.
+		RequestPlayerNames(ctx context.Context, sourceIDs []db.SourceID) (MlbPlayerNames, error)
+	}
+
+	// statsMlbProvider requests mlb data from statsapi.mlb.com
+	statsMlbProvider struct {
+		requester requester
+		baseURL   string
+	}
+)
+
+// RequestTeams implements the MlbProvider interface
+func (p statsMlbProvider) RequestTeams(ctx context.Context, year int, date string) (MlbTeams, error) {
+	var mlbTeams MlbTeams
+	uri := fmt.Sprintf("%s/api/v1/standings/regularSeason?leagueId=103,104&season=%d", p.baseURL, year)
+	if len(date) != 0 {
+		uri += "&date=" + date
+	}
+	uri = strings.ReplaceAll(uri, ",", "%2C")
+	err := p.requester.structPointerFromURI(ctx, uri, &mlbTeams)
+	return mlbTeams, err
+}
+
+// RequestPlayerStats implements the MlbProvider interface
+func (p statsMlbProvider) RequestPlayerStats(ctx context.Context, year int, sourceID db.SourceID, startDate, endDate string) (MlbPlayerStats, error) {
+	var mlbPlayerStatsURI string
+	switch {
+	case len(startDate) != 0 || len(endDate) != 0:
+		if len(startDate) == 0 {
+			startDate = fmt.Sprintf("%d-01-01", year)
+		}
+		if len(endDate) == 0 {
+			endDate = fmt.Sprintf("%d-12-31", year)
+		}
+		mlbPlayerStatsURI = fmt.Sprintf(
+			"%s/api/v1/people/%d/stats?&season=%d&stats=byDateRange&startDate=%s&endDate=%s&fields=stats,group,displayName,splits,stat,homeRuns,wins",
+			p.baseURL,
+			sourceID,
+			year,
+			startDate,
+			endDate)
+	default:
+		mlbPlayerStatsURI = fmt.Sprintf(
+			"%s/api/v1/people/%d/stats?&season=%d&stats=season&fields=stats,group,displayName,splits,stat,homeRuns,wins",
+			p.baseURL,
+			sourceID,
+			year)
+	}
+	mlbPlayerStatsURI = strings.ReplaceAll(mlbPlayerStatsURI, ",", "%2C")
+	var mlbPlayerStats MlbPlayerStats
+	err := p.requester.structPointerFromURI(ctx, mlbPlayerStatsURI, &mlbPlayerStats)
+	return mlbPlayerStats, err
+}
+
+// RequestPlayerNames implements the MlbProvider interface
+func (p statsMlbProvider) RequestPlayerNames(ctx context.Context, sourceIDs []db.SourceID) (MlbPlayerNames, error) {
+	sourceIDStrings := make([]string, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		sourceIDStrings[i] = strconv.Itoa(int(sourceID))
+	}
+	playerNamesURI := strings.ReplaceAll(
+		fmt.Sprintf(
+			"%s/api/v1/people?personIds=%s&fields=people,id,fullName",
+			p.baseURL,
+			strings.Join(sourceIDStrings, ",")),
+		",",
+		"%2C")
+	var mlbPlayerNames MlbPlayerNames
+	err := p.requester.structPointerFromURI(ctx, playerNamesURI, &mlbPlayerNames)
+	return mlbPlayerNames, err
+}
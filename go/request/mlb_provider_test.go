@@ -0,0 +1,97 @@
+package request
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type mockMlbProvider struct {
+	RequestTeamsFunc       func(year int, date string) (MlbTeams, error)
+	RequestPlayerStatsFunc func(year int, sourceID db.SourceID, startDate, endDate string) (MlbPlayerStats, error)
+	RequestPlayerNamesFunc func(sourceIDs []db.SourceID) (MlbPlayerNames, error)
+}
+
+func (p mockMlbProvider) RequestTeams(ctx context.Context, year int, date string) (MlbTeams, error) {
+	return p.RequestTeamsFunc(year, date)
+}
+
+func (p mockMlbProvider) RequestPlayerStats(ctx context.Context, year int, sourceID db.SourceID, startDate, endDate string) (MlbPlayerStats, error) {
+	return p.RequestPlayerStatsFunc(year, sourceID, startDate, endDate)
+}
+
+func (p mockMlbProvider) RequestPlayerNames(ctx context.Context, sourceIDs []db.SourceID) (MlbPlayerNames, error) {
+	return p.RequestPlayerNamesFunc(sourceIDs)
+}
+
+func TestStatsMlbProviderRequestPlayerStats(t *testing.T) {
+	requestPlayerStatsTests := []struct {
+		startDate string
+		endDate   string
+		wantURI   string
+	}{
+		{
+			wantURI: "/api/v1/people/547180/stats?&season=2019&stats=season&fields=stats%2Cgroup%2CdisplayName%2Csplits%2Cstat%2ChomeRuns%2Cwins",
+		},
+		{
+			startDate: "2019-07-01",
+			wantURI:   "/api/v1/people/547180/stats?&season=2019&stats=byDateRange&startDate=2019-07-01&endDate=2019-12-31&fields=stats%2Cgroup%2CdisplayName%2Csplits%2Cstat%2ChomeRuns%2Cwins",
+		},
+		{
+			endDate: "2019-06-30",
+			wantURI: "/api/v1/people/547180/stats?&season=2019&stats=byDateRange&startDate=2019-01-01&endDate=2019-06-30&fields=stats%2Cgroup%2CdisplayName%2Csplits%2Cstat%2ChomeRuns%2Cwins",
+		},
+	}
+	for i, test := range requestPlayerStatsTests {
+		var gotURI string
+		r := mockRequester{
+			structPointerFromURIFunc: func(uri string, v interface{}) error {
+				gotURI = uri
+				return nil
+			},
+		}
+		p := statsMlbProvider{requester: &r}
+		_, err := p.RequestPlayerStats(context.Background(), 2019, 547180, test.startDate, test.endDate)
+		switch {
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantURI != gotURI:
+			t.Errorf("Test %v: uris not equal:\nwanted: %v\ngot:    %v", i, test.wantURI, gotURI)
+		}
+	}
+}
+
+func TestMlbTeamRequestScoreCategory_provider(t *testing.T) {
+	july1 := time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)
+	standings := map[string]int{"": 81, "2019-06-30": 44}
+	p := mockMlbProvider{
+		RequestTeamsFunc: func(year int, date string) (MlbTeams, error) {
+			return MlbTeams{
+				Records: []MlbTeamRecords{{TeamRecords: []MlbTeamRecord{{
+					Team: MlbTeamRecordName{Name: "Philadelphia Phillies", ID: 143},
+					Wins: standings[date],
+				}}}},
+			}, nil
+		},
+	}
+	friends := []db.Friend{{ID: "1", Name: "Bobby", DisplayOrder: 1}}
+	players := []db.Player{{ID: "5", SourceID: 143, FriendID: "1", DisplayOrder: 1, AddDate: &july1}}
+	want := ScoreCategory{
+		PlayerType: db.PlayerTypeMlbTeam,
+		FriendScores: []FriendScore{{
+			ID: "1", Name: "Bobby", DisplayOrder: 1, Score: 37, // wins since added
+			PlayerScores: []PlayerScore{{ID: "5", Name: "Philadelphia Phillies", Score: 37, DisplayOrder: 1, SourceID: 143, AddDate: &july1}},
+		}},
+	}
+	r := mlbTeamRequester{provider: p}
+	got, err := r.RequestScoreCategory(context.Background(), db.PlayerTypeMlbTeam, db.PlayerTypeInfo{}, 2019, friends, players)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
@@ -1,8 +1,8 @@
 package request
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
@@ -10,7 +10,7 @@ import (
 type (
 	// mlbTeamRequester implements the ScoreCategorizer and Searcher interfaces
 	mlbTeamRequester struct {
-		requester requester
+		provider MlbProvider
 	}
 
 	// MlbTeams is used to unmarshal a wins request for all teams
@@ -38,56 +38,89 @@ type (
 )
 
 // RequestScoreCategory implements the ScoreCategorizer interface
-func (r *mlbTeamRequester) RequestScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error) {
+func (r *mlbTeamRequester) RequestScoreCategory(ctx context.Context, pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error) {
 	var scoreCategory ScoreCategory
-	teams, err := r.requestMlbTeams(year)
+	teams, err := r.provider.RequestTeams(ctx, year, "")
 	if err != nil {
 		return scoreCategory, err
 	}
-	sourceIDNameScores := make(map[db.SourceID]nameScore)
-	for _, record := range teams.Records {
-		for _, teamRecord := range record.TeamRecords {
-			sourceIDNameScores[teamRecord.Team.ID] = nameScore{
-				name:  teamRecord.Team.Name,
-				score: teamRecord.Wins,
+	playerNameScores := playerNameScoresFromSourceIDMap(players, teams.nameScores())
+	if err := r.setRosterWins(ctx, year, players, playerNameScores); err != nil {
+		return scoreCategory, err
+	}
+	return newScoreCategory(pt, ptInfo, friends, players, playerNameScores, false), nil
+}
+
+// setRosterWins changes the scores of teams that were not on a Friend's roster for the whole season to be the wins the team had while on the roster.
+// The wins are the difference of the wins in the standings at the end and the start of the range of dates the team was on the roster.
+func (r *mlbTeamRequester) setRosterWins(ctx context.Context, year int, players []db.Player, playerNameScores map[db.ID]nameScore) error {
+	standings := make(map[string]map[db.SourceID]nameScore)
+	winsOn := func(date string, sourceID db.SourceID) (int, error) {
+		if _, ok := standings[date]; !ok {
+			teams, err := r.provider.RequestTeams(ctx, year, date)
+			if err != nil {
+				return 0, err
 			}
+			standings[date] = teams.nameScores()
 		}
+		return standings[date][sourceID].score, nil
 	}
-	playerNameScores := playerNameScoresFromSourceIDMap(players, sourceIDNameScores)
-	return newScoreCategory(pt, ptInfo, friends, players, playerNameScores, false), nil
+	for _, player := range players {
+		statRange := newPlayerStatRange(player)
+		if !statRange.bounded() {
+			continue
+		}
+		ns := playerNameScores[player.ID]
+		if len(statRange.endDate) != 0 {
+			endWins, err := winsOn(statRange.endDate, player.SourceID)
+			if err != nil {
+				return err
+			}
+			ns.score = endWins
+		}
+		if player.AddDate != nil {
+			previousDate := player.AddDate.AddDate(0, 0, -1).Format(db.DateFormat)
+			startWins, err := winsOn(previousDate, player.SourceID)
+			if err != nil {
+				return err
+			}
+			ns.score -= startWins
+		}
+		playerNameScores[player.ID] = ns
+	}
+	return nil
 }
 
 // Search implements the Searcher interface
-func (r *mlbTeamRequester) Search(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+func (r *mlbTeamRequester) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
 	var teamSearchResults []PlayerSearchResult
-	teams, err := r.requestMlbTeams(year)
+	teams, err := r.provider.RequestTeams(ctx, year, "")
 	if err != nil {
 		return teamSearchResults, err
 	}
 
-	lowerQuery := strings.ToLower(playerNamePrefix)
 	for _, record := range teams.Records {
 		for _, teamRecord := range record.TeamRecords {
-			lowerTeamName := strings.ToLower(teamRecord.Team.Name)
-			if strings.Contains(lowerTeamName, lowerQuery) {
-				teamSearchResults = append(teamSearchResults, PlayerSearchResult{
-					Name:     teamRecord.Team.Name,
-					Details:  fmt.Sprintf("%d - %d Record", teamRecord.Wins, teamRecord.Losses),
-					SourceID: teamRecord.Team.ID,
-				})
-			}
+			teamSearchResults = append(teamSearchResults, PlayerSearchResult{
+				Name:     teamRecord.Team.Name,
+				Details:  fmt.Sprintf("%d - %d Record", teamRecord.Wins, teamRecord.Losses),
+				SourceID: teamRecord.Team.ID,
+			})
 		}
 	}
-	return teamSearchResults, nil
+	matched, unmatched := rankSearchResults(pt, playerNamePrefix, teamSearchResults)
+	return append(matched, unmatched...), nil // all teams are listed so they can be browsed
 }
 
-func (r *mlbTeamRequester) requestMlbTeams(year int) (MlbTeams, error) {
-	var mlbTeams MlbTeams
-	uri := strings.ReplaceAll(
-		fmt.Sprintf(
-			"http://statsapi.mlb.com/api/v1/standings/regularSeason?leagueId=103,104&season=%d", year),
-		",",
-		"%2C")
-	err := r.requester.structPointerFromURI(uri, &mlbTeams)
-	return mlbTeams, err
+func (mlbTeams MlbTeams) nameScores() map[db.SourceID]nameScore {
+	sourceIDNameScores := make(map[db.SourceID]nameScore)
+	for _, record := range mlbTeams.Records {
+		for _, teamRecord := range record.TeamRecords {
+			sourceIDNameScores[teamRecord.Team.ID] = nameScore{
+				name:  teamRecord.Team.Name,
+				score: teamRecord.Wins,
+			}
+		}
+	}
+	return sourceIDNameScores
 }
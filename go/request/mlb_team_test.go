@@ -1,8 +1,12 @@
 package request
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
@@ -51,8 +55,8 @@ func TestMlbTeamRequestScoreCategory(t *testing.T) {
 			return test.teamsJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		mlbTeamR := mlbTeamRequester{requester: r}
-		got, err := mlbTeamR.RequestScoreCategory(db.PlayerTypeMlbTeam, db.PlayerTypeInfo{}, 2001, test.friends, test.players)
+		mlbTeamR := mlbTeamRequester{provider: statsMlbProvider{requester: r}}
+		got, err := mlbTeamR.RequestScoreCategory(context.Background(), db.PlayerTypeMlbTeam, db.PlayerTypeInfo{}, 2001, test.friends, test.players)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -66,6 +70,53 @@ func TestMlbTeamRequestScoreCategory(t *testing.T) {
 	}
 }
 
+func TestMlbTeamRequestScoreCategory_rosterDates(t *testing.T) {
+	june1 := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+	july1 := time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)
+	friends := []db.Friend{{ID: "3", DisplayOrder: 1, Name: "Elias"}}
+	players := []db.Player{
+		{ID: "5", SourceID: 133, FriendID: "3", DisplayOrder: 1, AddDate: &june1, DropDate: &july1},
+		{ID: "8", SourceID: 136, FriendID: "3", DisplayOrder: 2, AddDate: &july1},
+	}
+	teamsJSON := func(athleticsWins, marinersWins int) string {
+		return fmt.Sprintf(`{"records":[{"teamRecords":[
+			{"team":{"id":133,"name":"Oakland Athletics"},"wins":%d},
+			{"team":{"id":136,"name":"Seattle Mariners"},"wins":%d}]}]}`, athleticsWins, marinersWins)
+	}
+	jsonFunc := func(uri string) string {
+		switch {
+		case strings.HasSuffix(uri, "&date=2019-05-31"):
+			return teamsJSON(20, 30)
+		case strings.HasSuffix(uri, "&date=2019-06-30"):
+			return teamsJSON(35, 50)
+		case strings.Contains(uri, "&date="):
+			return "" // will cause json unmarshal error
+		}
+		return teamsJSON(90, 100)
+	}
+	want := ScoreCategory{
+		PlayerType: db.PlayerTypeMlbTeam,
+		FriendScores: []FriendScore{
+			{
+				DisplayOrder: 1, ID: "3", Name: "Elias", Score: 65,
+				PlayerScores: []PlayerScore{
+					{ID: "5", Name: "Oakland Athletics", Score: 15, DisplayOrder: 1, SourceID: 133, AddDate: &june1, DropDate: &july1},
+					{ID: "8", Name: "Seattle Mariners", Score: 50, DisplayOrder: 2, SourceID: 136, AddDate: &july1},
+				},
+			},
+		},
+	}
+	r := newMockHTTPRequester(jsonFunc)
+	mlbTeamR := mlbTeamRequester{provider: statsMlbProvider{requester: r}}
+	got, err := mlbTeamR.RequestScoreCategory(context.Background(), db.PlayerTypeMlbTeam, db.PlayerTypeInfo{}, 2019, friends, players)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("Not equal:\nWanted: %v\nGot:    %v", want, got)
+	}
+}
+
 func TestMlbTeamPlayerSearchResults(t *testing.T) {
 	playerSearchResultsTests := []struct {
 		playerNamePrefix string
@@ -81,9 +132,43 @@ func TestMlbTeamPlayerSearchResults(t *testing.T) {
 						{"team":{"id":136,"name":"Seattle Mariners"},"wins":116,"losses":46}]},
 				{"teamRecords":[
 						{"team":{"id":112,"name":"Chicago Cubs"},"wins":88,"losses":74}]}]}`,
+			want: []PlayerSearchResult{
+				{Name: "Chicago Cubs", Details: "88 - 74 Record", SourceID: 112}, // starts with query
+				{Name: "Oakland Athletics", Details: "102 - 60 Record", SourceID: 133},
+				{Name: "Seattle Mariners", Details: "116 - 46 Record", SourceID: 136}, // unmatched teams are listed last
+			},
+		},
+		{
+			playerNamePrefix: "A's",
+			teamsJSON: `{"records":[
+				{"teamRecords":[
+						{"team":{"id":133,"name":"Oakland Athletics"},"wins":102,"losses":60},
+						{"team":{"id":136,"name":"Seattle Mariners"},"wins":116,"losses":46}]}]}`,
+			want: []PlayerSearchResult{
+				{Name: "Oakland Athletics", Details: "102 - 60 Record", SourceID: 133}, // nickname
+				{Name: "Seattle Mariners", Details: "116 - 46 Record", SourceID: 136},
+			},
+		},
+		{
+			playerNamePrefix: "marinres",
+			teamsJSON: `{"records":[
+				{"teamRecords":[
+						{"team":{"id":133,"name":"Oakland Athletics"},"wins":102,"losses":60},
+						{"team":{"id":136,"name":"Seattle Mariners"},"wins":116,"losses":46}]}]}`,
+			want: []PlayerSearchResult{
+				{Name: "Seattle Mariners", Details: "116 - 46 Record", SourceID: 136}, // typo
+				{Name: "Oakland Athletics", Details: "102 - 60 Record", SourceID: 133},
+			},
+		},
+		{
+			playerNamePrefix: "", // browse all teams
+			teamsJSON: `{"records":[
+				{"teamRecords":[
+						{"team":{"id":133,"name":"Oakland Athletics"},"wins":102,"losses":60},
+						{"team":{"id":136,"name":"Seattle Mariners"},"wins":116,"losses":46}]}]}`,
 			want: []PlayerSearchResult{
 				{Name: "Oakland Athletics", Details: "102 - 60 Record", SourceID: 133},
-				{Name: "Chicago Cubs", Details: "88 - 74 Record", SourceID: 112},
+				{Name: "Seattle Mariners", Details: "116 - 46 Record", SourceID: 136},
 			},
 		},
 		{
@@ -96,8 +181,8 @@ func TestMlbTeamPlayerSearchResults(t *testing.T) {
 			return test.teamsJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		mlbTeamR := mlbTeamRequester{requester: r}
-		got, err := mlbTeamR.Search(db.PlayerTypeMlbTeam, 2019, test.playerNamePrefix, true)
+		mlbTeamR := mlbTeamRequester{provider: statsMlbProvider{requester: r}}
+		got, err := mlbTeamR.Search(context.Background(), db.PlayerTypeMlbTeam, 2019, test.playerNamePrefix, true)
 		switch {
 		case test.wantErr:
 			if err == nil {
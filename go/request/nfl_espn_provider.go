@@ -0,0 +1,212 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// espnNflProvider implements the NflProvider interface with the public espn json apis.  It does not require a key.
+	espnNflProvider struct {
+		requester requester
+		// siteURL is the base url of the site api, which has standings
+		siteURL string
+		// coreURL is the base url of the core api, which has athletes and their stats
+		coreURL string
+	}
+
+	// EspnStandings contains the standings of the teams in each conference
+	EspnStandings struct {
+		Children []EspnStandingsGroup `json:"children"`
+	}
+
+	// EspnStandingsGroup contains the standings of the teams in a conference
+	EspnStandingsGroup struct {
+		Standings struct {
+			Entries []EspnStandingsEntry `json:"entries"`
+		} `json:"standings"`
+	}
+
+	// EspnStandingsEntry contains the stats of a team in the standings, such as "wins"
+	EspnStandingsEntry struct {
+		Team  EspnTeam   `json:"team"`
+		Stats []EspnStat `json:"stats"`
+	}
+
+	// EspnTeam contains the name of a team
+	EspnTeam struct {
+		ID          db.SourceID `json:"id,string"`
+		DisplayName string      `json:"displayName"`
+	}
+
+	// EspnStat is a named stat
+	EspnStat struct {
+		Name  string  `json:"name"`
+		Value float64 `json:"value"`
+	}
+
+	// EspnAthletes contains information about many athletes
+	EspnAthletes struct {
+		Items []EspnAthlete `json:"items"`
+	}
+
+	// EspnAthlete contains the name, position, and team of an athlete
+	EspnAthlete struct {
+		ID       db.SourceID `json:"id,string"`
+		FullName string      `json:"fullName"`
+		Position struct {
+			Abbreviation string `json:"abbreviation"`
+		} `json:"position"`
+		Team struct {
+			Abbreviation string `json:"abbreviation"`
+		} `json:"team"`
+	}
+
+	// EspnAthleteStatistics contains the season stats of an athlete, grouped in categories such as "passing"
+	EspnAthleteStatistics struct {
+		Splits struct {
+			Categories []EspnStatCategory `json:"categories"`
+		} `json:"splits"`
+	}
+
+	// EspnStatCategory contains the stats of a category
+	EspnStatCategory struct {
+		Name  string     `json:"name"`
+		Stats []EspnStat `json:"stats"`
+	}
+)
+
+// RequestTeams implements the NflProvider interface
+func (p espnNflProvider) RequestTeams(ctx context.Context, year int) ([]NflProviderTeam, error) {
+	uri := fmt.Sprintf("%s/apis/v2/sports/football/nfl/standings?season=%d", p.siteURL, year)
+	var espnStandings EspnStandings
+	if err := p.requester.structPointerFromURI(ctx, uri, &espnStandings); err != nil {
+		return nil, err
+	}
+	var nflTeams []NflProviderTeam
+	for _, group := range espnStandings.Children {
+		for _, entry := range group.Standings.Entries {
+			stats := espnStats(entry.Stats)
+			wins, losses, ties := stats["wins"], stats["losses"], stats["ties"]
+			nflTeams = append(nflTeams, NflProviderTeam{
+				SourceID: entry.Team.ID,
+				Name:     entry.Team.DisplayName,
+				Record:   fmt.Sprintf("%d-%d-%d", wins, losses, ties),
+				Wins:     wins,
+			})
+		}
+	}
+	sort.Slice(nflTeams, func(i, j int) bool {
+		return nflTeams[i].SourceID < nflTeams[j].SourceID
+	})
+	return nflTeams, nil
+}
+
+// RequestPlayers implements the NflProvider interface
+// The stats of each player are requested separately.
+func (p espnNflProvider) RequestPlayers(ctx context.Context, year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error) {
+	if len(sourceIDs) == 0 {
+		return nil, nil
+	}
+	espnAthletes, err := p.requestAthletes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	athletes := make(map[db.SourceID]EspnAthlete, len(espnAthletes.Items))
+	for _, athlete := range espnAthletes.Items {
+		athletes[athlete.ID] = athlete
+	}
+	nflPlayers := make([]NflProviderPlayer, 0, len(sourceIDs))
+	var mu sync.Mutex
+	g := newRequestGroup(ctx)
+	for _, sourceID := range sourceIDs {
+		athlete, ok := athletes[sourceID]
+		if !ok {
+			continue
+		}
+		g.do(func(ctx context.Context) error {
+			uri := fmt.Sprintf("%s/v2/sports/football/leagues/nfl/seasons/%d/types/2/athletes/%d/statistics", p.coreURL, year, sourceID)
+			var espnAthleteStatistics EspnAthleteStatistics
+			if err := p.requester.structPointerFromURI(ctx, uri, &espnAthleteStatistics); err != nil {
+				return err
+			}
+			nflPlayer := athlete.toProviderPlayer()
+			nflPlayer.Stats = espnAthleteStatistics.stats()
+			mu.Lock()
+			nflPlayers = append(nflPlayers, nflPlayer)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.wait(); err != nil {
+		return nil, err
+	}
+	return nflPlayers, nil
+}
+
+// SearchPlayers implements the NflProvider interface
+// All active athletes are requested and searched, so the response should be cached.
+func (p espnNflProvider) SearchPlayers(ctx context.Context, year int, query string) ([]NflProviderPlayer, error) {
+	espnAthletes, err := p.requestAthletes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var nflPlayers []NflProviderPlayer
+	lowerQuery := strings.ToLower(query)
+	for _, athlete := range espnAthletes.Items {
+		if strings.Contains(strings.ToLower(athlete.FullName), lowerQuery) {
+			nflPlayers = append(nflPlayers, athlete.toProviderPlayer())
+		}
+	}
+	return nflPlayers, nil
+}
+
+func (p espnNflProvider) requestAthletes(ctx context.Context) (*EspnAthletes, error) {
+	uri := fmt.Sprintf("%s/v3/sports/football/nfl/athletes?limit=20000&active=true", p.coreURL)
+	espnAthletes := new(EspnAthletes)
+	err := p.requester.structPointerFromURI(ctx, uri, espnAthletes)
+	return espnAthletes, err
+}
+
+func (athlete EspnAthlete) toProviderPlayer() NflProviderPlayer {
+	return NflProviderPlayer{
+		SourceID: athlete.ID,
+		Name:     athlete.FullName,
+		Position: athlete.Position.Abbreviation,
+		Team:     athlete.Team.Abbreviation,
+	}
+}
+
+// stats totals the touchdowns in the categories of the statistics
+func (s EspnAthleteStatistics) stats() NflPlayerStats {
+	var nflPlayerStats NflPlayerStats
+	for _, category := range s.Splits.Categories {
+		stats := espnStats(category.Stats)
+		switch category.Name {
+		case "passing":
+			nflPlayerStats.PassingTD = stats["passingTouchdowns"]
+		case "rushing":
+			nflPlayerStats.RushingTD = stats["rushingTouchdowns"]
+		case "receiving":
+			nflPlayerStats.ReceivingTD = stats["receivingTouchdowns"]
+		case "returning":
+			nflPlayerStats.ReturnTD = stats["kickReturnTouchdowns"] + stats["puntReturnTouchdowns"]
+		}
+	}
+	return nflPlayerStats
+}
+
+// espnStats maps the names of the stats to their values, which are whole numbers for counting stats
+func espnStats(stats []EspnStat) map[string]int {
+	m := make(map[string]int, len(stats))
+	for _, stat := range stats {
+		m[stat.Name] = int(math.Round(stat.Value))
+	}
+	return m
+}
@@ -0,0 +1,138 @@
+package request
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+func TestEspnNflProviderRequestTeams(t *testing.T) {
+	requestTeamsTests := []struct {
+		standingsJSON string
+		wantErr       bool
+		want          []NflProviderTeam
+	}{
+		{
+			wantErr: true, // no standingsJSON
+		},
+		{
+			standingsJSON: `{"children":[
+				{"standings":{"entries":[{"team":{"id":"26","displayName":"Seattle Seahawks"},"stats":[{"name":"wins","value":11.0},{"name":"losses","value":5.0},{"name":"ties","value":0.0},{"name":"winPercent","value":0.6875}]}]}},
+				{"standings":{"entries":[{"team":{"id":"16","displayName":"Minnesota Vikings"},"stats":[{"name":"losses","value":6.0},{"name":"wins","value":10.0}]}]}}]}`,
+			want: []NflProviderTeam{
+				{SourceID: 16, Name: "Minnesota Vikings", Record: "10-6-0", Wins: 10},
+				{SourceID: 26, Name: "Seattle Seahawks", Record: "11-5-0", Wins: 11},
+			},
+		},
+	}
+	for i, test := range requestTeamsTests {
+		jsonFunc := func(uri string) string {
+			if want := "/apis/v2/sports/football/nfl/standings?season=2019"; want != uri {
+				t.Errorf("Test %v: wanted uri %v, got %v", i, want, uri)
+			}
+			return test.standingsJSON
+		}
+		r := newMockHTTPRequester(jsonFunc)
+		p := espnNflProvider{requester: r}
+		got, err := p.RequestTeams(context.Background(), 2019)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error but did not get one", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: Not equal:\nWanted: %v\nGot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestEspnNflProviderRequestPlayers(t *testing.T) {
+	athletesJSON := `{"items":[
+		{"id":"14881","fullName":"Russell Wilson","position":{"abbreviation":"QB"},"team":{"abbreviation":"SEA"}},
+		{"id":"16725","fullName":"Tyler Lockett","position":{"abbreviation":"WR"},"team":{"abbreviation":"SEA"}}]}`
+	requestPlayersTests := []struct {
+		sourceIDs []db.SourceID
+		statsJSON map[string]string
+		wantErr   bool
+		want      []NflProviderPlayer
+	}{
+		{ // no players
+		},
+		{
+			sourceIDs: []db.SourceID{14881, 16725, 1}, // the last player is not an active athlete
+			statsJSON: map[string]string{
+				"14881": `{"splits":{"categories":[
+					{"name":"passing","stats":[{"name":"passingYards","value":4110.0},{"name":"passingTouchdowns","value":31.0}]},
+					{"name":"rushing","stats":[{"name":"rushingTouchdowns","value":3.0}]}]}}`,
+				"16725": `{"splits":{"categories":[
+					{"name":"receiving","stats":[{"name":"receivingTouchdowns","value":8.0}]},
+					{"name":"returning","stats":[{"name":"kickReturnTouchdowns","value":1.0},{"name":"puntReturnTouchdowns","value":1.0}]}]}}`,
+			},
+			want: []NflProviderPlayer{
+				{SourceID: 14881, Name: "Russell Wilson", Position: "QB", Team: "SEA", Stats: NflPlayerStats{PassingTD: 31, RushingTD: 3}},
+				{SourceID: 16725, Name: "Tyler Lockett", Position: "WR", Team: "SEA", Stats: NflPlayerStats{ReceivingTD: 8, ReturnTD: 2}},
+			},
+		},
+		{
+			sourceIDs: []db.SourceID{14881},
+			wantErr:   true, // no statsJSON
+		},
+	}
+	for i, test := range requestPlayersTests {
+		jsonFunc := func(uri string) string {
+			if strings.HasPrefix(uri, "/v3/sports/football/nfl/athletes?") {
+				return athletesJSON
+			}
+			for id, statsJSON := range test.statsJSON {
+				if uri == "/v2/sports/football/leagues/nfl/seasons/2019/types/2/athletes/"+id+"/statistics" {
+					return statsJSON
+				}
+			}
+			return ""
+		}
+		r := newMockHTTPRequester(jsonFunc)
+		p := espnNflProvider{requester: r}
+		got, err := p.RequestPlayers(context.Background(), 2019, test.sourceIDs)
+		sort.Slice(got, func(i, j int) bool {
+			return got[i].SourceID < got[j].SourceID
+		})
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error but did not get one", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case len(test.want) != len(got) || (len(got) != 0 && !reflect.DeepEqual(test.want, got)):
+			t.Errorf("Test %v: Not equal:\nWanted: %v\nGot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestEspnNflProviderSearchPlayers(t *testing.T) {
+	jsonFunc := func(uri string) string {
+		return `{"items":[
+			{"id":"14881","fullName":"Russell Wilson","position":{"abbreviation":"QB"},"team":{"abbreviation":"SEA"}},
+			{"id":"3116165","fullName":"Dontavius Russell","position":{"abbreviation":"DT"}},
+			{"id":"16725","fullName":"Tyler Lockett","position":{"abbreviation":"WR"},"team":{"abbreviation":"SEA"}}]}`
+	}
+	r := newMockHTTPRequester(jsonFunc)
+	p := espnNflProvider{requester: r}
+	want := []NflProviderPlayer{
+		{SourceID: 14881, Name: "Russell Wilson", Position: "QB", Team: "SEA"},
+		{SourceID: 3116165, Name: "Dontavius Russell", Position: "DT"},
+	}
+	got, err := p.SearchPlayers(context.Background(), 2019, "RUSSELL")
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("Not equal:\nWanted: %v\nGot:    %v", want, got)
+	}
+}
@@ -0,0 +1,193 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// fantasyNflProvider implements the NflProvider interface with api.fantasy.nfl.com
+	fantasyNflProvider struct {
+		requester requester
+	}
+
+	// NflTeamsSchedule contains information about NFL teams for a specific year
+	NflTeamsSchedule struct {
+		Teams map[db.SourceID]NflTeam `json:"nflTeams"`
+	}
+
+	// NflTeam contains information about an NFL team for a specific year
+	NflTeam struct {
+		Name   string `json:"fullName"`
+		Record string `json:"record"`
+	}
+
+	// NflPlayerSearch contains NflGames for a query
+	NflPlayerSearch struct {
+		Games map[string]NflGame `json:"games"`
+	}
+
+	// NflGame contains active NflPlayers for a particular query
+	NflGame struct {
+		Season  int                  `json:"season,string"`
+		Players map[string]NflPlayer `json:"players"`
+	}
+
+	// NflPlayer contains the player info and possibly stats
+	NflPlayer struct {
+		ID       db.SourceID                `json:"playerId,string"`
+		Name     string                     `json:"name"`
+		Position string                     `json:"position"`
+		Team     string                     `json:"nflTeamAbbr"`
+		Stats    map[string]json.RawMessage `json:"stats"`
+	}
+
+	// NflPlayerStats contains the stats totals a NflPlayerStat has accumulated during a particular year
+	// The meaning of these stats can be found at
+	// https://api.fantasy.nfl.com/v2/game/stats?appKey=test_key_1
+	NflPlayerStats struct {
+		PassingTD   int `json:"6,string"`
+		RushingTD   int `json:"15,string"`
+		ReceivingTD int `json:"22,string"`
+		ReturnTD    int `json:"28,string"`
+	}
+)
+
+// RequestTeams implements the NflProvider interface
+func (p fantasyNflProvider) RequestTeams(ctx context.Context, year int) ([]NflProviderTeam, error) {
+	uri := fmt.Sprintf("nfl/schedule?season=%d", year)
+	var nflSchedule NflTeamsSchedule
+	if err := p.requester.structPointerFromURI(ctx, uri, &nflSchedule); err != nil {
+		return nil, err
+	}
+	nflTeams := make([]NflProviderTeam, 0, len(nflSchedule.Teams))
+	for sourceID, nflTeam := range nflSchedule.Teams {
+		wins, err := nflTeam.wins()
+		if err != nil {
+			return nil, err
+		}
+		nflTeams = append(nflTeams, NflProviderTeam{
+			SourceID: sourceID,
+			Name:     nflTeam.Name,
+			Record:   nflTeam.Record,
+			Wins:     wins,
+		})
+	}
+	sort.Slice(nflTeams, func(i, j int) bool {
+		return nflTeams[i].SourceID < nflTeams[j].SourceID
+	})
+	return nflTeams, nil
+}
+
+// RequestPlayers implements the NflProvider interface
+func (p fantasyNflProvider) RequestPlayers(ctx context.Context, year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error) {
+	if len(sourceIDs) == 0 {
+		return nil, nil
+	}
+	requestedSourceIDs := make(map[db.SourceID]bool, len(sourceIDs))
+	services := make([]map[string]string, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		requestedSourceIDs[sourceID] = true
+		services[i] = map[string]string{
+			"playerDetails": fmt.Sprintf("season=%d&playerId=%d", year, sourceID),
+		}
+	}
+	servicesJSON, err := json.Marshal(services)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request url for bulk nfl player stats: %w", err)
+	}
+	uri := fmt.Sprintf("batchservices?services=%s", servicesJSON)
+	nflPlayerSearch, err := p.requestNflPlayerSearch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var nflPlayers []NflProviderPlayer
+	for id, nflPlayer := range nflPlayerSearch.players() {
+		if !requestedSourceIDs[nflPlayer.ID] {
+			continue
+		}
+		stats, err := nflPlayer.stats()
+		if err != nil {
+			return nil, fmt.Errorf("could not get season stats for player %v: %w", id, err)
+		}
+		providerPlayer := nflPlayer.toProviderPlayer()
+		providerPlayer.Stats = stats
+		nflPlayers = append(nflPlayers, providerPlayer)
+	}
+	return nflPlayers, nil
+}
+
+// SearchPlayers implements the NflProvider interface
+// searches active players
+func (p fantasyNflProvider) SearchPlayers(ctx context.Context, year int, query string) ([]NflProviderPlayer, error) {
+	positionIds := "1,2,3,4" // QB,RB,WR,TE
+	uri := fmt.Sprintf("players/autocomplete?positionIds=%s&query=%s", positionIds, query)
+	nflPlayerSearch, err := p.requestNflPlayerSearch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var nflPlayers []NflProviderPlayer
+	for _, nflPlayer := range nflPlayerSearch.players() {
+		nflPlayers = append(nflPlayers, nflPlayer.toProviderPlayer())
+	}
+	sort.Slice(nflPlayers, func(i, j int) bool {
+		return nflPlayers[i].SourceID < nflPlayers[j].SourceID
+	})
+	return nflPlayers, nil
+}
+
+func (p fantasyNflProvider) requestNflPlayerSearch(ctx context.Context, uri string) (*NflPlayerSearch, error) {
+	nflPlayerSearch := new(NflPlayerSearch)
+	err := p.requester.structPointerFromURI(ctx, uri, &nflPlayerSearch)
+	return nflPlayerSearch, err
+}
+
+func (nflTeam NflTeam) wins() (int, error) {
+	recordParts := strings.Split(nflTeam.Record, "-")
+	winsI, err := strconv.Atoi(recordParts[0])
+	if err != nil {
+		return -1, fmt.Errorf("invalid Wins number for %v", nflTeam)
+	}
+	return winsI, nil
+}
+
+func (s NflPlayerSearch) players() map[string]NflPlayer {
+	for _, g := range s.Games {
+		return g.Players
+	}
+	return nil
+}
+
+func (nflPlayer NflPlayer) toProviderPlayer() NflProviderPlayer {
+	return NflProviderPlayer{
+		SourceID: nflPlayer.ID,
+		Name:     nflPlayer.Name,
+		Position: nflPlayer.Position,
+		Team:     nflPlayer.Team,
+	}
+}
+
+// stats has special handling to return the first season's stats
+// the actual stats are structured like {"week:{YEAR:{WEEK:{K:V...}...}...},"season":{YEAR:{K:V...}...}
+func (nflPlayer NflPlayer) stats() (NflPlayerStats, error) {
+	var nflPlayerStats NflPlayerStats
+	rawStatsYearsMap, ok := nflPlayer.Stats["season"]
+	if !ok {
+		return nflPlayerStats, fmt.Errorf("no season stats for player %v", nflPlayer.ID)
+	}
+	var statsYears map[string]NflPlayerStats
+	err := json.Unmarshal(rawStatsYearsMap, &statsYears)
+	if err != nil {
+		return nflPlayerStats, fmt.Errorf("could not unmarshal season stats by year: %w", err)
+	}
+	for _, nflPlayerStats := range statsYears {
+		return nflPlayerStats, nil
+	}
+	return nflPlayerStats, fmt.Errorf("no season stats")
+}
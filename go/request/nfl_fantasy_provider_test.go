@@ -0,0 +1,109 @@
+package request
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func newFantasyNflProviders(r requester) nflProviders {
+	return nflProviders{{name: NflProviderFantasy, NflProvider: fantasyNflProvider{requester: r}}}
+}
+
+func TestNflTeamWins(t *testing.T) {
+	nflTeamWinsTests := []struct {
+		nflTeam   NflTeam
+		want      int
+		wantError bool
+	}{
+		{
+			nflTeam: NflTeam{Record: "7-9-0"},
+			want:    7,
+		},
+		{
+			nflTeam: NflTeam{Record: "6-9-1"},
+			want:    6,
+		},
+		{
+			nflTeam: NflTeam{Record: "16"},
+			want:    16,
+		},
+		{
+			nflTeam:   NflTeam{Record: ""},
+			wantError: true,
+		},
+		{
+			nflTeam:   NflTeam{Record: "eight-8-0"},
+			wantError: true,
+		},
+		{
+			nflTeam:   NflTeam{Record: "-4-12-0"},
+			wantError: true,
+		},
+		{
+			nflTeam:   NflTeam{Record: "four and ten"},
+			wantError: true,
+		},
+	}
+	for i, test := range nflTeamWinsTests {
+		got, err := test.nflTeam.wins()
+		switch {
+		case test.wantError:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: %v", i, err)
+		case test.want != got:
+			t.Errorf("Test %v: wanted %v, got %v", i, test.want, got)
+		}
+	}
+}
+
+func TestNflPlayerGetStats(t *testing.T) {
+	nflPlayerStatsTests := []struct {
+		stats   map[string]json.RawMessage
+		want    NflPlayerStats
+		wantErr bool
+	}{
+		{
+			stats: map[string]json.RawMessage{
+				"season": json.RawMessage(`{"2018":{"6":"35"}}`),
+			},
+			want: NflPlayerStats{
+				PassingTD: 35,
+			},
+		},
+		{
+			stats: map[string]json.RawMessage{ // bad key
+				"week": json.RawMessage(`{"2018":{"6":"35"}}`),
+			},
+			wantErr: true,
+		},
+		{
+			stats: map[string]json.RawMessage{ // bad json
+				"season": json.RawMessage(`{"2018":{"PassingTD":"35"}}`),
+			},
+			wantErr: true,
+		},
+		{
+			stats: map[string]json.RawMessage{ // any of the two could be picked, but should not crash
+				"season": json.RawMessage(`{"2018":{},"2019":{}}`),
+			},
+			want: NflPlayerStats{},
+		},
+	}
+	for i, test := range nflPlayerStatsTests {
+		nflPlayer := NflPlayer{
+			Stats: test.stats,
+		}
+		got, err := nflPlayer.stats()
+		switch {
+		case err != nil:
+			if !test.wantErr {
+				t.Errorf("Test %v: unexpected error: %v", i, err)
+			}
+		case test.want != got:
+			t.Errorf("Test %v:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
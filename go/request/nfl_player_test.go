@@ -1,7 +1,7 @@
 package request
 
 import (
-	"encoding/json"
+	"context"
 	"reflect"
 	"testing"
 
@@ -85,8 +85,8 @@ func TestNflPlayerRequestScoreCategory(t *testing.T) {
 			return test.playersJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		nflPlayerR := nflPlayerRequester{requester: r}
-		got, err := nflPlayerR.RequestScoreCategory(test.pt, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
+		nflPlayerR := nflPlayerRequester{providers: newFantasyNflProviders(r)}
+		got, err := nflPlayerR.RequestScoreCategory(context.Background(), test.pt, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -121,7 +121,7 @@ func TestNflPlayerPlayerSearchResults(t *testing.T) {
 				"2532975":{"playerId":"2532975","name":"Russell Wilson","position":"QB","nflTeamAbbr":"SEA"}
 				}}}}`,
 			want: []PlayerSearchResult{
-				{Name: "Russell Wilson", Details: "Team: SEA, Position: QB", SourceID: 2532975},
+				{Name: "Russell Wilson", Details: "Team: SEA, Position: QB", SourceID: 2532975, Position: "QB", Team: "SEA"},
 			},
 		},
 		{
@@ -133,8 +133,8 @@ func TestNflPlayerPlayerSearchResults(t *testing.T) {
 			return test.playersJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		nflPlayerR := nflPlayerRequester{requester: r}
-		got, err := nflPlayerR.Search(test.pt, 2019, test.playerNamePrefix, true)
+		nflPlayerR := nflPlayerRequester{providers: newFantasyNflProviders(r)}
+		got, err := nflPlayerR.Search(context.Background(), test.pt, 2019, test.playerNamePrefix, true)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -147,52 +147,3 @@ func TestNflPlayerPlayerSearchResults(t *testing.T) {
 		}
 	}
 }
-
-func TestNflPlayerGetStats(t *testing.T) {
-	nflPlayerStatsTests := []struct {
-		stats   map[string]json.RawMessage
-		want    NflPlayerStats
-		wantErr bool
-	}{
-		{
-			stats: map[string]json.RawMessage{
-				"season": json.RawMessage(`{"2018":{"6":"35"}}`),
-			},
-			want: NflPlayerStats{
-				PassingTD: 35,
-			},
-		},
-		{
-			stats: map[string]json.RawMessage{ // bad key
-				"week": json.RawMessage(`{"2018":{"6":"35"}}`),
-			},
-			wantErr: true,
-		},
-		{
-			stats: map[string]json.RawMessage{ // bad json
-				"season": json.RawMessage(`{"2018":{"PassingTD":"35"}}`),
-			},
-			wantErr: true,
-		},
-		{
-			stats: map[string]json.RawMessage{ // any of the two could be picked, but should not crash
-				"season": json.RawMessage(`{"2018":{},"2019":{}}`),
-			},
-			want: NflPlayerStats{},
-		},
-	}
-	for i, test := range nflPlayerStatsTests {
-		nflPlayer := NflPlayer{
-			Stats: test.stats,
-		}
-		got, err := nflPlayer.stats()
-		switch {
-		case err != nil:
-			if !test.wantErr {
-				t.Errorf("Test %v: unexpected error: %v", i, err)
-			}
-		case test.want != got:
-			t.Errorf("Test %v:\nwanted: %v\ngot:    %v", i, test.want, got)
-		}
-	}
-}
@@ -0,0 +1,265 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// NflProvider requests nfl standings, player stats, and player names from an external source.
+	// Each NflProvider has its own SourceIDs for teams and players.
+	NflProvider interface {
+		// RequestTeams requests the names and records of all teams for the year.
+		RequestTeams(ctx context.Context, year int) ([]NflProviderTeam, error)
+		// RequestPlayers requests the names and season stats of the players for the year.  Players without stats are not returned.
+		RequestPlayers(ctx context.Context, year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error)
+		// SearchPlayers requests the offensive players with names that contain the query.
+		SearchPlayers(ctx context.Context, year int, query string) ([]NflProviderPlayer, error)
+	}
+
+	// NflProviderTeam contains the record of an nfl team for a year.
+	NflProviderTeam struct {
+		SourceID db.SourceID
+		Name     string
+		// Record is the wins, losses, and ties of the team, such as "10-6-0"
+		Record string
+		Wins   int
+	}
+
+	// NflProviderPlayer contains the name, position, team, and stats of an nfl player.  The stats are empty for search results.
+	NflProviderPlayer struct {
+		SourceID db.SourceID
+		Name     string
+		Position string
+		Team     string
+		Stats    NflPlayerStats
+	}
+
+	// namedNflProvider is a NflProvider with the name it is configured by.
+	// Players that cannot be mapped to the SourceIDs of the provider are logged if it has a log.
+	namedNflProvider struct {
+		name string
+		log  *log.Logger
+		NflProvider
+	}
+
+	// nflProviders request nfl data from the first of the providers that succeeds.
+	// The SourceIDs of players are those of the first (primary) provider.  They are mapped to the SourceIDs of the fallback providers by the saved names of the players.
+	nflProviders []namedNflProvider
+
+	// sourceIDMap maps the SourceIDs of the primary provider to those of another provider.
+	sourceIDMap map[db.SourceID]db.SourceID
+)
+
+const (
+	// NflProviderFantasy requests nfl data from api.fantasy.nfl.com.  It requires an app key.
+	NflProviderFantasy = "fantasy"
+	// NflProviderEspn requests nfl data from the public espn json apis.
+	NflProviderEspn = "espn"
+)
+
+// defaultNflProviders are used when no providers are configured.
+var defaultNflProviders = []string{NflProviderFantasy, NflProviderEspn}
+
+// ParseNflProviders parses the comma-separated names of the nfl providers, primary first, such as "fantasy,espn".
+// The default providers are used if the names are empty.
+func ParseNflProviders(names string) ([]string, error) {
+	if len(strings.TrimSpace(names)) == 0 {
+		return defaultNflProviders, nil
+	}
+	var providers []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name != NflProviderFantasy && name != NflProviderEspn:
+			return nil, fmt.Errorf("unknown nfl provider: %q", name)
+		case seen[name]:
+			return nil, fmt.Errorf("duplicate nfl provider: %q", name)
+		}
+		seen[name] = true
+		providers = append(providers, name)
+	}
+	return providers, nil
+}
+
+// primary is the provider that searches are made with, so the SourceIDs of added players are always its SourceIDs.
+func (ps nflProviders) primary() (namedNflProvider, error) {
+	if len(ps) == 0 {
+		return namedNflProvider{}, fmt.Errorf("no nfl providers")
+	}
+	return ps[0], nil
+}
+
+// requestTeams requests the teams of the players from the first provider that succeeds, keyed by the SourceIDs of the primary provider.
+func (ps nflProviders) requestTeams(ctx context.Context, year int, players []db.Player) (map[db.SourceID]NflProviderTeam, error) {
+	return requestFirst(ctx, ps, func(i int, p namedNflProvider) (map[db.SourceID]NflProviderTeam, error) {
+		teams, err := p.RequestTeams(ctx, year)
+		if err != nil {
+			return nil, fmt.Errorf("requesting nfl teams from %v: %w", p.name, err)
+		}
+		teamsBySourceID := make(map[db.SourceID]NflProviderTeam, len(teams))
+		for _, team := range teams {
+			teamsBySourceID[team.SourceID] = team
+		}
+		if i == 0 {
+			return teamsBySourceID, nil
+		}
+		m, err := newTeamSourceIDMap(p, players, teams)
+		if err != nil {
+			return nil, fmt.Errorf("mapping nfl teams to %v: %w", p.name, err)
+		}
+		return rekey(m, teamsBySourceID), nil
+	})
+}
+
+// requestPlayers requests the stats of the players from the first provider that succeeds, keyed by the SourceIDs of the primary provider.
+func (ps nflProviders) requestPlayers(ctx context.Context, year int, players []db.Player) (map[db.SourceID]NflProviderPlayer, error) {
+	return requestFirst(ctx, ps, func(i int, p namedNflProvider) (map[db.SourceID]NflProviderPlayer, error) {
+		m := make(sourceIDMap, len(players))
+		for _, player := range players {
+			m[player.SourceID] = player.SourceID
+		}
+		if i != 0 {
+			var err error
+			if m, err = newPlayerSourceIDMap(ctx, year, players, p); err != nil {
+				return nil, fmt.Errorf("mapping nfl players to %v: %w", p.name, err)
+			}
+		}
+		sourceIDs := make([]db.SourceID, 0, len(m))
+		for _, sourceID := range m {
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+		nflPlayers, err := p.RequestPlayers(ctx, year, sourceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("requesting nfl players from %v: %w", p.name, err)
+		}
+		playersBySourceID := make(map[db.SourceID]NflProviderPlayer, len(nflPlayers))
+		for _, nflPlayer := range nflPlayers {
+			playersBySourceID[nflPlayer.SourceID] = nflPlayer
+		}
+		return rekey(m, playersBySourceID), nil
+	})
+}
+
+// requestFirst calls the request function with each provider in order until one succeeds.  If all fail, all of their errors are returned.
+func requestFirst[V any](ctx context.Context, ps nflProviders, request func(i int, p namedNflProvider) (map[db.SourceID]V, error)) (map[db.SourceID]V, error) {
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("no nfl providers")
+	}
+	var errs []error
+	for i, p := range ps {
+		values, err := request(i, p)
+		if err == nil {
+			return values, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// newTeamSourceIDMap maps the SourceIDs of the team players to the teams with the same names.
+// Teams that cannot be mapped are skipped so they are not scored by the provider.
+func newTeamSourceIDMap(p namedNflProvider, players []db.Player, teams []NflProviderTeam) (sourceIDMap, error) {
+	m := make(sourceIDMap, len(players))
+	for _, player := range players {
+		if len(player.Name) == 0 {
+			p.logSkipped(player, "no saved name")
+			continue
+		}
+		found := false
+		for _, team := range teams {
+			if strings.EqualFold(player.Name, team.Name) {
+				m[player.SourceID] = team.SourceID
+				found = true
+				break
+			}
+		}
+		if !found {
+			p.logSkipped(player, "no team with the name")
+		}
+	}
+	return m, checkMapped(m, players)
+}
+
+// newPlayerSourceIDMap maps the SourceIDs of the players to the players with the same names found by searching the provider.
+// Players that cannot be mapped, such as retired or renamed players, are skipped so they are not scored by the provider.
+func newPlayerSourceIDMap(ctx context.Context, year int, players []db.Player, p namedNflProvider) (sourceIDMap, error) {
+	m := make(sourceIDMap, len(players))
+	var mu sync.Mutex
+	g := newRequestGroup(ctx)
+	for _, player := range players {
+		if len(player.Name) == 0 {
+			p.logSkipped(player, "no saved name")
+			continue
+		}
+		g.do(func(ctx context.Context) error {
+			nflPlayers, err := p.SearchPlayers(ctx, year, player.Name)
+			if err != nil {
+				return err
+			}
+			for _, nflPlayer := range nflPlayers {
+				if strings.EqualFold(player.Name, nflPlayer.Name) {
+					mu.Lock()
+					m[player.SourceID] = nflPlayer.SourceID
+					mu.Unlock()
+					return nil
+				}
+			}
+			p.logSkipped(player, "no player with the name")
+			return nil
+		})
+	}
+	if err := g.wait(); err != nil {
+		return nil, err
+	}
+	return m, checkMapped(m, players)
+}
+
+// checkMapped returns an error if there are players but none of them could be mapped, so the provider cannot score them.
+func checkMapped(m sourceIDMap, players []db.Player) error {
+	if len(players) != 0 && len(m) == 0 {
+		return fmt.Errorf("none of the %v players could be mapped", len(players))
+	}
+	return nil
+}
+
+// logSkipped logs that the player cannot be mapped to the provider for the reason.
+func (p namedNflProvider) logSkipped(player db.Player, reason string) {
+	if p.log != nil {
+		p.log.Printf("not scoring nfl player %v (%q) with %v: %v", player.SourceID, player.Name, p.name, reason)
+	}
+}
+
+// rekey maps the values keyed by the SourceIDs of another provider to the SourceIDs of the primary provider.
+// Values for SourceIDs that are not mapped are not returned.
+func rekey[V any](m sourceIDMap, values map[db.SourceID]V) map[db.SourceID]V {
+	rekeyed := make(map[db.SourceID]V, len(m))
+	for primarySourceID, sourceID := range m {
+		if v, ok := values[sourceID]; ok {
+			rekeyed[primarySourceID] = v
+		}
+	}
+	return rekeyed
+}
+
+// matches determines if the player plays the position of the PlayerType.
+func (nflPlayer NflProviderPlayer) matches(pt db.PlayerType) bool {
+	switch nflPlayer.Position {
+	case "QB":
+		return pt == db.PlayerTypeNflQB
+	case "RB", "WR", "TE":
+		return pt == db.PlayerTypeNflMisc
+	default:
+		return false
+	}
+}
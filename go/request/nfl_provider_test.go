@@ -0,0 +1,230 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type mockNflProvider struct {
+	RequestTeamsFunc   func(year int) ([]NflProviderTeam, error)
+	RequestPlayersFunc func(year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error)
+	SearchPlayersFunc  func(year int, query string) ([]NflProviderPlayer, error)
+}
+
+func (p mockNflProvider) RequestTeams(ctx context.Context, year int) ([]NflProviderTeam, error) {
+	return p.RequestTeamsFunc(year)
+}
+
+func (p mockNflProvider) RequestPlayers(ctx context.Context, year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error) {
+	return p.RequestPlayersFunc(year, sourceIDs)
+}
+
+func (p mockNflProvider) SearchPlayers(ctx context.Context, year int, query string) ([]NflProviderPlayer, error) {
+	return p.SearchPlayersFunc(year, query)
+}
+
+func TestParseNflProviders(t *testing.T) {
+	parseNflProvidersTests := []struct {
+		names   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			want: []string{"fantasy", "espn"},
+		},
+		{
+			names: "espn",
+			want:  []string{"espn"},
+		},
+		{
+			names: " ESPN, fantasy ",
+			want:  []string{"espn", "fantasy"},
+		},
+		{
+			names:   "espn,yahoo",
+			wantErr: true, // unknown
+		},
+		{
+			names:   "espn,espn",
+			wantErr: true, // duplicate
+		},
+		{
+			names:   "espn,",
+			wantErr: true, // empty name
+		},
+	}
+	for i, test := range parseNflProvidersTests {
+		got, err := ParseNflProviders(test.names)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestNflProvidersRequestTeams(t *testing.T) {
+	newProvider := func(name string, teams []NflProviderTeam, err error) namedNflProvider {
+		return namedNflProvider{
+			name: name,
+			NflProvider: mockNflProvider{
+				RequestTeamsFunc: func(year int) ([]NflProviderTeam, error) {
+					return teams, err
+				},
+			},
+		}
+	}
+	fantasyTeams := []NflProviderTeam{{SourceID: 30, Name: "Seattle Seahawks", Wins: 10}, {SourceID: 20, Name: "Minnesota Vikings", Wins: 8}}
+	espnTeams := []NflProviderTeam{{SourceID: 26, Name: "Seattle Seahawks", Wins: 11}, {SourceID: 16, Name: "Minnesota Vikings", Wins: 10}}
+	players := []db.Player{{SourceID: 30, Name: "seattle seahawks"}}
+	requestTeamsTests := []struct {
+		providers nflProviders
+		players   []db.Player
+		want      map[db.SourceID]NflProviderTeam
+		wantErr   bool
+	}{
+		{
+			wantErr: true, // no providers
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", fantasyTeams, nil), newProvider("espn", espnTeams, nil)},
+			players:   players,
+			want:      map[db.SourceID]NflProviderTeam{30: fantasyTeams[0], 20: fantasyTeams[1]},
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnTeams, nil)},
+			players:   players,
+			want:      map[db.SourceID]NflProviderTeam{30: espnTeams[0]},
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnTeams, nil)},
+			players:   []db.Player{{SourceID: 30}},
+			wantErr:   true, // no saved name to map the team with
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnTeams, nil)},
+			players:   []db.Player{{SourceID: 29, Name: "San Francisco 49ers"}},
+			wantErr:   true, // no team with the name
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnTeams, nil)},
+			players:   []db.Player{{SourceID: 30, Name: "Seattle Seahawks"}, {SourceID: 29, Name: "San Francisco 49ers"}, {SourceID: 28}},
+			want:      map[db.SourceID]NflProviderTeam{30: espnTeams[0]}, // the teams that cannot be mapped are not scored
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", nil, errors.New("espn down"))},
+			players:   players,
+			wantErr:   true,
+		},
+	}
+	for i, test := range requestTeamsTests {
+		got, err := test.providers.requestTeams(context.Background(), 2019, test.players)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestNflProvidersRequestPlayers(t *testing.T) {
+	newProvider := func(name string, nflPlayers []NflProviderPlayer, err error) namedNflProvider {
+		return namedNflProvider{
+			name: name,
+			NflProvider: mockNflProvider{
+				RequestPlayersFunc: func(year int, sourceIDs []db.SourceID) ([]NflProviderPlayer, error) {
+					if err != nil {
+						return nil, err
+					}
+					requestedSourceIDs := make(map[db.SourceID]bool, len(sourceIDs))
+					for _, sourceID := range sourceIDs {
+						requestedSourceIDs[sourceID] = true
+					}
+					var requested []NflProviderPlayer
+					for _, nflPlayer := range nflPlayers {
+						if requestedSourceIDs[nflPlayer.SourceID] {
+							requested = append(requested, nflPlayer)
+						}
+					}
+					return requested, nil
+				},
+				SearchPlayersFunc: func(year int, query string) ([]NflProviderPlayer, error) {
+					return nflPlayers, nil
+				},
+			},
+		}
+	}
+	fantasyPlayers := []NflProviderPlayer{{SourceID: 2532975, Name: "Russell Wilson", Stats: NflPlayerStats{PassingTD: 35}}}
+	espnPlayers := []NflProviderPlayer{
+		{SourceID: 14880, Name: "Kirk Cousins", Stats: NflPlayerStats{PassingTD: 26}},
+		{SourceID: 14881, Name: "Russell Wilson", Stats: NflPlayerStats{PassingTD: 31}},
+	}
+	players := []db.Player{{SourceID: 2532975, Name: "Russell Wilson"}}
+	requestPlayersTests := []struct {
+		providers nflProviders
+		players   []db.Player
+		want      map[db.SourceID]NflProviderPlayer
+		wantErr   bool
+	}{
+		{
+			wantErr: true, // no providers
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", fantasyPlayers, nil), newProvider("espn", espnPlayers, nil)},
+			players:   players,
+			want:      map[db.SourceID]NflProviderPlayer{2532975: fantasyPlayers[0]},
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnPlayers, nil)},
+			players:   players,
+			want:      map[db.SourceID]NflProviderPlayer{2532975: espnPlayers[1]},
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnPlayers, nil)},
+			players:   []db.Player{{SourceID: 2532975}},
+			wantErr:   true, // no saved name to map the player with
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnPlayers, nil)},
+			players:   []db.Player{{SourceID: 2495454, Name: "Julio Jones"}},
+			wantErr:   true, // no player with the name
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", espnPlayers, nil)},
+			players:   []db.Player{{SourceID: 2532975, Name: "Russell Wilson"}, {SourceID: 2495454, Name: "Julio Jones"}, {SourceID: 2495455}},
+			want:      map[db.SourceID]NflProviderPlayer{2532975: espnPlayers[1]}, // the players that cannot be mapped are not scored
+		},
+		{
+			providers: nflProviders{newProvider("fantasy", nil, errors.New("no app key")), newProvider("espn", nil, errors.New("espn down"))},
+			players:   players,
+			wantErr:   true,
+		},
+	}
+	for i, test := range requestPlayersTests {
+		got, err := test.providers.requestPlayers(context.Background(), 2019, test.players)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
@@ -1,22 +1,24 @@
 package request
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
 
 type nflRequester struct {
 	appKey    string
+	baseURL   string
 	requester requester
 }
 
-func (n nflRequester) structPointerFromURI(uri string, v interface{}) error {
+func (n nflRequester) structPointerFromURI(ctx context.Context, uri string, v interface{}) error {
 	if len(uri) > 0 && uri[0] != '/' {
 		uri = "/" + uri
 	}
 	if !strings.Contains(uri, "?") {
 		uri = uri + "?"
 	}
-	uri = fmt.Sprintf("https://api.fantasy.nfl.com/v2%s&appKey=%s", uri, n.appKey)
-	return n.requester.structPointerFromURI(uri, v)
+	uri = fmt.Sprintf("%s/v2%s&appKey=%s", n.baseURL, uri, n.appKey)
+	return n.requester.structPointerFromURI(ctx, uri, v)
 }
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
@@ -27,6 +28,6 @@ func TestStructPointerFromURI(t *testing.T) {
 			appKey:    "XYZ",
 			requester: r,
 		}
-		nflR.structPointerFromURI(providedURI, nil)
+		nflR.structPointerFromURI(context.Background(), providedURI, nil)
 	}
 }
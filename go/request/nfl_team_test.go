@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -53,8 +54,8 @@ func TestNflTeamRequestScoreCategory(t *testing.T) {
 			return test.teamsJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		nflTeamR := nflTeamRequester{requester: r}
-		got, err := nflTeamR.RequestScoreCategory(db.PlayerTypeNflTeam, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
+		nflTeamR := nflTeamRequester{providers: newFantasyNflProviders(r)}
+		got, err := nflTeamR.RequestScoreCategory(context.Background(), db.PlayerTypeNflTeam, db.PlayerTypeInfo{}, 2019, test.friends, test.players)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -88,6 +89,27 @@ func TestNflTeamPlayerSearchResults(t *testing.T) {
 			want: []PlayerSearchResult{
 				{Name: "Green Bay Packers", Details: "2-0-0 Record", SourceID: 11},
 				{Name: "Tampa Bay Buccaneers", Details: "1-1-0 Record", SourceID: 31},
+				{Name: "Baltimore Ravens", Details: "2-0-0 Record", SourceID: 2}, // unmatched teams are listed last
+			},
+		},
+		{
+			playerNamePrefix: "Niners",
+			teamsJSON: `{"nflTeams":{
+				"29":{"fullName":"San Francisco 49ers","record":"4-12-0"},
+				"30":{"fullName":"Seattle Seahawks","record":"10-6-0"}}}`,
+			want: []PlayerSearchResult{
+				{Name: "San Francisco 49ers", Details: "4-12-0 Record", SourceID: 29},
+				{Name: "Seattle Seahawks", Details: "10-6-0 Record", SourceID: 30},
+			},
+		},
+		{
+			playerNamePrefix: "", // browse all teams
+			teamsJSON: `{"nflTeams":{
+				"29":{"fullName":"San Francisco 49ers","record":"4-12-0"},
+				"30":{"fullName":"Seattle Seahawks","record":"10-6-0"}}}`,
+			want: []PlayerSearchResult{
+				{Name: "San Francisco 49ers", Details: "4-12-0 Record", SourceID: 29},
+				{Name: "Seattle Seahawks", Details: "10-6-0 Record", SourceID: 30},
 			},
 		},
 	}
@@ -96,8 +118,8 @@ func TestNflTeamPlayerSearchResults(t *testing.T) {
 			return test.teamsJSON
 		}
 		r := newMockHTTPRequester(jsonFunc)
-		nflTeamR := nflTeamRequester{requester: r}
-		got, err := nflTeamR.Search(db.PlayerTypeMlbTeam, 2019, test.playerNamePrefix, true)
+		nflTeamR := nflTeamRequester{providers: newFantasyNflProviders(r)}
+		got, err := nflTeamR.Search(context.Background(), db.PlayerTypeNflTeam, 2019, test.playerNamePrefix, true)
 		switch {
 		case test.wantErr:
 			if err == nil {
@@ -110,53 +132,3 @@ func TestNflTeamPlayerSearchResults(t *testing.T) {
 		}
 	}
 }
-
-func TestNflTeamWins(t *testing.T) {
-	nflTeamWinsTests := []struct {
-		nflTeam   NflTeam
-		want      int
-		wantError bool
-	}{
-		{
-			nflTeam: NflTeam{Record: "7-9-0"},
-			want:    7,
-		},
-		{
-			nflTeam: NflTeam{Record: "6-9-1"},
-			want:    6,
-		},
-		{
-			nflTeam: NflTeam{Record: "16"},
-			want:    16,
-		},
-		{
-			nflTeam:   NflTeam{Record: ""},
-			wantError: true,
-		},
-		{
-			nflTeam:   NflTeam{Record: "eight-8-0"},
-			wantError: true,
-		},
-		{
-			nflTeam:   NflTeam{Record: "-4-12-0"},
-			wantError: true,
-		},
-		{
-			nflTeam:   NflTeam{Record: "four and ten"},
-			wantError: true,
-		},
-	}
-	for i, test := range nflTeamWinsTests {
-		got, err := test.nflTeam.wins()
-		switch {
-		case test.wantError:
-			if err == nil {
-				t.Errorf("Test %v: wanted error", i)
-			}
-		case err != nil:
-			t.Errorf("Test %v: %v", i, err)
-		case test.want != got:
-			t.Errorf("Test %v: wanted %v, got %v", i, test.want, got)
-		}
-	}
-}
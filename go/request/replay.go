@@ -0,0 +1,157 @@
+package request
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// ReplayClient is an HTTPClient that replays responses recorded in a directory so the server can run without network access.
+	// When recording, requests are made with another HTTPClient and their ok responses are saved to the directory.
+	ReplayClient struct {
+		httpClient HTTPClient
+		dir        string
+		record     bool
+	}
+
+	// recordedResponse is a response saved in a file, keyed by the scrubbed uri of its request.
+	recordedResponse struct {
+		URI          string `json:"uri"`
+		ContentType  string `json:"contentType,omitempty"`
+		ETag         string `json:"etag,omitempty"`
+		LastModified string `json:"lastModified,omitempty"`
+		Body         string `json:"body"`
+	}
+)
+
+// NewReplayClient creates a ReplayClient that replays responses recorded in the directory.
+// If record is true, requests are made with the HTTPClient and their responses are recorded instead.
+func NewReplayClient(httpClient HTTPClient, dir string, record bool) (*ReplayClient, error) {
+	if len(dir) == 0 {
+		return nil, fmt.Errorf("directory required to replay responses")
+	}
+	if record {
+		if httpClient == nil {
+			return nil, fmt.Errorf("HTTPClient required to record responses")
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("creating directory to record responses: %w", err)
+		}
+	}
+	c := ReplayClient{
+		httpClient: httpClient,
+		dir:        dir,
+		record:     record,
+	}
+	return &c, nil
+}
+
+// Do implements the HTTPClient interface.
+// Recorded responses are replayed without their request headers, so conditional requests always get the full response.
+func (c ReplayClient) Do(r *http.Request) (*http.Response, error) {
+	uri := scrubURI(r.URL)
+	if c.record {
+		return c.recordResponse(r, uri)
+	}
+	return c.replayResponse(r, uri)
+}
+
+// recordResponse makes the request and records the response if it is ok.
+func (c ReplayClient) recordResponse(r *http.Request, uri string) (*http.Response, error) {
+	response, err := c.httpClient.Do(r)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+	defer response.Body.Close()
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response to record for %v: %w", uri, err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(b))
+	rr := recordedResponse{
+		URI:          uri,
+		ContentType:  response.Header.Get("Content-Type"),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Body:         string(b),
+	}
+	if err := c.write(uri, rr); err != nil {
+		return nil, fmt.Errorf("recording response for %v: %w", uri, err)
+	}
+	return response, nil
+}
+
+// replayResponse creates a response from the one recorded for the uri.
+func (c ReplayClient) replayResponse(r *http.Request, uri string) (*http.Response, error) {
+	b, err := os.ReadFile(c.path(uri))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("no recorded response for %v", uri)
+	case err != nil:
+		return nil, fmt.Errorf("reading recorded response for %v: %w", uri, err)
+	}
+	var rr recordedResponse
+	if err := json.Unmarshal(b, &rr); err != nil {
+		return nil, fmt.Errorf("decoding recorded response for %v: %w", uri, err)
+	}
+	header := make(http.Header)
+	for key, value := range map[string]string{
+		"Content-Type":  rr.ContentType,
+		"ETag":          rr.ETag,
+		"Last-Modified": rr.LastModified,
+	} {
+		if len(value) != 0 {
+			header.Set(key, value)
+		}
+	}
+	response := http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rr.Body))),
+		ContentLength: int64(len(rr.Body)),
+		Request:       r,
+	}
+	return &response, nil
+}
+
+// write saves the recorded response to its file.
+// It is written to a temporary file first so concurrent requests for the uri never replay a partially written response.
+func (c ReplayClient) write(uri string, rr recordedResponse) error {
+	b, err := json.MarshalIndent(rr, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, "record-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(uri))
+}
+
+// path is the file the response for the uri is recorded in.
+// Uris contain characters that cannot be in file names, so they are hashed.
+func (c ReplayClient) path(uri string) string {
+	hash := sha256.Sum256([]byte(uri))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
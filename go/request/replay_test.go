@@ -0,0 +1,166 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewReplayClient(t *testing.T) {
+	newReplayClientTests := []struct {
+		httpClient HTTPClient
+		dir        string
+		record     bool
+		wantErr    bool
+	}{
+		{wantErr: true}, // no dir
+		{dir: t.TempDir()},
+		{dir: t.TempDir(), record: true, wantErr: true}, // no httpClient to record with
+		{httpClient: mockHTTPClient{}, dir: filepath.Join(t.TempDir(), "new"), record: true},
+	}
+	for i, test := range newReplayClientTests {
+		_, err := NewReplayClient(test.httpClient, test.dir, test.record)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestReplayClientDo_recordReplay(t *testing.T) {
+	dir := t.TempDir()
+	requestCount := 0
+	httpClient := mockHTTPClient{
+		DoFunc: func(r *http.Request) (*http.Response, error) {
+			requestCount++
+			statusCode := http.StatusOK
+			if strings.Contains(r.URL.Path, "missing") {
+				statusCode = http.StatusNotFound
+			}
+			response := http.Response{
+				StatusCode: statusCode,
+				Header:     http.Header{"Etag": {`"v1"`}},
+				Body:       io.NopCloser(strings.NewReader(`{"players":[]}`)),
+			}
+			return &response, nil
+		},
+	}
+	recordClient, err := NewReplayClient(httpClient, dir, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating record client: %v", err)
+	}
+	uri := "https://api.fantasy.nfl.com/v2/players/autocomplete?query=tom&appKey=secret_key"
+	doRequest := func(c HTTPClient, uri string) (string, error) {
+		r, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := c.Do(r)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+		b, err := io.ReadAll(response.Body)
+		return string(b), err
+	}
+	if got, err := doRequest(recordClient, uri); err != nil || got != `{"players":[]}` {
+		t.Errorf("wanted recorded response to be returned, got %v, %v", got, err)
+	}
+	if _, err := doRequest(recordClient, "https://api.fantasy.nfl.com/v2/missing?appKey=secret_key"); err != nil {
+		t.Errorf("unexpected error for response that is not ok: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("wanted only ok response to be recorded, got %v files", len(entries))
+	}
+	b, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case strings.Contains(string(b), "secret_key"):
+		t.Errorf("wanted app key to be scrubbed from recorded response, got %s", b)
+	}
+
+	replayClient, err := NewReplayClient(nil, dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating replay client: %v", err)
+	}
+	for _, replayURI := range []string{uri, "https://api.fantasy.nfl.com/v2/players/autocomplete?appKey=other_key&query=tom"} {
+		got, err := doRequest(replayClient, replayURI)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error replaying %v: %v", replayURI, err)
+		case got != `{"players":[]}`:
+			t.Errorf("wanted recorded response to be replayed for %v, got %v", replayURI, got)
+		}
+	}
+	if want := 2; want != requestCount {
+		t.Errorf("wanted %v requests to be made only when recording, got %v", want, requestCount)
+	}
+	if _, err := doRequest(replayClient, "https://api.fantasy.nfl.com/v2/players/autocomplete?query=peyton"); err == nil {
+		t.Error("wanted error replaying response that was not recorded")
+	}
+}
+
+func TestReplayClientDo_invalidRecording(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewReplayClient(nil, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "http://statsapi.mlb.com/api/v1/teams"
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(scrubURI(u)), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Do(r); err == nil {
+		t.Error("wanted error replaying invalid recording")
+	}
+}
+
+func TestScrubURI(t *testing.T) {
+	scrubURITests := []struct {
+		uri  string
+		want string
+	}{
+		{
+			uri:  "http://statsapi.mlb.com/api/v1/teams",
+			want: "http://statsapi.mlb.com/api/v1/teams",
+		},
+		{
+			uri:  "https://api.fantasy.nfl.com/v2/game/stats?appKey=test_key_1",
+			want: "https://api.fantasy.nfl.com/v2/game/stats",
+		},
+		{
+			uri:  "https://api.fantasy.nfl.com/v2/players/stats?statType=seasonStats&season=2019&appKey=test_key_1#top",
+			want: "https://api.fantasy.nfl.com/v2/players/stats?season=2019&statType=seasonStats",
+		},
+	}
+	for i, test := range scrubURITests {
+		u, err := url.Parse(test.uri)
+		if err != nil {
+			t.Fatalf("Test %v: %v", i, err)
+		}
+		if got := scrubURI(u); test.want != got {
+			t.Errorf("Test %v: wanted %v, got %v", i, test.want, got)
+		}
+	}
+}
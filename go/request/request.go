@@ -2,18 +2,22 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
 
 type (
 	requester interface {
-		structPointerFromURI(uri string, v interface{}) error
+		structPointerFromURI(ctx context.Context, uri string, v interface{}) error
 	}
 
 	// HTTPClient makes HTTP requests
@@ -21,32 +25,101 @@ type (
 		Do(r *http.Request) (*http.Response, error)
 	}
 
+	// BaseURLs are the schemes and hosts of the external sources for data, without trailing slashes.
+	BaseURLs struct {
+		MlbStats   string
+		MlbLookup  string
+		NflFantasy string
+		EspnSite   string
+		EspnCore   string
+		Github     string
+	}
+
 	httpRequester struct {
-		cache          Cache
+		cache          *Cache
 		httpClient     HTTPClient
 		logRequestURIs bool
 		log            *log.Logger
+		retryConfig    RetryConfig
+		breakers       *circuitBreakers
+		limiters       *hostLimiters
+		sleep          func(ctx context.Context, d time.Duration) error
+	}
+
+	// httpResponse is the body of a response and the validators to conditionally request it again.
+	httpResponse struct {
+		body         []byte
+		etag         string
+		lastModified string
+		notModified  bool
 	}
 )
 
+// NewBaseURLs creates the BaseURLs of the external sources for data.
+// If the upstream url is not empty, all data is requested from it instead, such as from a mock server.
+func NewBaseURLs(upstreamURL string) BaseURLs {
+	if len(upstreamURL) != 0 {
+		upstreamURL = strings.TrimSuffix(upstreamURL, "/")
+		return BaseURLs{
+			MlbStats:   upstreamURL,
+			MlbLookup:  upstreamURL,
+			NflFantasy: upstreamURL,
+			EspnSite:   upstreamURL,
+			EspnCore:   upstreamURL,
+			Github:     upstreamURL,
+		}
+	}
+	return BaseURLs{
+		MlbStats:   "http://statsapi.mlb.com",
+		MlbLookup:  "http://lookup-service-prod.mlb.com",
+		NflFantasy: "https://api.fantasy.nfl.com",
+		EspnSite:   "https://site.api.espn.com",
+		EspnCore:   "https://sports.core.api.espn.com",
+		Github:     "https://api.github.com",
+	}
+}
+
 // NewRequesters creates new ScoreCategorizers and Searchers for the specified PlayerTypes and an aboutRequester
-func NewRequesters(httpClient HTTPClient, c Cache, nflAppKey, environment string, logRequestURIs bool, log *log.Logger) (map[db.PlayerType]ScoreCategorizer, map[db.PlayerType]Searcher, AboutRequester) {
+// Responses are shared in the Cache.  Failed requests are retried according to the RetryConfig.  Requests to each host from all requesters share the HostLimits.
+// Data is requested from the hosts of the BaseURLs.  Nfl data is requested from the first of the named nfl providers that succeeds.
+func NewRequesters(httpClient HTTPClient, c *Cache, nflAppKey string, nflProviderNames []string, environment string, logRequestURIs bool, retryConfig RetryConfig, hostLimits HostLimits, baseURLs BaseURLs, log *log.Logger) (map[db.PlayerType]ScoreCategorizer, map[db.PlayerType]Searcher, AboutRequester) {
 	r := httpRequester{
 		cache:          c,
 		httpClient:     httpClient,
 		logRequestURIs: logRequestURIs,
 		log:            log,
+		retryConfig:    retryConfig,
+		breakers:       newCircuitBreakers(),
+		limiters:       newHostLimiters(hostLimits),
+		sleep:          sleep,
 	}
 	nflR := nflRequester{
 		appKey:    nflAppKey,
+		baseURL:   baseURLs.NflFantasy,
 		requester: &r,
 	}
 
-	mlbTeamR := mlbTeamRequester{requester: &r}
-	mlbPlayerR := mlbPlayerRequester{requester: &r}
-	mlbPlayerS := mlbPlayerSearcher{requester: &r}
-	nflTeamR := nflTeamRequester{requester: &nflR}
-	nflPlayerR := nflPlayerRequester{requester: &nflR}
+	mlbP := statsMlbProvider{requester: &r, baseURL: baseURLs.MlbStats}
+	mlbTeamR := mlbTeamRequester{provider: mlbP}
+	mlbPlayerR := mlbPlayerRequester{provider: mlbP}
+	mlbPeopleS := mlbPeopleSearcher{requester: &r, baseURL: baseURLs.MlbStats}
+	mlbPlayerS := mlbPlayerSearcher{requester: &r, baseURL: baseURLs.MlbLookup}
+	mlbPlayerFuzzyS := fuzzySearcher{fallbackSearcher{&mlbPeopleS, &mlbPlayerS}}
+	nflPs := make(nflProviders, 0, len(nflProviderNames))
+	for _, name := range nflProviderNames {
+		var p NflProvider
+		switch name {
+		case NflProviderFantasy:
+			p = fantasyNflProvider{requester: &nflR}
+		case NflProviderEspn:
+			p = espnNflProvider{requester: &r, siteURL: baseURLs.EspnSite, coreURL: baseURLs.EspnCore}
+		default:
+			continue
+		}
+		nflPs = append(nflPs, namedNflProvider{name: name, log: log, NflProvider: p})
+	}
+	nflTeamR := nflTeamRequester{providers: nflPs}
+	nflPlayerR := nflPlayerRequester{providers: nflPs}
 
 	scoreCategorizers := make(map[db.PlayerType]ScoreCategorizer)
 	scoreCategorizers[db.PlayerTypeMlbTeam] = &mlbTeamR
@@ -58,55 +131,159 @@ func NewRequesters(httpClient HTTPClient, c Cache, nflAppKey, environment string
 
 	searchers := make(map[db.PlayerType]Searcher)
 	searchers[db.PlayerTypeMlbTeam] = &mlbTeamR
-	searchers[db.PlayerTypeMlbHitter] = &mlbPlayerS
-	searchers[db.PlayerTypeMlbPitcher] = &mlbPlayerS
+	searchers[db.PlayerTypeMlbHitter] = mlbPlayerFuzzyS
+	searchers[db.PlayerTypeMlbPitcher] = mlbPlayerFuzzyS
 	searchers[db.PlayerTypeNflTeam] = &nflTeamR
-	searchers[db.PlayerTypeNflQB] = &nflPlayerR
-	searchers[db.PlayerTypeNflMisc] = &nflPlayerR
+	searchers[db.PlayerTypeNflQB] = fuzzySearcher{&nflPlayerR}
+	searchers[db.PlayerTypeNflMisc] = fuzzySearcher{&nflPlayerR}
 
-	aboutRequester := AboutRequester{environment: environment, requester: &r}
+	aboutRequester := AboutRequester{environment: environment, requester: &r, baseURL: baseURLs.Github}
 
 	return scoreCategorizers, searchers, aboutRequester
 }
 
-func (r *httpRequester) structPointerFromURI(uri string, v interface{}) error {
-	b, ok := r.cache.get(uri)
-	if !ok {
-		var err error
-		b, err = r.bytes(uri)
-		if err != nil {
-			return err
-		}
-		r.cache.add(uri, b)
+func (r *httpRequester) structPointerFromURI(ctx context.Context, uri string, v interface{}) error {
+	b, err := r.cachedBytes(ctx, uri)
+	if err != nil {
+		return err
 	}
-	err := json.Unmarshal(b, v)
+	err = json.Unmarshal(b, v)
 	if err != nil {
 		return fmt.Errorf("reading json when requesting %v: %w", uri, err)
 	}
 	return nil
 }
 
-func (r *httpRequester) bytes(uri string) ([]byte, error) {
+// cachedBytes returns the cached response of the uri if it is fresh.
+// Otherwise, the uri is requested, conditionally if the expired cached response has an ETag or Last-Modified time.
+func (r *httpRequester) cachedBytes(ctx context.Context, uri string) ([]byte, error) {
+	e, fresh := r.cache.get(uri)
+	if fresh {
+		return e.Body, nil
+	}
+	response, err := r.fetch(ctx, uri, e.conditionalHeader())
+	switch {
+	case err != nil:
+		return nil, err
+	case response.notModified:
+		if r.logRequestURIs {
+			r.log.Printf("cached response of %v was not modified", uri)
+		}
+		r.cache.revalidate(uri)
+		return e.Body, nil
+	}
+	r.cache.add(uri, cacheEntry{
+		Body:         response.body,
+		ETag:         response.etag,
+		LastModified: response.lastModified,
+	})
+	return response.body, nil
+}
+
+// fetch requests the uri with the header, retrying failures that might be temporary.
+// Retries stop when the context is done.
+func (r *httpRequester) fetch(ctx context.Context, uri string, header http.Header) (httpResponse, error) {
 	if r.logRequestURIs {
 		r.log.Printf("%T : requesting %v", r.httpClient, uri)
 	}
-	request, err := http.NewRequest("GET", uri, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
-		return nil, fmt.Errorf("initializing request to %v: %w", uri, err)
+		return httpResponse{}, fmt.Errorf("initializing request to %v: %w", uri, err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
 	}
 	request.Header.Add("Accept", "application/json")
-	response, err := r.httpClient.Do(request)
+	for retry := 1; ; retry++ {
+		response, err := r.attempt(request)
+		var re retryableError
+		if err == nil || !errors.As(err, &re) || retry > r.retryConfig.Retries || ctx.Err() != nil {
+			return response, err
+		}
+		wait := r.retryConfig.backoff(retry)
+		if re.retryAfter > 0 {
+			if re.retryAfter > r.retryConfig.MaxBackoff {
+				if r.logRequestURIs {
+					r.log.Printf("not retrying %v: server requested retry after %v", uri, re.retryAfter)
+				}
+				return httpResponse{}, err
+			}
+			wait = re.retryAfter
+		}
+		if r.logRequestURIs {
+			r.log.Printf("retrying %v in %v (retry %v of %v): %v", uri, wait, retry, r.retryConfig.Retries, err)
+		}
+		if err := r.sleep(ctx, wait); err != nil {
+			return httpResponse{}, fmt.Errorf("waiting to retry %v: %w", uri, err)
+		}
+	}
+}
+
+// attempt makes the request if the circuit breaker for its host allows it, waiting for the limits of the host.
+func (r *httpRequester) attempt(request *http.Request) (httpResponse, error) {
+	uri := request.URL.String()
+	host := request.URL.Host
+	if err := r.breakers.allow(host, time.Now()); err != nil {
+		if r.logRequestURIs {
+			r.log.Printf("not requesting %v: %v", uri, err)
+		}
+		return httpResponse{}, err
+	}
+	done, err := r.limiters.wait(request.Context(), host)
 	if err != nil {
-		return nil, fmt.Errorf("requesting %v: %w", uri, err)
+		// the request was canceled before it was made, so the circuit is left as it was
+		r.breakers.release(host)
+		return httpResponse{}, fmt.Errorf("waiting to request %v: %w", uri, err)
+	}
+	response, responded, err := r.do(request)
+	done()
+	if request.Context().Err() != nil {
+		// requests that are canceled are not results of the host, so the circuit is left as it was
+		r.breakers.release(host)
+		return response, err
 	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("expected ok response, got %v", response.StatusCode)
+	var re retryableError
+	failed := !responded || errors.As(err, &re)
+	if r.breakers.record(host, failed, time.Now(), r.retryConfig) && r.logRequestURIs {
+		r.log.Printf("pausing requests to %v for %v: %v", host, r.retryConfig.BreakerCooldown, err)
 	}
+	return response, err
+}
 
+// do makes the request.  Responses that were not modified since the validators in the request header do not have bodies.
+// The returned bool is true if the host responded to the request, even if the response is an error.
+func (r *httpRequester) do(request *http.Request) (httpResponse, bool, error) {
+	uri := request.URL.String()
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return httpResponse{}, false, checkDoError(fmt.Errorf("requesting %v: %w", uri, err))
+	}
 	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotModified {
+		return httpResponse{notModified: true}, true, nil
+	}
+	if err := checkResponse(response, time.Now()); err != nil {
+		return httpResponse{}, true, err
+	}
 	b, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading body of %v: %w", uri, err)
+		return httpResponse{}, true, fmt.Errorf("reading body of %v: %w", uri, err)
+	}
+	return httpResponse{
+		body:         b,
+		etag:         response.Header.Get("ETag"),
+		lastModified: response.Header.Get("Last-Modified"),
+	}, true, nil
+}
+
+// sleep waits for the duration or until the context is done, returning the error of the context if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
-	return b, nil
 }
@@ -2,6 +2,7 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -24,7 +25,7 @@ type (
 	}
 )
 
-func (r *mockRequester) structPointerFromURI(uri string, v interface{}) error {
+func (r *mockRequester) structPointerFromURI(ctx context.Context, uri string, v interface{}) error {
 	return r.structPointerFromURIFunc(uri, v)
 }
 
@@ -46,7 +47,7 @@ func newMockHTTPRequester(jsonFunc func(uriPath string) string) requester {
 		DoFunc: do,
 	}
 	return &httpRequester{
-		cache:      NewCache(0), // (do not cache)
+		cache:      NewCache(CacheConfig{}), // (do not cache)
 		httpClient: client,
 		// logRequestUris: true,
 	}
@@ -78,7 +79,7 @@ func TestStructPointerFromUri(t *testing.T) {
 		}
 		r := newMockHTTPRequester(jsonFunc)
 		var got interface{}
-		err := r.structPointerFromURI(test.uri, &got)
+		err := r.structPointerFromURI(context.Background(), test.uri, &got)
 		switch {
 		case test.wantError:
 			if err == nil {
@@ -95,7 +96,7 @@ func TestHttpRequesterLogRequestUrl(t *testing.T) {
 		buffer := bytes.NewBufferString("")
 		log := log.New(buffer, "test", log.LstdFlags)
 		r := httpRequester{
-			cache: NewCache(0), // (do not cache)
+			cache: NewCache(CacheConfig{}), // (do not cache)
 			httpClient: mockHTTPClient{
 				DoFunc: func(r *http.Request) (*http.Response, error) {
 					return nil, fmt.Errorf("request error")
@@ -105,7 +106,7 @@ func TestHttpRequesterLogRequestUrl(t *testing.T) {
 			log:            log,
 		}
 		uri := "TEST_URI"
-		r.bytes(uri)
+		r.fetch(context.Background(), uri, nil)
 		logText := buffer.String()
 		if test && !strings.Contains(logText, uri) {
 			t.Errorf("expected uri to be written to log, but was not; got: %v", logText)
@@ -119,7 +120,7 @@ func TestHttpRequesterLogRequestUrl(t *testing.T) {
 func TestStructPointerFromUri_requesterError(t *testing.T) {
 	doErr := errors.New("Do error")
 	r := httpRequester{
-		cache: NewCache(0), // (do not cache)
+		cache: NewCache(CacheConfig{}), // (do not cache)
 		httpClient: mockHTTPClient{
 			DoFunc: func(r *http.Request) (*http.Response, error) {
 				return nil, doErr
@@ -127,7 +128,7 @@ func TestStructPointerFromUri_requesterError(t *testing.T) {
 		},
 	}
 	var got interface{}
-	err := r.structPointerFromURI("uri", &got)
+	err := r.structPointerFromURI(context.Background(), "uri", &got)
 	if err == nil || !errors.Is(err, doErr) {
 		t.Errorf("expected request to fail, but did not or got wrong error: %v", err)
 	}
@@ -151,7 +152,7 @@ func (m mockReadCloser) Close() error {
 
 func TestStructPointerFromUri_okRequest(t *testing.T) {
 	r := httpRequester{
-		cache: NewCache(0), // (do not cache)
+		cache: NewCache(CacheConfig{}), // (do not cache)
 		httpClient: mockHTTPClient{
 			DoFunc: func(r *http.Request) (*http.Response, error) {
 				response := http.Response{
@@ -174,7 +175,7 @@ func TestStructPointerFromUri_okRequest(t *testing.T) {
 	}
 	want := 7
 	var got int
-	err := r.structPointerFromURI("uri", &got)
+	err := r.structPointerFromURI(context.Background(), "uri", &got)
 	switch {
 	case err != nil:
 		t.Errorf("unexpected error: %v", err)
@@ -185,7 +186,7 @@ func TestStructPointerFromUri_okRequest(t *testing.T) {
 
 func TestStructPointerFromUri_badRequest(t *testing.T) {
 	r := httpRequester{
-		cache: NewCache(0), // (do not cache)
+		cache: NewCache(CacheConfig{}), // (do not cache)
 		httpClient: mockHTTPClient{
 			DoFunc: func(r *http.Request) (*http.Response, error) {
 				response := http.Response{
@@ -200,7 +201,7 @@ func TestStructPointerFromUri_badRequest(t *testing.T) {
 			},
 		},
 	}
-	err := r.structPointerFromURI("uri", nil)
+	err := r.structPointerFromURI(context.Background(), "uri", nil)
 	if err == nil {
 		t.Errorf("expected error because the request is bad")
 	}
@@ -209,7 +210,7 @@ func TestStructPointerFromUri_badRequest(t *testing.T) {
 func TestStructPointerFromUri_readBytesError(t *testing.T) {
 	readErr := errors.New("read error")
 	r := httpRequester{
-		cache: NewCache(0), // (do not cache)
+		cache: NewCache(CacheConfig{}), // (do not cache)
 		httpClient: mockHTTPClient{
 			DoFunc: func(r *http.Request) (*http.Response, error) {
 				response := http.Response{
@@ -221,14 +222,14 @@ func TestStructPointerFromUri_readBytesError(t *testing.T) {
 		},
 	}
 	var got interface{}
-	err := r.structPointerFromURI("uri", &got)
+	err := r.structPointerFromURI(context.Background(), "uri", &got)
 	if err == nil || !errors.Is(err, readErr) {
 		t.Errorf("expected request to fail, but did not or got wrong error: %v", err)
 	}
 }
 
 func TestNewRequesters(t *testing.T) {
-	c := NewCache(0)
+	c := NewCache(CacheConfig{})
 	logRequestURIs := false
 	log := log.New(io.Discard, "test", log.LstdFlags)
 	httpClient := mockHTTPClient{
@@ -236,7 +237,7 @@ func TestNewRequesters(t *testing.T) {
 			return nil, nil
 		},
 	}
-	scoreCategorizers, searchers, aboutRequester := NewRequesters(httpClient, c, "dummyNflAppKey", "environmentName", logRequestURIs, log)
+	scoreCategorizers, searchers, aboutRequester := NewRequesters(httpClient, c, "dummyNflAppKey", defaultNflProviders, "environmentName", logRequestURIs, NewRetryConfig(0), NewHostLimits(0, 0), NewBaseURLs(""), log)
 	wantPlayerTypes := db.PlayerTypeMap{1: {}, 2: {}, 3: {}, 4: {}, 5: {}, 6: {}}
 	if len(wantPlayerTypes) != len(scoreCategorizers) {
 		t.Errorf("expected %v scoreCategorizers, but got %v", len(wantPlayerTypes), len(scoreCategorizers))
@@ -255,4 +256,24 @@ func TestNewRequesters(t *testing.T) {
 	if aboutRequester.environment != "environmentName" {
 		t.Errorf("environment not set for aboutRequester")
 	}
+	if aboutRequester.baseURL != "https://api.github.com" {
+		t.Errorf("baseURL not set for aboutRequester")
+	}
+}
+
+func TestNewBaseURLs(t *testing.T) {
+	want := BaseURLs{
+		MlbStats:   "http://localhost:8001",
+		MlbLookup:  "http://localhost:8001",
+		NflFantasy: "http://localhost:8001",
+		EspnSite:   "http://localhost:8001",
+		EspnCore:   "http://localhost:8001",
+		Github:     "http://localhost:8001",
+	}
+	if got := NewBaseURLs("http://localhost:8001/"); want != got {
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+	if got := NewBaseURLs(""); got.MlbStats != "http://statsapi.mlb.com" {
+		t.Errorf("wanted default base url for mlb stats, got %v", got.MlbStats)
+	}
 }
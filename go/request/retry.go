@@ -0,0 +1,186 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// RetryConfig describes how failed requests to external sources are retried.
+	RetryConfig struct {
+		// Retries is the number of times a request is retried after a server error, too many requests, or timeout.
+		Retries int
+		// Backoff is the longest wait before the first retry.  It doubles for each retry.  The actual wait is randomly jittered.
+		Backoff time.Duration
+		// MaxBackoff is the longest wait before any retry, including waits requested by Retry-After headers.
+		MaxBackoff time.Duration
+		// BreakerThreshold is the number of consecutive failed requests to a host after which requests to it fail immediately.
+		BreakerThreshold int
+		// BreakerCooldown is how long requests to a host fail immediately before a trial request is allowed.
+		BreakerCooldown time.Duration
+	}
+
+	// circuitBreakers track failed requests to hosts to stop requesting hosts that are down.
+	circuitBreakers struct {
+		mu       sync.Mutex
+		breakers map[string]*circuitBreaker
+	}
+
+	circuitBreaker struct {
+		failures  int
+		openUntil time.Time
+		trial     bool
+	}
+
+	// retryableError is an error for a request that might succeed if it is retried.
+	retryableError struct {
+		err        error
+		retryAfter time.Duration
+	}
+)
+
+// errCircuitOpen is returned for requests to hosts that have failed too often recently.
+var errCircuitOpen = errors.New("circuit open")
+
+// NewRetryConfig creates a RetryConfig with the number of retries and default waits.
+func NewRetryConfig(retries int) RetryConfig {
+	return RetryConfig{
+		Retries:          retries,
+		Backoff:          500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// allow determines if a request to the host can be made.  A nil circuitBreakers allows all requests.
+// After the cooldown of an open circuit, only one trial request is allowed until it succeeds or fails.
+func (cbs *circuitBreakers) allow(host string, now time.Time) error {
+	if cbs == nil {
+		return nil
+	}
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	cb, ok := cbs.breakers[host]
+	switch {
+	case !ok, cb.openUntil.IsZero():
+		return nil
+	case now.Before(cb.openUntil), cb.trial:
+		return fmt.Errorf("requests to %v paused after %v failures: %w", host, cb.failures, errCircuitOpen)
+	}
+	cb.trial = true
+	return nil
+}
+
+// release ends a trial request to the host that has no result, such as one that was canceled.
+// The circuit stays open so another trial request is allowed.
+func (cbs *circuitBreakers) release(host string) {
+	if cbs == nil {
+		return
+	}
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	if cb, ok := cbs.breakers[host]; ok {
+		cb.trial = false
+	}
+}
+
+// record updates the circuit for the host with the result of a request to it.
+// Failures open the circuit for the cooldown once the threshold is reached.  Successes close it.
+func (cbs *circuitBreakers) record(host string, failed bool, now time.Time, rc RetryConfig) (opened bool) {
+	if cbs == nil {
+		return false
+	}
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	if !failed {
+		delete(cbs.breakers, host)
+		return false
+	}
+	cb, ok := cbs.breakers[host]
+	if !ok {
+		cb = new(circuitBreaker)
+		cbs.breakers[host] = cb
+	}
+	cb.failures++
+	cb.trial = false
+	if rc.BreakerThreshold <= 0 || cb.failures < rc.BreakerThreshold {
+		return false
+	}
+	cb.openUntil = now.Add(rc.BreakerCooldown)
+	return true
+}
+
+func (err retryableError) Error() string {
+	return err.err.Error()
+}
+
+func (err retryableError) Unwrap() error {
+	return err.err
+}
+
+// checkResponse returns a retryableError for responses with server errors or too many requests and an error for other responses that are not ok.
+func checkResponse(response *http.Response, now time.Time) error {
+	switch {
+	case response.StatusCode == http.StatusOK:
+		return nil
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= 500:
+		return retryableError{
+			err:        fmt.Errorf("expected ok response, got %v", response.StatusCode),
+			retryAfter: retryAfter(response.Header.Get("Retry-After"), now),
+		}
+	}
+	return fmt.Errorf("expected ok response, got %v", response.StatusCode)
+}
+
+// checkDoError returns a retryableError if the error is from a request that timed out.
+func checkDoError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return retryableError{err: err}
+	}
+	return err
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or an http date.
+func retryAfter(header string, now time.Time) time.Duration {
+	if len(header) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// backoff returns how long to wait before the retry, which starts at one.
+// The wait is randomly chosen between half of and the full exponential backoff so retries from many requests are spread out.
+func (rc RetryConfig) backoff(retry int) time.Duration {
+	d := rc.Backoff
+	for i := 1; i < retry && d < rc.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > rc.MaxBackoff {
+		d = rc.MaxBackoff
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
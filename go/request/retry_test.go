@@ -0,0 +1,480 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	rc := RetryConfig{
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	}
+	start := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	cbs := newCircuitBreakers()
+	circuitBreakersTests := []struct {
+		host       string
+		now        time.Time
+		wantAllow  bool
+		failed     bool
+		wantOpened bool
+	}{
+		{host: "a", now: start, wantAllow: true, failed: true},
+		{host: "a", now: start, wantAllow: true, failed: true, wantOpened: true},
+		{host: "a", now: start.Add(30 * time.Second)},
+		{host: "b", now: start.Add(30 * time.Second), wantAllow: true, failed: true},              // other hosts are not affected
+		{host: "a", now: start.Add(time.Minute), wantAllow: true, failed: true, wantOpened: true}, // trial request fails
+		{host: "a", now: start.Add(90 * time.Second)},
+		{host: "a", now: start.Add(2 * time.Minute), wantAllow: true}, // trial request succeeds
+		{host: "a", now: start.Add(2 * time.Minute), wantAllow: true, failed: true},
+	}
+	for i, test := range circuitBreakersTests {
+		err := cbs.allow(test.host, test.now)
+		gotAllow := err == nil
+		switch {
+		case test.wantAllow != gotAllow:
+			t.Errorf("Test %v: wanted request allowed: %v, got error: %v", i, test.wantAllow, err)
+		case !gotAllow:
+			if !errors.Is(err, errCircuitOpen) {
+				t.Errorf("Test %v: wanted error to be %v, got %v", i, errCircuitOpen, err)
+			}
+		default:
+			if gotOpened := cbs.record(test.host, test.failed, test.now, rc); test.wantOpened != gotOpened {
+				t.Errorf("Test %v: wanted circuit opened: %v, got %v", i, test.wantOpened, gotOpened)
+			}
+		}
+	}
+}
+
+func TestCircuitBreakers_trialRequest(t *testing.T) {
+	rc := RetryConfig{
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+	}
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	cbs := newCircuitBreakers()
+	cbs.record("a", true, now, rc)
+	now = now.Add(time.Minute)
+	if err := cbs.allow("a", now); err != nil {
+		t.Errorf("wanted trial request after cooldown, got %v", err)
+	}
+	if err := cbs.allow("a", now); err == nil {
+		t.Errorf("wanted only one trial request while trial request is running")
+	}
+	cbs.release("a")
+	if err := cbs.allow("a", now); err != nil {
+		t.Errorf("wanted another trial request after trial request was released, got %v", err)
+	}
+	if err := cbs.allow("a", now); err == nil {
+		t.Errorf("wanted circuit to stay open after trial request was released")
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	now := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	checkResponseTests := []struct {
+		statusCode     int
+		retryAfter     string
+		wantErr        bool
+		wantRetryable  bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			statusCode: 200,
+		},
+		{
+			statusCode: 404,
+			wantErr:    true,
+		},
+		{
+			statusCode:    500,
+			wantErr:       true,
+			wantRetryable: true,
+		},
+		{
+			statusCode:     503,
+			retryAfter:     "120",
+			wantErr:        true,
+			wantRetryable:  true,
+			wantRetryAfter: 2 * time.Minute,
+		},
+		{
+			statusCode:     429,
+			retryAfter:     "Wed, 21 Aug 2019 10:00:30 GMT",
+			wantErr:        true,
+			wantRetryable:  true,
+			wantRetryAfter: 30 * time.Second,
+		},
+		{
+			statusCode:    429,
+			retryAfter:    "soon",
+			wantErr:       true,
+			wantRetryable: true,
+		},
+	}
+	for i, test := range checkResponseTests {
+		response := http.Response{
+			StatusCode: test.statusCode,
+			Header:     http.Header{},
+		}
+		if len(test.retryAfter) != 0 {
+			response.Header.Set("Retry-After", test.retryAfter)
+		}
+		err := checkResponse(&response, now)
+		var re retryableError
+		gotRetryable := errors.As(err, &re)
+		switch {
+		case test.wantErr != (err != nil):
+			t.Errorf("Test %v: wanted error: %v, got %v", i, test.wantErr, err)
+		case test.wantRetryable != gotRetryable:
+			t.Errorf("Test %v: wanted retryable error: %v, got %v", i, test.wantRetryable, err)
+		case test.wantRetryAfter != re.retryAfter:
+			t.Errorf("Test %v: wanted retry after %v, got %v", i, test.wantRetryAfter, re.retryAfter)
+		}
+	}
+}
+
+func TestCheckDoError(t *testing.T) {
+	checkDoErrorTests := []struct {
+		err           error
+		wantRetryable bool
+	}{
+		{
+			err: errors.New("connection refused"),
+		},
+		{
+			err:           fmt.Errorf("requesting uri: %w", context.DeadlineExceeded),
+			wantRetryable: true,
+		},
+	}
+	for i, test := range checkDoErrorTests {
+		err := checkDoError(test.err)
+		var re retryableError
+		switch {
+		case test.wantRetryable != errors.As(err, &re):
+			t.Errorf("Test %v: wanted retryable error: %v, got %v", i, test.wantRetryable, err)
+		case !errors.Is(err, test.err):
+			t.Errorf("Test %v: wanted error to wrap %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	rc := RetryConfig{
+		Backoff:    time.Second,
+		MaxBackoff: 5 * time.Second,
+	}
+	retryConfigBackoffTests := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{retry: 1, max: time.Second},
+		{retry: 2, max: 2 * time.Second},
+		{retry: 3, max: 4 * time.Second},
+		{retry: 4, max: 5 * time.Second},
+		{retry: 40, max: 5 * time.Second},
+	}
+	for i, test := range retryConfigBackoffTests {
+		for j := 0; j < 10; j++ {
+			if got := rc.backoff(test.retry); got < test.max/2 || got > test.max {
+				t.Errorf("Test %v: wanted backoff for retry %v between %v and %v, got %v", i, test.retry, test.max/2, test.max, got)
+			}
+		}
+	}
+}
+
+func TestHTTPRequesterBytes_retries(t *testing.T) {
+	httpRequesterBytesRetriesTests := []struct {
+		statusCodes  []int
+		retryAfter   string
+		retries      int
+		wantErr      bool
+		wantRequests int
+		wantWaits    []time.Duration
+	}{
+		{ // retries disabled
+			statusCodes:  []int{503, 200},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			statusCodes:  []int{503, 500, 200},
+			retries:      2,
+			wantRequests: 3,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			statusCodes:  []int{503, 503, 503, 503},
+			retries:      2,
+			wantErr:      true,
+			wantRequests: 3,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{ // client errors are not retried
+			statusCodes:  []int{404, 200},
+			retries:      2,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			statusCodes:  []int{429, 200},
+			retryAfter:   "3",
+			retries:      2,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{3 * time.Second},
+		},
+		{ // retry after is longer than max backoff
+			statusCodes:  []int{429, 200},
+			retryAfter:   "3600",
+			retries:      2,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+	for i, test := range httpRequesterBytesRetriesTests {
+		requests := 0
+		var waits []time.Duration
+		var logBuf bytes.Buffer
+		r := httpRequester{
+			cache: NewCache(CacheConfig{}),
+			httpClient: mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					response := http.Response{
+						StatusCode: test.statusCodes[requests],
+						Header:     http.Header{},
+						Body:       io.NopCloser(strings.NewReader("7")),
+					}
+					if len(test.retryAfter) != 0 {
+						response.Header.Set("Retry-After", test.retryAfter)
+					}
+					requests++
+					return &response, nil
+				},
+			},
+			logRequestURIs: true,
+			log:            log.New(&logBuf, "test", log.LstdFlags),
+			retryConfig: RetryConfig{
+				Retries:    test.retries,
+				Backoff:    time.Second,
+				MaxBackoff: 10 * time.Second,
+			},
+			breakers: newCircuitBreakers(),
+			sleep: func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			},
+		}
+		_, err := r.fetch(context.Background(), "http://example.com/stats", nil)
+		switch {
+		case test.wantErr != (err != nil):
+			t.Errorf("Test %v: wanted error: %v, got %v", i, test.wantErr, err)
+		case test.wantRequests != requests:
+			t.Errorf("Test %v: wanted %v requests, got %v", i, test.wantRequests, requests)
+		case len(test.wantWaits) != len(waits):
+			t.Errorf("Test %v: wanted waits %v, got %v", i, test.wantWaits, waits)
+		case len(waits) != 0 && !strings.Contains(logBuf.String(), "retrying http://example.com/stats"):
+			t.Errorf("Test %v: wanted retries to be logged, got %v", i, logBuf.String())
+		}
+		for j := range waits {
+			if j < len(test.wantWaits) && (waits[j] < test.wantWaits[j]/2 || waits[j] > test.wantWaits[j]) {
+				t.Errorf("Test %v: wanted wait %v to be at most %v, got %v", i, j, test.wantWaits[j], waits[j])
+			}
+		}
+	}
+}
+
+func TestHTTPRequesterBytes_circuitBreaker(t *testing.T) {
+	requests := 0
+	r := httpRequester{
+		cache: NewCache(CacheConfig{}),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				requests++
+				response := http.Response{
+					StatusCode: http.StatusBadGateway,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+				return &response, nil
+			},
+		},
+		retryConfig: RetryConfig{
+			Retries:          1,
+			BreakerThreshold: 3,
+			BreakerCooldown:  time.Hour,
+		},
+		breakers: newCircuitBreakers(),
+		sleep:    func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	if _, err := r.fetch(context.Background(), "http://statsapi.mlb.com/api/v1/teams", nil); err == nil || errors.Is(err, errCircuitOpen) {
+		t.Errorf("wanted bad gateway error, got %v", err)
+	}
+	// the circuit opens after the first attempt of the second request, so it is not retried
+	if _, err := r.fetch(context.Background(), "http://statsapi.mlb.com/api/v1/teams", nil); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("wanted retry to fail immediately after circuit opened, got %v", err)
+	}
+	if _, err := r.fetch(context.Background(), "http://statsapi.mlb.com/api/v1/people", nil); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("wanted requests to same host to fail immediately, got %v", err)
+	}
+	if want := 3; want != requests {
+		t.Errorf("wanted %v requests before circuit opened, got %v", want, requests)
+	}
+	if _, err := r.fetch(context.Background(), "http://api.fantasy.nfl.com/v2/players", nil); err == nil || errors.Is(err, errCircuitOpen) {
+		t.Errorf("wanted requests to other hosts to be made, got %v", err)
+	}
+}
+
+func TestHTTPRequesterBytes_canceled(t *testing.T) {
+	requests := 0
+	r := httpRequester{
+		cache: NewCache(CacheConfig{}),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				requests++
+				return nil, r.Context().Err()
+			},
+		},
+		retryConfig: RetryConfig{
+			Retries:          2,
+			BreakerThreshold: 1,
+			BreakerCooldown:  time.Hour,
+		},
+		breakers: newCircuitBreakers(),
+		sleep:    sleep,
+	}
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	if _, err := r.fetch(ctx, "http://statsapi.mlb.com/api/v1/teams", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted canceled error, got %v", err)
+	}
+	if want := 1; want != requests {
+		t.Errorf("wanted canceled request to not be retried: wanted %v requests, got %v", want, requests)
+	}
+	if err := r.breakers.allow("statsapi.mlb.com", time.Now()); err != nil {
+		t.Errorf("wanted canceled request to not count as failure of host, got %v", err)
+	}
+}
+
+func TestHTTPRequesterBytes_trialRequest(t *testing.T) {
+	trialRequestTests := []struct {
+		doErr      error
+		statusCode int
+		cancel     bool
+		wantClosed bool
+		wantTrial  bool
+	}{
+		{ // canceled trial requests do not close the circuit
+			doErr:     context.Canceled,
+			cancel:    true,
+			wantTrial: true,
+		},
+		{ // the host did not respond
+			doErr: errors.New("connection refused"),
+		},
+		{
+			statusCode: 503,
+		},
+		{ // the host responded, even though the response is not ok
+			statusCode: 404,
+			wantClosed: true,
+		},
+		{
+			statusCode: 200,
+			wantClosed: true,
+		},
+	}
+	for i, test := range trialRequestTests {
+		r := httpRequester{
+			cache: NewCache(CacheConfig{}),
+			httpClient: mockHTTPClient{
+				DoFunc: func(r *http.Request) (*http.Response, error) {
+					if test.doErr != nil {
+						return nil, test.doErr
+					}
+					return &http.Response{StatusCode: test.statusCode, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+				},
+			},
+			retryConfig: RetryConfig{
+				BreakerThreshold: 1,
+				BreakerCooldown:  time.Hour,
+			},
+			breakers: newCircuitBreakers(),
+			sleep:    sleep,
+		}
+		r.breakers.record("statsapi.mlb.com", true, time.Now().Add(-2*time.Hour), r.retryConfig) // the cooldown is over
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		if test.cancel {
+			cancelFunc()
+		}
+		r.fetch(ctx, "http://statsapi.mlb.com/api/v1/teams", nil)
+		cancelFunc()
+		_, open := r.breakers.breakers["statsapi.mlb.com"]
+		err := r.breakers.allow("statsapi.mlb.com", time.Now())
+		switch {
+		case test.wantClosed:
+			if open || err != nil {
+				t.Errorf("Test %v: wanted circuit to be closed after response from host, got %v", i, err)
+			}
+		case !open:
+			t.Errorf("Test %v: wanted circuit to not be closed", i)
+		case test.wantTrial:
+			if err != nil {
+				t.Errorf("Test %v: wanted another trial request to be allowed, got %v", i, err)
+			}
+		case !errors.Is(err, errCircuitOpen):
+			t.Errorf("Test %v: wanted circuit to be opened again after failed trial request, got %v", i, err)
+		}
+	}
+}
+
+func TestHTTPRequesterBytes_trialRequestCanceledWaiting(t *testing.T) {
+	r := httpRequester{
+		cache: NewCache(CacheConfig{}),
+		httpClient: mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				t.Error("request made after it was canceled")
+				return nil, errors.New("unexpected request")
+			},
+		},
+		retryConfig: RetryConfig{
+			BreakerThreshold: 1,
+			BreakerCooldown:  time.Hour,
+		},
+		breakers: newCircuitBreakers(),
+		limiters: newHostLimiters(HostLimits{MaxConcurrentRequests: 1}),
+		sleep:    sleep,
+	}
+	r.breakers.record("statsapi.mlb.com", true, time.Now().Add(-2*time.Hour), r.retryConfig) // the cooldown is over
+	// another request is being made, so the next request waits for the limits of the host
+	done, err := r.limiters.wait(context.Background(), "statsapi.mlb.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer done()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	if _, err := r.fetch(ctx, "http://statsapi.mlb.com/api/v1/teams", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted request to be canceled while waiting, got %v", err)
+	}
+	if err := r.breakers.allow("statsapi.mlb.com", time.Now()); err != nil {
+		t.Errorf("wanted another trial request to be allowed, got %v", err)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted sleep to stop when context is canceled, got %v", err)
+	}
+}
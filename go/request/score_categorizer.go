@@ -1,7 +1,9 @@
 package request
 
 import (
+	"context"
 	"sort"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
@@ -9,7 +11,7 @@ import (
 type (
 	// ScoreCategorizer requests data for and creates a ScoreCategory for the FriendPlayerInfo
 	ScoreCategorizer interface {
-		RequestScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error)
+		RequestScoreCategory(ctx context.Context, pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (ScoreCategory, error)
 	}
 
 	// ScoreCategory contain the FriendScores for each PlayerType
@@ -37,16 +39,18 @@ type (
 		Score        int
 		DisplayOrder int
 		SourceID     db.SourceID
+		AddDate      *time.Time `json:",omitempty"`
+		DropDate     *time.Time `json:",omitempty"`
+		// InfoRefreshTime is when the name of the player was last saved.
+		InfoRefreshTime *time.Time `json:",omitempty"`
 	}
 
-	playerName struct {
-		sourceID db.SourceID
-		name     string
-	}
-
-	playerStat struct {
-		sourceID db.SourceID
-		stat     int
+	// playerStatRange identifies the stats for a player that were accrued between the dates.
+	// The dates are formatted as yyyy-mm-dd and are empty if the range is not bounded.
+	playerStatRange struct {
+		sourceID  db.SourceID
+		startDate string
+		endDate   string
 	}
 
 	nameScore struct {
@@ -64,6 +68,16 @@ func newScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, friends []db.F
 	}
 }
 
+// NewRosterScoreCategory creates a ScoreCategory of the players without requesting their stats.
+// The players have their saved names and scores of zero.  It is used when stats cannot be requested.
+func NewRosterScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, friends []db.Friend, players []db.Player) ScoreCategory {
+	playerNameScores := make(map[db.ID]nameScore, len(players))
+	for _, player := range players {
+		playerNameScores[player.ID] = nameScore{name: player.Name}
+	}
+	return newScoreCategory(pt, ptInfo, friends, players, playerNameScores, false)
+}
+
 func newFriendScores(scoreType string, friends []db.Friend, players []db.Player, playerNameScores map[db.ID]nameScore, onlySumTopTwoPlayerScores bool) []FriendScore {
 	friendPlayers := make(map[db.ID][]db.Player, len(players))
 	for _, player := range players {
@@ -106,11 +120,14 @@ func newPlayerScores(players []db.Player, playerNameScores map[db.ID]nameScore)
 
 func newPlayerScore(player db.Player, playerNameScore nameScore) PlayerScore {
 	return PlayerScore{
-		ID:           player.ID,
-		Name:         playerNameScore.name,
-		Score:        playerNameScore.score,
-		DisplayOrder: player.DisplayOrder,
-		SourceID:     player.SourceID,
+		ID:              player.ID,
+		Name:            playerNameScore.name,
+		Score:           playerNameScore.score,
+		DisplayOrder:    player.DisplayOrder,
+		SourceID:        player.SourceID,
+		AddDate:         player.AddDate,
+		DropDate:        player.DropDate,
+		InfoRefreshTime: player.InfoRefreshTime,
 	}
 }
 
@@ -130,17 +147,37 @@ func getFriendScore(playerScores []PlayerScore, onlySumTopTwoPlayerScores bool)
 	return friendScore
 }
 
-func playerNameScoresFromFieldMaps(players []db.Player, names map[db.SourceID]string, stats map[db.SourceID]int) map[db.ID]nameScore {
+func playerNameScoresFromFieldMaps(players []db.Player, names map[db.SourceID]string, stats map[playerStatRange]int) map[db.ID]nameScore {
 	playerNameScores := make(map[db.ID]nameScore, len(players))
 	for _, player := range players {
 		playerNameScores[player.ID] = nameScore{
 			name:  names[player.SourceID],
-			score: stats[player.SourceID],
+			score: stats[newPlayerStatRange(player)],
 		}
 	}
 	return playerNameScores
 }
 
+// newPlayerStatRange creates the range of dates the player was on the Friend's roster
+func newPlayerStatRange(player db.Player) playerStatRange {
+	psr := playerStatRange{
+		sourceID: player.SourceID,
+	}
+	if player.AddDate != nil {
+		psr.startDate = player.AddDate.Format(db.DateFormat)
+	}
+	if player.DropDate != nil {
+		// the player is not on the roster on the day they are dropped
+		psr.endDate = player.DropDate.AddDate(0, 0, -1).Format(db.DateFormat)
+	}
+	return psr
+}
+
+// bounded determines whether the stats for the range are not for the whole season
+func (psr playerStatRange) bounded() bool {
+	return len(psr.startDate) != 0 || len(psr.endDate) != 0
+}
+
 func playerNameScoresFromSourceIDMap(players []db.Player, sourceIDNameScores map[db.SourceID]nameScore) map[db.ID]nameScore {
 	playerNameScores := make(map[db.ID]nameScore, len(players))
 	for _, player := range players {
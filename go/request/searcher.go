@@ -1,17 +1,74 @@
 package request
 
-import "github.com/jacobpatterson1549/nate-mlb/go/db"
+import (
+	"context"
+	"errors"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
 
 type (
 	// Searcher requests PlayerSearchResults
 	Searcher interface {
-		Search(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error)
+		Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error)
 	}
 
 	// PlayerSearchResult contains information about the result for a searched player.
+	// The Position and Team are only known for some players.
 	PlayerSearchResult struct {
 		Name     string
 		Details  string
 		SourceID db.SourceID
+		Position string
+		Team     string
+	}
+
+	// fallbackSearcher searches with each of its Searchers in order until one succeeds.
+	fallbackSearcher []Searcher
+
+	// fuzzySearcher ranks the results of a Searcher of players by relevance.
+	// Sources of players only find names that start with or contain the query, so the start of the query is searched when it has no results, which finds names with typos.
+	fuzzySearcher struct {
+		Searcher
 	}
 )
+
+// Search implements the Searcher interface
+// The results of the first Searcher that does not fail are returned.  If all fail, all of their errors are returned.
+func (searchers fallbackSearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+	var errs []error
+	for _, s := range searchers {
+		playerSearchResults, err := s.Search(ctx, pt, year, playerNamePrefix, activePlayersOnly)
+		if err == nil {
+			return playerSearchResults, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Search implements the Searcher interface
+// Results of the query that do not fuzzily match it are listed last.  Results of the broadened query must match the query.
+func (s fuzzySearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+	playerSearchResults, err := s.Searcher.Search(ctx, pt, year, playerNamePrefix, activePlayersOnly)
+	if err != nil {
+		return nil, err
+	}
+	if len(playerSearchResults) != 0 {
+		matched, unmatched := rankSearchResults(pt, playerNamePrefix, playerSearchResults)
+		return append(matched, unmatched...), nil
+	}
+	broadQuery, ok := broadenQuery(playerNamePrefix)
+	if !ok {
+		return playerSearchResults, nil
+	}
+	playerSearchResults, err = s.Searcher.Search(ctx, pt, year, broadQuery, activePlayersOnly)
+	if err != nil {
+		return nil, err
+	}
+	matched, _ := rankSearchResults(pt, playerNamePrefix, playerSearchResults)
+	return matched, nil
+}
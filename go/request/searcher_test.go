@@ -0,0 +1,181 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type mockSearcher struct {
+	SearchFunc func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error)
+}
+
+func (s mockSearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+	return s.SearchFunc(pt, year, playerNamePrefix, activePlayersOnly)
+}
+
+func TestFallbackSearcherSearch(t *testing.T) {
+	newSearcher := func(name string, err error) Searcher {
+		return mockSearcher{
+			SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+				if err != nil {
+					return nil, err
+				}
+				return []PlayerSearchResult{{Name: name}}, nil
+			},
+		}
+	}
+	fallbackSearcherSearchTests := []struct {
+		searchers   fallbackSearcher
+		want        []PlayerSearchResult
+		wantErrMsgs []string
+	}{
+		{
+			searchers: fallbackSearcher{newSearcher("first", nil), newSearcher("second", nil)},
+			want:      []PlayerSearchResult{{Name: "first"}},
+		},
+		{
+			searchers: fallbackSearcher{newSearcher("first", errors.New("first error")), newSearcher("second", nil)},
+			want:      []PlayerSearchResult{{Name: "second"}},
+		},
+		{
+			searchers:   fallbackSearcher{newSearcher("first", errors.New("first error")), newSearcher("second", errors.New("second error"))},
+			wantErrMsgs: []string{"first error", "second error"},
+		},
+	}
+	for i, test := range fallbackSearcherSearchTests {
+		got, err := test.searchers.Search(context.Background(), db.PlayerTypeMlbHitter, 2019, "a", true)
+		switch {
+		case len(test.wantErrMsgs) != 0:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+				continue
+			}
+			for _, wantErrMsg := range test.wantErrMsgs {
+				if !strings.Contains(err.Error(), wantErrMsg) {
+					t.Errorf("Test %v: wanted error to contain %q, got %v", i, wantErrMsg, err)
+				}
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: Not equal:\nWanted: %v\nGot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestFallbackSearcherSearch_canceled(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	searched := false
+	searchers := fallbackSearcher{
+		mockSearcher{
+			SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+				cancelFunc()
+				return nil, context.Canceled
+			},
+		},
+		mockSearcher{
+			SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+				searched = true
+				return nil, nil
+			},
+		},
+	}
+	if _, err := searchers.Search(ctx, db.PlayerTypeMlbHitter, 2019, "a", true); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted canceled error, got %v", err)
+	}
+	if searched {
+		t.Error("wanted searchers to not be tried after the search is canceled")
+	}
+}
+
+func TestFuzzySearcherSearch(t *testing.T) {
+	names := []string{"Mike Trotter", "Mike Trout", "Mookie Betts", "Troy Tulowitzki"}
+	fuzzySearcherSearchTests := []struct {
+		query       string
+		searchErr   error
+		wantQueries []string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			query:       "mike trout",
+			wantQueries: []string{"mike trout"},
+			want:        []string{"Mike Trout"},
+		},
+		{
+			query:       "mookie",
+			wantQueries: []string{"mookie"},
+			want:        []string{"Mookie Betts"},
+		},
+		{
+			query:       "trot",
+			wantQueries: []string{"trot"},
+			want:        []string{"Mike Trotter"},
+		},
+		{
+			query:       "mike truot",
+			wantQueries: []string{"mike truot", "tru"},
+		},
+		{
+			query:       "mike tuort", // typo in the broadened query
+			wantQueries: []string{"mike tuort", "tuo"},
+		},
+		{
+			query:       "troutt",
+			wantQueries: []string{"troutt", "tro"},
+			want:        []string{"Mike Trotter", "Mike Trout"},
+		},
+		{
+			query:       "mt",
+			wantQueries: []string{"mt"}, // too short to broaden
+		},
+		{
+			query:       "trout",
+			searchErr:   errors.New("search failed"),
+			wantQueries: []string{"trout"},
+			wantErr:     true,
+		},
+	}
+	for i, test := range fuzzySearcherSearchTests {
+		var gotQueries []string
+		s := fuzzySearcher{
+			mockSearcher{
+				SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]PlayerSearchResult, error) {
+					gotQueries = append(gotQueries, playerNamePrefix)
+					if test.searchErr != nil {
+						return nil, test.searchErr
+					}
+					var results []PlayerSearchResult
+					for _, name := range names {
+						if strings.Contains(strings.ToLower(name), playerNamePrefix) {
+							results = append(results, PlayerSearchResult{Name: name})
+						}
+					}
+					return results, nil
+				},
+			},
+		}
+		results, err := s.Search(context.Background(), db.PlayerTypeMlbHitter, 2019, test.query, true)
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Name)
+		}
+		switch {
+		case !reflect.DeepEqual(test.wantQueries, gotQueries):
+			t.Errorf("Test %v: queries not equal:\nWanted: %v\nGot:    %v", i, test.wantQueries, gotQueries)
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: Not equal:\nWanted: %v\nGot:    %v", i, test.want, got)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package request
 
 import (
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
 )
 
 func TestGetFriendScore(t *testing.T) {
@@ -51,3 +55,35 @@ func TestGetFriendScore(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRosterScoreCategory(t *testing.T) {
+	friends := []db.Friend{
+		{ID: "2", DisplayOrder: 2, Name: "Charles"},
+		{ID: "1", DisplayOrder: 1, Name: "Bobby"},
+	}
+	infoRefreshTime := time.Date(2019, time.June, 2, 6, 0, 0, 0, time.UTC)
+	players := []db.Player{
+		{ID: "1", SourceID: 547180, FriendID: "1", DisplayOrder: 1, Name: "Bryce Harper", InfoRefreshTime: &infoRefreshTime},
+		{ID: "2", SourceID: 545361, FriendID: "2", DisplayOrder: 1},
+	}
+	ptInfo := db.PlayerTypeInfo{Name: "Hitting", Description: "Home Runs", ScoreType: "HR"}
+	want := ScoreCategory{
+		Name:        "Hitting",
+		Description: "Home Runs",
+		PlayerType:  db.PlayerTypeMlbHitter,
+		FriendScores: []FriendScore{
+			{
+				ID: "1", Name: "Bobby", ScoreType: "HR", DisplayOrder: 1,
+				PlayerScores: []PlayerScore{{ID: "1", Name: "Bryce Harper", DisplayOrder: 1, SourceID: 547180, InfoRefreshTime: &infoRefreshTime}},
+			},
+			{
+				ID: "2", Name: "Charles", ScoreType: "HR", DisplayOrder: 2,
+				PlayerScores: []PlayerScore{{ID: "2", DisplayOrder: 1, SourceID: 545361}},
+			},
+		},
+	}
+	got := NewRosterScoreCategory(db.PlayerTypeMlbHitter, ptInfo, friends, players)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
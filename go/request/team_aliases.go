@@ -0,0 +1,93 @@
+package request
+
+import "github.com/jacobpatterson1549/nate-mlb/go/db"
+
+// mlbTeamAliases are the abbreviations and nicknames of mlb teams, keyed by their folded names.
+// Former names are included so teams match in past seasons.
+var mlbTeamAliases = map[string][]string{
+	"arizona diamondbacks":  {"ARI", "AZ", "D-backs", "Dbacks", "Snakes"},
+	"atlanta braves":        {"ATL", "Braves"},
+	"baltimore orioles":     {"BAL", "O's", "Orioles"},
+	"boston red sox":        {"BOS", "Red Sox", "BoSox"},
+	"chicago cubs":          {"CHC", "Cubs", "Cubbies"},
+	"chicago white sox":     {"CWS", "CHW", "White Sox", "ChiSox", "Pale Hose"},
+	"cincinnati reds":       {"CIN", "Reds", "Redlegs"},
+	"cleveland guardians":   {"CLE", "Guardians", "Guards"},
+	"cleveland indians":     {"CLE", "Indians", "Tribe"},
+	"colorado rockies":      {"COL", "Rockies", "Rox"},
+	"detroit tigers":        {"DET", "Tigers"},
+	"houston astros":        {"HOU", "Astros", "Stros"},
+	"kansas city royals":    {"KC", "KCR", "Royals"},
+	"los angeles angels":    {"LAA", "ANA", "Angels", "Halos"},
+	"los angeles dodgers":   {"LAD", "Dodgers"},
+	"miami marlins":         {"MIA", "Marlins", "Fish"},
+	"milwaukee brewers":     {"MIL", "Brewers", "Brew Crew"},
+	"minnesota twins":       {"MIN", "Twins"},
+	"new york mets":         {"NYM", "Mets", "Amazins"},
+	"new york yankees":      {"NYY", "Yankees", "Yanks", "Bronx Bombers"},
+	"oakland athletics":     {"OAK", "A's", "Athletics"},
+	"athletics":             {"ATH", "OAK", "A's"},
+	"philadelphia phillies": {"PHI", "Phillies", "Phils"},
+	"pittsburgh pirates":    {"PIT", "Pirates", "Bucs"},
+	"san diego padres":      {"SD", "SDP", "Padres", "Friars"},
+	"san francisco giants":  {"SF", "SFG", "Giants"},
+	"seattle mariners":      {"SEA", "Mariners", "M's"},
+	"st louis cardinals":    {"STL", "Cardinals", "Cards", "Redbirds"},
+	"tampa bay rays":        {"TB", "TBR", "Rays"},
+	"texas rangers":         {"TEX", "Rangers"},
+	"toronto blue jays":     {"TOR", "Blue Jays", "Jays"},
+	"washington nationals":  {"WSH", "WAS", "Nationals", "Nats"},
+}
+
+// nflTeamAliases are the abbreviations and nicknames of nfl teams, keyed by their folded names.
+// Former names are included so teams match in past seasons.
+var nflTeamAliases = map[string][]string{
+	"arizona cardinals":        {"ARI", "Cardinals", "Cards"},
+	"atlanta falcons":          {"ATL", "Falcons", "Dirty Birds"},
+	"baltimore ravens":         {"BAL", "Ravens"},
+	"buffalo bills":            {"BUF", "Bills"},
+	"carolina panthers":        {"CAR", "Panthers"},
+	"chicago bears":            {"CHI", "Bears"},
+	"cincinnati bengals":       {"CIN", "Bengals"},
+	"cleveland browns":         {"CLE", "Browns"},
+	"dallas cowboys":           {"DAL", "Cowboys", "Boys"},
+	"denver broncos":           {"DEN", "Broncos"},
+	"detroit lions":            {"DET", "Lions"},
+	"green bay packers":        {"GB", "GNB", "Packers", "Pack"},
+	"houston texans":           {"HOU", "Texans"},
+	"indianapolis colts":       {"IND", "Colts"},
+	"jacksonville jaguars":     {"JAX", "JAC", "Jaguars", "Jags"},
+	"kansas city chiefs":       {"KC", "KAN", "Chiefs"},
+	"las vegas raiders":        {"LV", "LVR", "Raiders"},
+	"oakland raiders":          {"OAK", "Raiders"},
+	"los angeles chargers":     {"LAC", "Chargers", "Bolts"},
+	"san diego chargers":       {"SD", "Chargers", "Bolts"},
+	"los angeles rams":         {"LA", "LAR", "Rams"},
+	"st louis rams":            {"STL", "Rams"},
+	"miami dolphins":           {"MIA", "Dolphins", "Fins", "Phins"},
+	"minnesota vikings":        {"MIN", "Vikings", "Vikes"},
+	"new england patriots":     {"NE", "NWE", "Patriots", "Pats"},
+	"new orleans saints":       {"NO", "NOR", "Saints"},
+	"new york giants":          {"NYG", "Giants", "G-Men", "Big Blue"},
+	"new york jets":            {"NYJ", "Jets", "Gang Green"},
+	"philadelphia eagles":      {"PHI", "Eagles"},
+	"pittsburgh steelers":      {"PIT", "Steelers"},
+	"san francisco 49ers":      {"SF", "SFO", "49ers", "Niners"},
+	"seattle seahawks":         {"SEA", "Seahawks", "Hawks"},
+	"tampa bay buccaneers":     {"TB", "TAM", "Buccaneers", "Bucs"},
+	"tennessee titans":         {"TEN", "Titans"},
+	"washington redskins":      {"WAS", "WSH"},
+	"washington football team": {"WAS", "WSH", "WFT"},
+	"washington commanders":    {"WAS", "WSH", "Commanders"},
+}
+
+// teamAliases are the aliases of the teams in the sport of the PlayerType, keyed by their folded names.
+func teamAliases(pt db.PlayerType) map[string][]string {
+	switch pt {
+	case db.PlayerTypeMlbTeam, db.PlayerTypeMlbHitter, db.PlayerTypeMlbPitcher:
+		return mlbTeamAliases
+	case db.PlayerTypeNflTeam, db.PlayerTypeNflQB, db.PlayerTypeNflMisc:
+		return nflTeamAliases
+	}
+	return nil
+}
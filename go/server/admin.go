@@ -1,10 +1,13 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 	"github.com/jacobpatterson1549/nate-mlb/go/request"
@@ -12,21 +15,31 @@ import (
 
 type (
 	adminDatastore interface {
-		SaveYears(st db.SportType, futureYears []db.Year) error
-		SaveFriends(st db.SportType, futureFriends []db.Friend) error
-		SavePlayers(st db.SportType, futurePlayers []db.Player) error
-		ClearStat(st db.SportType) error
-		SetUserPassword(username string, p db.Password) error
-		IsCorrectUserPassword(username string, p db.Password) (bool, error)
+		SaveYears(ctx context.Context, st db.SportType, futureYears []db.Year, rollovers ...db.YearRollover) error
+		SaveFriends(ctx context.Context, st db.SportType, futureFriends []db.Friend) error
+		SaveSubscriptions(ctx context.Context, st db.SportType, futureSubscriptions []db.Subscription) error
+		SavePlayers(ctx context.Context, st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error
+		ImportRosters(ctx context.Context, st db.SportType, newFriends []db.Friend, importedPlayers []db.ImportedPlayer, playerInfos []db.PlayerInfo) error
+		ClearStat(ctx context.Context, st db.SportType) error
+		SetUserPassword(ctx context.Context, username string, p db.Password) error
+		IsCorrectUserPassword(ctx context.Context, username string, p db.Password) (bool, error)
+	}
+	// FriendSubscription is the email address a friend is sent digests of the standings at.  The Email is empty if the friend is not subscribed.
+	FriendSubscription struct {
+		ID    db.ID
+		Name  string
+		Email string
 	}
 	adminCache interface {
 		Clear()
+		Stats() request.CacheStats
 	}
 )
 
 var (
 	playerDisplayOrderRE = regexp.MustCompile("^player-([0-9]+)-display-order$")
 	friendDisplayOrderRE = regexp.MustCompile("^friend-(.+)-display-order$")
+	subscriptionEmailRE  = regexp.MustCompile("^subscription-(.+)-email$")
 )
 
 func handleAdminPostRequest(ds adminDatastore, c adminCache, st db.SportType, r *http.Request) error {
@@ -40,6 +53,8 @@ func handleAdminPostRequest(ds adminDatastore, c adminCache, st db.SportType, r
 		adminAction = updateFriends
 	case "players":
 		adminAction = updatePlayers
+	case "subscriptions":
+		adminAction = updateSubscriptions
 	case "years":
 		adminAction = updateYears
 	case "cache":
@@ -74,26 +89,34 @@ func handleAdminSearchRequest(year int, searchers map[db.PlayerType]request.Sear
 	if !ok {
 		return nil, fmt.Errorf("no searcher for playerType %v", playerType)
 	}
-	return searcher.Search(playerType, year, searchQuery, activePlayersOnlyB)
+	playerSearchResults, err := searcher.Search(r.Context(), playerType, year, searchQuery, activePlayersOnlyB)
+	if err != nil {
+		return nil, err
+	}
+	return request.FilterSearchResults(playerType, playerSearchResults, r.FormValue("team"), r.FormValue("pos")), nil
 }
 
 func updatePlayers(ds adminDatastore, st db.SportType, r *http.Request) error {
 	var players []db.Player
+	var playerInfos []db.PlayerInfo
 	for k, v := range r.Form {
 		if matches := playerDisplayOrderRE.FindStringSubmatch(k); len(matches) > 1 {
-			player, err := getPlayer(st, r, matches[1], v[0])
+			player, err := getPlayer(r, matches[1], v[0])
 			if err != nil {
 				return err
 			}
 			players = append(players, player)
+			if playerInfo, ok := getPlayerInfo(r, player); ok {
+				playerInfos = append(playerInfos, playerInfo)
+			}
 		}
 	}
 
-	err := ds.SavePlayers(st, players)
+	err := ds.SavePlayers(r.Context(), st, players, playerInfos)
 	if err != nil {
 		return err
 	}
-	return ds.ClearStat(st)
+	return ds.ClearStat(r.Context(), st)
 }
 
 func updateFriends(ds adminDatastore, st db.SportType, r *http.Request) error {
@@ -109,40 +132,84 @@ func updateFriends(ds adminDatastore, st db.SportType, r *http.Request) error {
 		}
 	}
 
-	err := ds.SaveFriends(st, friends)
+	err := ds.SaveFriends(r.Context(), st, friends)
 	if err != nil {
 		return err
 	}
-	return ds.ClearStat(st)
+	return ds.ClearStat(r.Context(), st)
+}
+
+// updateSubscriptions subscribes the friends with email addresses to digests of the standings.  Friends without email addresses are unsubscribed.
+func updateSubscriptions(ds adminDatastore, st db.SportType, r *http.Request) error {
+	var subscriptions []db.Subscription
+	for k, v := range r.Form {
+		if matches := subscriptionEmailRE.FindStringSubmatch(k); len(matches) > 1 {
+			email := strings.TrimSpace(v[0])
+			if len(email) == 0 {
+				continue
+			}
+			subscription := db.Subscription{
+				FriendID: db.ID(matches[1]),
+				Email:    email,
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return ds.SaveSubscriptions(r.Context(), st, subscriptions)
+}
+
+// newFriendSubscriptions gets the subscriptions of the friends, in the order of the friends.
+func newFriendSubscriptions(friendScores []request.FriendScore, subscriptions []db.Subscription) []FriendSubscription {
+	emails := make(map[db.ID]string, len(subscriptions))
+	for _, subscription := range subscriptions {
+		emails[subscription.FriendID] = subscription.Email
+	}
+	friendSubscriptions := make([]FriendSubscription, len(friendScores))
+	for i, fs := range friendScores {
+		friendSubscriptions[i] = FriendSubscription{
+			ID:    fs.ID,
+			Name:  fs.Name,
+			Email: emails[fs.ID],
+		}
+	}
+	return friendSubscriptions
 }
 
 func updateYears(ds adminDatastore, st db.SportType, r *http.Request) error {
 	var years []db.Year
+	var rollovers []db.YearRollover
 	for _, y := range r.Form["year"] {
 		year, err := getYear(r, y)
 		if err != nil {
 			return err
 		}
 		years = append(years, year)
+		rollover, ok, err := getYearRollover(r, year)
+		if err != nil {
+			return err
+		}
+		if ok {
+			rollovers = append(rollovers, rollover)
+		}
 	}
 
-	return ds.SaveYears(st, years)
+	return ds.SaveYears(r.Context(), st, years, rollovers...)
 }
 
 func clearStat(ds adminDatastore, st db.SportType, r *http.Request) error {
-	return ds.ClearStat(st)
+	return ds.ClearStat(r.Context(), st)
 }
 
 func resetPassword(ds adminDatastore, st db.SportType, r *http.Request) error {
 	username := r.FormValue("username")
 	newPassword := r.FormValue("newPassword")
-	return ds.SetUserPassword(username, db.Password(newPassword))
+	return ds.SetUserPassword(r.Context(), username, db.Password(newPassword))
 }
 
 func verifyUserPassword(ds adminDatastore, r *http.Request) error {
 	username := r.FormValue("username")
 	password := r.FormValue("password")
-	correctPassword, err := ds.IsCorrectUserPassword(username, db.Password(password))
+	correctPassword, err := ds.IsCorrectUserPassword(r.Context(), username, db.Password(password))
 	if err != nil {
 		return fmt.Errorf("verifying password: %w", err)
 	}
@@ -152,7 +219,7 @@ func verifyUserPassword(ds adminDatastore, r *http.Request) error {
 	return nil
 }
 
-func getPlayer(st db.SportType, r *http.Request, id, displayOrder string) (db.Player, error) {
+func getPlayer(r *http.Request, id, displayOrder string) (db.Player, error) {
 	var player db.Player
 
 	player.ID = db.ID(id)
@@ -180,9 +247,54 @@ func getPlayer(st db.SportType, r *http.Request, id, displayOrder string) (db.Pl
 	friendID := r.FormValue(fmt.Sprintf("player-%s-friend-id", id))
 	player.FriendID = db.ID(friendID)
 
+	addDate, err := getDate(r, fmt.Sprintf("player-%s-add-date", id))
+	if err != nil {
+		return player, fmt.Errorf("converting player add date: %w", err)
+	}
+	player.AddDate = addDate
+
+	dropDate, err := getDate(r, fmt.Sprintf("player-%s-drop-date", id))
+	if err != nil {
+		return player, fmt.Errorf("converting player drop date: %w", err)
+	}
+	player.DropDate = dropDate
+
+	if !player.PlayerType.RosterDates() && (addDate != nil || dropDate != nil) {
+		return player, fmt.Errorf("roster dates are not supported for nfl players: remove the dates of player %v", player.SourceID)
+	}
+
 	return player, nil
 }
 
+// getPlayerInfo gets the info of the player if it was added from a search, returning false if the player does not have a name
+func getPlayerInfo(r *http.Request, player db.Player) (db.PlayerInfo, bool) {
+	name := r.FormValue(fmt.Sprintf("player-%s-name", player.ID))
+	if len(name) == 0 {
+		return db.PlayerInfo{}, false
+	}
+	playerInfo := db.PlayerInfo{
+		PlayerType: player.PlayerType,
+		SourceID:   player.SourceID,
+		Name:       name,
+		Position:   r.FormValue(fmt.Sprintf("player-%s-position", player.ID)),
+		Team:       r.FormValue(fmt.Sprintf("player-%s-team", player.ID)),
+	}
+	return playerInfo, true
+}
+
+// getDate parses the optional yyyy-mm-dd date for the form key, returning nil if it is not present
+func getDate(r *http.Request, key string) (*time.Time, error) {
+	dateS := r.FormValue(key)
+	if len(dateS) == 0 {
+		return nil, nil
+	}
+	date, err := time.Parse(db.DateFormat, dateS)
+	if err != nil {
+		return nil, fmt.Errorf("parsing '%v' as a date: %w", dateS, err)
+	}
+	return &date, nil
+}
+
 func getFriend(r *http.Request, id, displayOrder string) (db.Friend, error) {
 	var friend db.Friend
 
@@ -213,3 +325,32 @@ func getYear(r *http.Request, yearS string) (db.Year, error) {
 
 	return year, nil
 }
+
+// yearRolledOver determines if the request to update the years rolls over the rosters of any year.
+func yearRolledOver(r *http.Request) bool {
+	for _, y := range r.Form["year"] {
+		if len(r.FormValue(fmt.Sprintf("year-%s-rollover", y))) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getYearRollover gets the year to copy the rosters of the year from, if the year should be rolled over
+func getYearRollover(r *http.Request, year db.Year) (db.YearRollover, bool, error) {
+	rollover := db.YearRollover{
+		ToYear: year.Value,
+	}
+	fromYearS := r.FormValue(fmt.Sprintf("year-%d-rollover", year.Value))
+	if len(fromYearS) == 0 {
+		return rollover, false, nil
+	}
+	fromYearI, err := strconv.Atoi(fromYearS)
+	if err != nil {
+		return rollover, false, fmt.Errorf("converting year to roll over rosters from '%v' to number: %w", fromYearS, err)
+	}
+	rollover.FromYear = fromYearI
+	rollover.Keepers = r.FormValue(fmt.Sprintf("year-%d-keepers", year.Value)) == "on"
+
+	return rollover, true, nil
+}
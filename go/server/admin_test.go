@@ -1,11 +1,13 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"net/http/httptest"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
 	"github.com/jacobpatterson1549/nate-mlb/go/request"
@@ -46,6 +48,11 @@ func TestHandleAdminPostRequest(t *testing.T) {
 			action:                "players",
 			wantActionCount:       2,
 		},
+		{
+			isCorrectUserPassword: true,
+			action:                "subscriptions",
+			wantActionCount:       1,
+		},
 		{
 			isCorrectUserPassword: true,
 			action:                "years",
@@ -81,7 +88,7 @@ func TestHandleAdminPostRequest(t *testing.T) {
 				return nil
 			}
 		case "players":
-			ds.SavePlayersFunc = func(st db.SportType, futurePlayers []db.Player) error {
+			ds.SavePlayersFunc = func(st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error {
 				gotActionCount++
 				return nil
 			}
@@ -89,8 +96,13 @@ func TestHandleAdminPostRequest(t *testing.T) {
 				gotActionCount++
 				return nil
 			}
+		case "subscriptions":
+			ds.SaveSubscriptionsFunc = func(st db.SportType, futureSubscriptions []db.Subscription) error {
+				gotActionCount++
+				return nil
+			}
 		case "years":
-			ds.SaveYearsFunc = func(st db.SportType, futureYears []db.Year) error {
+			ds.SaveYearsFunc = func(st db.SportType, futureYears []db.Year, rollovers []db.YearRollover) error {
 				gotActionCount++
 				return nil
 			}
@@ -134,6 +146,8 @@ func TestHandleAdminSearchRequest(t *testing.T) {
 		searchQuery                 string
 		playerTypeID                string
 		activePlayersOnly           string
+		team                        string
+		position                    string
 		year                        int
 		searcherPlayerSearchResults []request.PlayerSearchResult
 		wantSearchQuery             string
@@ -192,6 +206,21 @@ func TestHandleAdminSearchRequest(t *testing.T) {
 				{Name: "happy path #2"},
 			},
 		},
+		{ // filtered by team and position
+			searchQuery:  "jose",
+			playerTypeID: "2",
+			team:         "Cleveland",
+			position:     "3b",
+			searcherPlayerSearchResults: []request.PlayerSearchResult{
+				{Name: "Jose Ramirez", Position: "3B", Team: "CLE"},
+				{Name: "Jose Altuve", Position: "2B", Team: "HOU"},
+				{Name: "Jose Miranda", Position: "3B", Team: "MIN"},
+			},
+			wantPlayerType: db.PlayerType(2),
+			wantPlayerSearchResults: []request.PlayerSearchResult{
+				{Name: "Jose Ramirez", Position: "3B", Team: "CLE"},
+			},
+		},
 	}
 	for i, test := range handleAdminSearchRequestTests {
 		r := httptest.NewRequest("POST", "/admin", nil)
@@ -199,6 +228,8 @@ func TestHandleAdminSearchRequest(t *testing.T) {
 		q.Add("q", test.searchQuery)
 		q.Add("pt", test.playerTypeID)
 		q.Add("apo", test.activePlayersOnly)
+		q.Add("team", test.team)
+		q.Add("pos", test.position)
 		r.URL.RawQuery = q.Encode()
 		searchers := make(map[db.PlayerType]request.Searcher, 1)
 		if len(test.searcherPlayerSearchResults) > 0 {
@@ -323,12 +354,14 @@ func TestUpdateFriends(t *testing.T) {
 }
 
 func TestUpdatePlayers(t *testing.T) {
+	june1 := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
 	updatePlayersTests := []struct {
-		st              db.SportType
-		form            map[string][]string
-		saveErr         error
-		wantErr         bool
-		wantSavePlayers []db.Player
+		st                  db.SportType
+		form                map[string][]string
+		saveErr             error
+		wantErr             bool
+		wantSavePlayers     []db.Player
+		wantSavePlayerInfos []db.PlayerInfo
 	}{
 		{},
 		{
@@ -395,6 +428,47 @@ func TestUpdatePlayers(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{ // roster dates
+			form: map[string][]string{
+				"player-7-display-order": {"1"},
+				"player-7-player-type":   {"3"},
+				"player-7-friend-id":     {"9"},
+				"player-7-source-id":     {"8"},
+				"player-7-add-date":      {"2019-06-01"},
+				"player-7-drop-date":     {""},
+			},
+			wantSavePlayers: []db.Player{
+				{
+					ID:           "7",
+					DisplayOrder: 1,
+					PlayerType:   3,
+					SourceID:     8,
+					FriendID:     "9",
+					AddDate:      &june1,
+				},
+			},
+		},
+		{ // roster dates are not supported for nfl players
+			st: db.SportTypeNfl,
+			form: map[string][]string{
+				"player-7-display-order": {"1"},
+				"player-7-player-type":   {"5"},
+				"player-7-friend-id":     {"9"},
+				"player-7-source-id":     {"8"},
+				"player-7-drop-date":     {"2019-06-01"},
+			},
+			wantErr: true,
+		},
+		{ // bad dropDate
+			form: map[string][]string{
+				"player-7-display-order": {"1"},
+				"player-7-player-type":   {"3"},
+				"player-7-friend-id":     {"9"},
+				"player-7-source-id":     {"8"},
+				"player-7-drop-date":     {"6/1/2019"},
+			},
+			wantErr: true,
+		},
 		{ // happy path
 			form: map[string][]string{
 				"player-6-display-order": {"2"},
@@ -423,10 +497,48 @@ func TestUpdatePlayers(t *testing.T) {
 				},
 			},
 		},
+		{ // player infos of searched players
+			form: map[string][]string{
+				"player-6-display-order": {"2"},
+				"player-7-display-order": {"1"},
+				"player-6-player-type":   {"2"},
+				"player-7-player-type":   {"2"},
+				"player-6-friend-id":     {"4"},
+				"player-7-friend-id":     {"4"},
+				"player-6-source-id":     {"8000"},
+				"player-7-source-id":     {"547180"},
+				"player-7-name":          {"Bryce Harper"},
+				"player-7-position":      {"RF"},
+				"player-7-team":          {"PHI"},
+			},
+			wantSavePlayers: []db.Player{
+				{ID: "7", DisplayOrder: 1, PlayerType: 2, SourceID: 547180, FriendID: "4"},
+				{ID: "6", DisplayOrder: 2, PlayerType: 2, SourceID: 8000, FriendID: "4"},
+			},
+			wantSavePlayerInfos: []db.PlayerInfo{
+				{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper", Position: "RF", Team: "PHI"},
+			},
+		},
+		{
+			form: map[string][]string{
+				"player-7-display-order": {"1"},
+				"player-7-player-type":   {"2"},
+				"player-7-friend-id":     {"4"},
+				"player-7-source-id":     {"547180"},
+				"player-7-name":          {"Bryce Harper"},
+			},
+			wantSavePlayers: []db.Player{
+				{ID: "7", DisplayOrder: 1, PlayerType: 2, SourceID: 547180, FriendID: "4"},
+			},
+			wantSavePlayerInfos: []db.PlayerInfo{
+				{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper"},
+			},
+			saveErr: errors.New("save players error"),
+		},
 	}
 	for i, test := range updatePlayersTests {
 		ds := mockAdminDatastore{
-			SavePlayersFunc: func(st db.SportType, futurePlayers []db.Player) error {
+			SavePlayersFunc: func(st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error {
 				playerDisplayOrder := func(i int) int {
 					return futurePlayers[i].DisplayOrder
 				}
@@ -436,6 +548,9 @@ func TestUpdatePlayers(t *testing.T) {
 				if !reflect.DeepEqual(test.wantSavePlayers, futurePlayers) {
 					t.Errorf("Test %v:\nwanted save players: %v\ngot: %v", i, test.wantSavePlayers, futurePlayers)
 				}
+				if !reflect.DeepEqual(test.wantSavePlayerInfos, playerInfos) {
+					t.Errorf("Test %v:\nwanted save player infos: %v\ngot: %v", i, test.wantSavePlayerInfos, playerInfos)
+				}
 				return test.saveErr
 			},
 		}
@@ -471,12 +586,66 @@ func TestUpdatePlayers(t *testing.T) {
 	}
 }
 
+func TestUpdateSubscriptions(t *testing.T) {
+	updateSubscriptionsTests := []struct {
+		form                  map[string][]string
+		saveErr               error
+		wantSaveSubscriptions []db.Subscription
+	}{
+		{},
+		{
+			saveErr: errors.New("save subscriptions error"),
+		},
+		{
+			form: map[string][]string{
+				"subscription-8-email":   {" bart@example.com "},
+				"subscription-007-email": {"alf@example.com"},
+				"subscription-3-email":   {""}, // unsubscribed
+				"friend-9-name":          {"carl"},
+			},
+			wantSaveSubscriptions: []db.Subscription{
+				{FriendID: "007", Email: "alf@example.com"},
+				{FriendID: "8", Email: "bart@example.com"},
+			},
+		},
+	}
+	for i, test := range updateSubscriptionsTests {
+		ds := mockAdminDatastore{
+			SaveSubscriptionsFunc: func(st db.SportType, futureSubscriptions []db.Subscription) error {
+				sort.Slice(futureSubscriptions, func(i, j int) bool {
+					return futureSubscriptions[i].FriendID < futureSubscriptions[j].FriendID
+				})
+				if !reflect.DeepEqual(test.wantSaveSubscriptions, futureSubscriptions) {
+					t.Errorf("Test %v:\nwanted save subscriptions: %v\ngot: %v", i, test.wantSaveSubscriptions, futureSubscriptions)
+				}
+				return test.saveErr
+			},
+		}
+		r := httptest.NewRequest("POST", "/admin", nil)
+		q := r.URL.Query()
+		for key, values := range test.form {
+			for _, value := range values {
+				q.Add(key, value)
+			}
+		}
+		r.URL.RawQuery = q.Encode()
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Test %v: could not parse request form: %v", i, err)
+		}
+		gotErr := updateSubscriptions(ds, 1, r)
+		if !errors.Is(gotErr, test.saveErr) {
+			t.Errorf("Test %v: wanted error %v, but got %v", i, test.saveErr, gotErr)
+		}
+	}
+}
+
 func TestUpdateYears(t *testing.T) {
 	updateYearsTests := []struct {
 		st            db.SportType
 		form          map[string][]string
 		wantErr       bool
 		wantSaveYears []db.Year
+		wantRollovers []db.YearRollover
 	}{
 		{},
 		{ // bad year
@@ -511,13 +680,61 @@ func TestUpdateYears(t *testing.T) {
 				},
 			},
 		},
+		{ // rollover
+			form: map[string][]string{
+				"year": {
+					"2019",
+					"2020",
+					"2021",
+				},
+				"year-active":        {"2020"},
+				"year-2020-rollover": {"2019"},
+				"year-2020-keepers":  {"on"},
+				"year-2021-rollover": {"2019"},
+			},
+			wantSaveYears: []db.Year{
+				{
+					Value: 2019,
+				},
+				{
+					Value:  2020,
+					Active: true,
+				},
+				{
+					Value: 2021,
+				},
+			},
+			wantRollovers: []db.YearRollover{
+				{
+					FromYear: 2019,
+					ToYear:   2020,
+					Keepers:  true,
+				},
+				{
+					FromYear: 2019,
+					ToYear:   2021,
+				},
+			},
+		},
+		{ // bad rollover year
+			form: map[string][]string{
+				"year": {
+					"2020",
+				},
+				"year-2020-rollover": {"last year"},
+			},
+			wantErr: true,
+		},
 	}
 	for i, test := range updateYearsTests {
 		ds := mockAdminDatastore{
-			SaveYearsFunc: func(st db.SportType, futureYears []db.Year) error {
+			SaveYearsFunc: func(st db.SportType, futureYears []db.Year, rollovers []db.YearRollover) error {
 				if !reflect.DeepEqual(test.wantSaveYears, futureYears) {
 					t.Errorf("Test %v:\nwanted save years: %v\ngot: %v", i, test.wantSaveYears, futureYears)
 				}
+				if !reflect.DeepEqual(test.wantRollovers, rollovers) {
+					t.Errorf("Test %v:\nwanted rollovers: %v\ngot: %v", i, test.wantRollovers, rollovers)
+				}
 				return nil
 			},
 		}
@@ -540,6 +757,8 @@ func TestUpdateYears(t *testing.T) {
 			}
 		case gotErr != nil:
 			t.Errorf("Test %v: unexpected error: %v", i, gotErr)
+		case yearRolledOver(r) != (len(test.wantRollovers) != 0):
+			t.Errorf("Test %v: wanted the years to be rolled over: %v", i, len(test.wantRollovers) != 0)
 		}
 	}
 }
@@ -574,42 +793,55 @@ type mockSearcher struct {
 	SearchFunc func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]request.PlayerSearchResult, error)
 }
 
-func (s mockSearcher) Search(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]request.PlayerSearchResult, error) {
+func (s mockSearcher) Search(ctx context.Context, pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]request.PlayerSearchResult, error) {
 	return s.SearchFunc(pt, year, playerNamePrefix, activePlayersOnly)
 }
 
 type mockAdminDatastore struct {
-	SaveYearsFunc             func(st db.SportType, futureYears []db.Year) error
+	SaveYearsFunc             func(st db.SportType, futureYears []db.Year, rollovers []db.YearRollover) error
 	SaveFriendsFunc           func(st db.SportType, futureFriends []db.Friend) error
-	SavePlayersFunc           func(st db.SportType, futurePlayers []db.Player) error
+	SaveSubscriptionsFunc     func(st db.SportType, futureSubscriptions []db.Subscription) error
+	SavePlayersFunc           func(st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error
+	ImportRostersFunc         func(st db.SportType, newFriends []db.Friend, importedPlayers []db.ImportedPlayer, playerInfos []db.PlayerInfo) error
 	ClearStatFunc             func(st db.SportType) error
 	SetUserPasswordFunc       func(username string, p db.Password) error
 	IsCorrectUserPasswordFunc func(username string, p db.Password) (bool, error)
 }
 
-func (ds mockAdminDatastore) SaveYears(st db.SportType, futureYears []db.Year) error {
-	return ds.SaveYearsFunc(st, futureYears)
+func (ds mockAdminDatastore) SaveYears(ctx context.Context, st db.SportType, futureYears []db.Year, rollovers ...db.YearRollover) error {
+	return ds.SaveYearsFunc(st, futureYears, rollovers)
 }
-func (ds mockAdminDatastore) SaveFriends(st db.SportType, futureFriends []db.Friend) error {
+func (ds mockAdminDatastore) SaveFriends(ctx context.Context, st db.SportType, futureFriends []db.Friend) error {
 	return ds.SaveFriendsFunc(st, futureFriends)
 }
-func (ds mockAdminDatastore) SavePlayers(st db.SportType, futurePlayers []db.Player) error {
-	return ds.SavePlayersFunc(st, futurePlayers)
+func (ds mockAdminDatastore) SaveSubscriptions(ctx context.Context, st db.SportType, futureSubscriptions []db.Subscription) error {
+	return ds.SaveSubscriptionsFunc(st, futureSubscriptions)
 }
-func (ds mockAdminDatastore) ClearStat(st db.SportType) error {
+func (ds mockAdminDatastore) SavePlayers(ctx context.Context, st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error {
+	return ds.SavePlayersFunc(st, futurePlayers, playerInfos)
+}
+func (ds mockAdminDatastore) ImportRosters(ctx context.Context, st db.SportType, newFriends []db.Friend, importedPlayers []db.ImportedPlayer, playerInfos []db.PlayerInfo) error {
+	return ds.ImportRostersFunc(st, newFriends, importedPlayers, playerInfos)
+}
+func (ds mockAdminDatastore) ClearStat(ctx context.Context, st db.SportType) error {
 	return ds.ClearStatFunc(st)
 }
-func (ds mockAdminDatastore) SetUserPassword(username string, p db.Password) error {
+func (ds mockAdminDatastore) SetUserPassword(ctx context.Context, username string, p db.Password) error {
 	return ds.SetUserPasswordFunc(username, p)
 }
-func (ds mockAdminDatastore) IsCorrectUserPassword(username string, p db.Password) (bool, error) {
+func (ds mockAdminDatastore) IsCorrectUserPassword(ctx context.Context, username string, p db.Password) (bool, error) {
 	return ds.IsCorrectUserPasswordFunc(username, p)
 }
 
 type mockCache struct {
 	ClearFunc func()
+	StatsFunc func() request.CacheStats
 }
 
 func (c mockCache) Clear() {
 	c.ClearFunc()
 }
+
+func (c mockCache) Stats() request.CacheStats {
+	return c.StatsFunc()
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// PastSeason contains the champions of the ScoreCategories of a year that is no longer active.
+	PastSeason struct {
+		Year      int
+		Champions []Champion
+	}
+
+	// Champion is the friend with the highest score in a ScoreCategory.
+	// Friends that tie for the highest score are champions together.
+	Champion struct {
+		ScoreCategory string
+		FriendNames   []string
+		Score         int
+	}
+
+	archiveDatastore interface {
+		GetYears(ctx context.Context, st db.SportType) ([]db.Year, error)
+		GetYearStat(ctx context.Context, st db.SportType, year int) (*db.Stat, error)
+		SportTypes() db.SportTypeMap
+	}
+)
+
+// yearPathRE matches the paths of the pages of past years, such as "/SportType/2019" and "/SportType/2019/export"
+var yearPathRE = regexp.MustCompile("^/SportType/([0-9]{4})(/export)?$")
+
+// parseYearPath gets the year and the path after the year from the path of a page of a past year.
+func parseYearPath(path string) (year int, subPath string, ok bool) {
+	matches := yearPathRE.FindStringSubmatch(path)
+	if len(matches) != 3 {
+		return 0, "", false
+	}
+	year, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, "", false
+	}
+	return year, matches[2], true
+}
+
+// getPastEtlStats gets the saved EtlStats of a year without refreshing them.
+// Nil EtlStats are returned if the year has not been saved or if it is the active year, which is returned as active.
+// The EtlStats have no ScoreCategories if stats were never saved for the year.
+func getPastEtlStats(ctx context.Context, st db.SportType, year int, ds archiveDatastore) (es *EtlStats, active bool, err error) {
+	years, err := ds.GetYears(ctx, st)
+	if err != nil {
+		return nil, false, err
+	}
+	saved := false
+	for _, y := range years {
+		if y.Value == year {
+			saved = true
+			active = y.Active
+		}
+	}
+	if !saved || active {
+		return nil, active, nil
+	}
+	es, err = getYearEtlStats(ctx, st, year, ds)
+	return es, false, err
+}
+
+// getYearEtlStats gets the saved EtlStats of the year, nil if the year has not been saved.
+func getYearEtlStats(ctx context.Context, st db.SportType, year int, ds archiveDatastore) (*EtlStats, error) {
+	stat, err := ds.GetYearStat(ctx, st, year)
+	if err != nil || stat == nil {
+		return nil, err
+	}
+	es := EtlStats{
+		sportTypeName: ds.SportTypes()[st].Name,
+		sportType:     st,
+		year:          year,
+	}
+	if stat.EtlTimestamp != nil && len(stat.EtlJSON) != 0 {
+		if err := es.setStat(*stat); err != nil {
+			return nil, err
+		}
+	}
+	return &es, nil
+}
+
+// getPastSeasons gets the champions of the years of the SportType that are not active and have saved stats, most recent first.
+func getPastSeasons(ctx context.Context, st db.SportType, ds archiveDatastore) ([]PastSeason, error) {
+	archivedEtlStats, err := getArchivedEtlStats(ctx, st, ds)
+	if err != nil {
+		return nil, err
+	}
+	pastSeasons := make([]PastSeason, len(archivedEtlStats))
+	for i, es := range archivedEtlStats {
+		pastSeasons[i] = PastSeason{
+			Year:      es.year,
+			Champions: es.champions(),
+		}
+	}
+	return pastSeasons, nil
+}
+
+// getArchivedEtlStats gets the saved EtlStats of the years of the SportType that are not active and have saved stats, most recent first.
+func getArchivedEtlStats(ctx context.Context, st db.SportType, ds archiveDatastore) ([]EtlStats, error) {
+	years, err := ds.GetYears(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(years, func(i, j int) bool {
+		return years[i].Value > years[j].Value
+	})
+	var archivedEtlStats []EtlStats
+	for _, year := range years {
+		if year.Active {
+			continue
+		}
+		es, err := getYearEtlStats(ctx, st, year.Value, ds)
+		if err != nil {
+			return nil, err
+		}
+		if es == nil || len(es.scoreCategories) == 0 {
+			continue
+		}
+		archivedEtlStats = append(archivedEtlStats, *es)
+	}
+	return archivedEtlStats, nil
+}
+
+// champions gets the Champions of the ScoreCategories that have friends.
+func (es EtlStats) champions() []Champion {
+	var champions []Champion
+	for _, sc := range es.scoreCategories {
+		if len(sc.FriendScores) == 0 {
+			continue
+		}
+		scores := make([]int, len(sc.FriendScores))
+		for i, fs := range sc.FriendScores {
+			scores[i] = fs.Score
+		}
+		c := Champion{
+			ScoreCategory: sc.Name,
+		}
+		for i, rank := range ranks(scores) {
+			if rank == 1 {
+				c.FriendNames = append(c.FriendNames, sc.FriendScores[i].Name)
+				c.Score = scores[i]
+			}
+		}
+		champions = append(champions, c)
+	}
+	return champions
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type mockArchiveDatastore struct {
+	GetYearsFunc    func(st db.SportType) ([]db.Year, error)
+	GetYearStatFunc func(st db.SportType, year int) (*db.Stat, error)
+	SportTypesFunc  func() db.SportTypeMap
+}
+
+func (m mockArchiveDatastore) GetYears(ctx context.Context, st db.SportType) ([]db.Year, error) {
+	return m.GetYearsFunc(st)
+}
+func (m mockArchiveDatastore) GetYearStat(ctx context.Context, st db.SportType, year int) (*db.Stat, error) {
+	return m.GetYearStatFunc(st, year)
+}
+func (m mockArchiveDatastore) SportTypes() db.SportTypeMap {
+	return m.SportTypesFunc()
+}
+
+func TestParseYearPath(t *testing.T) {
+	parseYearPathTests := []struct {
+		path        string
+		wantYear    int
+		wantSubPath string
+		wantOk      bool
+	}{
+		{path: "/SportType"},
+		{path: "/SportType/admin"},
+		{path: "/SportType/201"},
+		{path: "/SportType/2019/admin"},
+		{path: "/SportType/2019/export/"},
+		{path: "/SportType/2019", wantYear: 2019, wantOk: true},
+		{path: "/SportType/2019/export", wantYear: 2019, wantSubPath: "/export", wantOk: true},
+	}
+	for i, test := range parseYearPathTests {
+		gotYear, gotSubPath, gotOk := parseYearPath(test.path)
+		switch {
+		case test.wantOk != gotOk:
+			t.Errorf("Test %v: wanted ok to be %v for %v", i, test.wantOk, test.path)
+		case test.wantYear != gotYear, test.wantSubPath != gotSubPath:
+			t.Errorf("Test %v: wanted %v and %q, got %v and %q", i, test.wantYear, test.wantSubPath, gotYear, gotSubPath)
+		}
+	}
+}
+
+func TestGetPastEtlStats(t *testing.T) {
+	getPastEtlStatsTests := []struct {
+		year                int
+		wantNil             bool
+		wantActive          bool
+		wantScoreCategories int
+	}{
+		{year: 2016, wantNil: true},
+		{year: 2017},
+		{year: 2018, wantScoreCategories: 3},
+		{year: 2019, wantNil: true, wantActive: true},
+	}
+	etlTime := time.Date(2018, time.October, 1, 12, 0, 0, 0, time.UTC)
+	ds := mockArchiveDatastore{
+		GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+			return []db.Year{{Value: 2017}, {Value: 2018}, {Value: 2019, Active: true}}, nil
+		},
+		GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+			if year == 2017 {
+				return &db.Stat{SportType: st, Year: year}, nil // no stats
+			}
+			return &db.Stat{
+				SportType:    st,
+				Year:         year,
+				EtlTimestamp: &etlTime,
+				EtlJSON:      `[{"Name":"Teams"},{"Name":"Hitting"},{"Name":"Pitching"}]`,
+				Frozen:       true,
+			}, nil
+		},
+		SportTypesFunc: func() db.SportTypeMap {
+			return db.SportTypeMap{1: {Name: "mlb"}}
+		},
+	}
+	for i, test := range getPastEtlStatsTests {
+		es, active, err := getPastEtlStats(context.Background(), 1, test.year, ds)
+		switch {
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantActive != active:
+			t.Errorf("Test %v: wanted active to be %v", i, test.wantActive)
+		case test.wantNil:
+			if es != nil {
+				t.Errorf("Test %v: wanted nil EtlStats, got %v", i, es)
+			}
+		case es == nil:
+			t.Errorf("Test %v: wanted EtlStats", i)
+		case es.year != test.year, es.sportTypeName != "mlb", es.stale:
+			t.Errorf("Test %v: unwanted EtlStats: %v", i, es)
+		case test.wantScoreCategories != len(es.scoreCategories):
+			t.Errorf("Test %v: wanted %v ScoreCategories, got %v", i, test.wantScoreCategories, len(es.scoreCategories))
+		}
+	}
+}
+
+func TestGetPastEtlStats_errors(t *testing.T) {
+	getPastEtlStatsErrorsTests := []struct {
+		getYearsErr error
+		etlJSON     string
+	}{
+		{
+			getYearsErr: errors.New("get years error"),
+		},
+		{
+			etlJSON: "{bad json}",
+		},
+	}
+	for i, test := range getPastEtlStatsErrorsTests {
+		ds := mockArchiveDatastore{
+			GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+				return []db.Year{{Value: 2018}, {Value: 2019, Active: true}}, test.getYearsErr
+			},
+			GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+				return &db.Stat{EtlTimestamp: new(time.Time), EtlJSON: test.etlJSON}, nil
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+		}
+		_, _, err := getPastEtlStats(context.Background(), 1, 2018, ds)
+		switch {
+		case err == nil:
+			t.Errorf("Test %v: wanted error", i)
+		case test.getYearsErr != nil && !errors.Is(err, test.getYearsErr):
+			t.Errorf("Test %v: wanted %v, got %v", i, test.getYearsErr, err)
+		}
+	}
+}
+
+func TestGetPastSeasons(t *testing.T) {
+	want := []PastSeason{
+		{
+			Year: 2018,
+			Champions: []Champion{
+				{ScoreCategory: "Teams", FriendNames: []string{"Arnold", "Bert"}, Score: 90},
+				{ScoreCategory: "Hitting", FriendNames: []string{"Carl"}, Score: 40},
+			},
+		},
+	}
+	etlTime := time.Date(2018, time.October, 1, 12, 0, 0, 0, time.UTC)
+	ds := mockArchiveDatastore{
+		GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+			return []db.Year{{Value: 2017}, {Value: 2018}, {Value: 2019, Active: true}}, nil
+		},
+		GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+			if year == 2017 {
+				return &db.Stat{SportType: st, Year: year}, nil // no stats
+			}
+			return &db.Stat{
+				SportType:    st,
+				Year:         year,
+				EtlTimestamp: &etlTime,
+				EtlJSON: `[{"Name":"Teams","FriendScores":[{"Name":"Arnold","Score":90},{"Name":"Bert","Score":90},{"Name":"Carl","Score":80}]},` +
+					`{"Name":"Hitting","FriendScores":[{"Name":"Arnold","Score":12},{"Name":"Carl","Score":40}]},` +
+					`{"Name":"Pitching"}]`,
+				Frozen: true,
+			}, nil
+		},
+		SportTypesFunc: func() db.SportTypeMap {
+			return db.SportTypeMap{1: {Name: "mlb"}}
+		},
+	}
+	got, err := getPastSeasons(context.Background(), 1, ds)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("past seasons not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestGetPastSeasons_order(t *testing.T) {
+	ds := mockArchiveDatastore{
+		GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+			return []db.Year{{Value: 2016}, {Value: 2018}, {Value: 2017}}, nil
+		},
+		GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+			return &db.Stat{EtlTimestamp: new(time.Time), EtlJSON: `[{"FriendScores":[{"Name":"Arnold"}]}]`}, nil
+		},
+		SportTypesFunc: func() db.SportTypeMap {
+			return db.SportTypeMap{1: {Name: "mlb"}}
+		},
+	}
+	got, err := getPastSeasons(context.Background(), 1, ds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var gotYears []int
+	for _, ps := range got {
+		gotYears = append(gotYears, ps.Year)
+	}
+	if want := []int{2018, 2017, 2016}; !reflect.DeepEqual(want, gotYears) {
+		t.Errorf("wanted years %v, got %v", want, gotYears)
+	}
+}
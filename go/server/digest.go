@@ -0,0 +1,319 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+type (
+	// Digest describes the changes to the standings of a SportType since the last digest was sent.
+	Digest struct {
+		ApplicationName string
+		SportType       string
+		Year            int
+		Categories      []DigestCategory
+		// FriendName is the name of the friend the digest is sent to.
+		FriendName string
+	}
+
+	// DigestCategory contains the standings of the friends in a ScoreCategory and the players whose scores increased the most.
+	DigestCategory struct {
+		Name      string
+		Standings []DigestStanding
+		TopMovers []DigestMover
+	}
+
+	// DigestStanding is the rank of a friend in a ScoreCategory.  The PreviousRank is zero if the friend was not ranked before.
+	DigestStanding struct {
+		FriendID     db.ID
+		Name         string
+		Score        int
+		Rank         int
+		PreviousRank int
+	}
+
+	// DigestMover is a player whose score increased since the last digest was sent.
+	DigestMover struct {
+		PlayerName string
+		FriendName string
+		Score      int
+		Gain       int
+	}
+
+	// DigestConfig describes how digests of the standings are emailed to subscribed friends.
+	DigestConfig struct {
+		// SMTPAddr is the host and port of the mail server, such as "smtp.example.com:587".  Digests are not sent if it is empty.
+		SMTPAddr string
+		// SMTPUsername is the user to authenticate to the mail server as.  No authentication is used if it is empty.
+		SMTPUsername string
+		SMTPPassword string
+		// DigestFrom is the email address digests are sent from.
+		DigestFrom string
+	}
+
+	// digestSender emails digests to subscribed friends, at most once a day for each SportType.
+	digestSender struct {
+		addr     string
+		from     string
+		auth     smtp.Auth
+		sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+		mu       sync.Mutex
+		last     map[db.SportType]sentDigest
+	}
+
+	// sentDigest is the time the last digest of a SportType was sent and the stats it was created from.
+	sentDigest struct {
+		time  time.Time
+		stats EtlStats
+	}
+
+	digestDatastore interface {
+		GetSubscriptions(ctx context.Context, st db.SportType) ([]db.Subscription, error)
+	}
+)
+
+// maxDigestMovers is the most players listed as top movers in a DigestCategory.
+const maxDigestMovers = 3
+
+var digestTemplate = template.Must(template.New("digest").Parse(`Hi {{.FriendName}},
+
+Here are the {{.ApplicationName}} {{.SportType}} standings for {{.Year}}.
+{{ range .Categories }}
+{{.Name}}
+{{ range .Standings }}  {{.Rank}}. {{.Name}} - {{.Score}}{{ with .Change }} ({{.}}){{ end }}
+{{ end }}{{ if .TopMovers }}  Top movers:
+{{ range .TopMovers }}    {{.PlayerName}} ({{.FriendName}}) +{{.Gain}} to {{.Score}}
+{{ end }}{{ end }}{{ end }}`))
+
+// newDigestSender creates a digestSender for the config, nil if digests are not sent.
+func (cfg DigestConfig) newDigestSender() (*digestSender, error) {
+	if len(cfg.SMTPAddr) == 0 {
+		return nil, nil
+	}
+	host, _, err := net.SplitHostPort(cfg.SMTPAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp address: %w", err)
+	}
+	if len(cfg.DigestFrom) == 0 {
+		return nil, fmt.Errorf("digest from address required to send digests")
+	}
+	ds := digestSender{
+		addr:     cfg.SMTPAddr,
+		from:     cfg.DigestFrom,
+		sendMail: smtp.SendMail,
+	}
+	if len(cfg.SMTPUsername) != 0 {
+		ds.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+	}
+	return &ds, nil
+}
+
+// newDigest creates a Digest of the changes from the previous EtlStats to the current ones.
+// False is returned if there are no previous stats for the year or if no friend or player changed.
+func newDigest(applicationName string, previous, current EtlStats) (*Digest, bool) {
+	if previous.etlTime.IsZero() || previous.year != current.year {
+		return nil, false
+	}
+	d := Digest{
+		ApplicationName: applicationName,
+		SportType:       current.sportTypeName,
+		Year:            current.year,
+	}
+	changed := false
+	for _, sc := range current.scoreCategories {
+		if len(sc.FriendScores) == 0 {
+			continue
+		}
+		previousSC, _, _ := previous.scoreCategory(sc.PlayerType)
+		dc := newDigestCategory(previousSC, sc)
+		for _, s := range dc.Standings {
+			if s.Rank != s.PreviousRank {
+				changed = true
+			}
+		}
+		if len(dc.TopMovers) != 0 {
+			changed = true
+		}
+		d.Categories = append(d.Categories, dc)
+	}
+	return &d, changed
+}
+
+// newDigestCategory creates the standings and top movers of the ScoreCategory, matching friends and players by their ids.
+func newDigestCategory(previous, current request.ScoreCategory) DigestCategory {
+	dc := DigestCategory{
+		Name: current.Name,
+	}
+	previousRanks := friendRanks(previous)
+	previousPlayerScores := make(map[db.ID]int)
+	for _, fs := range previous.FriendScores {
+		for _, ps := range fs.PlayerScores {
+			previousPlayerScores[ps.ID] = ps.Score
+		}
+	}
+	currentRanks := friendRanks(current)
+	for _, fs := range current.FriendScores {
+		dc.Standings = append(dc.Standings, DigestStanding{
+			FriendID:     fs.ID,
+			Name:         fs.Name,
+			Score:        fs.Score,
+			Rank:         currentRanks[fs.ID],
+			PreviousRank: previousRanks[fs.ID],
+		})
+		for _, ps := range fs.PlayerScores {
+			previousScore, ok := previousPlayerScores[ps.ID]
+			if gain := ps.Score - previousScore; ok && gain > 0 {
+				dc.TopMovers = append(dc.TopMovers, DigestMover{
+					PlayerName: ps.Name,
+					FriendName: fs.Name,
+					Score:      ps.Score,
+					Gain:       gain,
+				})
+			}
+		}
+	}
+	sort.SliceStable(dc.Standings, func(i, j int) bool {
+		return dc.Standings[i].Rank < dc.Standings[j].Rank
+	})
+	sort.SliceStable(dc.TopMovers, func(i, j int) bool {
+		return dc.TopMovers[i].Gain > dc.TopMovers[j].Gain
+	})
+	if len(dc.TopMovers) > maxDigestMovers {
+		dc.TopMovers = dc.TopMovers[:maxDigestMovers]
+	}
+	return dc
+}
+
+// friendRanks gets the ranks of the friends in the ScoreCategory, keyed by their ids.
+func friendRanks(sc request.ScoreCategory) map[db.ID]int {
+	scores := make([]int, len(sc.FriendScores))
+	for i, fs := range sc.FriendScores {
+		scores[i] = fs.Score
+	}
+	m := make(map[db.ID]int, len(scores))
+	for i, rank := range ranks(scores) {
+		m[sc.FriendScores[i].ID] = rank
+	}
+	return m
+}
+
+// Change describes how the rank of the friend changed, such as "up 2".  It is empty if the rank did not change.
+func (s DigestStanding) Change() string {
+	switch {
+	case s.PreviousRank == 0:
+		return "new"
+	case s.Rank < s.PreviousRank:
+		return fmt.Sprintf("up %d", s.PreviousRank-s.Rank)
+	case s.Rank > s.PreviousRank:
+		return fmt.Sprintf("down %d", s.Rank-s.PreviousRank)
+	}
+	return ""
+}
+
+// next creates the digest of the changes to the standings since the last digest of the SportType was sent, or since the previous stats if none was sent this year.
+// The returned bool is false if a digest of the SportType was already sent on the day of the time or if the standings did not change.
+func (sender *digestSender) next(applicationName string, previous, current EtlStats, now time.Time) (*Digest, bool) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	last, ok := sender.last[current.sportType]
+	if ok {
+		y1, m1, d1 := last.time.Date()
+		y2, m2, d2 := now.Date()
+		if y1 == y2 && m1 == m2 && d1 == d2 {
+			return nil, false
+		}
+		if last.stats.year == current.year {
+			previous = last.stats
+		}
+	}
+	d, changed := newDigest(applicationName, previous, current)
+	if !changed {
+		return nil, false
+	}
+	if sender.last == nil {
+		sender.last = make(map[db.SportType]sentDigest)
+	}
+	sender.last[current.sportType] = sentDigest{time: now, stats: current}
+	return d, true
+}
+
+// send emails the digest to each subscribed friend that has a standing in it.
+// Every subscription is attempted.  The errors of the messages that could not be sent are joined.
+func (sender *digestSender) send(ctx context.Context, ds digestDatastore, st db.SportType, d Digest, sendTime time.Time) error {
+	subscriptions, err := ds.GetSubscriptions(ctx, st)
+	if err != nil {
+		return err
+	}
+	friendNames := make(map[db.ID]string)
+	for _, dc := range d.Categories {
+		for _, s := range dc.Standings {
+			friendNames[s.FriendID] = s.Name
+		}
+	}
+	var errs []error
+	for _, subscription := range subscriptions {
+		friendName, ok := friendNames[subscription.FriendID]
+		if !ok {
+			continue // the friend is no longer in the standings
+		}
+		d.FriendName = friendName
+		msg, err := sender.message(subscription.Email, d, sendTime)
+		if err == nil {
+			err = sender.sendMail(sender.addr, sender.auth, sender.from, []string{subscription.Email}, msg)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sending digest to %v: %w", friendName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// message creates the email of the digest to the address.
+func (sender *digestSender) message(to string, d Digest, sendTime time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, d); err != nil {
+		return nil, fmt.Errorf("rendering digest: %w", err)
+	}
+	subject := fmt.Sprintf("%s %s standings - %d", d.ApplicationName, d.SportType, d.Year)
+	headers := []string{
+		"From: " + sender.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + sendTime.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	var msg bytes.Buffer
+	msg.WriteString(strings.Join(headers, "\r\n"))
+	msg.WriteString("\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+// sendDigest emails a digest of the changes to the standings to the subscribed friends if digests are configured.
+func (s Server) sendDigest(ctx context.Context, previous, current EtlStats) {
+	if s.digests == nil {
+		return
+	}
+	now := s.ds.GetUtcTime()
+	d, ok := s.digests.next(s.DisplayName, previous, current, now)
+	if !ok {
+		return
+	}
+	if err := s.digests.send(ctx, s.ds, current.sportType, *d, now); err != nil {
+		s.log.Printf("sending %v digests: %v", current.sportTypeName, err)
+	}
+}
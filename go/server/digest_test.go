@@ -0,0 +1,425 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/smtp"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/mockserver"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+type mockDigestDatastore struct {
+	GetSubscriptionsFunc func(st db.SportType) ([]db.Subscription, error)
+}
+
+func (m mockDigestDatastore) GetSubscriptions(ctx context.Context, st db.SportType) ([]db.Subscription, error) {
+	return m.GetSubscriptionsFunc(st)
+}
+
+func TestNewDigest(t *testing.T) {
+	previous := EtlStats{
+		etlTime:       time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		sportTypeName: "mlb",
+		year:          2019,
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name:       "Hitting",
+				PlayerType: 2,
+				FriendScores: []request.FriendScore{
+					{ID: "1", Name: "Arnold", Score: 10, PlayerScores: []request.PlayerScore{{ID: "11", Name: "Harper", Score: 10}}},
+					{ID: "2", Name: "Bert", Score: 8, PlayerScores: []request.PlayerScore{{ID: "21", Name: "Trout", Score: 5}, {ID: "22", Name: "Judge", Score: 3}}},
+				},
+			},
+		},
+	}
+	current := EtlStats{
+		etlTime:       time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		sportTypeName: "mlb",
+		year:          2019,
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name:       "Hitting",
+				PlayerType: 2,
+				FriendScores: []request.FriendScore{
+					{ID: "1", Name: "Arnold", Score: 11, PlayerScores: []request.PlayerScore{{ID: "11", Name: "Harper", Score: 11}}},
+					{ID: "2", Name: "Bert", Score: 14, PlayerScores: []request.PlayerScore{{ID: "21", Name: "Trout", Score: 9}, {ID: "22", Name: "Judge", Score: 5}}},
+					{ID: "3", Name: "Carl", Score: 0},
+				},
+			},
+			{
+				Name:       "Pitching",
+				PlayerType: 3,
+			},
+		},
+	}
+	want := &Digest{
+		ApplicationName: "nate",
+		SportType:       "mlb",
+		Year:            2019,
+		Categories: []DigestCategory{
+			{
+				Name: "Hitting",
+				Standings: []DigestStanding{
+					{FriendID: "2", Name: "Bert", Score: 14, Rank: 1, PreviousRank: 2},
+					{FriendID: "1", Name: "Arnold", Score: 11, Rank: 2, PreviousRank: 1},
+					{FriendID: "3", Name: "Carl", Score: 0, Rank: 3},
+				},
+				TopMovers: []DigestMover{
+					{PlayerName: "Trout", FriendName: "Bert", Score: 9, Gain: 4},
+					{PlayerName: "Judge", FriendName: "Bert", Score: 5, Gain: 2},
+					{PlayerName: "Harper", FriendName: "Arnold", Score: 11, Gain: 1},
+				},
+			},
+		},
+	}
+	got, changed := newDigest("nate", previous, current)
+	switch {
+	case !changed:
+		t.Errorf("wanted digest to be changed")
+	case !reflect.DeepEqual(want, got):
+		t.Errorf("digests not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestNewDigest_unchanged(t *testing.T) {
+	previous := EtlStats{
+		etlTime: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 10}, {ID: "2", Name: "Bert", Score: 8}}},
+		},
+	}
+	current := EtlStats{
+		etlTime: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 11}, {ID: "2", Name: "Bert", Score: 14}}},
+		},
+	}
+	newYear := current
+	newYear.year = 2020
+	newDigestTests := []struct {
+		previous EtlStats
+		current  EtlStats
+	}{
+		{ // no previous stats
+			current: current,
+		},
+		{ // the scores of the previous year are not compared
+			previous: previous,
+			current:  newYear,
+		},
+		{ // no changes
+			previous: current,
+			current:  current,
+		},
+	}
+	for i, test := range newDigestTests {
+		if _, changed := newDigest("nate", test.previous, test.current); changed {
+			t.Errorf("Test %v: wanted digest to not be changed", i)
+		}
+	}
+}
+
+func TestDigestStandingChange(t *testing.T) {
+	changeTests := []struct {
+		rank         int
+		previousRank int
+		want         string
+	}{
+		{rank: 1, previousRank: 1},
+		{rank: 1, previousRank: 3, want: "up 2"},
+		{rank: 4, previousRank: 3, want: "down 1"},
+		{rank: 2, want: "new"},
+	}
+	for i, test := range changeTests {
+		s := DigestStanding{Rank: test.rank, PreviousRank: test.previousRank}
+		if got := s.Change(); test.want != got {
+			t.Errorf("Test %v: wanted %q, got %q", i, test.want, got)
+		}
+	}
+}
+
+func TestNewDigestSender(t *testing.T) {
+	newDigestSenderTests := []struct {
+		cfg      DigestConfig
+		wantNil  bool
+		wantAuth bool
+		wantErr  bool
+	}{
+		{
+			wantNil: true,
+		},
+		{
+			cfg:     DigestConfig{SMTPAddr: "smtp.example.com", DigestFrom: "stats@example.com"},
+			wantErr: true, // no port
+		},
+		{
+			cfg:     DigestConfig{SMTPAddr: "smtp.example.com:587"},
+			wantErr: true,
+		},
+		{
+			cfg: DigestConfig{SMTPAddr: "smtp.example.com:587", DigestFrom: "stats@example.com"},
+		},
+		{
+			cfg:      DigestConfig{SMTPAddr: "smtp.example.com:587", SMTPUsername: "user", SMTPPassword: "secret", DigestFrom: "stats@example.com"},
+			wantAuth: true,
+		},
+	}
+	for i, test := range newDigestSenderTests {
+		got, err := test.cfg.newDigestSender()
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantNil:
+			if got != nil {
+				t.Errorf("Test %v: wanted no digest sender, got %v", i, got)
+			}
+		case got == nil:
+			t.Errorf("Test %v: wanted digest sender", i)
+		case got.addr != test.cfg.SMTPAddr, got.from != test.cfg.DigestFrom, test.wantAuth != (got.auth != nil):
+			t.Errorf("Test %v: unwanted digest sender for %v: %v", i, test.cfg, got)
+		}
+	}
+}
+
+func TestDigestSenderSend(t *testing.T) {
+	smtpServer, err := mockserver.NewSMTPServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go smtpServer.Serve()
+	defer smtpServer.Close()
+	cfg := DigestConfig{
+		SMTPAddr:     smtpServer.Addr(),
+		SMTPUsername: "user",
+		SMTPPassword: "secret",
+		DigestFrom:   "stats@example.com",
+	}
+	sender, err := cfg.newDigestSender()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := mockDigestDatastore{
+		GetSubscriptionsFunc: func(st db.SportType) ([]db.Subscription, error) {
+			return []db.Subscription{
+				{FriendID: "2", Email: "bert@example.com"},
+				{FriendID: "9", Email: "removed@example.com"}, // not in the standings
+			}, nil
+		},
+	}
+	d := Digest{
+		ApplicationName: "nate",
+		SportType:       "mlb",
+		Year:            2019,
+		Categories: []DigestCategory{
+			{
+				Name: "Hitting",
+				Standings: []DigestStanding{
+					{FriendID: "2", Name: "Bert", Score: 14, Rank: 1, PreviousRank: 2},
+					{FriendID: "1", Name: "Arnold", Score: 11, Rank: 2, PreviousRank: 1},
+					{FriendID: "3", Name: "Carl", Score: 0, Rank: 3},
+				},
+				TopMovers: []DigestMover{
+					{PlayerName: "Trout", FriendName: "Bert", Score: 9, Gain: 4},
+					{PlayerName: "Judge", FriendName: "Bert", Score: 5, Gain: 2},
+					{PlayerName: "Harper", FriendName: "Arnold", Score: 11, Gain: 1},
+				},
+			},
+		},
+	}
+	sendTime := time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC)
+	if err := sender.send(context.Background(), ds, 1, d, sendTime); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	messages := smtpServer.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("wanted 1 message, got %v", len(messages))
+	}
+	m := messages[0]
+	wantBody := `Hi Bert,
+
+Here are the nate mlb standings for 2019.
+
+Hitting
+  1. Bert - 14 (up 1)
+  2. Arnold - 11 (down 1)
+  3. Carl - 0 (new)
+  Top movers:
+    Trout (Bert) +4 to 9
+    Judge (Bert) +2 to 5
+    Harper (Arnold) +1 to 11
+`
+	_, gotBody, _ := strings.Cut(m.Data, "\n\n")
+	switch {
+	case m.From != "stats@example.com", !reflect.DeepEqual([]string{"bert@example.com"}, m.To):
+		t.Errorf("unwanted sender or recipients: %v, %v", m.From, m.To)
+	case !strings.Contains(m.Data, "Subject: nate mlb standings - 2019\n"):
+		t.Errorf("wanted subject in message: %q", m.Data)
+	case wantBody != gotBody:
+		t.Errorf("bodies not equal:\nwanted: %q\ngot:    %q", wantBody, gotBody)
+	}
+}
+
+func TestDigestSenderSend_errors(t *testing.T) {
+	d := Digest{
+		Categories: []DigestCategory{
+			{Standings: []DigestStanding{{FriendID: "1", Name: "Arnold"}, {FriendID: "2", Name: "Bert"}}},
+		},
+	}
+	getSubscriptionsErr := errors.New("get subscriptions error")
+	ds := mockDigestDatastore{
+		GetSubscriptionsFunc: func(st db.SportType) ([]db.Subscription, error) {
+			return nil, getSubscriptionsErr
+		},
+	}
+	sender := digestSender{}
+	if err := sender.send(context.Background(), ds, 1, d, time.Time{}); !errors.Is(err, getSubscriptionsErr) {
+		t.Errorf("wanted %v, got %v", getSubscriptionsErr, err)
+	}
+	ds.GetSubscriptionsFunc = func(st db.SportType) ([]db.Subscription, error) {
+		return []db.Subscription{{FriendID: "1", Email: "arnold@example.com"}, {FriendID: "2", Email: "bert@example.com"}}, nil
+	}
+	sendMailErr := errors.New("send mail error")
+	var sentTo []string
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo = append(sentTo, to...)
+		return sendMailErr
+	}
+	err := sender.send(context.Background(), ds, 1, d, time.Time{})
+	switch {
+	case !errors.Is(err, sendMailErr):
+		t.Errorf("wanted %v, got %v", sendMailErr, err)
+	case len(sentTo) != 2:
+		t.Errorf("wanted digest to be sent to each friend after failures, got %v", sentTo)
+	}
+}
+
+func TestDigestSenderNext(t *testing.T) {
+	day := time.Date(2019, time.June, 2, 6, 0, 0, 0, time.UTC)
+	previous := EtlStats{
+		etlTime: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 10}, {ID: "2", Name: "Bert", Score: 8}}},
+		},
+	}
+	current := EtlStats{
+		etlTime: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 11}, {ID: "2", Name: "Bert", Score: 14}}},
+		},
+	}
+	lastYear := previous
+	lastYear.year = 2018
+	nextTests := []struct {
+		last             *sentDigest
+		previous         EtlStats
+		now              time.Time
+		wantOk           bool
+		wantPreviousRank int
+	}{
+		{
+			previous:         previous,
+			now:              day,
+			wantOk:           true,
+			wantPreviousRank: 2,
+		},
+		{ // unchanged
+			previous: current,
+			now:      day,
+		},
+		{ // already sent today
+			last:     &sentDigest{time: day.Add(-5 * time.Hour), stats: previous},
+			previous: previous,
+			now:      day,
+		},
+		{ // the changes since the last digest are sent, not the changes since the previous refresh
+			last:             &sentDigest{time: day.Add(-24 * time.Hour), stats: previous},
+			previous:         current,
+			now:              day,
+			wantOk:           true,
+			wantPreviousRank: 2,
+		},
+		{ // the last digest of the previous year is not compared
+			last:             &sentDigest{time: day.Add(-24 * time.Hour), stats: lastYear},
+			previous:         previous,
+			now:              day,
+			wantOk:           true,
+			wantPreviousRank: 2,
+		},
+	}
+	for i, test := range nextTests {
+		var sender digestSender
+		if test.last != nil {
+			sender.last = map[db.SportType]sentDigest{current.sportType: *test.last}
+		}
+		d, ok := sender.next("nate", test.previous, current, test.now)
+		switch {
+		case test.wantOk != ok:
+			t.Errorf("Test %v: wanted ok: %v, got %v", i, test.wantOk, ok)
+		case !ok:
+		case d.Categories[0].Standings[0].PreviousRank != test.wantPreviousRank:
+			t.Errorf("Test %v: wanted previous rank of leader to be %v, got %v", i, test.wantPreviousRank, d.Categories[0].Standings[0])
+		case !sender.last[current.sportType].time.Equal(test.now):
+			t.Errorf("Test %v: wanted the time of the digest to be kept", i)
+		}
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	previous := EtlStats{
+		etlTime: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 10}}},
+		},
+	}
+	current := EtlStats{
+		etlTime: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 11}, {ID: "3", Name: "Carl", Score: 0}}},
+		},
+	}
+	var sentTo []string
+	s := Server{
+		Config: Config{DisplayName: "nate"},
+		log:    log.New(io.Discard, "test", log.LstdFlags),
+		ds: mockServerDatastore{
+			GetSubscriptionsFunc: func(st db.SportType) ([]db.Subscription, error) {
+				return []db.Subscription{{FriendID: "3", Email: "carl@example.com"}}, nil
+			},
+			etlDatastore: mockEtlDatastore{
+				GetUtcTimeFunc: func() time.Time {
+					return time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC)
+				},
+			},
+		},
+		digests: &digestSender{
+			sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				sentTo = append(sentTo, to...)
+				return nil
+			},
+		},
+	}
+	s.sendDigest(context.Background(), current, current) // unchanged
+	s.sendDigest(context.Background(), previous, current)
+	s.sendDigest(context.Background(), previous, current) // already sent today
+	if want := []string{"carl@example.com"}; !reflect.DeepEqual(want, sentTo) {
+		t.Errorf("wanted digest to be sent once to %v, got %v", want, sentTo)
+	}
+}
@@ -0,0 +1,481 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// DraftTab provides the friends and player types that can be used in a draft.
+	DraftTab struct {
+		Friends     []db.Friend
+		PlayerTypes []DraftPlayerType
+	}
+
+	// DraftPlayerType is a PlayerType that players can be drafted for.
+	DraftPlayerType struct {
+		PlayerType db.PlayerType
+		Name       string
+	}
+
+	// DraftPick is a player selected by a friend during a draft.
+	DraftPick struct {
+		Round      int
+		FriendID   db.ID
+		FriendName string
+		PlayerType db.PlayerType
+		SourceID   db.SourceID
+		PlayerName string
+		Position   string
+		Team       string
+		Skipped    bool
+	}
+
+	// DraftBoard is the state of a draft that is shown to the participants.
+	DraftBoard struct {
+		Started           bool
+		Order             string
+		Round             int
+		Rounds            int
+		Done              bool
+		Paused            bool
+		FriendNames       []string
+		CurrentFriendID   db.ID
+		CurrentFriendName string
+		SecondsLeft       int
+		Picks             []DraftPick
+	}
+
+	// draft is a live draft of players for the friends of a SportType.
+	draft struct {
+		order          string
+		friends        []db.Friend
+		rounds         int
+		pickDuration   time.Duration
+		turnStart      time.Time
+		picks          []DraftPick
+		takenSourceIDs map[db.PlayerType]map[db.SourceID]bool
+	}
+
+	// draftRoom holds the live drafts for each SportType.
+	draftRoom struct {
+		mu     sync.Mutex
+		drafts map[db.SportType]*draft
+	}
+
+	draftDatastore interface {
+		GetFriends(ctx context.Context, st db.SportType) ([]db.Friend, error)
+		GetPlayers(ctx context.Context, st db.SportType) ([]db.Player, error)
+		PlayerTypes() db.PlayerTypeMap
+		GetUtcTime() time.Time
+		adminDatastore
+	}
+)
+
+const (
+	draftOrderSnake  = "snake"
+	draftOrderLinear = "linear"
+)
+
+var draftFriendOrderRE = regexp.MustCompile("^friend-(.+)-draft-order$")
+
+func newDraftRoom() *draftRoom {
+	return &draftRoom{
+		drafts: make(map[db.SportType]*draft),
+	}
+}
+
+// GetName implements the Tab interface for DraftTab
+func (DraftTab) GetName() string {
+	return "Draft"
+}
+
+// GetID implements the Tab interface for DraftTab
+func (dt DraftTab) GetID() string {
+	return jsID(dt.GetName())
+}
+
+func newDraftTab(ctx context.Context, st db.SportType, ds draftDatastore) (DraftTab, error) {
+	var dt DraftTab
+	friends, err := ds.GetFriends(ctx, st)
+	if err != nil {
+		return dt, err
+	}
+	sort.Slice(friends, func(i, j int) bool {
+		return friends[i].DisplayOrder < friends[j].DisplayOrder
+	})
+	dt.Friends = friends
+	playerTypes := ds.PlayerTypes()
+	for pt, ptInfo := range playerTypes {
+		if ptInfo.SportType == st {
+			dt.PlayerTypes = append(dt.PlayerTypes, DraftPlayerType{PlayerType: pt, Name: ptInfo.Name})
+		}
+	}
+	sort.Slice(dt.PlayerTypes, func(i, j int) bool {
+		return playerTypes[dt.PlayerTypes[i].PlayerType].DisplayOrder < playerTypes[dt.PlayerTypes[j].PlayerType].DisplayOrder
+	})
+	return dt, nil
+}
+
+func newDraft(order string, friends []db.Friend, rounds int, pickDuration time.Duration, startTime time.Time, players []db.Player) (*draft, error) {
+	switch {
+	case order != draftOrderSnake && order != draftOrderLinear:
+		return nil, fmt.Errorf("invalid draft order: %q", order)
+	case len(friends) == 0:
+		return nil, fmt.Errorf("friends required to start draft")
+	case rounds <= 0:
+		return nil, fmt.Errorf("draft must have at least one round: %v", rounds)
+	case pickDuration < 0:
+		return nil, fmt.Errorf("pick duration must not be negative: %v", pickDuration)
+	}
+	takenSourceIDs := make(map[db.PlayerType]map[db.SourceID]bool)
+	for _, player := range players {
+		if player.DropDate != nil {
+			continue // dropped players can be drafted again
+		}
+		if _, ok := takenSourceIDs[player.PlayerType]; !ok {
+			takenSourceIDs[player.PlayerType] = make(map[db.SourceID]bool)
+		}
+		takenSourceIDs[player.PlayerType][player.SourceID] = true
+	}
+	d := draft{
+		order:          order,
+		friends:        friends,
+		rounds:         rounds,
+		pickDuration:   pickDuration,
+		turnStart:      startTime,
+		takenSourceIDs: takenSourceIDs,
+	}
+	return &d, nil
+}
+
+// currentRound is the zero-based round of the next pick
+func (d draft) currentRound() int {
+	return len(d.picks) / len(d.friends)
+}
+
+// currentFriend is the friend who makes the next pick
+func (d draft) currentFriend() db.Friend {
+	i := len(d.picks) % len(d.friends)
+	if d.order == draftOrderSnake && d.currentRound()%2 == 1 {
+		i = len(d.friends) - 1 - i
+	}
+	return d.friends[i]
+}
+
+// done determines whether every friend has picked or skipped in the last round
+func (d draft) done() bool {
+	return len(d.picks) >= d.rounds*len(d.friends)
+}
+
+// paused determines whether the pick timer is stopped because every friend skipped their last turn in a row.
+// The timer starts again after the next pick.
+func (d draft) paused() bool {
+	n := len(d.friends)
+	if d.pickDuration <= 0 || len(d.picks) < n {
+		return false
+	}
+	for _, pick := range d.picks[len(d.picks)-n:] {
+		if !pick.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// skipExpiredTurns skips the picks of friends whose turns ran out of time.
+// Turns are not skipped after the last round or after every friend has skipped in a row, so an idle draft stops growing.
+func (d *draft) skipExpiredTurns(now time.Time) {
+	if d.pickDuration <= 0 {
+		return
+	}
+	for turnEnd := d.turnStart.Add(d.pickDuration); !now.Before(turnEnd) && !d.done() && !d.paused(); turnEnd = d.turnStart.Add(d.pickDuration) {
+		friend := d.currentFriend()
+		d.picks = append(d.picks, DraftPick{
+			Round:      d.currentRound() + 1,
+			FriendID:   friend.ID,
+			FriendName: friend.Name,
+			Skipped:    true,
+		})
+		d.turnStart = turnEnd
+	}
+}
+
+func (d *draft) pick(now time.Time, playerInfo db.PlayerInfo) error {
+	d.skipExpiredTurns(now)
+	if d.done() {
+		return fmt.Errorf("all %v rounds of the draft have been picked", d.rounds)
+	}
+	pt, sourceID := playerInfo.PlayerType, playerInfo.SourceID
+	if d.takenSourceIDs[pt][sourceID] {
+		return fmt.Errorf("%v (%v) has already been taken", playerInfo.Name, sourceID)
+	}
+	if _, ok := d.takenSourceIDs[pt]; !ok {
+		d.takenSourceIDs[pt] = make(map[db.SourceID]bool)
+	}
+	d.takenSourceIDs[pt][sourceID] = true
+	friend := d.currentFriend()
+	d.picks = append(d.picks, DraftPick{
+		Round:      d.currentRound() + 1,
+		FriendID:   friend.ID,
+		FriendName: friend.Name,
+		PlayerType: pt,
+		SourceID:   sourceID,
+		PlayerName: playerInfo.Name,
+		Position:   playerInfo.Position,
+		Team:       playerInfo.Team,
+	})
+	d.turnStart = now
+	return nil
+}
+
+func (d *draft) board(now time.Time) DraftBoard {
+	d.skipExpiredTurns(now)
+	friendNames := make([]string, len(d.friends))
+	for i, f := range d.friends {
+		friendNames[i] = f.Name
+	}
+	picks := make([]DraftPick, len(d.picks))
+	copy(picks, d.picks)
+	b := DraftBoard{
+		Started:     true,
+		Order:       d.order,
+		Round:       d.currentRound() + 1,
+		Rounds:      d.rounds,
+		FriendNames: friendNames,
+		Picks:       picks,
+	}
+	if d.done() {
+		b.Round = d.rounds
+		b.Done = true
+		return b
+	}
+	friend := d.currentFriend()
+	b.CurrentFriendID = friend.ID
+	b.CurrentFriendName = friend.Name
+	b.Paused = d.paused()
+	if d.pickDuration > 0 && !b.Paused {
+		b.SecondsLeft = int(d.turnStart.Add(d.pickDuration).Sub(now).Seconds())
+	}
+	return b
+}
+
+// players adds the drafted players to the existing players.
+func (d draft) players(existingPlayers []db.Player) []db.Player {
+	draftedPlayers := make([]db.Player, 0, len(d.picks))
+	for i, pick := range d.picks {
+		if pick.Skipped {
+			continue
+		}
+		draftedPlayers = append(draftedPlayers, db.Player{
+			ID:         db.ID(fmt.Sprintf("draft-%d", i)), // not an existing id, so it will be added
+			PlayerType: pick.PlayerType,
+			SourceID:   pick.SourceID,
+			FriendID:   pick.FriendID,
+		})
+	}
+	return appendPlayers(existingPlayers, draftedPlayers)
+}
+
+// appendPlayers adds the new players to the existing players.
+// New players are added after the existing players of the same friend and player type, in order.
+func appendPlayers(existingPlayers, newPlayers []db.Player) []db.Player {
+	type friendPlayerType struct {
+		friendID db.ID
+		pt       db.PlayerType
+	}
+	displayOrders := make(map[friendPlayerType]int)
+	players := make([]db.Player, len(existingPlayers), len(existingPlayers)+len(newPlayers))
+	copy(players, existingPlayers)
+	for _, player := range existingPlayers {
+		fpt := friendPlayerType{friendID: player.FriendID, pt: player.PlayerType}
+		if player.DisplayOrder > displayOrders[fpt] {
+			displayOrders[fpt] = player.DisplayOrder
+		}
+	}
+	for _, player := range newPlayers {
+		fpt := friendPlayerType{friendID: player.FriendID, pt: player.PlayerType}
+		displayOrders[fpt]++
+		player.DisplayOrder = displayOrders[fpt]
+		players = append(players, player)
+	}
+	return players
+}
+
+// playerInfos are the infos of the drafted players that have names.
+func (d draft) playerInfos() []db.PlayerInfo {
+	var playerInfos []db.PlayerInfo
+	for _, pick := range d.picks {
+		if pick.Skipped || len(pick.PlayerName) == 0 {
+			continue
+		}
+		playerInfos = append(playerInfos, db.PlayerInfo{
+			PlayerType: pick.PlayerType,
+			SourceID:   pick.SourceID,
+			Name:       pick.PlayerName,
+			Position:   pick.Position,
+			Team:       pick.Team,
+		})
+	}
+	return playerInfos
+}
+
+func (dr *draftRoom) board(st db.SportType, now time.Time) DraftBoard {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	d, ok := dr.drafts[st]
+	if !ok {
+		return DraftBoard{}
+	}
+	return d.board(now)
+}
+
+func handleDraftPostRequest(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error {
+	if err := verifyUserPassword(ds, r); err != nil {
+		return err
+	}
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	actionParam := r.FormValue("action")
+	var draftAction func(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error
+	switch actionParam {
+	case "start":
+		draftAction = startDraft
+	case "pick":
+		draftAction = pickDraftPlayer
+	case "finalize":
+		draftAction = finalizeDraft
+	case "cancel":
+		draftAction = cancelDraft
+	default:
+		return fmt.Errorf("invalid draft action: %v", actionParam)
+	}
+	return draftAction(ds, dr, st, r)
+}
+
+func startDraft(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error {
+	if _, ok := dr.drafts[st]; ok {
+		return fmt.Errorf("draft already started")
+	}
+	friends, err := getDraftFriends(ds, st, r)
+	if err != nil {
+		return err
+	}
+	rounds := r.FormValue("rounds")
+	roundsI, err := strconv.Atoi(rounds)
+	if err != nil {
+		return fmt.Errorf("converting draft rounds '%v' to number: %w", rounds, err)
+	}
+	pickSeconds := r.FormValue("pick-seconds")
+	pickSecondsI := 0
+	if len(pickSeconds) != 0 {
+		pickSecondsI, err = strconv.Atoi(pickSeconds)
+		if err != nil {
+			return fmt.Errorf("converting pick seconds '%v' to number: %w", pickSeconds, err)
+		}
+	}
+	pickDuration := time.Duration(pickSecondsI) * time.Second
+	players, err := ds.GetPlayers(r.Context(), st)
+	if err != nil {
+		return err
+	}
+	order := r.FormValue("order")
+	d, err := newDraft(order, friends, roundsI, pickDuration, ds.GetUtcTime(), players)
+	if err != nil {
+		return err
+	}
+	dr.drafts[st] = d
+	return nil
+}
+
+// getDraftFriends gets the friends in the order they will make picks in the first round.
+func getDraftFriends(ds draftDatastore, st db.SportType, r *http.Request) ([]db.Friend, error) {
+	friends, err := ds.GetFriends(r.Context(), st)
+	if err != nil {
+		return nil, err
+	}
+	friendsByID := make(map[db.ID]db.Friend, len(friends))
+	for _, friend := range friends {
+		friendsByID[friend.ID] = friend
+	}
+	draftOrders := make(map[db.ID]int)
+	var draftFriends []db.Friend
+	for k, v := range r.Form {
+		if matches := draftFriendOrderRE.FindStringSubmatch(k); len(matches) > 1 {
+			friendID := db.ID(matches[1])
+			friend, ok := friendsByID[friendID]
+			if !ok {
+				return nil, fmt.Errorf("no friend with id %v", friendID)
+			}
+			draftOrder, err := strconv.Atoi(v[0])
+			if err != nil {
+				return nil, fmt.Errorf("converting friend draft order '%v' to number: %w", v[0], err)
+			}
+			draftOrders[friendID] = draftOrder
+			draftFriends = append(draftFriends, friend)
+		}
+	}
+	sort.Slice(draftFriends, func(i, j int) bool {
+		return draftOrders[draftFriends[i].ID] < draftOrders[draftFriends[j].ID]
+	})
+	return draftFriends, nil
+}
+
+func pickDraftPlayer(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error {
+	d, ok := dr.drafts[st]
+	if !ok {
+		return fmt.Errorf("draft not started")
+	}
+	playerType := r.FormValue("player-type")
+	playerTypeI, err := strconv.Atoi(playerType)
+	if err != nil {
+		return fmt.Errorf("converting player type '%v' to number: %w", playerType, err)
+	}
+	pt := db.PlayerType(playerTypeI)
+	if ptInfo, ok := ds.PlayerTypes()[pt]; !ok || ptInfo.SportType != st {
+		return fmt.Errorf("cannot draft player with PlayerType %v for SportType %v", pt, st)
+	}
+	sourceID := r.FormValue("source-id")
+	sourceIDI, err := strconv.Atoi(sourceID)
+	if err != nil {
+		return fmt.Errorf("converting player source id '%v' to number: %w", sourceID, err)
+	}
+	playerInfo := db.PlayerInfo{
+		PlayerType: pt,
+		SourceID:   db.SourceID(sourceIDI),
+		Name:       r.FormValue("player-name"),
+		Position:   r.FormValue("player-position"),
+		Team:       r.FormValue("player-team"),
+	}
+	return d.pick(ds.GetUtcTime(), playerInfo)
+}
+
+func finalizeDraft(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error {
+	d, ok := dr.drafts[st]
+	if !ok {
+		return fmt.Errorf("draft not started")
+	}
+	players, err := ds.GetPlayers(r.Context(), st)
+	if err != nil {
+		return err
+	}
+	// the infos are saved with the players so drafted players have names when their stats cannot be requested
+	if err := ds.SavePlayers(r.Context(), st, d.players(players), d.playerInfos()); err != nil {
+		return err
+	}
+	delete(dr.drafts, st)
+	return ds.ClearStat(r.Context(), st)
+}
+
+func cancelDraft(ds draftDatastore, dr *draftRoom, st db.SportType, r *http.Request) error {
+	delete(dr.drafts, st)
+	return nil
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+func TestNewDraft(t *testing.T) {
+	newDraftTests := []struct {
+		order        string
+		friends      []db.Friend
+		rounds       int
+		pickDuration time.Duration
+		wantErr      bool
+	}{
+		{
+			rounds:  1,
+			wantErr: true, // no order
+		},
+		{
+			order:   "random",
+			friends: []db.Friend{{ID: "1"}},
+			rounds:  1,
+			wantErr: true,
+		},
+		{
+			order:   draftOrderSnake,
+			rounds:  1,
+			wantErr: true, // no friends
+		},
+		{
+			order:   draftOrderSnake,
+			friends: []db.Friend{{ID: "1"}},
+			wantErr: true, // no rounds
+		},
+		{
+			order:        draftOrderLinear,
+			friends:      []db.Friend{{ID: "1"}},
+			rounds:       1,
+			pickDuration: -1 * time.Second,
+			wantErr:      true,
+		},
+		{
+			order:   draftOrderLinear,
+			friends: []db.Friend{{ID: "1"}},
+			rounds:  1,
+		},
+	}
+	for i, test := range newDraftTests {
+		_, err := newDraft(test.order, test.friends, test.rounds, test.pickDuration, time.Time{}, nil)
+		gotErr := err != nil
+		if test.wantErr != gotErr {
+			t.Errorf("Test %v: wanted error %v, got %v", i, test.wantErr, err)
+		}
+	}
+}
+
+func TestDraftCurrentFriend(t *testing.T) {
+	friends := []db.Friend{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	draftCurrentFriendTests := []struct {
+		order string
+		want  []db.ID
+	}{
+		{
+			order: draftOrderLinear,
+			want:  []db.ID{"a", "b", "c", "a", "b", "c", "a"},
+		},
+		{
+			order: draftOrderSnake,
+			want:  []db.ID{"a", "b", "c", "c", "b", "a", "a"},
+		},
+	}
+	for i, test := range draftCurrentFriendTests {
+		d, err := newDraft(test.order, friends, 3, 0, time.Time{}, nil)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		got := make([]db.ID, len(test.want))
+		for j := range test.want {
+			got[j] = d.currentFriend().ID
+			d.picks = append(d.picks, DraftPick{Skipped: true})
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("Test %v: wanted %v order to be %v, got %v", i, test.order, test.want, got)
+		}
+	}
+}
+
+func TestDraftPick(t *testing.T) {
+	start := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	friends := []db.Friend{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}}
+	dropDate := time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC)
+	players := []db.Player{
+		{PlayerType: 2, SourceID: 547180},
+		{PlayerType: 2, SourceID: 592450, DropDate: &dropDate},
+	}
+	d, err := newDraft(draftOrderSnake, friends, 10, time.Minute, start, players)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.pick(start.Add(10*time.Second), db.PlayerInfo{PlayerType: 2, SourceID: 547180, Name: "Bryce Harper"}); err == nil {
+		t.Error("wanted error picking player that is already on a roster")
+	}
+	if err := d.pick(start.Add(20*time.Second), db.PlayerInfo{PlayerType: 2, SourceID: 592450, Name: "Aaron Judge", Position: "RF", Team: "NYY"}); err != nil {
+		t.Errorf("unexpected error picking dropped player: %v", err)
+	}
+	if err := d.pick(start.Add(30*time.Second), db.PlayerInfo{PlayerType: 2, SourceID: 592450, Name: "Aaron Judge"}); err == nil {
+		t.Error("wanted error picking player that was already drafted")
+	}
+	if err := d.pick(start.Add(40*time.Second), db.PlayerInfo{PlayerType: 3, SourceID: 592450, Name: "Aaron Judge"}); err != nil {
+		t.Errorf("unexpected error picking same source id for different player type: %v", err)
+	}
+	// Bob's second pick (a snake order) times out, then Alice times out, pausing the timer
+	got := d.board(start.Add(40*time.Second + 2*time.Minute + 15*time.Second))
+	want := DraftBoard{
+		Started:           true,
+		Order:             draftOrderSnake,
+		Round:             3,
+		Rounds:            10,
+		Paused:            true,
+		FriendNames:       []string{"Alice", "Bob"},
+		CurrentFriendID:   "a",
+		CurrentFriendName: "Alice",
+		Picks: []DraftPick{
+			{Round: 1, FriendID: "a", FriendName: "Alice", PlayerType: 2, SourceID: 592450, PlayerName: "Aaron Judge", Position: "RF", Team: "NYY"},
+			{Round: 1, FriendID: "b", FriendName: "Bob", PlayerType: 3, SourceID: 592450, PlayerName: "Aaron Judge"},
+			{Round: 2, FriendID: "b", FriendName: "Bob", Skipped: true},
+			{Round: 2, FriendID: "a", FriendName: "Alice", Skipped: true},
+		},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("draft boards not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestDraftBoard_idle(t *testing.T) {
+	start := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	friends := []db.Friend{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	d, err := newDraft(draftOrderLinear, friends, 100, time.Second, start, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	idleEnd := start.Add(24 * time.Hour)
+	if b := d.board(idleEnd); len(b.Picks) != len(friends) || !b.Paused || b.SecondsLeft != 0 {
+		t.Errorf("wanted timer to be paused after every friend skipped once, got %v picks, paused: %v", len(b.Picks), b.Paused)
+	}
+	if err := d.pick(idleEnd, db.PlayerInfo{PlayerType: 2, SourceID: 1}); err != nil {
+		t.Fatalf("unexpected error picking when timer is paused: %v", err)
+	}
+	if b := d.board(idleEnd.Add(1500 * time.Millisecond)); len(b.Picks) != len(friends)+2 || b.Paused {
+		t.Errorf("wanted timer to start again after pick, got %v picks, paused: %v", len(b.Picks), b.Paused)
+	}
+}
+
+func TestDraftPick_done(t *testing.T) {
+	friends := []db.Friend{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}}
+	d, err := newDraft(draftOrderSnake, friends, 1, 0, time.Time{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i <= len(friends); i++ {
+		if err := d.pick(time.Time{}, db.PlayerInfo{PlayerType: 2, SourceID: db.SourceID(i)}); err != nil {
+			t.Fatalf("unexpected error making pick %v: %v", i, err)
+		}
+	}
+	if err := d.pick(time.Time{}, db.PlayerInfo{PlayerType: 2, SourceID: 3}); err == nil {
+		t.Error("wanted error picking after the last round")
+	}
+	b := d.board(time.Time{})
+	switch {
+	case !b.Done, b.Round != 1, len(b.CurrentFriendID) != 0:
+		t.Errorf("wanted draft to be done after the last round, got %v", b)
+	case len(b.Picks) != len(friends):
+		t.Errorf("wanted %v picks, got %v", len(friends), len(b.Picks))
+	}
+}
+
+func TestDraftPlayers(t *testing.T) {
+	d := draft{
+		picks: []DraftPick{
+			{FriendID: "a", PlayerType: 2, SourceID: 11},
+			{FriendID: "b", PlayerType: 2, SourceID: 12},
+			{FriendID: "b", Skipped: true},
+			{FriendID: "a", PlayerType: 2, SourceID: 13},
+		},
+	}
+	existingPlayers := []db.Player{
+		{ID: "7", FriendID: "a", PlayerType: 2, SourceID: 10, DisplayOrder: 4},
+	}
+	want := []db.Player{
+		{ID: "7", FriendID: "a", PlayerType: 2, SourceID: 10, DisplayOrder: 4},
+		{ID: "draft-0", FriendID: "a", PlayerType: 2, SourceID: 11, DisplayOrder: 5},
+		{ID: "draft-1", FriendID: "b", PlayerType: 2, SourceID: 12, DisplayOrder: 1},
+		{ID: "draft-3", FriendID: "a", PlayerType: 2, SourceID: 13, DisplayOrder: 6},
+	}
+	got := d.players(existingPlayers)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("players not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestHandleDraftPostRequest(t *testing.T) {
+	st := db.SportTypeMlb
+	friends := []db.Friend{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}}
+	var savedPlayers []db.Player
+	var savedPlayerInfos []db.PlayerInfo
+	clearStatCalled := false
+	ds := mockServerDatastore{
+		adminDatastore: mockAdminDatastore{
+			IsCorrectUserPasswordFunc: func(username string, p db.Password) (bool, error) {
+				return p == "secret", nil
+			},
+			SavePlayersFunc: func(st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error {
+				savedPlayers = futurePlayers
+				savedPlayerInfos = playerInfos
+				return nil
+			},
+			ClearStatFunc: func(st db.SportType) error {
+				clearStatCalled = true
+				return nil
+			},
+		},
+		etlDatastore: mockEtlDatastore{
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return friends, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{
+					db.PlayerTypeMlbHitter: {SportType: db.SportTypeMlb},
+					db.PlayerTypeNflQB:     {SportType: db.SportTypeNfl},
+				}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return time.Time{}
+			},
+		},
+	}
+	dr := newDraftRoom()
+	handleDraftPostRequestTests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{query: "action=start&password=wrong&order=snake&rounds=2&friend-b-draft-order=1&friend-a-draft-order=2", wantErr: true},
+		{query: "action=pick&password=secret&player-type=2&source-id=1", wantErr: true}, // not started
+		{query: "action=start&password=secret&order=snake&rounds=2&friend-b-draft-order=1&friend-c-draft-order=2", wantErr: true},
+		{query: "action=start&password=secret&order=snake&rounds=2&friend-b-draft-order=1&friend-a-draft-order=2&pick-seconds=x", wantErr: true},
+		{query: "action=start&password=secret&order=snake&friend-b-draft-order=1&friend-a-draft-order=2", wantErr: true}, // no rounds
+		{query: "action=start&password=secret&order=snake&rounds=2&friend-b-draft-order=1&friend-a-draft-order=2"},
+		{query: "action=start&password=secret&order=snake&rounds=2&friend-b-draft-order=1&friend-a-draft-order=2", wantErr: true}, // already started
+		{query: "action=pick&password=secret&player-type=2&source-id=547180&player-name=Bryce+Harper&player-position=RF&player-team=PHI"},
+		{query: "action=pick&password=secret&player-type=2&source-id=547180&player-name=Bryce+Harper", wantErr: true}, // taken
+		{query: "action=pick&password=secret&player-type=5&source-id=2558125&player-name=Patrick+Mahomes", wantErr: true},
+		{query: "action=pick&password=secret&player-type=2&source-id=592450&player-name=Aaron+Judge"},
+		{query: "action=unknown&password=secret", wantErr: true},
+		{query: "action=finalize&password=secret"},
+		{query: "action=finalize&password=secret", wantErr: true}, // already finalized
+	}
+	for i, test := range handleDraftPostRequestTests {
+		r := httptest.NewRequest("POST", "/draft?"+test.query, nil)
+		err := handleDraftPostRequest(ds, dr, st, r)
+		gotErr := err != nil
+		if test.wantErr != gotErr {
+			t.Errorf("Test %v: wanted error %v, got %v", i, test.wantErr, err)
+		}
+	}
+	wantSavedPlayers := []db.Player{
+		{ID: "draft-0", FriendID: "b", PlayerType: db.PlayerTypeMlbHitter, SourceID: 547180, DisplayOrder: 1},
+		{ID: "draft-1", FriendID: "a", PlayerType: db.PlayerTypeMlbHitter, SourceID: 592450, DisplayOrder: 1},
+	}
+	wantSavedPlayerInfos := []db.PlayerInfo{
+		{PlayerType: db.PlayerTypeMlbHitter, SourceID: 547180, Name: "Bryce Harper", Position: "RF", Team: "PHI"},
+		{PlayerType: db.PlayerTypeMlbHitter, SourceID: 592450, Name: "Aaron Judge"},
+	}
+	switch {
+	case !reflect.DeepEqual(wantSavedPlayers, savedPlayers):
+		t.Errorf("saved players not equal:\nwanted: %v\ngot:    %v", wantSavedPlayers, savedPlayers)
+	case !reflect.DeepEqual(wantSavedPlayerInfos, savedPlayerInfos):
+		t.Errorf("saved player infos not equal:\nwanted: %v\ngot:    %v", wantSavedPlayerInfos, savedPlayerInfos)
+	case !clearStatCalled:
+		t.Error("wanted stat to be cleared after draft finalized")
+	case dr.board(st, time.Time{}).Started:
+		t.Error("wanted draft to be removed after it was finalized")
+	}
+}
+
+func TestFinalizeDraft_saveError(t *testing.T) {
+	st := db.SportTypeMlb
+	saveErr := errors.New("save error")
+	ds := mockServerDatastore{
+		adminDatastore: mockAdminDatastore{
+			SavePlayersFunc: func(st db.SportType, futurePlayers []db.Player, playerInfos []db.PlayerInfo) error {
+				return saveErr
+			},
+		},
+		etlDatastore: mockEtlDatastore{
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+		},
+	}
+	dr := newDraftRoom()
+	dr.drafts[st] = &draft{order: draftOrderLinear, friends: []db.Friend{{ID: "a"}}}
+	r := httptest.NewRequest("POST", "/draft", nil)
+	err := finalizeDraft(ds, dr, st, r)
+	switch {
+	case !errors.Is(err, saveErr):
+		t.Errorf("wanted %v, got %v", saveErr, err)
+	case !dr.board(st, time.Time{}).Started:
+		t.Error("wanted draft to be kept when it could not be saved")
+	}
+}
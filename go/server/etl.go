@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -19,12 +21,26 @@ type (
 		sportTypeName   string
 		sportType       db.SportType
 		year            int
+		stale           bool
+		statuses        map[db.PlayerType]EtlStatus
+	}
+	// EtlStatus describes the last refresh of the ScoreCategory for a PlayerType.
+	// If the refresh failed, the ScoreCategory from the last successful refresh is kept.
+	EtlStatus struct {
+		PlayerType db.PlayerType
+		Name       string
+		// FetchTime is when the ScoreCategory was last fetched successfully.  It is zero if it never was.
+		FetchTime time.Time
+		// FailTime is when the last refresh failed.  It is zero if the last refresh succeeded.
+		FailTime time.Time
+		Error    string
 	}
 	etlDatastore interface {
-		GetStat(st db.SportType) (*db.Stat, error)
-		GetFriends(st db.SportType) ([]db.Friend, error)
-		GetPlayers(st db.SportType) ([]db.Player, error)
-		SetStat(stat db.Stat) error
+		GetStat(ctx context.Context, st db.SportType) (*db.Stat, error)
+		GetFriends(ctx context.Context, st db.SportType) ([]db.Friend, error)
+		GetPlayers(ctx context.Context, st db.SportType) ([]db.Player, error)
+		SetStat(ctx context.Context, stat db.Stat) error
+		SavePlayerInfos(ctx context.Context, st db.SportType, playerInfos []db.PlayerInfo) error
 		SportTypes() db.SportTypeMap
 		PlayerTypes() db.PlayerTypeMap
 		GetUtcTime() time.Time
@@ -36,15 +52,22 @@ type (
 		friends []db.Friend
 		players []db.Player
 	}
+	scoreCategoryResult struct {
+		pt            db.PlayerType
+		pti           db.PlayerTypeInfo
+		players       []db.Player
+		scoreCategory request.ScoreCategory
+		err           error
+	}
 )
 
-// getEtlStats retrieves, calculates, and caches the player stats
-func getEtlStats(st db.SportType, ds etlDatastore, scoreCategorizers map[db.PlayerType]request.ScoreCategorizer) (*EtlStats, error) {
-	currentTime := ds.GetUtcTime()
+// getEtlStats retrieves the cached player stats.
+// The stats are stale if they were not calculated after the etlRefreshTime, unless they are frozen.
+func getEtlStats(ctx context.Context, st db.SportType, ds etlDatastore, etlRefreshTime time.Time) (*EtlStats, error) {
 	es := EtlStats{
-		etlRefreshTime: previousMidnight(currentTime),
+		etlRefreshTime: etlRefreshTime,
 	}
-	stat, err := ds.GetStat(st)
+	stat, err := ds.GetStat(ctx, st)
 	if err != nil {
 		return nil, err
 	}
@@ -54,41 +77,158 @@ func getEtlStats(st db.SportType, ds etlDatastore, scoreCategorizers map[db.Play
 	es.sportTypeName = ds.SportTypes()[st].Name
 	es.sportType = st
 	es.year = stat.Year
-	if err := updateStat(stat, st, ds, scoreCategorizers, es.etlRefreshTime, currentTime); err != nil {
+	if stat.EtlTimestamp == nil || len(stat.EtlJSON) == 0 {
+		es.stale = true
+		return &es, nil
+	}
+	es.stale = !stat.Frozen && stat.EtlTimestamp.Before(etlRefreshTime)
+	if err := es.setStat(*stat); err != nil {
+		return nil, err
+	}
+	return &es, nil
+}
+
+// refreshEtlStats calculates and caches the player stats.
+// Categories that cannot be fetched keep their results from the previous refresh and are marked as failed in their EtlStatus.
+// Nil EtlStats are returned if the SportType has no stats to refresh or if its stats are frozen.
+func refreshEtlStats(ctx context.Context, st db.SportType, ds etlDatastore, scoreCategorizers map[db.PlayerType]request.ScoreCategorizer) (*EtlStats, error) {
+	stat, err := ds.GetStat(ctx, st)
+	if err != nil {
 		return nil, err
 	}
+	if stat == nil || (stat.Frozen && stat.EtlTimestamp != nil && len(stat.EtlJSON) != 0) {
+		return nil, nil
+	}
+	var previous EtlStats
+	if len(stat.EtlJSON) != 0 { // cleared stats keep their last payload
+		if err := previous.setStat(*stat); err != nil {
+			previous = EtlStats{} // the previous stats are only used when categories cannot be fetched
+		}
+	}
+	scoreCategoryResults, err := getScoreCategories(ctx, st, ds, stat.Year, scoreCategorizers)
+	if err != nil {
+		return nil, err
+	}
+	currentTime := ds.GetUtcTime()
+	scoreCategories, statuses, err := mergeScoreCategories(previous, scoreCategoryResults, currentTime)
+	if err != nil {
+		return nil, fmt.Errorf("refreshing stats for sportType %v, year %v: %w", st, stat.Year, err)
+	}
+	etlJSON, err := json.Marshal(scoreCategories)
+	if err != nil {
+		return nil, fmt.Errorf("converting stats to json for sportType %v, year %v: %w", st, stat.Year, err)
+	}
+	etlStatusJSON, err := json.Marshal(statuses)
+	if err != nil {
+		return nil, fmt.Errorf("converting stat statuses to json for sportType %v, year %v: %w", st, stat.Year, err)
+	}
+	// the names are saved so they are not requested again for players added before their infos were saved
+	if playerInfos := requestedPlayerInfos(scoreCategoryResults); len(playerInfos) != 0 {
+		if err := ds.SavePlayerInfos(ctx, st, playerInfos); err != nil {
+			return nil, fmt.Errorf("saving requested player infos for sportType %v: %w", st, err)
+		}
+	}
+	stat.EtlJSON = string(etlJSON)
+	stat.EtlStatusJSON = string(etlStatusJSON)
+	stat.EtlTimestamp = &currentTime
+	if err := ds.SetStat(ctx, *stat); err != nil {
+		return nil, err
+	}
+	es := EtlStats{
+		sportTypeName: ds.SportTypes()[st].Name,
+		sportType:     st,
+		year:          stat.Year,
+	}
 	if err := es.setStat(*stat); err != nil {
 		return nil, err
 	}
 	return &es, nil
 }
 
-func updateStat(stat *db.Stat, st db.SportType, ds etlDatastore, scoreCategorizers map[db.PlayerType]request.ScoreCategorizer, etlRefreshTime, currentTime time.Time) error {
-	if stat.EtlTimestamp == nil || len(stat.EtlJSON) == 0 || stat.EtlTimestamp.Before(etlRefreshTime) {
-		scoreCategories, err := getScoreCategories(st, ds, stat.Year, scoreCategorizers)
-		if err != nil {
-			return err
+// mergeScoreCategories combines the fetched ScoreCategories with the previous ones of categories that could not be fetched.
+// Categories that failed without previous results are empty.  An error is returned if no category has results.
+func mergeScoreCategories(previous EtlStats, scoreCategoryResults []scoreCategoryResult, currentTime time.Time) ([]request.ScoreCategory, []EtlStatus, error) {
+	scoreCategories := make([]request.ScoreCategory, len(scoreCategoryResults))
+	statuses := make([]EtlStatus, len(scoreCategoryResults))
+	var errs []error
+	hasResults := len(scoreCategoryResults) == 0
+	for i, r := range scoreCategoryResults {
+		if r.err == nil {
+			scoreCategories[i] = r.scoreCategory
+			statuses[i] = EtlStatus{
+				PlayerType: r.pt,
+				Name:       r.scoreCategory.Name,
+				FetchTime:  currentTime,
+			}
+			hasResults = true
+			continue
 		}
-		etlJSON, err := json.Marshal(scoreCategories)
-		if err != nil {
-			return fmt.Errorf("converting stats to json for sportType %v, year %v: %w", st, stat.Year, err)
+		errs = append(errs, r.err)
+		sc, status, ok := previous.scoreCategory(r.pt)
+		if !ok {
+			sc = request.ScoreCategory{
+				Name:        r.pti.Name,
+				Description: r.pti.Description,
+				PlayerType:  r.pt,
+			}
+			status = EtlStatus{
+				PlayerType: r.pt,
+				Name:       r.pti.Name,
+			}
 		}
-		stat.EtlJSON = string(etlJSON)
-		stat.EtlTimestamp = &currentTime
-		err = ds.SetStat(*stat)
-		if err != nil {
-			return err
+		if !status.FetchTime.IsZero() {
+			hasResults = true
 		}
+		status.FailTime = currentTime
+		status.Error = r.err.Error()
+		scoreCategories[i] = sc
+		statuses[i] = status
 	}
-	return nil
+	if !hasResults {
+		return nil, nil, errors.Join(errs...)
+	}
+	return scoreCategories, statuses, nil
+}
+
+// requestedPlayerInfos creates the infos of the players without saved names from the names in the fetched ScoreCategories.
+func requestedPlayerInfos(scoreCategoryResults []scoreCategoryResult) []db.PlayerInfo {
+	var playerInfos []db.PlayerInfo
+	for _, r := range scoreCategoryResults {
+		if r.err != nil {
+			continue
+		}
+		names := make(map[db.ID]string)
+		for _, fs := range r.scoreCategory.FriendScores {
+			for _, ps := range fs.PlayerScores {
+				names[ps.ID] = ps.Name
+			}
+		}
+		added := make(map[db.SourceID]bool)
+		for _, player := range r.players {
+			name := names[player.ID]
+			if len(player.Name) != 0 || len(name) == 0 || added[player.SourceID] {
+				continue
+			}
+			playerInfos = append(playerInfos, db.PlayerInfo{
+				PlayerType: r.pt,
+				SourceID:   player.SourceID,
+				Name:       name,
+			})
+			added[player.SourceID] = true
+		}
+	}
+	return playerInfos
 }
 
-func getScoreCategories(st db.SportType, ds etlDatastore, year int, scoreCategorizers map[db.PlayerType]request.ScoreCategorizer) ([]request.ScoreCategory, error) {
-	friends, err := ds.GetFriends(st)
+// getScoreCategories requests the ScoreCategories of the PlayerTypes of the SportType, in display order.
+// Errors requesting individual ScoreCategories are returned in their results.
+// A failed ScoreCategory does not cancel the others so their stats can still be saved, but every request is waited for.
+func getScoreCategories(ctx context.Context, st db.SportType, ds etlDatastore, year int, scoreCategorizers map[db.PlayerType]request.ScoreCategorizer) ([]scoreCategoryResult, error) {
+	friends, err := ds.GetFriends(ctx, st)
 	if err != nil {
 		return nil, err
 	}
-	players, err := ds.GetPlayers(st)
+	players, err := ds.GetPlayers(ctx, st)
 	if err != nil {
 		return nil, err
 	}
@@ -98,8 +238,7 @@ func getScoreCategories(st db.SportType, ds etlDatastore, year int, scoreCategor
 	for _, player := range players {
 		playersByType[player.PlayerType] = append(playersByType[player.PlayerType], player)
 	}
-	scoreCategoriesCh := make(chan request.ScoreCategory, len(stPlayerTypes))
-	quit := make(chan error)
+	resultsCh := make(chan scoreCategoryResult, len(stPlayerTypes))
 	for _, pt := range stPlayerTypes {
 		sci := scoreCategoryInfo{
 			pt:      pt,
@@ -108,26 +247,62 @@ func getScoreCategories(st db.SportType, ds etlDatastore, year int, scoreCategor
 			friends: friends,
 			players: playersByType[pt],
 		}
-		go getScoreCategory(sci, scoreCategorizers[pt], scoreCategoriesCh, quit)
-	}
-	scoreCategories := make([]request.ScoreCategory, len(stPlayerTypes))
-	finishedScoreCategories := 0
-	for {
-		select {
-		case err = <-quit:
-			return nil, err
-		case scoreCategory := <-scoreCategoriesCh:
-			scoreCategories[finishedScoreCategories] = scoreCategory
-			finishedScoreCategories++
-		}
-		if finishedScoreCategories == len(stPlayerTypes) {
-			displayOrder := func(i int) int { return playerTypes[scoreCategories[i].PlayerType].DisplayOrder }
-			sort.Slice(scoreCategories, func(i, j int) bool {
-				return displayOrder(i) < displayOrder(j)
-			})
-			return scoreCategories, nil
+		go getScoreCategory(ctx, sci, scoreCategorizers[pt], resultsCh)
+	}
+	results := make([]scoreCategoryResult, len(stPlayerTypes))
+	for i := range results {
+		results[i] = <-resultsCh
+	}
+	displayOrder := func(i int) int { return results[i].pti.DisplayOrder }
+	sort.Slice(results, func(i, j int) bool {
+		return displayOrder(i) < displayOrder(j)
+	})
+	return results, nil
+}
+
+// getRosterEtlStats creates EtlStats of the saved friends and players without requesting their stats.
+// It is used so the players can still be administered when the stats cannot be requested.
+// Every ScoreCategory is marked as failed with the error from requesting the stats.
+func getRosterEtlStats(ctx context.Context, st db.SportType, ds etlDatastore, etlErr error) (*EtlStats, error) {
+	stat, err := ds.GetStat(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	friends, err := ds.GetFriends(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	players, err := ds.GetPlayers(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	playersByType := make(map[db.PlayerType][]db.Player)
+	for _, player := range players {
+		playersByType[player.PlayerType] = append(playersByType[player.PlayerType], player)
+	}
+	currentTime := ds.GetUtcTime()
+	playerTypes := ds.PlayerTypes()
+	stPlayerTypes := getPlayerTypes(st, playerTypes)
+	es := EtlStats{
+		scoreCategories: make([]request.ScoreCategory, len(stPlayerTypes)),
+		sportTypeName:   ds.SportTypes()[st].Name,
+		sportType:       st,
+		statuses:        make(map[db.PlayerType]EtlStatus, len(stPlayerTypes)),
+	}
+	if stat != nil {
+		es.year = stat.Year
+	}
+	for i, pt := range stPlayerTypes {
+		pti := playerTypes[pt]
+		es.scoreCategories[i] = request.NewRosterScoreCategory(pt, pti, friends, playersByType[pt])
+		es.statuses[pt] = EtlStatus{
+			PlayerType: pt,
+			Name:       pti.Name,
+			FailTime:   currentTime,
+			Error:      etlErr.Error(),
 		}
 	}
+	return &es, nil
 }
 
 func getPlayerTypes(st db.SportType, playerTypes db.PlayerTypeMap) []db.PlayerType {
@@ -144,27 +319,26 @@ func getPlayerTypes(st db.SportType, playerTypes db.PlayerTypeMap) []db.PlayerTy
 	return playerTypesList
 }
 
-func getScoreCategory(sci scoreCategoryInfo, scoreCategorizer request.ScoreCategorizer, scoreCategories chan<- request.ScoreCategory, quit chan<- error) {
+func getScoreCategory(ctx context.Context, sci scoreCategoryInfo, scoreCategorizer request.ScoreCategorizer, results chan<- scoreCategoryResult) {
+	result := scoreCategoryResult{
+		pt:      sci.pt,
+		pti:     sci.pti,
+		players: sci.players,
+	}
 	if scoreCategorizer == nil {
-		quit <- fmt.Errorf("no ScoreCategorizer for PlayerType %v", sci.pt)
+		result.err = fmt.Errorf("no ScoreCategorizer for PlayerType %v", sci.pt)
+		results <- result
 		return
 	}
 	// providing playerType here is somewhat redundant, but this allows some scoreCategorizers to handle multiple PlayerTypes
-	scoreCategory, err := scoreCategorizer.RequestScoreCategory(sci.pt, sci.pti, sci.year, sci.friends, sci.players)
+	scoreCategory, err := scoreCategorizer.RequestScoreCategory(ctx, sci.pt, sci.pti, sci.year, sci.friends, sci.players)
 	if err != nil {
-		quit <- err
+		result.err = fmt.Errorf("requesting %v stats: %w", sci.pti.Name, err)
+		results <- result
 		return
 	}
-	scoreCategories <- scoreCategory
-}
-
-// previousMidnight returns the previous midnight relative to Honolulu time (UTC-10)
-func previousMidnight(t time.Time) time.Time {
-	midnight := time.Date(t.Year(), t.Month(), t.Day(), 10, 0, 0, 0, time.UTC)
-	if midnight.After(t) {
-		midnight = midnight.Add(-24 * time.Hour)
-	}
-	return midnight.In(t.Location())
+	result.scoreCategory = scoreCategory
+	results <- result
 }
 
 // setStat sets the etlTime and scoreCategories (etlJson) from the Stat
@@ -177,7 +351,58 @@ func (es *EtlStats) setStat(stat db.Stat) error {
 	if err != nil {
 		return fmt.Errorf("decoding ScoreCategories from Stat etlJSON: %w", err)
 	}
-	es.etlTime = *stat.EtlTimestamp
+	if stat.EtlTimestamp != nil {
+		es.etlTime = *stat.EtlTimestamp
+	}
 	es.scoreCategories = scoreCategories
+	if len(stat.EtlStatusJSON) != 0 {
+		var statuses []EtlStatus
+		if err := json.Unmarshal([]byte(stat.EtlStatusJSON), &statuses); err != nil {
+			return fmt.Errorf("decoding EtlStatuses from Stat etlStatusJSON: %w", err)
+		}
+		es.statuses = make(map[db.PlayerType]EtlStatus, len(statuses))
+		for _, status := range statuses {
+			es.statuses[status.PlayerType] = status
+		}
+	}
 	return nil
 }
+
+// status gets the EtlStatus of the ScoreCategory.
+// Stats that were saved without statuses were fetched successfully at the etlTime.
+func (es EtlStats) status(sc request.ScoreCategory) EtlStatus {
+	if status, ok := es.statuses[sc.PlayerType]; ok {
+		return status
+	}
+	return EtlStatus{
+		PlayerType: sc.PlayerType,
+		Name:       sc.Name,
+		FetchTime:  es.etlTime,
+	}
+}
+
+// scoreCategory gets the ScoreCategory for the PlayerType and its status, if it exists.
+func (es EtlStats) scoreCategory(pt db.PlayerType) (request.ScoreCategory, EtlStatus, bool) {
+	for _, sc := range es.scoreCategories {
+		if sc.PlayerType == pt {
+			return sc, es.status(sc), true
+		}
+	}
+	return request.ScoreCategory{}, EtlStatus{}, false
+}
+
+// failedStatuses gets the EtlStatuses of the ScoreCategories that could not be fetched at the last refresh.
+func (es EtlStats) failedStatuses() []EtlStatus {
+	var failedStatuses []EtlStatus
+	for _, sc := range es.scoreCategories {
+		if status := es.status(sc); status.Failed() {
+			failedStatuses = append(failedStatuses, status)
+		}
+	}
+	return failedStatuses
+}
+
+// Failed determines if the ScoreCategory could not be fetched at the last refresh.
+func (status EtlStatus) Failed() bool {
+	return len(status.Error) != 0
+}
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,66 +14,32 @@ import (
 	"github.com/jacobpatterson1549/nate-mlb/go/request"
 )
 
-func TestPreviousMidnight(t *testing.T) {
-	pacificLocation, err := time.LoadLocation("America/Los_Angeles")
-	if err != nil {
-		t.Fatal(err)
-	}
-	hawaiiLocation, err := time.LoadLocation("Pacific/Honolulu")
-	if err != nil {
-		t.Fatal(err)
-	}
-	previousMidnightTests := []struct {
-		dateTime time.Time
-		want     time.Time
-	}{
-		{
-			dateTime: time.Date(2019, time.August, 22, 0, 0, 0, 0, time.UTC), // 12 AM
-			want:     time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
-		},
-		{
-			dateTime: time.Date(2019, time.August, 21, 16, 15, 17, 0, time.UTC),
-			want:     time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
-		},
-		{
-			dateTime: time.Date(2019, time.August, 22, 2, 0, 0, 0, pacificLocation), // 2 AM
-			want:     time.Date(2019, time.August, 21, 3, 0, 0, 0, pacificLocation),
-		},
-		{
-			dateTime: time.Date(2019, time.August, 22, 0, 0, 0, 0, hawaiiLocation), // 12 AM
-			want:     time.Date(2019, time.August, 22, 0, 0, 0, 0, hawaiiLocation),
-		},
-	}
-	for i, test := range previousMidnightTests {
-		got := previousMidnight(test.dateTime)
-		if test.want != got {
-			t.Errorf("Test %d:\n\twanted %v\n\tgot    %v", i, test.want, got)
-		}
-	}
-}
-
 type mockEtlDatastore struct {
-	GetStatFunc     func(st db.SportType) (*db.Stat, error)
-	GetFriendsFunc  func(st db.SportType) ([]db.Friend, error)
-	GetPlayersFunc  func(st db.SportType) ([]db.Player, error)
-	SetStatFunc     func(stat db.Stat) error
-	SportTypesFunc  func() db.SportTypeMap
-	PlayerTypesFunc func() db.PlayerTypeMap
-	GetUtcTimeFunc  func() time.Time
+	GetStatFunc         func(st db.SportType) (*db.Stat, error)
+	GetFriendsFunc      func(st db.SportType) ([]db.Friend, error)
+	GetPlayersFunc      func(st db.SportType) ([]db.Player, error)
+	SetStatFunc         func(stat db.Stat) error
+	SavePlayerInfosFunc func(st db.SportType, playerInfos []db.PlayerInfo) error
+	SportTypesFunc      func() db.SportTypeMap
+	PlayerTypesFunc     func() db.PlayerTypeMap
+	GetUtcTimeFunc      func() time.Time
 }
 
-func (m mockEtlDatastore) GetStat(st db.SportType) (*db.Stat, error) {
+func (m mockEtlDatastore) GetStat(ctx context.Context, st db.SportType) (*db.Stat, error) {
 	return m.GetStatFunc(st)
 }
-func (m mockEtlDatastore) GetFriends(st db.SportType) ([]db.Friend, error) {
+func (m mockEtlDatastore) GetFriends(ctx context.Context, st db.SportType) ([]db.Friend, error) {
 	return m.GetFriendsFunc(st)
 }
-func (m mockEtlDatastore) GetPlayers(st db.SportType) ([]db.Player, error) {
+func (m mockEtlDatastore) GetPlayers(ctx context.Context, st db.SportType) ([]db.Player, error) {
 	return m.GetPlayersFunc(st)
 }
-func (m mockEtlDatastore) SetStat(stat db.Stat) error {
+func (m mockEtlDatastore) SetStat(ctx context.Context, stat db.Stat) error {
 	return m.SetStatFunc(stat)
 }
+func (m mockEtlDatastore) SavePlayerInfos(ctx context.Context, st db.SportType, playerInfos []db.PlayerInfo) error {
+	return m.SavePlayerInfosFunc(st, playerInfos)
+}
 func (m mockEtlDatastore) SportTypes() db.SportTypeMap {
 	return m.SportTypesFunc()
 }
@@ -112,8 +82,7 @@ func TestGetScoreCategory(t *testing.T) {
 		},
 	}
 	for i, test := range getScoreCategoryTests {
-		scoreCategories := make(chan request.ScoreCategory, 1)
-		quit := make(chan error, 1)
+		results := make(chan scoreCategoryResult, 1)
 		sci := scoreCategoryInfo{
 			pt:      test.pt,
 			pti:     test.pti,
@@ -121,18 +90,21 @@ func TestGetScoreCategory(t *testing.T) {
 			friends: test.friends,
 			players: test.players,
 		}
-		getScoreCategory(sci, test.scoreCategorizer, scoreCategories, quit)
+		getScoreCategory(context.Background(), sci, test.scoreCategorizer, results)
 		select {
-		case got := <-scoreCategories:
-			if test.wantErr || !reflect.DeepEqual(test.wantScoreCategory, got) {
-				t.Errorf("Test %v: wanted scoreCategory %v, got scoreCategory: %v (expected error: %v)", i, test.wantScoreCategory, got, test.wantErr)
-			}
-		case got := <-quit:
-			if !test.wantErr {
-				t.Errorf("Test %v: unexpected error: %v", i, got)
+		case got := <-results:
+			switch {
+			case test.wantErr:
+				if got.err == nil {
+					t.Errorf("Test %v: wanted error", i)
+				}
+			case got.err != nil:
+				t.Errorf("Test %v: unexpected error: %v", i, got.err)
+			case !reflect.DeepEqual(test.wantScoreCategory, got.scoreCategory):
+				t.Errorf("Test %v: wanted scoreCategory %v, got scoreCategory: %v", i, test.wantScoreCategory, got.scoreCategory)
 			}
 		default:
-			t.Errorf("Test %v: did not get message on any channel", i)
+			t.Errorf("Test %v: did not get result", i)
 		}
 	}
 }
@@ -141,7 +113,7 @@ type mockScoreCategorizer struct {
 	RequestScoreCategoryFunc func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error)
 }
 
-func (m mockScoreCategorizer) RequestScoreCategory(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+func (m mockScoreCategorizer) RequestScoreCategory(ctx context.Context, pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
 	return m.RequestScoreCategoryFunc(pt, ptInfo, year, friends, players)
 }
 
@@ -169,10 +141,11 @@ func TestGetPlayerTypes(t *testing.T) {
 func TestSetStat(t *testing.T) {
 	time1 := time.Date(2019, time.October, 17, 15, 41, 42, 0, time.UTC)
 	setStatTests := []struct {
-		statEtlTimestamp time.Time
-		statEtlJSON      string
-		wantErr          bool
-		want             EtlStats
+		statEtlTimestamp  time.Time
+		statEtlJSON       string
+		statEtlStatusJSON string
+		wantErr           bool
+		want              EtlStats
 	}{
 		{ // no EtlJSON (see below for set iff len>0 switch)
 			wantErr: true,
@@ -192,11 +165,38 @@ func TestSetStat(t *testing.T) {
 				},
 			},
 		},
+		{ // bad EtlStatusJSON
+			statEtlTimestamp:  time1,
+			statEtlJSON:       `[]`,
+			statEtlStatusJSON: `bad encoding`,
+			wantErr:           true,
+		},
+		{ // with statuses
+			statEtlTimestamp:  time1,
+			statEtlJSON:       `[{"Name":"something","PlayerType":4}]`,
+			statEtlStatusJSON: `[{"PlayerType":4,"Name":"something","FetchTime":"2019-10-16T15:41:42Z","FailTime":"2019-10-17T15:41:42Z","Error":"timeout"}]`,
+			want: EtlStats{
+				etlTime: time1,
+				scoreCategories: []request.ScoreCategory{
+					{Name: "something", PlayerType: 4},
+				},
+				statuses: map[db.PlayerType]EtlStatus{
+					4: {
+						PlayerType: 4,
+						Name:       "something",
+						FetchTime:  time1.AddDate(0, 0, -1),
+						FailTime:   time1,
+						Error:      "timeout",
+					},
+				},
+			},
+		},
 	}
 	for i, test := range setStatTests {
 		es := EtlStats{}
 		stat := db.Stat{
-			EtlTimestamp: &test.statEtlTimestamp,
+			EtlTimestamp:  &test.statEtlTimestamp,
+			EtlStatusJSON: test.statEtlStatusJSON,
 		}
 		if len(test.statEtlJSON) > 0 {
 			stat.EtlJSON = test.statEtlJSON
@@ -214,3 +214,534 @@ func TestSetStat(t *testing.T) {
 		}
 	}
 }
+
+func TestGetEtlStats(t *testing.T) {
+	etlRefreshTime := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	beforeRefresh := etlRefreshTime.Add(-time.Minute)
+	afterRefresh := etlRefreshTime.Add(time.Minute)
+	getEtlStatsTests := []struct {
+		stat        *db.Stat
+		getStatErr  error
+		wantErr     bool
+		wantStale   bool
+		wantEtlTime time.Time
+	}{
+		{ // no stat for year
+		},
+		{
+			getStatErr: fmt.Errorf("get stat error"),
+			wantErr:    true,
+		},
+		{ // no snapshot
+			stat:      &db.Stat{Year: 2019},
+			wantStale: true,
+		},
+		{
+			stat:        &db.Stat{Year: 2019, EtlTimestamp: &beforeRefresh, EtlJSON: "[]"},
+			wantStale:   true,
+			wantEtlTime: beforeRefresh,
+		},
+		{
+			stat:        &db.Stat{Year: 2019, EtlTimestamp: &afterRefresh, EtlJSON: "[]"},
+			wantEtlTime: afterRefresh,
+		},
+		{ // frozen stats are never stale
+			stat:        &db.Stat{Year: 2019, EtlTimestamp: &beforeRefresh, EtlJSON: "[]", Frozen: true},
+			wantEtlTime: beforeRefresh,
+		},
+		{ // frozen without snapshot
+			stat:      &db.Stat{Year: 2019, Frozen: true},
+			wantStale: true,
+		},
+		{ // cleared stats are refreshed before they are served
+			stat:      &db.Stat{Year: 2019, EtlJSON: "[]"},
+			wantStale: true,
+		},
+		{
+			stat:    &db.Stat{Year: 2019, EtlTimestamp: &afterRefresh, EtlJSON: "{bad json}"},
+			wantErr: true,
+		},
+	}
+	for i, test := range getEtlStatsTests {
+		ds := mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				return test.stat, test.getStatErr
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+		}
+		es, err := getEtlStats(context.Background(), 1, ds, etlRefreshTime)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantStale != es.stale:
+			t.Errorf("Test %v: wanted stale %v, got %v", i, test.wantStale, es.stale)
+		case !test.wantEtlTime.Equal(es.etlTime):
+			t.Errorf("Test %v: wanted etlTime %v, got %v", i, test.wantEtlTime, es.etlTime)
+		}
+	}
+}
+
+func TestRefreshEtlStats(t *testing.T) {
+	currentTime := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	refreshEtlStatsTests := []struct {
+		stat       *db.Stat
+		requestErr error
+		setStatErr error
+		wantErr    bool
+		wantNil    bool
+	}{
+		{ // no stat for year
+			wantNil: true,
+		},
+		{
+			stat:       &db.Stat{Year: 2019},
+			requestErr: fmt.Errorf("request error"),
+			wantErr:    true,
+		},
+		{
+			stat:       &db.Stat{Year: 2019},
+			setStatErr: fmt.Errorf("set stat error"),
+			wantErr:    true,
+		},
+		{
+			stat: &db.Stat{Year: 2019},
+		},
+		{ // frozen
+			stat:    &db.Stat{Year: 2019, EtlTimestamp: &currentTime, EtlJSON: "[]", Frozen: true},
+			wantNil: true,
+		},
+		{ // frozen without snapshot
+			stat: &db.Stat{Year: 2019, Frozen: true},
+		},
+	}
+	for i, test := range refreshEtlStatsTests {
+		var savedStat *db.Stat
+		ds := mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				return test.stat, nil
+			},
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return nil, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{2: {SportType: 1}}
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return currentTime
+			},
+			SetStatFunc: func(stat db.Stat) error {
+				savedStat = &stat
+				return test.setStatErr
+			},
+		}
+		scoreCategorizers := map[db.PlayerType]request.ScoreCategorizer{
+			2: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					return request.ScoreCategory{PlayerType: pt}, test.requestErr
+				},
+			},
+		}
+		es, err := refreshEtlStats(context.Background(), 1, ds, scoreCategorizers)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantNil:
+			if es != nil || savedStat != nil {
+				t.Errorf("Test %v: wanted nil EtlStats and no stat saved, got %v and %v", i, es, savedStat)
+			}
+		case savedStat == nil || !currentTime.Equal(*savedStat.EtlTimestamp):
+			t.Errorf("Test %v: wanted stat saved with EtlTimestamp %v, got %v", i, currentTime, savedStat)
+		case !currentTime.Equal(es.etlTime) || len(es.scoreCategories) != 1 || es.year != 2019:
+			t.Errorf("Test %v: unwanted EtlStats: %v", i, es)
+		}
+	}
+}
+
+func TestRefreshEtlStats_savePlayerInfos(t *testing.T) {
+	players := []db.Player{
+		{ID: "1", PlayerType: 2, SourceID: 547180, FriendID: "7", Name: "Bryce Harper"},
+		{ID: "2", PlayerType: 2, SourceID: 592450, FriendID: "7"},
+		{ID: "3", PlayerType: 2, SourceID: 592450, FriendID: "8"}, // also on another roster
+		{ID: "4", PlayerType: 3, SourceID: 605400, FriendID: "7"}, // stats cannot be fetched
+	}
+	saveErr := errors.New("save player infos error")
+	refreshEtlStatsSavePlayerInfosTests := []struct {
+		saveErr         error
+		wantPlayerInfos []db.PlayerInfo
+		wantErr         bool
+	}{
+		{
+			wantPlayerInfos: []db.PlayerInfo{{PlayerType: 2, SourceID: 592450, Name: "Aaron Judge"}},
+		},
+		{
+			saveErr:         saveErr,
+			wantPlayerInfos: []db.PlayerInfo{{PlayerType: 2, SourceID: 592450, Name: "Aaron Judge"}},
+			wantErr:         true,
+		},
+	}
+	for i, test := range refreshEtlStatsSavePlayerInfosTests {
+		var gotPlayerInfos []db.PlayerInfo
+		setStatCalled := false
+		ds := mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				return &db.Stat{Year: 2019}, nil
+			},
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return []db.Friend{{ID: "7", Name: "Alice"}, {ID: "8", Name: "Bob"}}, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return players, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{
+					2: {SportType: 1, DisplayOrder: 1},
+					3: {SportType: 1, DisplayOrder: 2},
+				}
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+			},
+			SavePlayerInfosFunc: func(st db.SportType, playerInfos []db.PlayerInfo) error {
+				gotPlayerInfos = playerInfos
+				return test.saveErr
+			},
+			SetStatFunc: func(stat db.Stat) error {
+				setStatCalled = true
+				return nil
+			},
+		}
+		names := map[db.SourceID]string{547180: "Bryce Harper", 592450: "Aaron Judge", 605400: "Aaron Nola"}
+		scoreCategorizers := map[db.PlayerType]request.ScoreCategorizer{
+			2: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					sc := request.ScoreCategory{PlayerType: pt}
+					for _, player := range players {
+						sc.FriendScores = append(sc.FriendScores, request.FriendScore{
+							ID:           player.FriendID,
+							PlayerScores: []request.PlayerScore{{ID: player.ID, SourceID: player.SourceID, Name: names[player.SourceID]}},
+						})
+					}
+					return sc, nil
+				},
+			},
+			3: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					return request.ScoreCategory{}, fmt.Errorf("timeout")
+				},
+			},
+		}
+		_, err := refreshEtlStats(context.Background(), 1, ds, scoreCategorizers)
+		switch {
+		case test.wantErr:
+			if !errors.Is(err, test.saveErr) || setStatCalled {
+				t.Errorf("Test %v: wanted %v before stat was set, got %v", i, test.saveErr, err)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.wantPlayerInfos, gotPlayerInfos):
+			t.Errorf("Test %v: saved player infos not equal:\nwanted: %v\ngot:    %v", i, test.wantPlayerInfos, gotPlayerInfos)
+		}
+	}
+}
+
+func TestRefreshEtlStats_keepPreviousStats(t *testing.T) {
+	previousTime := time.Date(2019, time.August, 20, 10, 0, 0, 0, time.UTC)
+	currentTime := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	refreshEtlStatsKeepPreviousStatsTests := []struct {
+		etlJSON             string
+		etlStatusJSON       string
+		cleared             bool
+		wantErr             bool
+		wantScoreCategories []request.ScoreCategory
+		wantStatuses        []EtlStatus
+	}{
+		{ // previous stats are kept
+			etlJSON: `[{"Name":"hitting","PlayerType":2,"FriendScores":[{"Name":"Bob","Score":7}]},{"Name":"pitching","PlayerType":3}]`,
+			wantScoreCategories: []request.ScoreCategory{
+				{Name: "hitting", PlayerType: 2, FriendScores: []request.FriendScore{{Name: "Bob", Score: 7}}},
+				{Name: "pitching", PlayerType: 3, FriendScores: []request.FriendScore{{Name: "Alice", Score: 4}}},
+			},
+			wantStatuses: []EtlStatus{
+				{PlayerType: 2, Name: "hitting", FetchTime: previousTime, FailTime: currentTime, Error: "requesting Hitting stats: timeout"},
+				{PlayerType: 3, Name: "pitching", FetchTime: currentTime},
+			},
+		},
+		{ // the previous fetch time is kept when the category fails again
+			etlJSON:       `[{"Name":"hitting","PlayerType":2},{"Name":"pitching","PlayerType":3}]`,
+			etlStatusJSON: `[{"PlayerType":2,"Name":"hitting","FetchTime":"2019-08-19T10:00:00Z","FailTime":"2019-08-20T10:00:00Z","Error":"timeout"}]`,
+			wantScoreCategories: []request.ScoreCategory{
+				{Name: "hitting", PlayerType: 2},
+				{Name: "pitching", PlayerType: 3, FriendScores: []request.FriendScore{{Name: "Alice", Score: 4}}},
+			},
+			wantStatuses: []EtlStatus{
+				{PlayerType: 2, Name: "hitting", FetchTime: previousTime.AddDate(0, 0, -1), FailTime: currentTime, Error: "requesting Hitting stats: timeout"},
+				{PlayerType: 3, Name: "pitching", FetchTime: currentTime},
+			},
+		},
+		{ // previous stats are kept when the stats were cleared
+			etlJSON:       `[{"Name":"hitting","PlayerType":2,"FriendScores":[{"Name":"Bob","Score":7}]},{"Name":"pitching","PlayerType":3}]`,
+			etlStatusJSON: `[{"PlayerType":2,"Name":"hitting","FetchTime":"2019-08-20T10:00:00Z"}]`,
+			cleared:       true,
+			wantScoreCategories: []request.ScoreCategory{
+				{Name: "hitting", PlayerType: 2, FriendScores: []request.FriendScore{{Name: "Bob", Score: 7}}},
+				{Name: "pitching", PlayerType: 3, FriendScores: []request.FriendScore{{Name: "Alice", Score: 4}}},
+			},
+			wantStatuses: []EtlStatus{
+				{PlayerType: 2, Name: "hitting", FetchTime: previousTime, FailTime: currentTime, Error: "requesting Hitting stats: timeout"},
+				{PlayerType: 3, Name: "pitching", FetchTime: currentTime},
+			},
+		},
+		{ // no previous stats
+			wantScoreCategories: []request.ScoreCategory{
+				{Name: "Hitting", Description: "hits", PlayerType: 2},
+				{Name: "pitching", PlayerType: 3, FriendScores: []request.FriendScore{{Name: "Alice", Score: 4}}},
+			},
+			wantStatuses: []EtlStatus{
+				{PlayerType: 2, Name: "Hitting", FailTime: currentTime, Error: "requesting Hitting stats: timeout"},
+				{PlayerType: 3, Name: "pitching", FetchTime: currentTime},
+			},
+		},
+	}
+	for i, test := range refreshEtlStatsKeepPreviousStatsTests {
+		stat := db.Stat{Year: 2019, EtlJSON: test.etlJSON, EtlStatusJSON: test.etlStatusJSON}
+		if len(test.etlJSON) != 0 && !test.cleared {
+			stat.EtlTimestamp = &previousTime
+		}
+		ds := mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				return &stat, nil
+			},
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return nil, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{
+					2: {SportType: 1, Name: "Hitting", Description: "hits", DisplayOrder: 1},
+					3: {SportType: 1, Name: "Pitching", DisplayOrder: 2},
+				}
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return currentTime
+			},
+			SetStatFunc: func(s db.Stat) error {
+				stat = s
+				return nil
+			},
+		}
+		scoreCategorizers := map[db.PlayerType]request.ScoreCategorizer{
+			2: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					return request.ScoreCategory{}, fmt.Errorf("timeout")
+				},
+			},
+			3: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					return request.ScoreCategory{Name: "pitching", PlayerType: pt, FriendScores: []request.FriendScore{{Name: "Alice", Score: 4}}}, nil
+				},
+			},
+		}
+		es, err := refreshEtlStats(context.Background(), 1, ds, scoreCategorizers)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		gotStatuses := make([]EtlStatus, len(es.scoreCategories))
+		for j, sc := range es.scoreCategories {
+			gotStatuses[j] = es.status(sc)
+		}
+		switch {
+		case !reflect.DeepEqual(test.wantScoreCategories, es.scoreCategories):
+			t.Errorf("Test %v: score categories not equal:\nwanted: %v\ngot:    %v", i, test.wantScoreCategories, es.scoreCategories)
+		case !reflect.DeepEqual(test.wantStatuses, gotStatuses):
+			t.Errorf("Test %v: statuses not equal:\nwanted: %v\ngot:    %v", i, test.wantStatuses, gotStatuses)
+		case len(es.failedStatuses()) != 1 || !es.failedStatuses()[0].Failed():
+			t.Errorf("Test %v: wanted one failed status, got %v", i, es.failedStatuses())
+		}
+	}
+}
+
+func TestMergeScoreCategories_allFailed(t *testing.T) {
+	previousTime := time.Date(2019, time.August, 20, 10, 0, 0, 0, time.UTC)
+	currentTime := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	results := []scoreCategoryResult{
+		{pt: 2, err: fmt.Errorf("timeout")},
+		{pt: 3, err: fmt.Errorf("bad gateway")},
+	}
+	if _, _, err := mergeScoreCategories(EtlStats{}, results, currentTime); err == nil {
+		t.Error("wanted error when no categories have stats")
+	}
+	previous := EtlStats{
+		etlTime:         previousTime,
+		scoreCategories: []request.ScoreCategory{{PlayerType: 3}},
+	}
+	if _, _, err := mergeScoreCategories(previous, results, currentTime); err != nil {
+		t.Errorf("unexpected error when a category has previous stats: %v", err)
+	}
+}
+
+// blockingScoreCategorizer waits to request score categories until the context is done.
+type blockingScoreCategorizer struct {
+	started chan<- struct{}
+}
+
+func (sc blockingScoreCategorizer) RequestScoreCategory(ctx context.Context, pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+	sc.started <- struct{}{}
+	<-ctx.Done()
+	return request.ScoreCategory{}, ctx.Err()
+}
+
+func TestGetScoreCategories_errors(t *testing.T) {
+	ds := mockEtlDatastore{
+		GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+			return nil, nil
+		},
+		GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+			return nil, nil
+		},
+		PlayerTypesFunc: func() db.PlayerTypeMap {
+			return db.PlayerTypeMap{
+				1: {SportType: 1, Name: "hitting", DisplayOrder: 1},
+				2: {SportType: 1, Name: "pitching", DisplayOrder: 2},
+				3: {SportType: 1, Name: "teams", DisplayOrder: 3},
+			}
+		},
+	}
+	failingScoreCategorizer := mockScoreCategorizer{
+		RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+			return request.ScoreCategory{}, fmt.Errorf("request error")
+		},
+	}
+	started := make(chan struct{}, 1)
+	scoreCategorizers := map[db.PlayerType]request.ScoreCategorizer{
+		1: failingScoreCategorizer,
+		2: failingScoreCategorizer,
+		3: blockingScoreCategorizer{started: started},
+	}
+	before := runtime.NumGoroutine()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancelFunc()
+	}()
+	results, err := getScoreCategories(ctx, 1, ds, 2019, scoreCategorizers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("wanted a result for each category, got %v", results)
+	}
+	for i, r := range results {
+		if r.err == nil {
+			t.Errorf("Test %v: wanted error for %v", i, r.pti.Name)
+		}
+	}
+	if !errors.Is(results[2].err, context.Canceled) {
+		t.Errorf("wanted canceled request error for %v, got %v", results[2].pti.Name, results[2].err)
+	}
+	_, _, err = mergeScoreCategories(EtlStats{}, results, time.Now())
+	switch {
+	case err == nil:
+		t.Error("wanted error when all categories failed")
+	default:
+		for _, name := range []string{"hitting", "pitching", "teams"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("wanted error to list failed %v category, got %v", name, err)
+			}
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Errorf("wanted all goroutines to exit, but %v are still running", runtime.NumGoroutine()-before)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGetRosterEtlStats(t *testing.T) {
+	currentTime := time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC)
+	getRosterEtlStatsTests := []struct {
+		getStatErr    error
+		getFriendsErr error
+		getPlayersErr error
+		wantErr       bool
+	}{
+		{},
+		{getStatErr: errors.New("getStat error"), wantErr: true},
+		{getFriendsErr: errors.New("getFriends error"), wantErr: true},
+		{getPlayersErr: errors.New("getPlayers error"), wantErr: true},
+	}
+	for i, test := range getRosterEtlStatsTests {
+		ds := mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				return &db.Stat{Year: 2019}, test.getStatErr
+			},
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return []db.Friend{{ID: "1", Name: "Bobby"}}, test.getFriendsErr
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				players := []db.Player{
+					{ID: "1", PlayerType: 2, SourceID: 547180, FriendID: "1", Name: "Bryce Harper"},
+					{ID: "2", PlayerType: 3, SourceID: 543037, FriendID: "1"},
+				}
+				return players, test.getPlayersErr
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{1: {Name: "mlb"}}
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{
+					2: {SportType: 1, Name: "hitting", DisplayOrder: 2},
+					3: {SportType: 1, Name: "pitching", DisplayOrder: 3},
+					5: {SportType: 2, Name: "nfl teams", DisplayOrder: 1},
+				}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return currentTime
+			},
+		}
+		es, err := getRosterEtlStats(context.Background(), 1, ds, errors.New("stats api down"))
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case es.year != 2019 || es.sportTypeName != "mlb":
+			t.Errorf("Test %v: wanted roster stats for 2019 mlb, got %v", i, es)
+		case len(es.scoreCategories) != 2 || es.scoreCategories[0].PlayerType != 2 || es.scoreCategories[1].PlayerType != 3:
+			t.Errorf("Test %v: wanted a ScoreCategory for each PlayerType of the SportType, got %v", i, es.scoreCategories)
+		case es.scoreCategories[0].FriendScores[0].PlayerScores[0].Name != "Bryce Harper":
+			t.Errorf("Test %v: wanted saved player name to be used, got %v", i, es.scoreCategories[0])
+		case len(es.failedStatuses()) != 2 || es.failedStatuses()[0].Error != "stats api down" || !es.failedStatuses()[0].FailTime.Equal(currentTime):
+			t.Errorf("Test %v: wanted every category to have failed with the stats error, got %v", i, es.failedStatuses())
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+type (
+	// StatsEvent is sent to clients viewing stats when the stats of a SportType change.
+	StatsEvent struct {
+		EtlTime         time.Time
+		ScoreCategories []request.ScoreCategory
+		Statuses        []EtlStatus
+	}
+
+	// eventBroker sends events to the clients subscribed to a SportType.
+	eventBroker struct {
+		mu          sync.Mutex
+		subscribers map[db.SportType]map[chan []byte]bool
+	}
+)
+
+// eventKeepAlivePeriod is how often a comment is sent to idle clients so connections are not closed by proxies.
+const eventKeepAlivePeriod = 30 * time.Second
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		subscribers: make(map[db.SportType]map[chan []byte]bool),
+	}
+}
+
+// subscribe creates a channel that receives the events for the SportType.
+func (eb *eventBroker) subscribe(st db.SportType) chan []byte {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	ch := make(chan []byte, 1)
+	if _, ok := eb.subscribers[st]; !ok {
+		eb.subscribers[st] = make(map[chan []byte]bool)
+	}
+	eb.subscribers[st][ch] = true
+	return ch
+}
+
+func (eb *eventBroker) unsubscribe(st db.SportType, ch chan []byte) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	delete(eb.subscribers[st], ch)
+}
+
+// publish sends the data to all subscribers of the SportType.
+// Subscribers that have not received the previous event are sent the new event instead.
+func (eb *eventBroker) publish(st db.SportType, data []byte) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	for ch := range eb.subscribers[st] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- data
+	}
+}
+
+// publishStats sends the scoreCategories of the EtlStats to subscribers of its SportType.
+func (eb *eventBroker) publishStats(es EtlStats) error {
+	statsEvent := StatsEvent{
+		EtlTime:         es.etlTime,
+		ScoreCategories: es.scoreCategories,
+		Statuses:        make([]EtlStatus, len(es.scoreCategories)),
+	}
+	for i, sc := range es.scoreCategories {
+		statsEvent.Statuses[i] = es.status(sc)
+	}
+	data, err := json.Marshal(statsEvent)
+	if err != nil {
+		return fmt.Errorf("converting StatsEvent to json: %w", err)
+	}
+	eb.publish(es.sportType, data)
+	return nil
+}
+
+// serveEvents writes events for the SportType to the response until the request is done.
+func (eb *eventBroker) serveEvents(st db.SportType, w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming events not supported")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	ch := eb.subscribe(st)
+	defer eb.unsubscribe(st, ch)
+	keepAlive := time.NewTicker(eventKeepAlivePeriod)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil // client disconnected
+			}
+		case data := <-ch:
+			if _, err := fmt.Fprintf(w, "event: stats\ndata: %s\n\n", data); err != nil {
+				return nil // client disconnected
+			}
+		}
+		flusher.Flush()
+	}
+}
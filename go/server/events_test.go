@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+func TestEventBrokerPublish(t *testing.T) {
+	eb := newEventBroker()
+	mlbCh := eb.subscribe(db.SportTypeMlb)
+	nflCh := eb.subscribe(db.SportTypeNfl)
+	eb.publish(db.SportTypeMlb, []byte("first"))
+	eb.publish(db.SportTypeMlb, []byte("second")) // should replace first event because it was not received
+	select {
+	case got := <-mlbCh:
+		if want := "second"; want != string(got) {
+			t.Errorf("wanted %v, got %v", want, string(got))
+		}
+	default:
+		t.Error("wanted event for mlb subscriber")
+	}
+	select {
+	case got := <-nflCh:
+		t.Errorf("did not want event for nfl subscriber, got %s", got)
+	default:
+	}
+	eb.unsubscribe(db.SportTypeMlb, mlbCh)
+	eb.publish(db.SportTypeMlb, []byte("third"))
+	select {
+	case got := <-mlbCh:
+		t.Errorf("did not want event after unsubscribing, got %s", got)
+	default:
+	}
+}
+
+func TestEventBrokerServeEvents(t *testing.T) {
+	eb := newEventBroker()
+	st := db.SportTypeMlb
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := withGzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := eb.serveEvents(st, w, r); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}))
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	r, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+	resp, err := ts.Client().Do(r) // the client transparently requests and decompresses gzip
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if want, got := "text/event-stream", resp.Header.Get("Content-Type"); want != got {
+		t.Errorf("wanted content type %v, got %v", want, got)
+	}
+	for !eb.hasSubscribers(st) {
+		time.Sleep(time.Millisecond)
+	}
+	es := EtlStats{
+		sportType:       st,
+		etlTime:         time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC),
+		scoreCategories: []request.ScoreCategory{{Name: "Teams"}},
+		statuses: map[db.PlayerType]EtlStatus{
+			0: {
+				Name:      "Teams",
+				FetchTime: time.Date(2020, time.March, 31, 0, 0, 0, 0, time.UTC),
+				FailTime:  time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC),
+				Error:     "timeout",
+			},
+		},
+	}
+	if err := eb.publishStats(es); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	br := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected error reading event: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	want := "event: stats\n" +
+		`data: {"EtlTime":"2020-04-01T00:00:00Z","ScoreCategories":[{"Name":"Teams","Description":"","PlayerType":0,"FriendScores":null}],` +
+		`"Statuses":[{"PlayerType":0,"Name":"Teams","FetchTime":"2020-03-31T00:00:00Z","FailTime":"2020-04-01T00:00:00Z","Error":"timeout"}]}` + "\n" +
+		"\n"
+	if got := strings.Join(lines, ""); want != got {
+		t.Errorf("events not equal:\nwanted: %q\ngot:    %q", want, got)
+	}
+}
+
+func (eb *eventBroker) hasSubscribers(st db.SportType) bool {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return len(eb.subscribers[st]) > 0
+}
@@ -2,15 +2,88 @@ package server
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
 	"github.com/jacobpatterson1549/nate-mlb/go/request"
 )
 
+type (
+	// Export is the structured export of the scores of a SportType for a year.
+	Export struct {
+		ApplicationName string
+		SportType       string
+		Year            int
+		AsOf            time.Time
+		ScoreCategories []ExportScoreCategory
+	}
+
+	// ExportScoreCategory contains the scores of the friends for a PlayerType.
+	ExportScoreCategory struct {
+		Name        string
+		Description string
+		PlayerType  db.PlayerType
+		Friends     []ExportFriend
+	}
+
+	// ExportFriend contains the score of a friend and the scores of the friend's players.
+	// The Rank is the place of the friend in the ScoreCategory.
+	ExportFriend struct {
+		Name    string
+		Score   int
+		Rank    int
+		Players []ExportPlayer
+	}
+
+	// ExportPlayer is the score of a player on a friend's roster.
+	// The Rank is the place of the player among all of the players in the ScoreCategory.
+	ExportPlayer struct {
+		Name     string
+		SourceID db.SourceID
+		Score    int
+		Rank     int
+		AddDate  *time.Time `json:",omitempty"`
+		DropDate *time.Time `json:",omitempty"`
+	}
+
+	// exportFormat is a way to write the scores to a file.
+	exportFormat struct {
+		fileSuffix  string
+		contentType string
+		export      func(es EtlStats, applicationName string, w io.Writer) error
+	}
+)
+
+// exportFormats are the formats that EtlStats can be exported to, by the names used to request them
+var exportFormats = map[string]exportFormat{
+	"csv":  {fileSuffix: ".csv", contentType: "text/csv", export: exportToCsv},
+	"tidy": {fileSuffix: "_tidy.csv", contentType: "text/csv", export: exportToTidyCsv},
+	"json": {fileSuffix: ".json", contentType: "application/json", export: exportToJSON},
+	"xlsx": {fileSuffix: ".xlsx", contentType: xlsxContentType, export: exportToXlsx},
+}
+
+// getExportFormat gets the export format with the name, which is csv if the name is empty.
+func getExportFormat(name string) (exportFormat, error) {
+	if len(name) == 0 {
+		name = "csv"
+	}
+	ef, ok := exportFormats[name]
+	if !ok {
+		return ef, fmt.Errorf("unknown export format: %q", name)
+	}
+	return ef, nil
+}
+
 func exportToCsv(es EtlStats, applicationName string, w io.Writer) error {
 	records := createCsvRecords(es, applicationName)
+	return writeCsvRecords(records, w)
+}
+
+func writeCsvRecords(records [][]string, w io.Writer) error {
 	csvWriter := csv.NewWriter(w)
 	err := csvWriter.WriteAll(records)
 	if err != nil {
@@ -52,3 +125,125 @@ func createCsvRecord(sc request.ScoreCategory, fs request.FriendScore, ps reques
 	record[4] = strconv.Itoa(ps.Score)
 	return record
 }
+
+// exportToTidyCsv writes a row for each player, with a header row.
+func exportToTidyCsv(es EtlStats, applicationName string, w io.Writer) error {
+	records := [][]string{{"category", "friend", "player", "score", "rank"}}
+	for _, row := range newExport(es, applicationName).playerRows() {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = fmt.Sprint(cell)
+		}
+		records = append(records, record)
+	}
+	return writeCsvRecords(records, w)
+}
+
+func exportToJSON(es EtlStats, applicationName string, w io.Writer) error {
+	e := newExport(es, applicationName)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(e); err != nil {
+		return fmt.Errorf("writing to json: %w", err)
+	}
+	return nil
+}
+
+// exportToXlsx writes a workbook with a sheet of the scores of the players and a sheet of the scores of the friends.
+func exportToXlsx(es EtlStats, applicationName string, w io.Writer) error {
+	e := newExport(es, applicationName)
+	playersSheet := xlsxSheet{
+		name: "Players",
+		rows: append([][]interface{}{{"category", "friend", "player", "score", "rank"}}, e.playerRows()...),
+	}
+	friendsSheet := xlsxSheet{
+		name: "Friends",
+		rows: [][]interface{}{{"category", "friend", "score", "rank"}},
+	}
+	for _, sc := range e.ScoreCategories {
+		for _, f := range sc.Friends {
+			friendsSheet.rows = append(friendsSheet.rows, []interface{}{sc.Name, f.Name, f.Score, f.Rank})
+		}
+	}
+	return writeXlsx(w, playersSheet, friendsSheet)
+}
+
+func newExport(es EtlStats, applicationName string) Export {
+	e := Export{
+		ApplicationName: applicationName,
+		SportType:       es.sportTypeName,
+		Year:            es.year,
+		AsOf:            es.etlTime,
+		ScoreCategories: make([]ExportScoreCategory, len(es.scoreCategories)),
+	}
+	for i, sc := range es.scoreCategories {
+		e.ScoreCategories[i] = newExportScoreCategory(sc)
+	}
+	return e
+}
+
+func newExportScoreCategory(sc request.ScoreCategory) ExportScoreCategory {
+	var friendScores, playerScores []int
+	for _, fs := range sc.FriendScores {
+		friendScores = append(friendScores, fs.Score)
+		for _, ps := range fs.PlayerScores {
+			playerScores = append(playerScores, ps.Score)
+		}
+	}
+	friendRanks, playerRanks := ranks(friendScores), ranks(playerScores)
+	esc := ExportScoreCategory{
+		Name:        sc.Name,
+		Description: sc.Description,
+		PlayerType:  sc.PlayerType,
+		Friends:     make([]ExportFriend, len(sc.FriendScores)),
+	}
+	p := 0
+	for i, fs := range sc.FriendScores {
+		ef := ExportFriend{
+			Name:    fs.Name,
+			Score:   fs.Score,
+			Rank:    friendRanks[i],
+			Players: make([]ExportPlayer, len(fs.PlayerScores)),
+		}
+		for j, ps := range fs.PlayerScores {
+			ef.Players[j] = ExportPlayer{
+				Name:     ps.Name,
+				SourceID: ps.SourceID,
+				Score:    ps.Score,
+				Rank:     playerRanks[p],
+				AddDate:  ps.AddDate,
+				DropDate: ps.DropDate,
+			}
+			p++
+		}
+		esc.Friends[i] = ef
+	}
+	return esc
+}
+
+// ranks are the places of the scores, with the highest score first.  Equal scores have the same rank, so the scores 9, 7, 7, 5 have the ranks 1, 2, 2, 4.
+func ranks(scores []int) []int {
+	ranks := make([]int, len(scores))
+	for i, score := range scores {
+		ranks[i] = 1
+		for _, other := range scores {
+			if other > score {
+				ranks[i]++
+			}
+		}
+	}
+	return ranks
+}
+
+// playerRows are the category, friend, player, score, and rank of each player.
+func (e Export) playerRows() [][]interface{} {
+	var rows [][]interface{}
+	for _, sc := range e.ScoreCategories {
+		for _, f := range sc.Friends {
+			for _, p := range f.Players {
+				rows = append(rows, []interface{}{sc.Name, f.Name, p.Name, p.Score, p.Rank})
+			}
+		}
+	}
+	return rows
+}
@@ -2,10 +2,14 @@ package server
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
 	"github.com/jacobpatterson1549/nate-mlb/go/request"
 )
 
@@ -108,3 +112,243 @@ func TestCreateCsvRecords(t *testing.T) {
 		t.Errorf("different CSV:\nwanted: %v\ngot:    %v", want, got)
 	}
 }
+
+func TestNewExport(t *testing.T) {
+	addDate := time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+	es := EtlStats{
+		etlTime: time.Date(2018, time.September, 3, 12, 0, 0, 0, time.UTC),
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name:        "teams",
+				Description: "Wins",
+				PlayerType:  db.PlayerTypeNflTeam,
+				FriendScores: []request.FriendScore{
+					{
+						Name: "Arnold",
+						PlayerScores: []request.PlayerScore{
+							{Name: "San Francisco 49ers", Score: 4, SourceID: 25},
+							{Name: "Arizona Cardinals", Score: 7, SourceID: 22, AddDate: &addDate},
+						},
+						Score: 11,
+					},
+					{
+						Name: "Bert",
+						PlayerScores: []request.PlayerScore{
+							{Name: "Green Bay Packers", Score: 7, SourceID: 9},
+						},
+						Score: 7,
+					},
+				},
+			},
+		},
+		sportTypeName: "american football",
+		year:          2018,
+	}
+	want := Export{
+		ApplicationName: "app3",
+		SportType:       "american football",
+		Year:            2018,
+		AsOf:            es.etlTime,
+		ScoreCategories: []ExportScoreCategory{
+			{
+				Name:        "teams",
+				Description: "Wins",
+				PlayerType:  db.PlayerTypeNflTeam,
+				Friends: []ExportFriend{
+					{
+						Name:  "Arnold",
+						Score: 11,
+						Rank:  1,
+						Players: []ExportPlayer{
+							{Name: "San Francisco 49ers", SourceID: 25, Score: 4, Rank: 3},
+							{Name: "Arizona Cardinals", SourceID: 22, Score: 7, Rank: 1, AddDate: &addDate},
+						},
+					},
+					{
+						Name:  "Bert",
+						Score: 7,
+						Rank:  2,
+						Players: []ExportPlayer{
+							{Name: "Green Bay Packers", SourceID: 9, Score: 7, Rank: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+	got := newExport(es, "app3")
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("exports not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestRanks(t *testing.T) {
+	ranksTests := []struct {
+		scores []int
+		want   []int
+	}{
+		{scores: []int{}, want: []int{}},
+		{scores: []int{3}, want: []int{1}},
+		{scores: []int{5, 9, 7, 7}, want: []int{4, 1, 2, 2}},
+		{scores: []int{0, 0}, want: []int{1, 1}},
+	}
+	for i, test := range ranksTests {
+		if got := ranks(test.scores); !reflect.DeepEqual(test.want, got) {
+			t.Errorf("Test %v: wanted ranks of %v to be %v, got %v", i, test.scores, test.want, got)
+		}
+	}
+}
+
+func TestExportToTidyCsv(t *testing.T) {
+	es := EtlStats{
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name: "teams",
+				FriendScores: []request.FriendScore{
+					{
+						Name: "Arnold",
+						PlayerScores: []request.PlayerScore{
+							{Name: "San Francisco 49ers", Score: 4},
+							{Name: "Arizona Cardinals", Score: 7},
+						},
+						Score: 11,
+					},
+					{
+						Name: "Bert",
+						PlayerScores: []request.PlayerScore{
+							{Name: "Green Bay Packers", Score: 7},
+						},
+						Score: 7,
+					},
+				},
+			},
+		},
+	}
+	var w bytes.Buffer
+	err := exportToTidyCsv(es, "app4", &w)
+	want := "category,friend,player,score,rank\n" +
+		"teams,Arnold,San Francisco 49ers,4,3\n" +
+		"teams,Arnold,Arizona Cardinals,7,1\n" +
+		"teams,Bert,Green Bay Packers,7,1\n"
+	got := w.String()
+	switch {
+	case err != nil:
+		t.Errorf("did not expect error: %v", err)
+	case want != got:
+		t.Errorf("different csv:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestExportToJSON(t *testing.T) {
+	es := EtlStats{
+		etlTime: time.Date(2018, time.September, 3, 12, 0, 0, 0, time.UTC),
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name:       "teams",
+				PlayerType: db.PlayerTypeNflTeam,
+				FriendScores: []request.FriendScore{
+					{
+						Name: "Arnold",
+						PlayerScores: []request.PlayerScore{
+							{Name: "San Francisco 49ers", Score: 4, SourceID: 25},
+						},
+						Score: 4,
+					},
+				},
+			},
+		},
+		sportTypeName: "american football",
+		year:          2018,
+	}
+	var w bytes.Buffer
+	if err := exportToJSON(es, "app5", &w); err != nil {
+		t.Fatalf("did not expect error: %v", err)
+	}
+	var got Export
+	if err := json.Unmarshal(w.Bytes(), &got); err != nil {
+		t.Fatalf("reading exported json: %v", err)
+	}
+	want := newExport(es, "app5")
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("exports not equal after reading json:\nwanted: %v\ngot:    %v", want, got)
+	}
+	if strings.Contains(w.String(), "DropDate") {
+		t.Errorf("wanted missing drop dates to be omitted: %v", w.String())
+	}
+}
+
+func TestExportToXlsx(t *testing.T) {
+	es := EtlStats{
+		scoreCategories: []request.ScoreCategory{
+			{
+				Name: "teams",
+				FriendScores: []request.FriendScore{
+					{
+						Name: "Arnold",
+						PlayerScores: []request.PlayerScore{
+							{Name: "San Francisco 49ers", Score: 4},
+							{Name: "Arizona Cardinals", Score: 7},
+						},
+						Score: 11,
+					},
+					{
+						Name: "Bert",
+						PlayerScores: []request.PlayerScore{
+							{Name: "Green Bay Packers", Score: 7},
+						},
+						Score: 7,
+					},
+				},
+			},
+		},
+	}
+	var w bytes.Buffer
+	if err := exportToXlsx(es, "app6", &w); err != nil {
+		t.Fatalf("did not expect error: %v", err)
+	}
+	sheets := readXlsxSheets(t, w.Bytes())
+	want := [][][]string{
+		{
+			{"category", "friend", "player", "score", "rank"},
+			{"teams", "Arnold", "San Francisco 49ers", "4", "3"},
+			{"teams", "Arnold", "Arizona Cardinals", "7", "1"},
+			{"teams", "Bert", "Green Bay Packers", "7", "1"},
+		},
+		{
+			{"category", "friend", "score", "rank"},
+			{"teams", "Arnold", "11", "1"},
+			{"teams", "Bert", "7", "2"},
+		},
+	}
+	if !reflect.DeepEqual(want, sheets) {
+		t.Errorf("sheets not equal:\nwanted: %v\ngot:    %v", want, sheets)
+	}
+}
+
+func TestGetExportFormat(t *testing.T) {
+	getExportFormatTests := []struct {
+		name           string
+		wantFileSuffix string
+		wantErr        bool
+	}{
+		{name: "", wantFileSuffix: ".csv"},
+		{name: "csv", wantFileSuffix: ".csv"},
+		{name: "tidy", wantFileSuffix: "_tidy.csv"},
+		{name: "json", wantFileSuffix: ".json"},
+		{name: "xlsx", wantFileSuffix: ".xlsx"},
+		{name: "pdf", wantErr: true},
+	}
+	for i, test := range getExportFormatTests {
+		got, err := getExportFormat(test.name)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantFileSuffix != got.fileSuffix:
+			t.Errorf("Test %v: wanted file suffix %q, got %q", i, test.wantFileSuffix, got.fileSuffix)
+		}
+	}
+}
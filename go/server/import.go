@@ -0,0 +1,436 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+type (
+	// ImportRow is a row of a roster csv or a player of a json export with the players it can be for.
+	// The player of the row is a name or source id.  The Line is zero for players of a json export.
+	ImportRow struct {
+		Line           int
+		FriendName     string
+		NewFriend      bool
+		PlayerType     db.PlayerType
+		PlayerTypeName string
+		Player         string
+		Matches        []request.PlayerSearchResult
+		// Resolved is true if the first match is clearly the player of the row.  Otherwise, one of the matches must be chosen when the import is reviewed.
+		Resolved bool
+		Error    string
+	}
+
+	// importPlayer is a player chosen for a friend when an import was reviewed.
+	importPlayer struct {
+		friendName string
+		playerInfo db.PlayerInfo
+	}
+
+	// rosteredPlayers are the source ids of the players on rosters, by PlayerType.
+	rosteredPlayers map[db.PlayerType]map[db.SourceID]bool
+
+	importDatastore interface {
+		GetFriends(ctx context.Context, st db.SportType) ([]db.Friend, error)
+		GetPlayers(ctx context.Context, st db.SportType) ([]db.Player, error)
+		PlayerTypes() db.PlayerTypeMap
+		adminDatastore
+	}
+)
+
+var importSourceIDRE = regexp.MustCompile("^import-([0-9]+)-source-id$")
+
+func handleImportPostRequest(ds importDatastore, searchers map[db.PlayerType]request.Searcher, year int, st db.SportType, r *http.Request) ([]ImportRow, error) {
+	if err := verifyUserPassword(ds, r); err != nil {
+		return nil, err
+	}
+	actionParam := r.FormValue("action")
+	switch actionParam {
+	case "preview":
+		return previewImport(ds, searchers, year, st, r)
+	case "import":
+		return nil, importPlayers(ds, st, r)
+	default:
+		return nil, fmt.Errorf("invalid import action: %v", actionParam)
+	}
+}
+
+// previewImport reads the rows of the roster and searches for their players so they can be reviewed.
+// The roster is a csv or a json export.
+func previewImport(ds importDatastore, searchers map[db.PlayerType]request.Searcher, year int, st db.SportType, r *http.Request) ([]ImportRow, error) {
+	roster := r.FormValue("roster")
+	parseImport := parseImportCsv
+	if strings.HasPrefix(strings.TrimSpace(roster), "{") {
+		parseImport = parseImportJSON
+	}
+	rows, err := parseImport(strings.NewReader(roster), st, ds.PlayerTypes())
+	if err != nil {
+		return nil, err
+	}
+	friends, err := ds.GetFriends(r.Context(), st)
+	if err != nil {
+		return nil, err
+	}
+	players, err := ds.GetPlayers(r.Context(), st)
+	if err != nil {
+		return nil, err
+	}
+	friendNames := make(map[string]bool, len(friends))
+	for _, friend := range friends {
+		friendNames[strings.ToLower(friend.Name)] = true
+	}
+	var wg sync.WaitGroup
+	for i := range rows {
+		rows[i].NewFriend = !friendNames[strings.ToLower(rows[i].FriendName)]
+		if len(rows[i].Error) != 0 || rows[i].Resolved {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rows[i].resolve(r.Context(), searchers, year)
+		}()
+	}
+	wg.Wait()
+	markRosteredPlayers(rows, players)
+	return rows, nil
+}
+
+// parseImportCsv reads the rows of friend names, player types, and players from the csv.
+// The player type can be the id or name of a PlayerType of the SportType.  A header row that starts with "friend" and lines that start with "#" are skipped.
+// Rows with invalid values have errors, but the csv must be well formed.
+func parseImportCsv(r io.Reader, st db.SportType, playerTypes db.PlayerTypeMap) ([]ImportRow, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+	csvReader.Comment = '#'
+	var rows []ImportRow
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv: %w", err)
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(rows) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "friend") {
+			continue
+		}
+		row := ImportRow{
+			Line:       line,
+			FriendName: strings.TrimSpace(record[0]),
+			Player:     strings.TrimSpace(record[2]),
+		}
+		pt, err := parseImportPlayerType(strings.TrimSpace(record[1]), st, playerTypes)
+		switch {
+		case err != nil:
+			row.Error = err.Error()
+		case len(row.FriendName) == 0:
+			row.Error = "missing friend name"
+		case len(row.Player) == 0:
+			row.Error = "missing player name or source id"
+		}
+		row.PlayerType = pt
+		row.PlayerTypeName = playerTypes[pt].Name
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no players to import")
+	}
+	return rows, nil
+}
+
+// parseImportJSON reads the rows of the players on the rosters of a json Export.
+// The players have the source ids and names of the export, so they are not searched for.
+// Dropped players are skipped and the dates players were added are not kept because they are for the season that was exported.
+func parseImportJSON(r io.Reader, st db.SportType, playerTypes db.PlayerTypeMap) ([]ImportRow, error) {
+	var e Export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("reading json: %w", err)
+	}
+	var rows []ImportRow
+	for _, sc := range e.ScoreCategories {
+		ptInfo, ok := playerTypes[sc.PlayerType]
+		for _, f := range sc.Friends {
+			for _, p := range f.Players {
+				if p.DropDate != nil {
+					continue
+				}
+				row := ImportRow{
+					FriendName:     f.Name,
+					PlayerType:     sc.PlayerType,
+					PlayerTypeName: ptInfo.Name,
+					Player:         p.Name,
+				}
+				if len(row.Player) == 0 {
+					row.Player = strconv.Itoa(int(p.SourceID))
+				}
+				switch {
+				case !ok || ptInfo.SportType != st:
+					row.Error = fmt.Sprintf("no player type with id %v", sc.PlayerType)
+				case len(row.FriendName) == 0:
+					row.Error = "missing friend name"
+				default:
+					row.Matches = []request.PlayerSearchResult{{Name: p.Name, SourceID: p.SourceID}}
+					row.Resolved = true
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no players to import")
+	}
+	return rows, nil
+}
+
+// parseImportPlayerType finds the PlayerType of the SportType with the id or name
+func parseImportPlayerType(playerType string, st db.SportType, playerTypes db.PlayerTypeMap) (db.PlayerType, error) {
+	if playerTypeI, err := strconv.Atoi(playerType); err == nil {
+		pt := db.PlayerType(playerTypeI)
+		if ptInfo, ok := playerTypes[pt]; ok && ptInfo.SportType == st {
+			return pt, nil
+		}
+		return 0, fmt.Errorf("no player type with id %v", playerType)
+	}
+	for pt, ptInfo := range playerTypes {
+		if ptInfo.SportType == st && strings.EqualFold(ptInfo.Name, playerType) {
+			return pt, nil
+		}
+	}
+	return 0, fmt.Errorf("no player type named %q", playerType)
+}
+
+// resolve searches for the players of the row.
+// A source id is the only match, but it has no name.  Inactive players are searched for if no active players match the name.
+func (row *ImportRow) resolve(ctx context.Context, searchers map[db.PlayerType]request.Searcher, year int) {
+	if sourceID, err := strconv.Atoi(row.Player); err == nil {
+		row.Matches = []request.PlayerSearchResult{{SourceID: db.SourceID(sourceID)}}
+		row.Resolved = true
+		return
+	}
+	searcher, ok := searchers[row.PlayerType]
+	if !ok {
+		row.Error = fmt.Sprintf("no searcher for playerType %v", row.PlayerType)
+		return
+	}
+	matches, err := searcher.Search(ctx, row.PlayerType, year, row.Player, true)
+	if err == nil && len(matches) == 0 {
+		matches, err = searcher.Search(ctx, row.PlayerType, year, row.Player, false)
+	}
+	switch {
+	case err != nil:
+		row.Error = err.Error()
+		return
+	case len(matches) == 0:
+		row.Error = "no players found"
+		return
+	}
+	best, ok := request.BestMatch(row.PlayerType, row.Player, matches)
+	if !ok {
+		row.Matches = matches
+		return
+	}
+	row.Matches = make([]request.PlayerSearchResult, 1, len(matches))
+	row.Matches[0] = best
+	for _, match := range matches {
+		if match.SourceID != best.SourceID {
+			row.Matches = append(row.Matches, match)
+		}
+	}
+	row.Resolved = true
+}
+
+// markRosteredPlayers adds errors to resolved rows for players that are already on a roster or are in earlier rows.
+// Players that were dropped can be imported again.
+func markRosteredPlayers(rows []ImportRow, players []db.Player) {
+	rp := newRosteredPlayers(players)
+	for i, row := range rows {
+		if row.Resolved && len(row.Error) == 0 && !rp.add(row.PlayerType, row.Matches[0].SourceID) {
+			rows[i].Error = "player is already on a roster"
+		}
+	}
+}
+
+// newRosteredPlayers creates the rosteredPlayers of the players that have not been dropped.
+func newRosteredPlayers(players []db.Player) rosteredPlayers {
+	rp := make(rosteredPlayers)
+	for _, player := range players {
+		if player.DropDate == nil {
+			rp.add(player.PlayerType, player.SourceID)
+		}
+	}
+	return rp
+}
+
+// add puts the player on a roster, returning false if it already was on one.
+func (rp rosteredPlayers) add(pt db.PlayerType, sourceID db.SourceID) bool {
+	if _, ok := rp[pt]; !ok {
+		rp[pt] = make(map[db.SourceID]bool)
+	}
+	if rp[pt][sourceID] {
+		return false
+	}
+	rp[pt][sourceID] = true
+	return true
+}
+
+// importPlayers adds the reviewed players to the rosters of their friends.
+// Friends that do not exist are added in the same transaction as the players.
+func importPlayers(ds importDatastore, st db.SportType, r *http.Request) error {
+	reviewedPlayers, err := getImportPlayers(st, ds.PlayerTypes(), r)
+	if err != nil {
+		return err
+	}
+	friends, err := ds.GetFriends(r.Context(), st)
+	if err != nil {
+		return err
+	}
+	futureFriends := addImportFriends(friends, reviewedPlayers)
+	newFriends := futureFriends[len(friends):]
+	friendIDs := make(map[string]db.ID, len(futureFriends))
+	friendNames := make(map[db.ID]string, len(futureFriends))
+	for _, friend := range futureFriends {
+		friendIDs[strings.ToLower(friend.Name)] = friend.ID
+		friendNames[friend.ID] = friend.Name
+	}
+	existingPlayers, err := ds.GetPlayers(r.Context(), st)
+	if err != nil {
+		return err
+	}
+	rp := newRosteredPlayers(existingPlayers)
+	newPlayers := make([]db.Player, len(reviewedPlayers))
+	var playerInfos []db.PlayerInfo
+	for i, ip := range reviewedPlayers {
+		friendID := friendIDs[strings.ToLower(ip.friendName)]
+		if !rp.add(ip.playerInfo.PlayerType, ip.playerInfo.SourceID) {
+			return fmt.Errorf("player %v (%v) is already on a roster", ip.playerInfo.SourceID, ip.playerInfo.Name)
+		}
+		newPlayers[i] = db.Player{
+			PlayerType: ip.playerInfo.PlayerType,
+			SourceID:   ip.playerInfo.SourceID,
+			FriendID:   friendID,
+		}
+		if len(ip.playerInfo.Name) != 0 {
+			playerInfos = append(playerInfos, ip.playerInfo)
+		}
+	}
+	players := appendPlayers(existingPlayers, newPlayers)
+	importedPlayers := make([]db.ImportedPlayer, len(newPlayers))
+	for i, player := range players[len(existingPlayers):] {
+		importedPlayers[i] = db.ImportedPlayer{
+			PlayerType:   player.PlayerType,
+			SourceID:     player.SourceID,
+			FriendName:   friendNames[player.FriendID],
+			DisplayOrder: player.DisplayOrder,
+		}
+	}
+	if err := ds.ImportRosters(r.Context(), st, newFriends, importedPlayers, playerInfos); err != nil {
+		return err
+	}
+	return ds.ClearStat(r.Context(), st)
+}
+
+// getImportPlayers gets the reviewed players from the form in the order of the rows they are from.
+func getImportPlayers(st db.SportType, playerTypes db.PlayerTypeMap, r *http.Request) ([]importPlayer, error) {
+	indexes := make(map[string]int)
+	var keys []string
+	for k := range r.Form {
+		if matches := importSourceIDRE.FindStringSubmatch(k); len(matches) > 1 {
+			index, err := strconv.Atoi(matches[1])
+			if err != nil {
+				return nil, fmt.Errorf("converting import row '%v' to number: %w", matches[1], err)
+			}
+			indexes[matches[1]] = index
+			keys = append(keys, matches[1])
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no players to import")
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return indexes[keys[i]] < indexes[keys[j]]
+	})
+	players := make([]importPlayer, len(keys))
+	for i, key := range keys {
+		ip, err := getImportPlayer(st, playerTypes, r, key)
+		if err != nil {
+			return nil, err
+		}
+		players[i] = ip
+	}
+	return players, nil
+}
+
+func getImportPlayer(st db.SportType, playerTypes db.PlayerTypeMap, r *http.Request, key string) (importPlayer, error) {
+	var ip importPlayer
+
+	ip.friendName = r.FormValue(fmt.Sprintf("import-%s-friend-name", key))
+	if len(ip.friendName) == 0 {
+		return ip, fmt.Errorf("missing friend name of import row %v", key)
+	}
+
+	playerType := r.FormValue(fmt.Sprintf("import-%s-player-type", key))
+	playerTypeI, err := strconv.Atoi(playerType)
+	if err != nil {
+		return ip, fmt.Errorf("converting player type '%v' to number: %w", playerType, err)
+	}
+	pt := db.PlayerType(playerTypeI)
+	if ptInfo, ok := playerTypes[pt]; !ok || ptInfo.SportType != st {
+		return ip, fmt.Errorf("cannot import player with PlayerType %v for SportType %v", pt, st)
+	}
+	ip.playerInfo.PlayerType = pt
+
+	sourceID := r.FormValue(fmt.Sprintf("import-%s-source-id", key))
+	sourceIDI, err := strconv.Atoi(sourceID)
+	if err != nil {
+		return ip, fmt.Errorf("converting player source id '%v' to number: %w", sourceID, err)
+	}
+	ip.playerInfo.SourceID = db.SourceID(sourceIDI)
+
+	ip.playerInfo.Name = r.FormValue(fmt.Sprintf("import-%s-name", key))
+	ip.playerInfo.Position = r.FormValue(fmt.Sprintf("import-%s-position", key))
+	ip.playerInfo.Team = r.FormValue(fmt.Sprintf("import-%s-team", key))
+
+	return ip, nil
+}
+
+// addImportFriends adds the friends of the imported players that do not exist after the existing friends.
+func addImportFriends(friends []db.Friend, reviewedPlayers []importPlayer) []db.Friend {
+	futureFriends := make([]db.Friend, len(friends))
+	copy(futureFriends, friends)
+	friendNames := make(map[string]bool, len(friends))
+	displayOrder := 0
+	for _, friend := range friends {
+		friendNames[strings.ToLower(friend.Name)] = true
+		if friend.DisplayOrder >= displayOrder {
+			displayOrder = friend.DisplayOrder + 1
+		}
+	}
+	for i, ip := range reviewedPlayers {
+		if friendNames[strings.ToLower(ip.friendName)] {
+			continue
+		}
+		friendNames[strings.ToLower(ip.friendName)] = true
+		futureFriends = append(futureFriends, db.Friend{
+			ID:           db.ID(fmt.Sprintf("import-%d", i)), // not an existing id, so it will be added
+			DisplayOrder: displayOrder,
+			Name:         ip.friendName,
+		})
+		displayOrder++
+	}
+	return futureFriends
+}
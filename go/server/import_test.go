@@ -0,0 +1,371 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+var importPlayerTypes = db.PlayerTypeMap{
+	db.PlayerTypeMlbTeam:   {SportType: db.SportTypeMlb, Name: "Teams"},
+	db.PlayerTypeMlbHitter: {SportType: db.SportTypeMlb, Name: "Hitting"},
+	db.PlayerTypeNflQB:     {SportType: db.SportTypeNfl, Name: "Quarterbacks"},
+}
+
+func TestParseImportCsv(t *testing.T) {
+	parseImportCsvTests := []struct {
+		csv     string
+		want    []ImportRow
+		wantErr bool
+	}{
+		{
+			csv:     "",
+			wantErr: true,
+		},
+		{
+			csv:     "Friend,Player Type,Player\n",
+			wantErr: true,
+		},
+		{
+			csv:     "Alice,Hitting\n",
+			wantErr: true,
+		},
+		{
+			csv: "Friend,Player Type,Player\n# keepers\nAlice, hitting, Mike Trout\nBob,1,Cubs\n",
+			want: []ImportRow{
+				{Line: 3, FriendName: "Alice", PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "Mike Trout"},
+				{Line: 4, FriendName: "Bob", PlayerType: db.PlayerTypeMlbTeam, PlayerTypeName: "Teams", Player: "Cubs"},
+			},
+		},
+		{
+			csv: "Alice,Quarterbacks,Tom Brady\nAlice,5,Tom Brady\nAlice,Pitching,545361\n,Hitting,545361\nAlice,Hitting,\n",
+			want: []ImportRow{
+				{Line: 1, FriendName: "Alice", Player: "Tom Brady", Error: `no player type named "Quarterbacks"`},
+				{Line: 2, FriendName: "Alice", Player: "Tom Brady", Error: "no player type with id 5"},
+				{Line: 3, FriendName: "Alice", Player: "545361", Error: `no player type named "Pitching"`},
+				{Line: 4, PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "545361", Error: "missing friend name"},
+				{Line: 5, FriendName: "Alice", PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Error: "missing player name or source id"},
+			},
+		},
+	}
+	for i, test := range parseImportCsvTests {
+		got, err := parseImportCsv(strings.NewReader(test.csv), db.SportTypeMlb, importPlayerTypes)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: rows not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	parseImportJSONTests := []struct {
+		json    string
+		want    []ImportRow
+		wantErr bool
+	}{
+		{
+			json:    "{",
+			wantErr: true,
+		},
+		{
+			json:    `{"ScoreCategories":[]}`,
+			wantErr: true,
+		},
+		{
+			json: `{"ScoreCategories":[{"PlayerType":2,"Friends":[{"Name":"Alice","Players":[` +
+				`{"Name":"Mike Trout","SourceID":545361,"AddDate":"2019-04-01T00:00:00Z"},` +
+				`{"SourceID":592450},` +
+				`{"Name":"Bryce Harper","SourceID":547180,"DropDate":"2019-05-01T00:00:00Z"}]}]}]}`,
+			want: []ImportRow{
+				{FriendName: "Alice", PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "Mike Trout", Matches: []request.PlayerSearchResult{{Name: "Mike Trout", SourceID: 545361}}, Resolved: true},
+				{FriendName: "Alice", PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "592450", Matches: []request.PlayerSearchResult{{SourceID: 592450}}, Resolved: true},
+			},
+		},
+		{
+			json: `{"ScoreCategories":[` +
+				`{"PlayerType":5,"Friends":[{"Name":"Alice","Players":[{"Name":"Tom Brady","SourceID":2504211}]}]},` +
+				`{"PlayerType":1,"Friends":[{"Players":[{"Name":"Cubs","SourceID":112}]}]}]}`,
+			want: []ImportRow{
+				{FriendName: "Alice", PlayerType: db.PlayerTypeNflQB, PlayerTypeName: "Quarterbacks", Player: "Tom Brady", Error: "no player type with id 5"},
+				{PlayerType: db.PlayerTypeMlbTeam, PlayerTypeName: "Teams", Player: "Cubs", Error: "missing friend name"},
+			},
+		},
+	}
+	for i, test := range parseImportJSONTests {
+		got, err := parseImportJSON(strings.NewReader(test.json), db.SportTypeMlb, importPlayerTypes)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.want, got):
+			t.Errorf("Test %v: rows not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestImportRowResolve(t *testing.T) {
+	trout := request.PlayerSearchResult{Name: "Mike Trout", SourceID: 545361}
+	smith1 := request.PlayerSearchResult{Name: "Will Smith", SourceID: 669257}
+	smith2 := request.PlayerSearchResult{Name: "Will Smith", SourceID: 519293}
+	oldTrout := request.PlayerSearchResult{Name: "Steve Trout", SourceID: 123}
+	searchErr := errors.New("search error")
+	searchers := map[db.PlayerType]request.Searcher{
+		db.PlayerTypeMlbHitter: mockSearcher{
+			SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]request.PlayerSearchResult, error) {
+				switch {
+				case playerNamePrefix == "error":
+					return nil, searchErr
+				case playerNamePrefix == "Will Smith":
+					return []request.PlayerSearchResult{smith1, smith2}, nil
+				case playerNamePrefix == "Trout" && activePlayersOnly:
+					return []request.PlayerSearchResult{oldTrout, trout}, nil
+				case playerNamePrefix == "Steve Trout" && !activePlayersOnly:
+					return []request.PlayerSearchResult{oldTrout}, nil
+				}
+				return nil, nil
+			},
+		},
+	}
+	importRowResolveTests := []struct {
+		pt   db.PlayerType
+		row  ImportRow
+		want ImportRow
+	}{
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "545361"},
+			want: ImportRow{Player: "545361", Matches: []request.PlayerSearchResult{{SourceID: 545361}}, Resolved: true},
+		},
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "Trout"},
+			want: ImportRow{Player: "Trout", Matches: []request.PlayerSearchResult{oldTrout, trout}},
+		},
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "Will Smith"},
+			want: ImportRow{Player: "Will Smith", Matches: []request.PlayerSearchResult{smith1, smith2}},
+		},
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "Steve Trout"},
+			want: ImportRow{Player: "Steve Trout", Matches: []request.PlayerSearchResult{oldTrout}, Resolved: true},
+		},
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "Nobody"},
+			want: ImportRow{Player: "Nobody", Error: "no players found"},
+		},
+		{
+			pt:   db.PlayerTypeMlbHitter,
+			row:  ImportRow{Player: "error"},
+			want: ImportRow{Player: "error", Error: searchErr.Error()},
+		},
+		{
+			pt:   db.PlayerTypeMlbTeam,
+			row:  ImportRow{Player: "Cubs"},
+			want: ImportRow{Player: "Cubs", Error: "no searcher for playerType 1"},
+		},
+	}
+	for i, test := range importRowResolveTests {
+		got := test.row
+		got.PlayerType = test.pt
+		test.want.PlayerType = test.pt
+		got.resolve(context.Background(), searchers, 2019)
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("Test %v: rows not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestHandleImportPostRequest(t *testing.T) {
+	st := db.SportTypeMlb
+	friends := []db.Friend{{ID: "a", DisplayOrder: 0, Name: "Alice"}}
+	dropDate := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+	existingPlayers := []db.Player{
+		{ID: "p1", FriendID: "a", PlayerType: db.PlayerTypeMlbHitter, SourceID: 592450, DisplayOrder: 1},
+		{ID: "p2", FriendID: "a", PlayerType: db.PlayerTypeMlbHitter, SourceID: 660271, DisplayOrder: 2, DropDate: &dropDate},
+	}
+	var savedFriends []db.Friend
+	var savedPlayers []db.ImportedPlayer
+	var savedPlayerInfos []db.PlayerInfo
+	clearStatCalled := false
+	ds := mockServerDatastore{
+		adminDatastore: mockAdminDatastore{
+			IsCorrectUserPasswordFunc: func(username string, p db.Password) (bool, error) {
+				return p == "secret", nil
+			},
+			ImportRostersFunc: func(st db.SportType, newFriends []db.Friend, importedPlayers []db.ImportedPlayer, playerInfos []db.PlayerInfo) error {
+				savedFriends = newFriends
+				savedPlayers = importedPlayers
+				savedPlayerInfos = playerInfos
+				return nil
+			},
+			ClearStatFunc: func(st db.SportType) error {
+				clearStatCalled = true
+				return nil
+			},
+		},
+		etlDatastore: mockEtlDatastore{
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return friends, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return existingPlayers, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return importPlayerTypes
+			},
+		},
+	}
+	searchers := map[db.PlayerType]request.Searcher{
+		db.PlayerTypeMlbHitter: mockSearcher{
+			SearchFunc: func(pt db.PlayerType, year int, playerNamePrefix string, activePlayersOnly bool) ([]request.PlayerSearchResult, error) {
+				return []request.PlayerSearchResult{
+					{Name: "Aaron Judge", SourceID: 592450},
+					{Name: "Mike Trout", SourceID: 545361, Position: "CF", Team: "LAA"},
+				}, nil
+			},
+		},
+	}
+	handleImportPostRequestTests := []struct {
+		form     url.Values
+		wantRows []ImportRow
+		wantErr  bool
+	}{
+		{
+			form:    url.Values{"action": {"preview"}, "password": {"wrong"}, "roster": {"Alice,Hitting,Mike Trout"}},
+			wantErr: true,
+		},
+		{
+			form:    url.Values{"action": {"unknown"}, "password": {"secret"}},
+			wantErr: true,
+		},
+		{
+			form:    url.Values{"action": {"preview"}, "password": {"secret"}, "roster": {"Alice,Hitting"}},
+			wantErr: true,
+		},
+		{
+			form: url.Values{"action": {"preview"}, "password": {"secret"}, "roster": {"alice,Hitting,Mike Trout\nBob,Hitting,Judge\nBob,Hitting,Trout"}},
+			wantRows: []ImportRow{
+				{
+					Line: 1, FriendName: "alice", PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "Mike Trout", Resolved: true,
+					Matches: []request.PlayerSearchResult{{Name: "Mike Trout", SourceID: 545361, Position: "CF", Team: "LAA"}, {Name: "Aaron Judge", SourceID: 592450}},
+				},
+				{
+					Line: 2, FriendName: "Bob", NewFriend: true, PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "Judge", Resolved: true,
+					Matches: []request.PlayerSearchResult{{Name: "Aaron Judge", SourceID: 592450}, {Name: "Mike Trout", SourceID: 545361, Position: "CF", Team: "LAA"}},
+					Error:   "player is already on a roster",
+				},
+				{
+					Line: 3, FriendName: "Bob", NewFriend: true, PlayerType: db.PlayerTypeMlbHitter, PlayerTypeName: "Hitting", Player: "Trout", Resolved: true,
+					Matches: []request.PlayerSearchResult{{Name: "Mike Trout", SourceID: 545361, Position: "CF", Team: "LAA"}, {Name: "Aaron Judge", SourceID: 592450}},
+					Error:   "player is already on a roster", // in the first row
+				},
+			},
+		},
+		{
+			form:    url.Values{"action": {"import"}, "password": {"secret"}},
+			wantErr: true,
+		},
+		{
+			form: url.Values{"action": {"import"}, "password": {"secret"},
+				"import-0-friend-name": {"Alice"}, "import-0-player-type": {"3"}, "import-0-source-id": {"1"}},
+			wantErr: true,
+		},
+		{
+			form: url.Values{"action": {"import"}, "password": {"secret"},
+				"import-0-friend-name": {"Alice"}, "import-0-player-type": {"2"}, "import-0-source-id": {"592450"}},
+			wantErr: true, // already rostered
+		},
+		{
+			form: url.Values{"action": {"import"}, "password": {"secret"},
+				"import-10-friend-name": {"bob"}, "import-10-player-type": {"2"}, "import-10-source-id": {"1"},
+				"import-11-friend-name": {"bob"}, "import-11-player-type": {"2"}, "import-11-source-id": {"660271"}, // dropped
+				"import-2-friend-name": {"alice"}, "import-2-player-type": {"2"}, "import-2-source-id": {"545361"},
+				"import-2-name": {"Mike Trout"}, "import-2-position": {"CF"}, "import-2-team": {"LAA"}},
+		},
+	}
+	for i, test := range handleImportPostRequestTests {
+		r := httptest.NewRequest("POST", "/admin/import", strings.NewReader(test.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		gotRows, err := handleImportPostRequest(ds, searchers, 2019, st, r)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !reflect.DeepEqual(test.wantRows, gotRows):
+			t.Errorf("Test %v: rows not equal:\nwanted: %v\ngot:    %v", i, test.wantRows, gotRows)
+		}
+	}
+	wantSavedFriends := []db.Friend{
+		{ID: "import-1", DisplayOrder: 1, Name: "bob"},
+	}
+	wantSavedPlayers := []db.ImportedPlayer{
+		{FriendName: "Alice", PlayerType: db.PlayerTypeMlbHitter, SourceID: 545361, DisplayOrder: 3},
+		{FriendName: "bob", PlayerType: db.PlayerTypeMlbHitter, SourceID: 1, DisplayOrder: 1},
+		{FriendName: "bob", PlayerType: db.PlayerTypeMlbHitter, SourceID: 660271, DisplayOrder: 2},
+	}
+	wantSavedPlayerInfos := []db.PlayerInfo{
+		{PlayerType: db.PlayerTypeMlbHitter, SourceID: 545361, Name: "Mike Trout", Position: "CF", Team: "LAA"},
+	}
+	switch {
+	case !reflect.DeepEqual(wantSavedFriends, savedFriends):
+		t.Errorf("saved friends not equal:\nwanted: %v\ngot:    %v", wantSavedFriends, savedFriends)
+	case !reflect.DeepEqual(wantSavedPlayers, savedPlayers):
+		t.Errorf("saved players not equal:\nwanted: %v\ngot:    %v", wantSavedPlayers, savedPlayers)
+	case !reflect.DeepEqual(wantSavedPlayerInfos, savedPlayerInfos):
+		t.Errorf("saved player infos not equal:\nwanted: %v\ngot:    %v", wantSavedPlayerInfos, savedPlayerInfos)
+	case !clearStatCalled:
+		t.Error("wanted stat to be cleared after import")
+	}
+}
+
+func TestImportPlayers_importRostersError(t *testing.T) {
+	saveErr := errors.New("save error")
+	ds := mockServerDatastore{
+		adminDatastore: mockAdminDatastore{
+			ImportRostersFunc: func(st db.SportType, newFriends []db.Friend, importedPlayers []db.ImportedPlayer, playerInfos []db.PlayerInfo) error {
+				return saveErr
+			},
+		},
+		etlDatastore: mockEtlDatastore{
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return nil, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return importPlayerTypes
+			},
+		},
+	}
+	form := url.Values{"import-0-friend-name": {"Alice"}, "import-0-player-type": {"2"}, "import-0-source-id": {"545361"}}
+	r := httptest.NewRequest("POST", "/admin/import", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ParseForm()
+	if err := importPlayers(ds, db.SportTypeMlb, r); !errors.Is(err, saveErr) {
+		t.Errorf("wanted %v, got %v", saveErr, err)
+	}
+}
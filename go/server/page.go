@@ -31,9 +31,31 @@ type (
 	}
 
 	// StatsTab provides stats information
+	// Archived tabs have the final stats of a past year, which are not updated.
 	StatsTab struct {
 		ScoreCategory request.ScoreCategory
+		Status        EtlStatus
 		ExportURL     string
+		Archived      bool
+	}
+
+	// HomeTab lists the sports with their past seasons.
+	HomeTab struct {
+		Name   string
+		Sports []HomeSport
+	}
+
+	// HomeSport is a SportEntry with its PastSeasons
+	HomeSport struct {
+		SportEntry
+		PastSeasons []PastSeason
+	}
+
+	// RecordsTab shows one kind of AllTimeRecords, which is named by the Action.
+	RecordsTab struct {
+		Name    string
+		Action  string
+		Records *AllTimeRecords
 	}
 
 	// AdminTab provides tabs with admin tasks.
@@ -116,6 +138,26 @@ func (at AdminTab) GetID() string {
 	return jsID(at.GetName())
 }
 
+// GetName implements the Tab interface for HomeTab
+func (ht HomeTab) GetName() string {
+	return ht.Name
+}
+
+// GetID implements the Tab interface for HomeTab
+func (ht HomeTab) GetID() string {
+	return jsID(ht.GetName())
+}
+
+// GetName implements the Tab interface for RecordsTab
+func (rt RecordsTab) GetName() string {
+	return rt.Name
+}
+
+// GetID implements the Tab interface for RecordsTab
+func (rt RecordsTab) GetID() string {
+	return jsID(rt.GetName())
+}
+
 // GetName implements the Tab interface for StatsTab
 func (st StatsTab) GetName() string {
 	return st.ScoreCategory.Name
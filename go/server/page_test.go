@@ -54,6 +54,8 @@ func TestNewPage(t *testing.T) {
 	time2 := time.Date(2019, time.October, 17, 3, 19, 42, 200, time.UTC)
 	time3 := time.Date(2019, time.June, 6, 12, 0, 0, 0, time.UTC)
 	ds := mockServerDatastore{
+		nil,
+		nil,
 		nil,
 		nil,
 		mockEtlDatastore{
@@ -130,6 +132,12 @@ func TestTabGetID(t *testing.T) {
 			},
 			want: "american-football",
 		},
+		{
+			tab: RecordsTab{
+				Name: "Best Seasons",
+			},
+			want: "best-seasons",
+		},
 	}
 	for i, test := range getNameTests {
 		got := test.tab.GetID()
@@ -158,6 +166,12 @@ func TestTabGetName(t *testing.T) {
 			},
 			want: "Lacrosse",
 		},
+		{
+			tab: RecordsTab{
+				Name: "Top Picks",
+			},
+			want: "Top Picks",
+		},
 	}
 	for i, test := range getNameTests {
 		got := test.tab.GetName()
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// AllTimeRecords are the records of the friends and players across the years of a SportType that are no longer active.
+	// Friends are matched across years by their names.
+	AllTimeRecords struct {
+		Years       []int
+		Friends     []FriendRecord
+		BestSeasons []CategorySeasons
+		TopPicks    []PickRecord
+	}
+
+	// FriendRecord contains the finishes of a friend in the ScoreCategories of past years.
+	// Titles are the finishes in first place and Podiums are the finishes in the top three places.
+	FriendRecord struct {
+		Name     string
+		Seasons  int
+		Titles   int
+		Podiums  int
+		Finishes []Finish
+	}
+
+	// Finish is the place of a friend in a ScoreCategory of a year.
+	Finish struct {
+		Year          int
+		ScoreCategory string
+		Rank          int
+		Score         int
+	}
+
+	// CategorySeasons are the best single-season scores of friends in a ScoreCategory, highest first.
+	CategorySeasons struct {
+		ScoreCategory string
+		Seasons       []SeasonRecord
+	}
+
+	// SeasonRecord is the score of a friend in a year.
+	SeasonRecord struct {
+		FriendName string
+		Year       int
+		Score      int
+	}
+
+	// PickRecord contains the number of times a player was picked and how many of the picks were by the winner of the ScoreCategory.
+	PickRecord struct {
+		PlayerName    string
+		ScoreCategory string
+		Picks         int
+		Titles        int
+		TotalScore    int
+	}
+
+	// pickKey identifies a player in a ScoreCategory across years.
+	pickKey struct {
+		pt       db.PlayerType
+		sourceID db.SourceID
+		name     string
+	}
+)
+
+const (
+	bestSeasonsPerCategory = 5
+	maxTopPicks            = 10
+)
+
+// getAllTimeRecords gets the AllTimeRecords of the SportType from the saved stats of the years that are not active.
+func getAllTimeRecords(ctx context.Context, st db.SportType, ds archiveDatastore) (*AllTimeRecords, error) {
+	archivedEtlStats, err := getArchivedEtlStats(ctx, st, ds)
+	if err != nil {
+		return nil, err
+	}
+	return newAllTimeRecords(archivedEtlStats), nil
+}
+
+// newAllTimeRecords aggregates the EtlStats, which should be ordered with the most recent year first.
+// The most recent names of the friends and ScoreCategories are used.
+func newAllTimeRecords(archivedEtlStats []EtlStats) *AllTimeRecords {
+	var atr AllTimeRecords
+	friendRecords := make(map[string]*FriendRecord)
+	var friendKeys []string
+	friendYears := make(map[string]map[int]bool)
+	categorySeasons := make(map[db.PlayerType]*CategorySeasons)
+	var playerTypes []db.PlayerType
+	pickRecords := make(map[pickKey]*PickRecord)
+	for _, es := range archivedEtlStats {
+		atr.Years = append(atr.Years, es.year)
+		for _, sc := range es.scoreCategories {
+			cs, ok := categorySeasons[sc.PlayerType]
+			if !ok {
+				cs = &CategorySeasons{ScoreCategory: sc.Name}
+				categorySeasons[sc.PlayerType] = cs
+				playerTypes = append(playerTypes, sc.PlayerType)
+			}
+			scores := make([]int, len(sc.FriendScores))
+			for i, fs := range sc.FriendScores {
+				scores[i] = fs.Score
+			}
+			for i, rank := range ranks(scores) {
+				fs := sc.FriendScores[i]
+				key := friendKey(fs.Name)
+				fr, ok := friendRecords[key]
+				if !ok {
+					fr = &FriendRecord{Name: fs.Name}
+					friendRecords[key] = fr
+					friendKeys = append(friendKeys, key)
+					friendYears[key] = make(map[int]bool)
+				}
+				if !friendYears[key][es.year] {
+					friendYears[key][es.year] = true
+					fr.Seasons++
+				}
+				if rank == 1 {
+					fr.Titles++
+				}
+				if rank <= 3 {
+					fr.Podiums++
+				}
+				fr.Finishes = append(fr.Finishes, Finish{
+					Year:          es.year,
+					ScoreCategory: sc.Name,
+					Rank:          rank,
+					Score:         fs.Score,
+				})
+				cs.Seasons = append(cs.Seasons, SeasonRecord{
+					FriendName: fr.Name,
+					Year:       es.year,
+					Score:      fs.Score,
+				})
+				for _, ps := range fs.PlayerScores {
+					pk := pickKey{pt: sc.PlayerType, sourceID: ps.SourceID}
+					if ps.SourceID == 0 {
+						pk.name = strings.ToLower(ps.Name)
+					}
+					pr, ok := pickRecords[pk]
+					if !ok {
+						pr = &PickRecord{PlayerName: ps.Name, ScoreCategory: cs.ScoreCategory}
+						pickRecords[pk] = pr
+					}
+					pr.Picks++
+					pr.TotalScore += ps.Score
+					if rank == 1 {
+						pr.Titles++
+					}
+				}
+			}
+		}
+	}
+	atr.Friends = make([]FriendRecord, len(friendKeys))
+	for i, key := range friendKeys {
+		atr.Friends[i] = *friendRecords[key]
+	}
+	sort.SliceStable(atr.Friends, func(i, j int) bool {
+		a, b := atr.Friends[i], atr.Friends[j]
+		switch {
+		case a.Titles != b.Titles:
+			return a.Titles > b.Titles
+		case a.Podiums != b.Podiums:
+			return a.Podiums > b.Podiums
+		}
+		return a.Seasons > b.Seasons
+	})
+	atr.BestSeasons = make([]CategorySeasons, len(playerTypes))
+	for i, pt := range playerTypes {
+		cs := *categorySeasons[pt]
+		sort.SliceStable(cs.Seasons, func(i, j int) bool {
+			return cs.Seasons[i].Score > cs.Seasons[j].Score
+		})
+		if len(cs.Seasons) > bestSeasonsPerCategory {
+			cs.Seasons = cs.Seasons[:bestSeasonsPerCategory]
+		}
+		atr.BestSeasons[i] = cs
+	}
+	for _, pr := range pickRecords {
+		if pr.Titles > 0 {
+			atr.TopPicks = append(atr.TopPicks, *pr)
+		}
+	}
+	sort.Slice(atr.TopPicks, func(i, j int) bool {
+		a, b := atr.TopPicks[i], atr.TopPicks[j]
+		switch {
+		case a.Titles != b.Titles:
+			return a.Titles > b.Titles
+		case a.Picks != b.Picks:
+			return a.Picks < b.Picks // players who won in fewer picks are more valuable
+		case a.TotalScore != b.TotalScore:
+			return a.TotalScore > b.TotalScore
+		}
+		return a.PlayerName < b.PlayerName
+	})
+	if len(atr.TopPicks) > maxTopPicks {
+		atr.TopPicks = atr.TopPicks[:maxTopPicks]
+	}
+	return &atr
+}
+
+// friendKey is used to match friends with the same name in different years.
+func friendKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
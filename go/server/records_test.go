@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+func TestNewAllTimeRecords(t *testing.T) {
+	archivedEtlStats := []EtlStats{
+		{
+			year: 2019,
+			scoreCategories: []request.ScoreCategory{
+				{
+					Name:       "Teams",
+					PlayerType: 1,
+					FriendScores: []request.FriendScore{
+						{Name: "Arnold", Score: 70, PlayerScores: []request.PlayerScore{{Name: "Cubs", SourceID: 112, Score: 70}}},
+						{Name: "Bert", Score: 95, PlayerScores: []request.PlayerScore{{Name: "Yankees", SourceID: 147, Score: 95}}},
+					},
+				},
+			},
+		},
+		{
+			year: 2018,
+			scoreCategories: []request.ScoreCategory{
+				{
+					Name:       "Old Teams",
+					PlayerType: 1,
+					FriendScores: []request.FriendScore{
+						{Name: "arnold ", Score: 90, PlayerScores: []request.PlayerScore{{Name: "Yankees", SourceID: 147, Score: 90}}},
+						{Name: "Bert", Score: 90, PlayerScores: []request.PlayerScore{{Name: "Cubs", SourceID: 112, Score: 90}}},
+						{Name: "Carl", Score: 80},
+						{Name: "Dave", Score: 10},
+					},
+				},
+			},
+		},
+	}
+	want := &AllTimeRecords{
+		Years: []int{2019, 2018},
+		Friends: []FriendRecord{
+			{
+				Name:    "Bert",
+				Seasons: 2,
+				Titles:  2,
+				Podiums: 2,
+				Finishes: []Finish{
+					{Year: 2019, ScoreCategory: "Teams", Rank: 1, Score: 95},
+					{Year: 2018, ScoreCategory: "Old Teams", Rank: 1, Score: 90},
+				},
+			},
+			{
+				Name:    "Arnold",
+				Seasons: 2,
+				Titles:  1,
+				Podiums: 2,
+				Finishes: []Finish{
+					{Year: 2019, ScoreCategory: "Teams", Rank: 2, Score: 70},
+					{Year: 2018, ScoreCategory: "Old Teams", Rank: 1, Score: 90},
+				},
+			},
+			{
+				Name:     "Carl",
+				Seasons:  1,
+				Podiums:  1,
+				Finishes: []Finish{{Year: 2018, ScoreCategory: "Old Teams", Rank: 3, Score: 80}},
+			},
+			{
+				Name:     "Dave",
+				Seasons:  1,
+				Finishes: []Finish{{Year: 2018, ScoreCategory: "Old Teams", Rank: 4, Score: 10}},
+			},
+		},
+		BestSeasons: []CategorySeasons{
+			{
+				ScoreCategory: "Teams",
+				Seasons: []SeasonRecord{
+					{FriendName: "Bert", Year: 2019, Score: 95},
+					{FriendName: "Arnold", Year: 2018, Score: 90},
+					{FriendName: "Bert", Year: 2018, Score: 90},
+					{FriendName: "Carl", Year: 2018, Score: 80},
+					{FriendName: "Arnold", Year: 2019, Score: 70},
+				},
+			},
+		},
+		TopPicks: []PickRecord{
+			{PlayerName: "Yankees", ScoreCategory: "Teams", Picks: 2, Titles: 2, TotalScore: 185},
+			{PlayerName: "Cubs", ScoreCategory: "Teams", Picks: 2, Titles: 1, TotalScore: 160},
+		},
+	}
+	got := newAllTimeRecords(archivedEtlStats)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("records not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestNewAllTimeRecords_empty(t *testing.T) {
+	got := newAllTimeRecords(nil)
+	if len(got.Years) != 0 || len(got.Friends) != 0 || len(got.BestSeasons) != 0 || len(got.TopPicks) != 0 {
+		t.Errorf("wanted empty records, got %v", got)
+	}
+}
+
+func TestGetAllTimeRecords(t *testing.T) {
+	etlTime := time.Date(2018, time.October, 1, 12, 0, 0, 0, time.UTC)
+	ds := mockArchiveDatastore{
+		GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+			return []db.Year{{Value: 2017}, {Value: 2018}, {Value: 2019, Active: true}}, nil
+		},
+		GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+			if year == 2017 {
+				return &db.Stat{SportType: st, Year: year}, nil // no stats
+			}
+			return &db.Stat{
+				SportType:    st,
+				Year:         year,
+				EtlTimestamp: &etlTime,
+				EtlJSON: `[{"Name":"Teams","FriendScores":[{"Name":"Arnold","Score":90},{"Name":"Bert","Score":90},{"Name":"Carl","Score":80}]},` +
+					`{"Name":"Hitting","FriendScores":[{"Name":"Arnold","Score":12},{"Name":"Carl","Score":40}]}]`,
+				Frozen: true,
+			}, nil
+		},
+		SportTypesFunc: func() db.SportTypeMap {
+			return db.SportTypeMap{1: {Name: "mlb"}}
+		},
+	}
+	got, err := getAllTimeRecords(context.Background(), 1, ds)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual([]int{2018}, got.Years):
+		t.Errorf("wanted only the stats of 2018, got %v", got.Years)
+	case len(got.Friends) != 3, got.Friends[2].Name != "Bert", got.Friends[2].Podiums != 1:
+		t.Errorf("wanted Bert to be last with the fewest top finishes, got %v", got.Friends)
+	}
+}
+
+func TestGetAllTimeRecords_error(t *testing.T) {
+	ds := mockArchiveDatastore{
+		GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+			return []db.Year{{Value: 2018}, {Value: 2019, Active: true}}, nil
+		},
+		GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+			return nil, errors.New("get year stat error")
+		},
+		SportTypesFunc: func() db.SportTypeMap {
+			return db.SportTypeMap{1: {Name: "mlb"}}
+		},
+	}
+	if _, err := getAllTimeRecords(context.Background(), 1, ds); err == nil {
+		t.Error("wanted error")
+	}
+}
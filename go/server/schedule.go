@@ -0,0 +1,268 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// cronSchedule contains the times described by a cron expression with five fields: minute, hour, day of month, month, and day of week.
+	// Times are evaluated in the location of the time passed to next or prev.  Scheduled days must match the day of month, month, and day of week fields.
+	cronSchedule struct {
+		minutes     map[int]bool
+		hours       map[int]bool
+		daysOfMonth map[int]bool
+		months      map[int]bool
+		daysOfWeek  map[int]bool
+	}
+
+	cronField struct {
+		name     string
+		min, max int
+	}
+
+	// etlSchedule contains the times stats for a SportType are refreshed in a time zone.
+	etlSchedule struct {
+		location      *time.Location
+		expressions   []string
+		cronSchedules []cronSchedule
+	}
+)
+
+const (
+	// defaultEtlSchedule refreshes stats daily at midnight in the defaultEtlTimeZone.
+	defaultEtlSchedule = "0 0 * * *"
+	// defaultEtlTimeZone is Honolulu (UTC-10), where midnight is after the last games in the United States have finished.
+	defaultEtlTimeZone = "Pacific/Honolulu"
+)
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+// parseCronSchedule creates a cronSchedule from the expression.
+// Each field can be a *, a number, a range such as 1-5, a step such as */15 or 1-31/2, or a list of those separated by commas.
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %v fields", expression, len(cronFields))
+	}
+	values := make([]map[int]bool, len(cronFields))
+	for i, cf := range cronFields {
+		v, err := cf.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("parsing cron expression %q: %w", expression, err)
+		}
+		values[i] = v
+	}
+	cs := cronSchedule{
+		minutes:     values[0],
+		hours:       values[1],
+		daysOfMonth: values[2],
+		months:      values[3],
+		daysOfWeek:  values[4],
+	}
+	return &cs, nil
+}
+
+func (cf cronField) parse(field string) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid %v step: %q", cf.name, part)
+			}
+		}
+		first, last := cf.min, cf.max
+		switch i := strings.Index(rangePart, "-"); {
+		case rangePart == "*":
+		case i >= 0:
+			var err1, err2 error
+			first, err1 = strconv.Atoi(rangePart[:i])
+			last, err2 = strconv.Atoi(rangePart[i+1:])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid %v range: %q", cf.name, part)
+			}
+		default:
+			var err error
+			first, err = strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v: %q", cf.name, part)
+			}
+			if step == 1 {
+				last = first
+			}
+		}
+		if first < cf.min || last > cf.max || first > last {
+			return nil, fmt.Errorf("%v must be between %v and %v: %q", cf.name, cf.min, cf.max, part)
+		}
+		for v := first; v <= last; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// matchesDay determines if the day of the time is in the schedule
+func (cs cronSchedule) matchesDay(t time.Time) bool {
+	return cs.months[int(t.Month())] && cs.daysOfMonth[t.Day()] && cs.daysOfWeek[int(t.Weekday())]
+}
+
+// next returns the first scheduled time after t
+// Hours are skipped by adding durations rather than by creating dates so times in daylight saving time gaps are not repeated.
+func (cs cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	loc := t.Location()
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case !cs.matchesDay(t):
+			if nextDay := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc); nextDay.After(t) {
+				t = nextDay
+			} else {
+				t = t.Add(time.Minute)
+			}
+		case !cs.hours[t.Hour()]:
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+		case !cs.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{} // never, such as on February 30
+}
+
+// prev returns the last scheduled time that is not after t
+func (cs cronSchedule) prev(t time.Time) time.Time {
+	t = t.Truncate(time.Minute)
+	loc := t.Location()
+	for limit := t.AddDate(-5, 0, 0); t.After(limit); {
+		switch {
+		case !cs.matchesDay(t):
+			if prevDay := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute); prevDay.Before(t) {
+				t = prevDay
+			} else {
+				t = t.Add(-time.Minute)
+			}
+		case !cs.hours[t.Hour()]:
+			t = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+		case !cs.minutes[t.Minute()]:
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// newEtlSchedule creates an etlSchedule for the cron expressions in the time zone, such as "America/New_York".
+func newEtlSchedule(timeZone string, expressions ...string) (*etlSchedule, error) {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("loading time zone: %w", err)
+	}
+	es := etlSchedule{
+		location: location,
+	}
+	for _, expression := range expressions {
+		cs, err := parseCronSchedule(expression)
+		if err != nil {
+			return nil, err
+		}
+		es.expressions = append(es.expressions, strings.TrimSpace(expression))
+		es.cronSchedules = append(es.cronSchedules, *cs)
+	}
+	return &es, nil
+}
+
+// next returns the first time after t that any of the cron schedules has, in UTC
+func (es etlSchedule) next(t time.Time) time.Time {
+	var next time.Time
+	for _, cs := range es.cronSchedules {
+		if n := cs.next(t.In(es.location)); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next.UTC()
+}
+
+// prev returns the last time not after t that any of the cron schedules has, in UTC
+func (es etlSchedule) prev(t time.Time) time.Time {
+	var prev time.Time
+	for _, cs := range es.cronSchedules {
+		if p := cs.prev(t.In(es.location)); p.After(prev) {
+			prev = p
+		}
+	}
+	return prev.UTC()
+}
+
+// String describes the cron expressions and time zone of the schedule
+func (es etlSchedule) String() string {
+	return fmt.Sprintf("%s (%v)", strings.Join(es.expressions, " | "), es.location)
+}
+
+// parseEtlSchedules creates the etlSchedules for each SportType.
+// The schedules are separated by semicolons, with each containing the url of the SportType, an equals sign, and cron expressions separated by pipes, such as "nfl=*/30 17-23 * 9-12 0|0 0 * * *".
+// The time zones are separated by semicolons, with each containing the url of the SportType, an equals sign, and the name of the time zone, such as "nfl=America/New_York".
+// SportTypes that are not configured use the default schedule and time zone.
+func parseEtlSchedules(etlSchedules, etlTimeZones string, sportTypesByURL map[string]db.SportType) (map[db.SportType]etlSchedule, error) {
+	expressions, err := parseSportTypeValues(etlSchedules, sportTypesByURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schedules: %w", err)
+	}
+	timeZones, err := parseSportTypeValues(etlTimeZones, sportTypesByURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing time zones: %w", err)
+	}
+	schedules := make(map[db.SportType]etlSchedule, len(sportTypesByURL))
+	for _, st := range sportTypesByURL {
+		stExpressions := []string{defaultEtlSchedule}
+		if e, ok := expressions[st]; ok {
+			stExpressions = strings.Split(e, "|")
+		}
+		timeZone := defaultEtlTimeZone
+		if tz, ok := timeZones[st]; ok {
+			timeZone = tz
+		}
+		schedule, err := newEtlSchedule(timeZone, stExpressions...)
+		if err != nil {
+			return nil, err
+		}
+		schedules[st] = *schedule
+	}
+	return schedules, nil
+}
+
+// parseSportTypeValues maps the values of semicolon-separated sportURL=value pairs to their SportTypes
+func parseSportTypeValues(s string, sportTypesByURL map[string]db.SportType) (map[db.SportType]string, error) {
+	values := make(map[db.SportType]string)
+	for _, pair := range strings.Split(s, ";") {
+		if len(strings.TrimSpace(pair)) == 0 {
+			continue
+		}
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%q must be in the form sportURL=value", pair)
+		}
+		stURL := strings.TrimSpace(pair[:i])
+		st, ok := sportTypesByURL[stURL]
+		if !ok {
+			return nil, fmt.Errorf("no sport type for %q", pair)
+		}
+		values[st] = strings.TrimSpace(pair[i+1:])
+	}
+	return values, nil
+}
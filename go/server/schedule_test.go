@@ -0,0 +1,347 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	parseCronScheduleTests := []struct {
+		expression  string
+		wantErr     bool
+		wantMinutes []int
+		wantHours   []int
+	}{
+		{
+			expression: "",
+			wantErr:    true,
+		},
+		{
+			expression: "* * * *",
+			wantErr:    true,
+		},
+		{
+			expression: "60 * * * *",
+			wantErr:    true,
+		},
+		{
+			expression: "* 5-3 * * *",
+			wantErr:    true,
+		},
+		{
+			expression: "*/0 * * * *",
+			wantErr:    true,
+		},
+		{
+			expression: "* * 0 * *",
+			wantErr:    true,
+		},
+		{
+			expression: "x * * * *",
+			wantErr:    true,
+		},
+		{
+			expression:  "0 10 * * *",
+			wantMinutes: []int{0},
+			wantHours:   []int{10},
+		},
+		{
+			expression:  "*/15 1-3,20 * * 0",
+			wantMinutes: []int{0, 15, 30, 45},
+			wantHours:   []int{1, 2, 3, 20},
+		},
+		{
+			expression:  "50/5 8-16/4 * * *",
+			wantMinutes: []int{50, 55},
+			wantHours:   []int{8, 12, 16},
+		},
+	}
+	for i, test := range parseCronScheduleTests {
+		cs, err := parseCronSchedule(test.expression)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error parsing %q", i, test.expression)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case !equalValues(test.wantMinutes, cs.minutes):
+			t.Errorf("Test %v: wanted minutes %v, got %v", i, test.wantMinutes, cs.minutes)
+		case !equalValues(test.wantHours, cs.hours):
+			t.Errorf("Test %v: wanted hours %v, got %v", i, test.wantHours, cs.hours)
+		}
+	}
+}
+
+func equalValues(want []int, got map[int]bool) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for _, v := range want {
+		if !got[v] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	cronScheduleNextTests := []struct {
+		expression string
+		t          time.Time
+		want       time.Time
+	}{
+		{
+			expression: "0 10 * * *",
+			t:          time.Date(2019, time.August, 21, 9, 59, 59, 0, time.UTC),
+			want:       time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "0 10 * * *",
+			t:          time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2019, time.August, 22, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "0 10 * * *",
+			t:          time.Date(2019, time.December, 31, 23, 0, 0, 0, time.UTC),
+			want:       time.Date(2020, time.January, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "*/20 17-23 * 9-12 0",                                   // every 20 minutes on Sunday afternoons in the fall
+			t:          time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC), // Wednesday
+			want:       time.Date(2019, time.September, 1, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "*/20 17-23 * 9-12 0",
+			t:          time.Date(2019, time.September, 1, 17, 21, 0, 0, time.UTC),
+			want:       time.Date(2019, time.September, 1, 17, 40, 0, 0, time.UTC),
+		},
+		{
+			expression: "0 0 30 2 *", // never
+			t:          time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Time{},
+		},
+	}
+	for i, test := range cronScheduleNextTests {
+		cs, err := parseCronSchedule(test.expression)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		got := cs.next(test.t)
+		if !test.want.Equal(got) {
+			t.Errorf("Test %d: next time after %v for %q:\n\twanted %v\n\tgot    %v", i, test.t, test.expression, test.want, got)
+		}
+	}
+}
+
+func TestCronSchedulePrev(t *testing.T) {
+	cronSchedulePrevTests := []struct {
+		expression string
+		t          time.Time
+		want       time.Time
+	}{
+		{
+			expression: "0 10 * * *",
+			t:          time.Date(2019, time.August, 22, 0, 0, 0, 0, time.UTC),
+			want:       time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "0 10 * * *",
+			t:          time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			expression: "*/20 17-23 * 9-12 0",
+			t:          time.Date(2020, time.January, 1, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2019, time.December, 29, 23, 40, 0, 0, time.UTC),
+		},
+		{
+			expression: "0 0 30 2 *", // never
+			t:          time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Time{},
+		},
+	}
+	for i, test := range cronSchedulePrevTests {
+		cs, err := parseCronSchedule(test.expression)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		got := cs.prev(test.t)
+		if !test.want.Equal(got) {
+			t.Errorf("Test %d: previous time before %v for %q:\n\twanted %v\n\tgot    %v", i, test.t, test.expression, test.want, got)
+		}
+	}
+}
+
+func TestEtlSchedule(t *testing.T) {
+	pacificLocation, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hawaiiLocation, err := time.LoadLocation("Pacific/Honolulu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	etlScheduleTests := []struct {
+		timeZone    string
+		expressions []string
+		t           time.Time
+		wantPrev    time.Time
+		wantNext    time.Time
+	}{
+		{
+			timeZone:    defaultEtlTimeZone,
+			expressions: []string{defaultEtlSchedule},
+			t:           time.Date(2019, time.August, 22, 0, 0, 0, 0, time.UTC), // 12 AM
+			wantPrev:    time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			wantNext:    time.Date(2019, time.August, 22, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			timeZone:    defaultEtlTimeZone,
+			expressions: []string{defaultEtlSchedule},
+			t:           time.Date(2019, time.August, 21, 16, 15, 17, 0, time.UTC),
+			wantPrev:    time.Date(2019, time.August, 21, 10, 0, 0, 0, time.UTC),
+			wantNext:    time.Date(2019, time.August, 22, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			timeZone:    defaultEtlTimeZone,
+			expressions: []string{defaultEtlSchedule},
+			t:           time.Date(2019, time.August, 22, 2, 0, 0, 0, pacificLocation), // 2 AM
+			wantPrev:    time.Date(2019, time.August, 21, 3, 0, 0, 0, pacificLocation),
+			wantNext:    time.Date(2019, time.August, 22, 3, 0, 0, 0, pacificLocation),
+		},
+		{
+			timeZone:    defaultEtlTimeZone,
+			expressions: []string{defaultEtlSchedule},
+			t:           time.Date(2019, time.August, 22, 0, 0, 0, 0, hawaiiLocation), // 12 AM
+			wantPrev:    time.Date(2019, time.August, 22, 0, 0, 0, 0, hawaiiLocation),
+			wantNext:    time.Date(2019, time.August, 23, 0, 0, 0, 0, hawaiiLocation),
+		},
+		{ // hourly during Sunday games, daily otherwise
+			timeZone:    "America/New_York",
+			expressions: []string{"0 13-23 * * 0", "0 3 * * *"},
+			t:           time.Date(2019, time.September, 8, 19, 30, 0, 0, time.UTC), // Sunday 3:30 PM EDT
+			wantPrev:    time.Date(2019, time.September, 8, 19, 0, 0, 0, time.UTC),
+			wantNext:    time.Date(2019, time.September, 8, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			timeZone:    "America/New_York",
+			expressions: []string{"0 13-23 * * 0", "0 3 * * *"},
+			t:           time.Date(2019, time.September, 10, 12, 0, 0, 0, time.UTC), // Tuesday 8 AM EDT
+			wantPrev:    time.Date(2019, time.September, 10, 7, 0, 0, 0, time.UTC),
+			wantNext:    time.Date(2019, time.September, 11, 7, 0, 0, 0, time.UTC),
+		},
+		{ // daylight savings time starts
+			timeZone:    "America/New_York",
+			expressions: []string{"0 3 * * *"},
+			t:           time.Date(2019, time.March, 9, 12, 0, 0, 0, time.UTC),
+			wantPrev:    time.Date(2019, time.March, 9, 8, 0, 0, 0, time.UTC),
+			wantNext:    time.Date(2019, time.March, 10, 7, 0, 0, 0, time.UTC),
+		},
+	}
+	for i, test := range etlScheduleTests {
+		es, err := newEtlSchedule(test.timeZone, test.expressions...)
+		if err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		gotPrev := es.prev(test.t)
+		gotNext := es.next(test.t)
+		switch {
+		case !test.wantPrev.Equal(gotPrev):
+			t.Errorf("Test %d: previous time before %v for %v:\n\twanted %v\n\tgot    %v", i, test.t, es, test.wantPrev, gotPrev)
+		case !test.wantNext.Equal(gotNext):
+			t.Errorf("Test %d: next time after %v for %v:\n\twanted %v\n\tgot    %v", i, test.t, es, test.wantNext, gotNext)
+		}
+	}
+}
+
+func TestNewEtlSchedule_invalid(t *testing.T) {
+	if _, err := newEtlSchedule("Mars/Olympus_Mons", defaultEtlSchedule); err == nil {
+		t.Error("wanted error for invalid time zone")
+	}
+	if _, err := newEtlSchedule(defaultEtlTimeZone, "0 0 * *"); err == nil {
+		t.Error("wanted error for invalid cron expression")
+	}
+}
+
+func TestEtlScheduleString(t *testing.T) {
+	es, err := newEtlSchedule("America/New_York", "0 13-23 * * 0", " 0 3 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "0 13-23 * * 0 | 0 3 * * * (America/New_York)"
+	if got := es.String(); want != got {
+		t.Errorf("wanted %q, got %q", want, got)
+	}
+}
+
+func TestParseEtlSchedules(t *testing.T) {
+	sportTypesByURL := map[string]db.SportType{
+		"mlb": db.SportTypeMlb,
+		"nfl": db.SportTypeNfl,
+	}
+	parseEtlSchedulesTests := []struct {
+		etlSchedules  string
+		etlTimeZones  string
+		wantErr       bool
+		wantMlbString string
+		wantNflString string
+	}{
+		{
+			wantMlbString: "0 0 * * * (Pacific/Honolulu)",
+			wantNflString: "0 0 * * * (Pacific/Honolulu)",
+		},
+		{
+			etlSchedules:  "nfl=0 * * * 0|0 0 * * *",
+			etlTimeZones:  "nfl=America/New_York",
+			wantMlbString: "0 0 * * * (Pacific/Honolulu)",
+			wantNflString: "0 * * * 0 | 0 0 * * * (America/New_York)",
+		},
+		{
+			etlSchedules:  "mlb=0 9 * * *; nfl=0 11 * * *;",
+			etlTimeZones:  "mlb=UTC",
+			wantMlbString: "0 9 * * * (UTC)",
+			wantNflString: "0 11 * * * (Pacific/Honolulu)",
+		},
+		{
+			etlSchedules: "nba=0 10 * * *",
+			wantErr:      true,
+		},
+		{
+			etlSchedules: "mlb 0 10 * * *",
+			wantErr:      true,
+		},
+		{
+			etlSchedules: "mlb=0 10 * *",
+			wantErr:      true,
+		},
+		{
+			etlTimeZones: "mlb=Pacific/Atlantis",
+			wantErr:      true,
+		},
+		{
+			etlTimeZones: "nba=UTC",
+			wantErr:      true,
+		},
+	}
+	for i, test := range parseEtlSchedulesTests {
+		got, err := parseEtlSchedules(test.etlSchedules, test.etlTimeZones, sportTypesByURL)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantMlbString != got[db.SportTypeMlb].String():
+			t.Errorf("Test %v: wanted mlb schedule %v, got %v", i, test.wantMlbString, got[db.SportTypeMlb])
+		case test.wantNflString != got[db.SportTypeNfl].String():
+			t.Errorf("Test %v: wanted nfl schedule %v, got %v", i, test.wantNflString, got[db.SportTypeNfl])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+)
+
+type (
+	// etlRefreshes coordinates refreshes of the stats of SportTypes so only one refresh runs at a time for each.
+	// Callers that request a refresh while one is running wait for it to finish instead of starting another.
+	etlRefreshes struct {
+		mu           sync.Mutex
+		running      map[db.SportType]*etlRefresh
+		sportMetrics map[db.SportType]*EtlRefreshMetrics
+	}
+
+	// etlRefresh is a refresh that is running.  The err is set before done is closed.
+	// The refresh is canceled when all of its waiters stop waiting.
+	etlRefresh struct {
+		done    chan struct{}
+		err     error
+		waiters int
+		cancel  context.CancelFunc
+	}
+
+	// EtlRefreshMetrics counts the refreshes of the stats of a SportType.
+	EtlRefreshMetrics struct {
+		// Refreshes is the number of times the stats were calculated.
+		Refreshes int
+		// Coalesced is the number of refresh requests that waited on a refresh that was already running.
+		Coalesced int
+	}
+)
+
+func newEtlRefreshes() *etlRefreshes {
+	return &etlRefreshes{
+		running:      make(map[db.SportType]*etlRefresh),
+		sportMetrics: make(map[db.SportType]*EtlRefreshMetrics),
+	}
+}
+
+// do runs the refresh function for the SportType if it is not already running.
+// If it is running, do waits for it to finish and returns its error.
+// do stops waiting when the context is done.  The refresh is canceled once every caller waiting on it has stopped waiting.
+func (er *etlRefreshes) do(ctx context.Context, st db.SportType, refresh func(ctx context.Context) error) error {
+	er.mu.Lock()
+	m, ok := er.sportMetrics[st]
+	if !ok {
+		m = new(EtlRefreshMetrics)
+		er.sportMetrics[st] = m
+	}
+	r, ok := er.running[st]
+	if ok {
+		m.Coalesced++
+	} else {
+		m.Refreshes++
+		refreshCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		r = &etlRefresh{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		er.running[st] = r
+		go er.run(refreshCtx, st, r, refresh)
+	}
+	r.waiters++
+	er.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		er.mu.Lock()
+		r.waiters--
+		if r.waiters == 0 {
+			r.cancel()
+			er.remove(st, r) // do not let new callers wait on the canceled refresh
+		}
+		er.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// run calls the refresh function, closing the done channel of the etlRefresh when it returns.
+func (er *etlRefreshes) run(ctx context.Context, st db.SportType, r *etlRefresh, refresh func(ctx context.Context) error) {
+	r.err = refresh(ctx)
+	r.cancel()
+	er.mu.Lock()
+	er.remove(st, r)
+	er.mu.Unlock()
+	close(r.done)
+}
+
+// remove stops tracking the etlRefresh if it is the one running for the SportType.  The mutex must be locked.
+func (er *etlRefreshes) remove(st db.SportType, r *etlRefresh) {
+	if er.running[st] == r {
+		delete(er.running, st)
+	}
+}
+
+// metrics gets a copy of the refresh metrics for the SportType.
+func (er *etlRefreshes) metrics(st db.SportType) EtlRefreshMetrics {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if m, ok := er.sportMetrics[st]; ok {
+		return *m
+	}
+	return EtlRefreshMetrics{}
+}
+
+// getEtlStats gets the EtlStats for the SportType.
+// Stale stats are refreshed in the background while the last snapshot is served.
+// If there is no snapshot, the stats are refreshed before they are returned.
+func (s Server) getEtlStats(ctx context.Context, st db.SportType) (*EtlStats, error) {
+	etlRefreshTime := s.etlSchedules[st].prev(s.ds.GetUtcTime())
+	es, err := getEtlStats(ctx, st, s.ds, etlRefreshTime)
+	switch {
+	case err != nil:
+		return nil, err
+	case !es.stale:
+		return es, nil
+	case es.etlTime.IsZero():
+		if err := s.refreshEtlStats(ctx, st); err != nil {
+			return nil, err
+		}
+		return getEtlStats(ctx, st, s.ds, etlRefreshTime)
+	}
+	go func() {
+		// the refresh is not canceled when the stale stats have been served
+		if err := s.refreshEtlStats(context.WithoutCancel(ctx), st); err != nil {
+			s.log.Printf("refreshing stale stats: %v", err)
+		}
+	}()
+	return es, nil
+}
+
+// refreshEtlStats recalculates the stats for the SportType and publishes them to event subscribers.
+// Digests of the changes to the standings are sent to subscribed friends and webhooks are notified of them if they are configured.
+// If the stats are already being refreshed, the running refresh is waited on instead.
+func (s Server) refreshEtlStats(ctx context.Context, st db.SportType) error {
+	return s.etlRefreshes.do(ctx, st, func(ctx context.Context) error {
+		var previous *EtlStats
+		if s.digests != nil || s.webhooks != nil {
+			var err error
+			if previous, err = getEtlStats(ctx, st, s.ds, time.Time{}); err != nil {
+				return err
+			}
+		}
+		es, err := refreshEtlStats(ctx, st, s.ds, s.scoreCategorizers)
+		if err != nil || es == nil {
+			return err
+		}
+		for _, status := range es.failedStatuses() {
+			s.log.Printf("keeping previous %v stats: %v", status.Name, status.Error)
+		}
+		if err := s.events.publishStats(*es); err != nil {
+			s.log.Printf("publishing stats: %v", err)
+		}
+		if previous != nil {
+			// the digests and notifications are sent after the refresh so waiters do not wait on the mail server or webhooks
+			go s.sendDigest(context.WithoutCancel(ctx), *previous, *es)
+			go s.notifyWebhooks(context.WithoutCancel(ctx), newStandingsWebhookEvents(s.DisplayName, *previous, *es))
+		}
+		return nil
+	})
+}
+
+// runEtlScheduler refreshes the stats of each SportType at its scheduled times until the context is done.
+func (s Server) runEtlScheduler(ctx context.Context) {
+	for st, schedule := range s.etlSchedules {
+		go s.scheduleEtlRefreshes(ctx, st, schedule)
+	}
+}
+
+func (s Server) scheduleEtlRefreshes(ctx context.Context, st db.SportType, schedule etlSchedule) {
+	for {
+		currentTime := s.ds.GetUtcTime()
+		nextTime := schedule.next(currentTime)
+		if nextTime.IsZero() {
+			s.log.Printf("no scheduled stats refreshes for SportType %v", st)
+			return
+		}
+		t := time.NewTimer(nextTime.Sub(currentTime))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+			if err := s.refreshEtlStats(ctx, st); err != nil {
+				s.log.Printf("refreshing scheduled stats: %v", err)
+			}
+		}
+	}
+}
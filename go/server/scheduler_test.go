@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+func TestEtlRefreshesDo(t *testing.T) {
+	er := newEtlRefreshes()
+	refreshErr := errors.New("refresh error")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	refreshCount := 0
+	refresh := func(ctx context.Context) error {
+		refreshCount++
+		close(started)
+		<-release
+		return refreshErr
+	}
+	errs := make(chan error, 3)
+	go func() {
+		errs <- er.do(context.Background(), db.SportTypeMlb, refresh)
+	}()
+	<-started
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- er.do(context.Background(), db.SportTypeMlb, refresh) // should not be called
+		}()
+	}
+	for er.metrics(db.SportTypeMlb).Coalesced != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := er.do(context.Background(), db.SportTypeNfl, func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("unexpected error refreshing other SportType while first is running: %v", err)
+	}
+	close(release)
+	for i := 0; i < 3; i++ {
+		if err := <-errs; !errors.Is(err, refreshErr) {
+			t.Errorf("wanted %v, got %v", refreshErr, err)
+		}
+	}
+	wantMetrics := EtlRefreshMetrics{Refreshes: 1, Coalesced: 2}
+	switch {
+	case refreshCount != 1:
+		t.Errorf("wanted 1 refresh, got %v", refreshCount)
+	case wantMetrics != er.metrics(db.SportTypeMlb):
+		t.Errorf("wanted metrics %v, got %v", wantMetrics, er.metrics(db.SportTypeMlb))
+	}
+	if err := er.do(context.Background(), db.SportTypeMlb, func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("unexpected error refreshing after previous refresh finished: %v", err)
+	}
+	if want, got := 2, er.metrics(db.SportTypeMlb).Refreshes; want != got {
+		t.Errorf("wanted %v refreshes after previous refresh finished, got %v", want, got)
+	}
+}
+
+func TestEtlRefreshesDo_canceled(t *testing.T) {
+	er := newEtlRefreshes()
+	started := make(chan struct{})
+	refreshErrs := make(chan error, 1)
+	refresh := func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		refreshErrs <- ctx.Err()
+		return ctx.Err()
+	}
+	ctx1, cancelFunc1 := context.WithCancel(context.Background())
+	ctx2, cancelFunc2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		errs <- er.do(ctx1, db.SportTypeMlb, refresh)
+	}()
+	<-started
+	go func() {
+		errs <- er.do(ctx2, db.SportTypeMlb, refresh) // should not be called
+	}()
+	for er.metrics(db.SportTypeMlb).Coalesced != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancelFunc1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted first caller to stop waiting when its context is canceled, got %v", err)
+	}
+	select {
+	case err := <-refreshErrs:
+		t.Errorf("wanted refresh to keep running while a caller waits on it, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	cancelFunc2()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted second caller to stop waiting when its context is canceled, got %v", err)
+	}
+	if err := <-refreshErrs; !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted refresh to be canceled when no callers wait on it, got %v", err)
+	}
+	if err := er.do(context.Background(), db.SportTypeMlb, func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("wanted new refresh after canceled refresh, got %v", err)
+	}
+}
+
+// newEtlTestServer creates a Server whose stat is stored in the stat pointer.
+// Refreshes are sent on the refreshed channel.
+func newEtlTestServer(stat *db.Stat, currentTime time.Time, refreshed chan<- db.Stat) Server {
+	schedule, _ := newEtlSchedule(defaultEtlTimeZone, defaultEtlSchedule)
+	var statMu sync.Mutex
+	ds := mockServerDatastore{
+		etlDatastore: mockEtlDatastore{
+			GetStatFunc: func(st db.SportType) (*db.Stat, error) {
+				statMu.Lock()
+				defer statMu.Unlock()
+				statCopy := *stat
+				return &statCopy, nil
+			},
+			GetFriendsFunc: func(st db.SportType) ([]db.Friend, error) {
+				return nil, nil
+			},
+			GetPlayersFunc: func(st db.SportType) ([]db.Player, error) {
+				return nil, nil
+			},
+			PlayerTypesFunc: func() db.PlayerTypeMap {
+				return db.PlayerTypeMap{2: {SportType: db.SportTypeMlb}}
+			},
+			SportTypesFunc: func() db.SportTypeMap {
+				return db.SportTypeMap{db.SportTypeMlb: {Name: "mlb"}}
+			},
+			GetUtcTimeFunc: func() time.Time {
+				return currentTime
+			},
+			SetStatFunc: func(s db.Stat) error {
+				statMu.Lock()
+				*stat = s
+				statMu.Unlock()
+				refreshed <- s
+				return nil
+			},
+		},
+	}
+	return Server{
+		ds:  ds,
+		log: log.New(io.Discard, "test", log.LstdFlags),
+		scoreCategorizers: map[db.PlayerType]request.ScoreCategorizer{
+			2: mockScoreCategorizer{
+				RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+					return request.ScoreCategory{Name: "refreshed", PlayerType: pt}, nil
+				},
+			},
+		},
+		events:       newEventBroker(),
+		etlSchedules: map[db.SportType]etlSchedule{db.SportTypeMlb: *schedule},
+		etlRefreshes: newEtlRefreshes(),
+	}
+}
+
+func TestServerGetEtlStats(t *testing.T) {
+	currentTime := time.Date(2019, time.August, 21, 16, 0, 0, 0, time.UTC)
+	beforeRefresh := time.Date(2019, time.August, 21, 9, 0, 0, 0, time.UTC)
+	afterRefresh := time.Date(2019, time.August, 21, 11, 0, 0, 0, time.UTC)
+	snapshot := `[{"Name":"snapshot"}]`
+	serverGetEtlStatsTests := []struct {
+		stat          db.Stat
+		wantRefreshed bool
+		wantName      string
+	}{
+		{ // fresh snapshot
+			stat:     db.Stat{Year: 2019, EtlTimestamp: &afterRefresh, EtlJSON: snapshot},
+			wantName: "snapshot",
+		},
+		{ // stale snapshot is served while refreshing in the background
+			stat:          db.Stat{Year: 2019, EtlTimestamp: &beforeRefresh, EtlJSON: snapshot},
+			wantRefreshed: true,
+			wantName:      "snapshot",
+		},
+		{ // no snapshot is refreshed before returning
+			stat:          db.Stat{Year: 2019},
+			wantRefreshed: true,
+			wantName:      "refreshed",
+		},
+	}
+	for i, test := range serverGetEtlStatsTests {
+		refreshed := make(chan db.Stat, 1)
+		stat := test.stat
+		s := newEtlTestServer(&stat, currentTime, refreshed)
+		es, err := s.getEtlStats(context.Background(), db.SportTypeMlb)
+		switch {
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		case len(es.scoreCategories) != 1 || es.scoreCategories[0].Name != test.wantName:
+			t.Errorf("Test %v: wanted stats named %v, got %v", i, test.wantName, es.scoreCategories)
+		}
+		select {
+		case <-refreshed:
+			if !test.wantRefreshed {
+				t.Errorf("Test %v: did not want refresh", i)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if test.wantRefreshed {
+				t.Errorf("Test %v: wanted refresh", i)
+			}
+		}
+	}
+}
+
+func TestServerGetEtlStats_concurrent(t *testing.T) {
+	stat := db.Stat{Year: 2019}
+	refreshed := make(chan db.Stat)
+	s := newEtlTestServer(&stat, time.Time{}, refreshed)
+	release := make(chan struct{})
+	s.scoreCategorizers[2] = mockScoreCategorizer{
+		RequestScoreCategoryFunc: func(pt db.PlayerType, ptInfo db.PlayerTypeInfo, year int, friends []db.Friend, players []db.Player) (request.ScoreCategory, error) {
+			<-release
+			return request.ScoreCategory{PlayerType: pt}, nil
+		},
+	}
+	n := 5
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := s.getEtlStats(context.Background(), db.SportTypeMlb)
+			errs <- err
+		}()
+	}
+	for s.etlRefreshes.metrics(db.SportTypeMlb).Coalesced != n-1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release) // the only refresh is blocked until all requests wait on it
+	<-refreshed
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if want, got := 1, s.etlRefreshes.metrics(db.SportTypeMlb).Refreshes; want != got {
+		t.Errorf("wanted %v refresh for concurrent requests, got %v", want, got)
+	}
+}
+
+func TestServerScheduleEtlRefreshes(t *testing.T) {
+	currentTime := time.Date(2019, time.August, 21, 9, 59, 59, 950000000, time.UTC) // 50ms before the default schedule
+	stat := db.Stat{Year: 2019}
+	refreshed := make(chan db.Stat, 1)
+	s := newEtlTestServer(&stat, currentTime, refreshed)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	s.runEtlScheduler(ctx)
+	select {
+	case got := <-refreshed:
+		if !currentTime.Equal(*got.EtlTimestamp) {
+			t.Errorf("wanted stat refreshed at %v, got %v", currentTime, got.EtlTimestamp)
+		}
+	case <-time.After(time.Second):
+		t.Error("wanted scheduled refresh")
+	}
+}
@@ -3,6 +3,7 @@ package server
 
 import (
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -10,6 +11,8 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -21,13 +24,31 @@ import (
 type (
 	// Config contains fields which describe the server
 	Config struct {
-		DisplayName    string
-		Port           string
-		NflAppKey      string
+		DisplayName string
+		Port        string
+		NflAppKey   string
+		// NflProviders are the comma-separated names of the sources of nfl data, primary first, such as "fantasy,espn".  The default providers are used if it is empty.
+		NflProviders   string
 		LogRequestURIs bool
-		HTMLFS         fs.FS
-		JavascriptFS   fs.FS
-		StaticFS       fs.FS
+		// RequestRetries is the number of times failed requests to external sources are retried
+		RequestRetries int
+		// RequestConcurrency is the most requests that can be made to an external host at once
+		RequestConcurrency int
+		// RequestRate is the most requests that can be started to an external host each second
+		RequestRate float64
+		// UpstreamURL is the url of a server to request all stats from instead of the external sources, such as a mock server.  The external sources are used if it is empty.
+		UpstreamURL string
+		// CacheFile is the file responses to requests are saved in so they are kept when the server restarts.  Responses are not saved if it is empty.
+		CacheFile string
+		// EtlSchedules are the semicolon-separated cron schedules to refresh stats for each sport, such as "mlb=0 0 * * *"
+		EtlSchedules string
+		// EtlTimeZones are the semicolon-separated time zones of the EtlSchedules for each sport, such as "mlb=America/New_York"
+		EtlTimeZones string
+		DigestConfig
+		WebhookConfig
+		HTMLFS       fs.FS
+		JavascriptFS fs.FS
+		StaticFS     fs.FS
 	}
 
 	// Server contains data to serve pages for the user.
@@ -41,18 +62,26 @@ type (
 		scoreCategorizers map[db.PlayerType]request.ScoreCategorizer
 		searchers         map[db.PlayerType]request.Searcher
 		aboutRequester    AboutRequester
+		drafts            *draftRoom
+		events            *eventBroker
+		etlSchedules      map[db.SportType]etlSchedule
+		etlRefreshes      *etlRefreshes
+		digests           *digestSender
+		webhooks          *webhookNotifier
 	}
 
 	// ServerDatastore provides a way for the server to store and retrieve data.
 	ServerDatastore interface {
-		GetYears(st db.SportType) ([]db.Year, error)
+		GetYears(ctx context.Context, st db.SportType) ([]db.Year, error)
+		GetYearStat(ctx context.Context, st db.SportType, year int) (*db.Stat, error)
+		GetSubscriptions(ctx context.Context, st db.SportType) ([]db.Subscription, error)
 		adminDatastore
 		etlDatastore
 	}
 
 	// AboutRequester gets the previous deployment info for the app.
 	AboutRequester interface {
-		PreviousDeployment() (*request.Deployment, error)
+		PreviousDeployment(ctx context.Context) (*request.Deployment, error)
 	}
 )
 
@@ -69,25 +98,50 @@ func (cfg Config) New(log *log.Logger, ds ServerDatastore, httpClient request.HT
 	case httpClient == nil:
 		return nil, fmt.Errorf("httpClient required")
 	}
+	nflProviders, err := request.ParseNflProviders(cfg.NflProviders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nfl providers: %w", err)
+	}
 	sportTypes := ds.SportTypes()
-	if _, ok := sportTypes[db.SportTypeNfl]; ok && len(cfg.NflAppKey) == 0 {
-		return nil, fmt.Errorf("nfl app key required")
+	if _, ok := sportTypes[db.SportTypeNfl]; ok && len(cfg.NflAppKey) == 0 && slices.Contains(nflProviders, request.NflProviderFantasy) {
+		return nil, fmt.Errorf("nfl app key required for the %v nfl provider", request.NflProviderFantasy)
 	}
 	sportEntries := newSportEntries(sportTypes)
 	sportTypesByURL := make(map[string]db.SportType, len(sportTypes))
 	for st, sti := range sportTypes {
 		sportTypesByURL[sti.URL] = st
 	}
-	c := request.NewCache(100)
+	etlSchedules, err := parseEtlSchedules(cfg.EtlSchedules, cfg.EtlTimeZones, sportTypesByURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid etl schedules: %w", err)
+	}
+	digests, err := cfg.newDigestSender()
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest config: %w", err)
+	}
+	webhooks, err := cfg.newWebhookNotifier()
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	c := request.NewCache(request.NewCacheConfig(100, cfg.CacheFile))
+	if err := c.Load(); err != nil {
+		log.Printf("starting with empty request cache: %v", err)
+	}
 	environment := cfg.DisplayName
-	scoreCategorizers, searchers, aboutRequester := request.NewRequesters(httpClient, c, cfg.NflAppKey, environment, cfg.LogRequestURIs, log)
+	scoreCategorizers, searchers, aboutRequester := request.NewRequesters(httpClient, c, cfg.NflAppKey, nflProviders, environment, cfg.LogRequestURIs, request.NewRetryConfig(cfg.RequestRetries), request.NewHostLimits(cfg.RequestConcurrency, cfg.RequestRate), request.NewBaseURLs(cfg.UpstreamURL), log)
 	s := Server{
 		sportEntries:      sportEntries,
 		sportTypesByURL:   sportTypesByURL,
-		requestCache:      &c,
+		requestCache:      c,
 		scoreCategorizers: scoreCategorizers,
 		searchers:         searchers,
 		aboutRequester:    aboutRequester,
+		drafts:            newDraftRoom(),
+		events:            newEventBroker(),
+		etlSchedules:      etlSchedules,
+		etlRefreshes:      newEtlRefreshes(),
+		digests:           digests,
+		webhooks:          webhooks,
 		log:               log,
 		ds:                ds,
 	}
@@ -100,6 +154,12 @@ func (cfg Config) validate() error {
 		return fmt.Errorf("invalid port number: %s", cfg.Port)
 	}
 	switch {
+	case cfg.RequestRetries < 0:
+		return fmt.Errorf("request retries must not be negative: %v", cfg.RequestRetries)
+	case cfg.RequestConcurrency < 0:
+		return fmt.Errorf("request concurrency must not be negative: %v", cfg.RequestConcurrency)
+	case cfg.RequestRate < 0:
+		return fmt.Errorf("request rate must not be negative: %v", cfg.RequestRate)
 	case cfg.HTMLFS == nil:
 		return fmt.Errorf("html filesystem required")
 	case cfg.JavascriptFS == nil:
@@ -113,6 +173,9 @@ func (cfg Config) validate() error {
 // Run configures and starts the server
 func (s Server) Run() error {
 	h := s.handler()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	s.runEtlScheduler(ctx)
 	addr := fmt.Sprintf(":%s", s.Port)
 	s.log.Println("starting server - locally running at http://127.0.0.1" + addr)
 	if err := http.ListenAndServe(addr, h); err != http.ErrServerClosed { // BLOCKS
@@ -191,12 +254,28 @@ func (s Server) handleGet(st db.SportType, path string, w http.ResponseWriter, r
 		s.handleStatsPage(st, w, r)
 	case "/SportType/export":
 		s.handleExport(st, w, r)
+	case "/SportType/events":
+		s.handleEvents(st, w, r)
+	case "/SportType/records":
+		s.handleRecordsPage(st, w, r)
 	case "/SportType/admin":
 		s.handleAdminPage(st, w, r)
-	case "/SportType/admin/search":
+	case "/SportType/admin/search", "/SportType/draft/search":
 		s.handleAdminSearch(st, w, r)
+	case "/SportType/draft":
+		s.handleDraftPage(st, w, r)
+	case "/SportType/draft/board":
+		s.handleDraftBoard(st, w, r)
 	default:
-		w.WriteHeader(http.StatusNotFound)
+		year, subPath, ok := parseYearPath(path)
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+		case subPath == "/export":
+			s.handlePastExport(st, year, w, r)
+		default:
+			s.handlePastStatsPage(st, year, w, r)
+		}
 	}
 }
 
@@ -204,6 +283,10 @@ func (s Server) handlePost(st db.SportType, path string, w http.ResponseWriter,
 	switch path {
 	case "/SportType/admin":
 		s.handleAdminPost(st, w, r)
+	case "/SportType/admin/import":
+		s.handleImportPost(st, w, r)
+	case "/SportType/draft":
+		s.handleDraftPost(st, w, r)
 	default:
 		w.WriteHeader(http.StatusNotFound)
 	}
@@ -211,13 +294,27 @@ func (s Server) handlePost(st db.SportType, path string, w http.ResponseWriter,
 
 func (s Server) handleHomePage(st db.SportType, w http.ResponseWriter, r *http.Request) {
 	title := fmt.Sprintf("%s Stats", s.DisplayName)
-	homeTab := AdminTab{Name: "Home"}
+	homeTab := HomeTab{
+		Name:   "Home",
+		Sports: make([]HomeSport, len(s.sportEntries)),
+	}
+	for i, se := range s.sportEntries {
+		pastSeasons, err := getPastSeasons(r.Context(), se.sportType, s.ds)
+		if err != nil {
+			// the sports are still listed when their past seasons cannot be read
+			s.log.Printf("getting past %v seasons: %v", se.Name, err)
+		}
+		homeTab.Sports[i] = HomeSport{
+			SportEntry:  se,
+			PastSeasons: pastSeasons,
+		}
+	}
 	homePage := newPage(s, title, []Tab{homeTab}, false, TimesMessage{}, "home")
 	s.renderTemplate(w, homePage)
 }
 
 func (s Server) handleStatsPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
-	es, err := getEtlStats(st, s.ds, s.scoreCategorizers)
+	es, err := s.getEtlStats(r.Context(), st)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -227,6 +324,7 @@ func (s Server) handleStatsPage(st db.SportType, w http.ResponseWriter, r *http.
 	for i, sc := range es.scoreCategories {
 		tabs[i] = StatsTab{
 			ScoreCategory: sc,
+			Status:        es.status(sc),
 			ExportURL:     fmt.Sprintf("/%s/export", stURL),
 		}
 	}
@@ -237,9 +335,15 @@ func (s Server) handleStatsPage(st db.SportType, w http.ResponseWriter, r *http.
 			},
 		}
 	}
+	etlSchedule := s.etlSchedules[st]
+	nextEtlRefreshTime := etlSchedule.next(s.ds.GetUtcTime())
 	timesMessage := TimesMessage{
-		Messages: []string{"Stats reset daily after first page load is loaded after", "and last reset at"},
-		Times:    []time.Time{es.etlRefreshTime, es.etlTime},
+		Messages: []string{
+			fmt.Sprintf("Stats are refreshed on the schedule %v.  The last scheduled refresh was at", etlSchedule),
+			"and stats were last refreshed at",
+			"The next refresh is at",
+		},
+		Times: []time.Time{es.etlRefreshTime, es.etlTime, nextEtlRefreshTime},
 	}
 	stName := s.ds.SportTypes()[st].Name
 	title := fmt.Sprintf("%s %s stats - %d", s.DisplayName, stName, es.year)
@@ -247,13 +351,94 @@ func (s Server) handleStatsPage(st db.SportType, w http.ResponseWriter, r *http.
 	s.renderTemplate(w, statsPage)
 }
 
-func (s Server) handleAdminPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
-	es, err := getEtlStats(st, s.ds, s.scoreCategorizers)
+// handlePastStatsPage renders the final stats of a year that is no longer active
+func (s Server) handlePastStatsPage(st db.SportType, year int, w http.ResponseWriter, r *http.Request) {
+	es, ok := s.getPastEtlStats(st, year, "", w, r)
+	if !ok {
+		return
+	}
+	tabs := make([]Tab, len(es.scoreCategories))
+	stURL := s.ds.SportTypes()[st].URL
+	for i, sc := range es.scoreCategories {
+		tabs[i] = StatsTab{
+			ScoreCategory: sc,
+			Status:        es.status(sc),
+			ExportURL:     fmt.Sprintf("/%s/%d/export", stURL, year),
+			Archived:      true,
+		}
+	}
+	if len(tabs) == 0 {
+		tabs = []Tab{
+			StatsTab{
+				ScoreCategory: request.ScoreCategory{Name: "No Stats"},
+				Archived:      true,
+			},
+		}
+	}
+	var timesMessage TimesMessage
+	if !es.etlTime.IsZero() {
+		timesMessage.Messages = []string{"These are the final stats, which were last refreshed at"}
+		timesMessage.Times = []time.Time{es.etlTime}
+	}
+	stName := s.ds.SportTypes()[st].Name
+	title := fmt.Sprintf("%s %s stats - %d", s.DisplayName, stName, year)
+	statsPage := newPage(s, title, tabs, true, timesMessage, "stats")
+	s.renderTemplate(w, statsPage)
+}
+
+// getPastEtlStats gets the stats of a year that is no longer active.
+// If the year is active, the request is redirected to the page of the current stats at the subPath.
+// False is returned if the stats could not be gotten, after the response is written.
+func (s Server) getPastEtlStats(st db.SportType, year int, subPath string, w http.ResponseWriter, r *http.Request) (*EtlStats, bool) {
+	es, active, err := getPastEtlStats(r.Context(), st, year, s.ds)
+	switch {
+	case err != nil:
+		s.handleError(w, err)
+		return nil, false
+	case active:
+		stURL := s.ds.SportTypes()[st].URL
+		u := url.URL{Path: fmt.Sprintf("/%s%s", stURL, subPath), RawQuery: r.URL.RawQuery}
+		w.Header().Add("Location", u.String())
+		w.WriteHeader(http.StatusSeeOther)
+		return nil, false
+	case es == nil:
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	return es, true
+}
+
+// handleRecordsPage renders the all-time records of the friends and players in the years that are no longer active
+func (s Server) handleRecordsPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	records, err := getAllTimeRecords(r.Context(), st, s.ds)
 	if err != nil {
 		s.handleError(w, err)
 		return
 	}
-	years, err := s.ds.GetYears(st)
+	tabs := []Tab{
+		RecordsTab{Name: "Friends", Action: "friends", Records: records},
+		RecordsTab{Name: "Best Seasons", Action: "seasons", Records: records},
+		RecordsTab{Name: "Top Picks", Action: "picks", Records: records},
+	}
+	timesMessage := TimesMessage{}
+	stName := s.ds.SportTypes()[st].Name
+	title := fmt.Sprintf("%s %s Hall of Fame", s.DisplayName, stName)
+	recordsPage := newPage(s, title, tabs, true, timesMessage, "records")
+	s.renderTemplate(w, recordsPage)
+}
+
+func (s Server) handleAdminPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	es, err := s.getEtlStats(r.Context(), st)
+	if err != nil {
+		// the players are shown with their saved names so they can be administered when the stats cannot be requested
+		s.log.Printf("showing players without stats: %v", err)
+		es, err = getRosterEtlStats(r.Context(), st, s.ds, err)
+		if err != nil {
+			s.handleError(w, err)
+			return
+		}
+	}
+	years, err := s.ds.GetYears(r.Context(), st)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -262,13 +447,17 @@ func (s Server) handleAdminPage(st db.SportType, w http.ResponseWriter, r *http.
 	for i, sc := range es.scoreCategories {
 		scoreCategoriesData[i] = sc
 	}
-	var friendsData []interface{}
+	var friendScores []request.FriendScore
 	if len(es.scoreCategories) > 0 {
-		friendsData = make([]interface{}, len(es.scoreCategories[0].FriendScores))
-		for i, fs := range es.scoreCategories[0].FriendScores {
-			friendsData[i] = fs
-		}
+		friendScores = es.scoreCategories[0].FriendScores
 	}
+	subscriptions, err := s.ds.GetSubscriptions(r.Context(), st)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	subscriptionsData := []interface{}{s.digests != nil, newFriendSubscriptions(friendScores, subscriptions)}
+	webhooksData := []interface{}{s.webhooks != nil, s.webhooks.recentDeliveries()}
 	yearsData := make([]interface{}, len(years))
 	for i, year := range years {
 		yearsData[i] = year
@@ -276,8 +465,11 @@ func (s Server) handleAdminPage(st db.SportType, w http.ResponseWriter, r *http.
 	tabs := []Tab{
 		AdminTab{Name: "Players", Action: "players", Data: scoreCategoriesData},
 		AdminTab{Name: "Friends", Action: "friends", Data: scoreCategoriesData},
+		AdminTab{Name: "Import", Action: "import"},
+		AdminTab{Name: "Digest", Action: "subscriptions", Data: subscriptionsData},
+		AdminTab{Name: "Webhooks", Action: "webhooks", Data: webhooksData},
 		AdminTab{Name: "Years", Action: "years", Data: yearsData},
-		AdminTab{Name: "Clear Cache", Action: "cache"},
+		AdminTab{Name: "Clear Cache", Action: "cache", Data: []interface{}{s.etlRefreshes.metrics(st), es.failedStatuses(), s.requestCache.Stats()}},
 		AdminTab{Name: "Reset Password", Action: "password"},
 	}
 	timesMessage := TimesMessage{}
@@ -287,8 +479,21 @@ func (s Server) handleAdminPage(st db.SportType, w http.ResponseWriter, r *http.
 	s.renderTemplate(w, adminPage)
 }
 
+func (s Server) handleDraftPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	draftTab, err := newDraftTab(r.Context(), st, s.ds)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	timesMessage := TimesMessage{}
+	stName := s.ds.SportTypes()[st].Name
+	title := fmt.Sprintf("%s %s Draft", s.DisplayName, stName)
+	draftPage := newPage(s, title, []Tab{draftTab}, false, timesMessage, "draft")
+	s.renderTemplate(w, draftPage)
+}
+
 func (s Server) handleAboutPage(st db.SportType, w http.ResponseWriter, r *http.Request) {
-	lastDeploy, err := s.aboutRequester.PreviousDeployment()
+	lastDeploy, err := s.aboutRequester.PreviousDeployment(r.Context())
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -305,15 +510,47 @@ func (s Server) handleAboutPage(st db.SportType, w http.ResponseWriter, r *http.
 }
 
 func (s Server) handleExport(st db.SportType, w http.ResponseWriter, r *http.Request) {
-	es, err := getEtlStats(st, s.ds, s.scoreCategorizers)
+	s.writeExport(w, r, func() (*EtlStats, bool) {
+		es, err := s.getEtlStats(r.Context(), st)
+		if err != nil {
+			s.handleError(w, err)
+			return nil, false
+		}
+		return es, true
+	})
+}
+
+func (s Server) handlePastExport(st db.SportType, year int, w http.ResponseWriter, r *http.Request) {
+	s.writeExport(w, r, func() (*EtlStats, bool) {
+		return s.getPastEtlStats(st, year, "/export", w, r)
+	})
+}
+
+// writeExport writes the stats in the requested format as an attachment.
+// The getEtlStats function writes the response if it cannot get the stats.
+func (s Server) writeExport(w http.ResponseWriter, r *http.Request, getEtlStats func() (*EtlStats, bool)) {
+	ef, err := getExportFormat(r.FormValue("format"))
 	if err != nil {
-		s.handleError(w, err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	es, ok := getEtlStats()
+	if !ok {
+		return
 	}
 	asOfDate := es.etlTime.Format("2006-01-02")
-	fileName := fmt.Sprintf("%s_%s-%d_%s.csv", s.DisplayName, es.sportTypeName, es.year, asOfDate)
+	fileName := fmt.Sprintf("%s_%s-%d_%s%s", s.DisplayName, es.sportTypeName, es.year, asOfDate, ef.fileSuffix)
 	contentDisposition := fmt.Sprintf(`attachment; filename="%s"`, fileName)
 	w.Header().Set("Content-Disposition", contentDisposition)
-	if err := exportToCsv(*es, s.DisplayName, w); err != nil {
+	w.Header().Set("Content-Type", ef.contentType)
+	if err := ef.export(*es, s.DisplayName, w); err != nil {
+		s.handleError(w, err)
+	}
+}
+
+func (s Server) handleEvents(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	if err := s.events.serveEvents(st, w, r); err != nil {
 		s.handleError(w, err)
 	}
 }
@@ -357,18 +594,38 @@ func (s Server) parseTemplate(w http.ResponseWriter, p Page) (*template.Template
 	return t, nil
 }
 
+// refreshRosters refreshes the stats of the SportType after an admin change to its rosters so clients viewing them see the change.
+// The webhooks are notified of the change in the background.
+func (s Server) refreshRosters(ctx context.Context, st db.SportType, change string) {
+	es, err := s.getEtlStats(ctx, st)
+	if err != nil {
+		s.log.Printf("refreshing stats after an admin %v: %v", change, err)
+		return
+	}
+	e := newRosterWebhookEvent(s.DisplayName, es.sportTypeName, es.year, change)
+	go s.notifyWebhooks(context.WithoutCancel(ctx), []WebhookEvent{e})
+}
+
 func (s Server) handleAdminPost(st db.SportType, w http.ResponseWriter, r *http.Request) {
 	if err := handleAdminPostRequest(s.ds, s.requestCache, st, r); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
 		return
 	}
+	switch action := r.FormValue("action"); action {
+	case "players", "friends":
+		s.refreshRosters(r.Context(), st, "edited the "+action)
+	case "years":
+		if yearRolledOver(r) {
+			s.refreshRosters(r.Context(), st, "rolled over the rosters")
+		}
+	}
 	w.Header().Add("Location", r.URL.Path)
 	w.WriteHeader(http.StatusSeeOther)
 }
 
 func (s Server) handleAdminSearch(st db.SportType, w http.ResponseWriter, r *http.Request) {
-	es, err := getEtlStats(st, s.ds, s.scoreCategorizers)
+	es, err := s.getEtlStats(r.Context(), st)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -384,6 +641,52 @@ func (s Server) handleAdminSearch(st db.SportType, w http.ResponseWriter, r *htt
 	}
 }
 
+func (s Server) handleImportPost(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	es, err := s.getEtlStats(r.Context(), st)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	importRows, err := handleImportPostRequest(s.ds, s.searchers, es.year, st, r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if r.FormValue("action") == "import" {
+		s.refreshRosters(r.Context(), st, "imported rosters")
+		w.Header().Add("Location", strings.TrimSuffix(r.URL.Path, "/import"))
+		w.WriteHeader(http.StatusSeeOther)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(importRows); err != nil {
+		s.handleError(w, fmt.Errorf("converting ImportRows (%v) to json: %w", importRows, err))
+		return
+	}
+}
+
+func (s Server) handleDraftBoard(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	draftBoard := s.drafts.board(st, s.ds.GetUtcTime())
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(draftBoard); err != nil {
+		s.handleError(w, fmt.Errorf("converting DraftBoard (%v) to json: %w", draftBoard, err))
+		return
+	}
+}
+
+func (s Server) handleDraftPost(st db.SportType, w http.ResponseWriter, r *http.Request) {
+	if err := handleDraftPostRequest(s.ds, s.drafts, st, r); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if r.FormValue("action") == "finalize" {
+		s.refreshRosters(r.Context(), st, "finalized the draft")
+	}
+	w.Header().Add("Location", r.URL.Path+"/board")
+	w.WriteHeader(http.StatusSeeOther)
+}
+
 func (s Server) transformURLPath(r *http.Request) (st db.SportType, path string) {
 	urlPath := r.URL.Path
 	parts := strings.Split(urlPath, "/")
@@ -408,3 +711,13 @@ type wrappedResponseWriter struct {
 func (wrw wrappedResponseWriter) Write(p []byte) (n int, err error) {
 	return wrw.Writer.Write(p)
 }
+
+// Flush flushes the wrapped writer and the response if they support flushing.
+func (wrw wrappedResponseWriter) Flush() {
+	if f, ok := wrw.Writer.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := wrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
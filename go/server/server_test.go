@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"io"
 	"log"
 	"net/http"
@@ -16,15 +17,25 @@ import (
 )
 
 type mockServerDatastore struct {
-	GetYearsFunc func(st db.SportType) ([]db.Year, error)
+	GetYearsFunc         func(st db.SportType) ([]db.Year, error)
+	GetYearStatFunc      func(st db.SportType, year int) (*db.Stat, error)
+	GetSubscriptionsFunc func(st db.SportType) ([]db.Subscription, error)
 	adminDatastore
 	etlDatastore
 }
 
-func (ds mockServerDatastore) GetYears(st db.SportType) ([]db.Year, error) {
+func (ds mockServerDatastore) GetYears(ctx context.Context, st db.SportType) ([]db.Year, error) {
 	return ds.GetYearsFunc(st)
 }
 
+func (ds mockServerDatastore) GetYearStat(ctx context.Context, st db.SportType, year int) (*db.Stat, error) {
+	return ds.GetYearStatFunc(st, year)
+}
+
+func (ds mockServerDatastore) GetSubscriptions(ctx context.Context, st db.SportType) ([]db.Subscription, error) {
+	return ds.GetSubscriptionsFunc(st)
+}
+
 type mockHTTPClient struct {
 	DoFunc func(r *http.Request) (*http.Response, error)
 }
@@ -37,7 +48,7 @@ type mockAboutRequester struct {
 	PreviousDeploymentFunc func() (*request.Deployment, error)
 }
 
-func (m mockAboutRequester) PreviousDeployment() (*request.Deployment, error) {
+func (m mockAboutRequester) PreviousDeployment(ctx context.Context) (*request.Deployment, error) {
 	return m.PreviousDeploymentFunc()
 }
 
@@ -89,9 +100,14 @@ func TestTransformURLPath(t *testing.T) {
 
 func TestNew(t *testing.T) {
 	newConfigTests := []struct {
-		serverName string
-		port       string
-		wantErr    bool
+		serverName         string
+		port               string
+		requestRetries     int
+		requestConcurrency int
+		requestRate        float64
+		noNflAppKey        bool
+		nflProviders       string
+		wantErr            bool
 	}{
 		{ // invalid port
 			wantErr: true,
@@ -100,13 +116,48 @@ func TestNew(t *testing.T) {
 			port:    "four",
 			wantErr: true,
 		},
+		{
+			port:           "8000",
+			requestRetries: -1,
+			wantErr:        true,
+		},
+		{
+			port:               "8000",
+			requestConcurrency: -1,
+			wantErr:            true,
+		},
+		{
+			port:        "8000",
+			requestRate: -0.5,
+			wantErr:     true,
+		},
+		{
+			port:         "8000",
+			nflProviders: "espn,yahoo",
+			wantErr:      true,
+		},
+		{ // the default fantasy provider requires an app key
+			port:        "8000",
+			noNflAppKey: true,
+			wantErr:     true,
+		},
+		{
+			port:         "8000",
+			noNflAppKey:  true,
+			nflProviders: "espn",
+		},
 		{ // happy path
-			serverName: "my server",
-			port:       "8000",
+			serverName:         "my server",
+			port:               "8000",
+			requestRetries:     2,
+			requestConcurrency: 4,
+			requestRate:        10,
 		},
 	}
 	for i, test := range newConfigTests {
 		ds := mockServerDatastore{
+			nil,
+			nil,
 			nil,
 			nil,
 			mockEtlDatastore{
@@ -124,14 +175,22 @@ func TestNew(t *testing.T) {
 		jsFS := fstest.MapFS{}
 		staticFS := fstest.MapFS{}
 		httpClient := mockHTTPClient{}
+		nflAppKey := "dummyNflAppKey"
+		if test.noNflAppKey {
+			nflAppKey = ""
+		}
 		cfg := Config{
-			DisplayName:    test.serverName,
-			Port:           test.port,
-			NflAppKey:      "dummyNflAppKey",
-			LogRequestURIs: logRequestURIs,
-			HTMLFS:         htmlFS,
-			JavascriptFS:   jsFS,
-			StaticFS:       staticFS,
+			DisplayName:        test.serverName,
+			Port:               test.port,
+			NflAppKey:          nflAppKey,
+			NflProviders:       test.nflProviders,
+			LogRequestURIs:     logRequestURIs,
+			RequestRetries:     test.requestRetries,
+			RequestConcurrency: test.requestConcurrency,
+			RequestRate:        test.requestRate,
+			HTMLFS:             htmlFS,
+			JavascriptFS:       jsFS,
+			StaticFS:           staticFS,
 		}
 		s, err := cfg.New(log, ds, httpClient)
 		switch {
@@ -184,14 +243,39 @@ func TestServerHandlers(t *testing.T) {
 		{wantCode: 200, method: "GET", path: "/about"},
 		{wantCode: 200, method: "GET", path: "/st_1_url"},
 		{wantCode: 200, method: "GET", path: "/st_1_url/export"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/export?format=json"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/export?format=tidy"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/export?format=xlsx"},
+		{wantCode: 400, method: "GET", path: "/st_1_url/export?format=pdf"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/2018"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/2018/export?format=json"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/2019"}, // active year: should redirect to 200
+		{wantCode: 200, method: "GET", path: "/st_1_url/2019/export"},
+		{wantCode: 404, method: "GET", path: "/st_1_url/2017"},
+		{wantCode: 404, method: "GET", path: "/st_1_url/2017/export"},
+		{wantCode: 404, method: "GET", path: "/st_1_url/2018/admin"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/records"},
 		{wantCode: 200, method: "GET", path: "/st_1_url/admin"},
 		{wantCode: 200, method: "GET", path: "/st_1_url/admin/search?q=name&pt=77"},
 		{wantCode: 200, method: "POST", path: "/st_1_url/admin?action=password"}, // should redirect to 200
+		{wantCode: 200, method: "POST", path: "/st_1_url/admin/import?action=preview&roster=a,1,b"},
+		{wantCode: 400, method: "POST", path: "/st_1_url/admin/import?action=import"}, // no players
+		{wantCode: 200, method: "GET", path: "/st_1_url/draft"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/draft/board"},
+		{wantCode: 200, method: "GET", path: "/st_1_url/draft/search?q=name&pt=77"},
+		{wantCode: 200, method: "POST", path: "/st_1_url/draft?action=cancel"},            // should redirect to 200
+		{wantCode: 400, method: "POST", path: "/st_1_url/draft?action=start&order=snake"}, // no friends
 		{wantCode: 405, method: "HEAD", path: "/"},
 	}
 	for i, test := range tests {
 		ds := mockServerDatastore{
 			GetYearsFunc: func(st db.SportType) ([]db.Year, error) {
+				return []db.Year{{Value: 2018}, {Value: 2019, Active: true}}, nil
+			},
+			GetYearStatFunc: func(st db.SportType, year int) (*db.Stat, error) {
+				return &db.Stat{SportType: st, Year: year}, nil
+			},
+			GetSubscriptionsFunc: func(st db.SportType) ([]db.Subscription, error) {
 				return nil, nil
 			},
 			adminDatastore: mockAdminDatastore{
@@ -238,10 +322,12 @@ func TestServerHandlers(t *testing.T) {
 	</body>
 </html>`),
 			},
-			"html/home/tab.html":  &fstest.MapFile{Data: []byte(`1`)},
-			"html/about/tab.html": &fstest.MapFile{Data: []byte(`2`)},
-			"html/stats/tab.html": &fstest.MapFile{Data: []byte(`3`)},
-			"html/admin/tab.html": &fstest.MapFile{Data: []byte(`4`)},
+			"html/home/tab.html":    &fstest.MapFile{Data: []byte(`1`)},
+			"html/about/tab.html":   &fstest.MapFile{Data: []byte(`2`)},
+			"html/stats/tab.html":   &fstest.MapFile{Data: []byte(`3`)},
+			"html/admin/tab.html":   &fstest.MapFile{Data: []byte(`4`)},
+			"html/draft/tab.html":   &fstest.MapFile{Data: []byte(`5`)},
+			"html/records/tab.html": &fstest.MapFile{Data: []byte(`6`)},
 		}
 		jsFS := fstest.MapFS{}
 		staticFS := fstest.MapFS{
@@ -261,8 +347,11 @@ func TestServerHandlers(t *testing.T) {
 				JavascriptFS: jsFS,
 				StaticFS:     staticFS,
 			},
-			log: log,
-			ds:  ds,
+			log:          log,
+			ds:           ds,
+			drafts:       newDraftRoom(),
+			events:       newEventBroker(),
+			etlRefreshes: newEtlRefreshes(),
 			aboutRequester: mockAboutRequester{
 				PreviousDeploymentFunc: func() (*request.Deployment, error) {
 					return new(request.Deployment), nil
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+type (
+	// WebhookConfig describes the webhooks that are notified when the standings or rosters change.
+	WebhookConfig struct {
+		// Webhooks are the semicolon-separated formats and urls of the webhooks, such as "slack=https://hooks.slack.com/services/...".
+		// The formats are slack, discord, and json.  No webhooks are notified if it is empty.
+		Webhooks string
+		// WebhookSecret is the key the payloads are signed with.  The signature is not sent if it is empty.
+		WebhookSecret string
+	}
+
+	// WebhookEvent is a change that webhooks are notified of.
+	WebhookEvent struct {
+		// Kind is the type of change: leader, rank, or roster.
+		Kind            string   `json:"kind"`
+		ApplicationName string   `json:"applicationName"`
+		SportType       string   `json:"sportType"`
+		Year            int      `json:"year"`
+		Title           string   `json:"title"`
+		Lines           []string `json:"lines"`
+	}
+
+	// WebhookDelivery is the result of notifying a webhook of an event.  The Error is empty if the delivery succeeded.
+	WebhookDelivery struct {
+		Time        time.Time
+		Kind        string
+		Format      string
+		Destination string
+		Attempts    int
+		StatusCode  int
+		Error       string
+	}
+
+	webhook struct {
+		format string
+		url    string
+	}
+
+	// webhookNotifier posts events to webhooks, retrying failed deliveries and keeping a log of the recent ones.
+	webhookNotifier struct {
+		webhooks   []webhook
+		secret     []byte
+		httpClient request.HTTPClient
+		retries    int
+		backoff    time.Duration
+		mu         sync.Mutex
+		deliveries []WebhookDelivery
+	}
+)
+
+const (
+	// webhookSignatureHeader is the header of the hex-encoded HMAC-SHA256 signature of the payload.
+	webhookSignatureHeader = "X-Webhook-Signature"
+	// webhookTimeout is the longest a post to a webhook can take.
+	webhookTimeout = 10 * time.Second
+	// webhookBackoff is the wait before the first retry of a failed delivery.  It doubles for each retry.
+	webhookBackoff = time.Second
+	// maxWebhookDeliveries is the most deliveries kept in the log.
+	maxWebhookDeliveries = 50
+)
+
+var (
+	webhookTemplateFuncs = template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+	// webhookTemplates create the json payloads for the formats of webhooks.
+	webhookTemplates = map[string]*template.Template{
+		"slack": template.Must(template.New("slack").Funcs(webhookTemplateFuncs).Parse(
+			`{"text":{{json .Title}},"blocks":[{"type":"header","text":{"type":"plain_text","text":{{json .Title}}}},{"type":"section","text":{"type":"mrkdwn","text":{{json .Text}}}}]}`)),
+		"discord": template.Must(template.New("discord").Funcs(webhookTemplateFuncs).Parse(
+			`{"username":{{json .ApplicationName}},"embeds":[{"title":{{json .Title}},"description":{{json .Text}}}]}`)),
+		"json": template.Must(template.New("json").Funcs(webhookTemplateFuncs).Parse(
+			`{{json .}}`)),
+	}
+)
+
+// newWebhookNotifier creates a webhookNotifier for the config, nil if no webhooks are configured.
+// Failed deliveries are retried the number of times that requests to external sources are.
+// Webhooks are posted to with a client of their own so they are not replayed or recorded with the responses of the external sources, which would keep the tokens in their urls.
+func (cfg Config) newWebhookNotifier() (*webhookNotifier, error) {
+	var webhooks []webhook
+	for _, pair := range strings.Split(cfg.Webhooks, ";") {
+		if len(strings.TrimSpace(pair)) == 0 {
+			continue
+		}
+		format, rawURL, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q must be in the form format=url", pair)
+		}
+		format = strings.TrimSpace(format)
+		if _, ok := webhookTemplates[format]; !ok {
+			return nil, fmt.Errorf("unknown webhook format %q", format)
+		}
+		rawURL = strings.TrimSpace(rawURL)
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return nil, fmt.Errorf("invalid %v webhook url", format)
+		}
+		webhooks = append(webhooks, webhook{format: format, url: rawURL})
+	}
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+	n := webhookNotifier{
+		webhooks:   webhooks,
+		secret:     []byte(cfg.WebhookSecret),
+		httpClient: &http.Client{Timeout: webhookTimeout},
+		retries:    cfg.RequestRetries,
+		backoff:    webhookBackoff,
+	}
+	return &n, nil
+}
+
+// Text is the lines of the event.
+func (e WebhookEvent) Text() string {
+	return strings.Join(e.Lines, "\n")
+}
+
+// newStandingsWebhookEvents creates events for the leaders and ranks of friends that changed from the previous EtlStats to the current ones.
+// A leader event is created for each ScoreCategory with a new leader.  The rank changes of all ScoreCategories are combined into one rank event.
+func newStandingsWebhookEvents(applicationName string, previous, current EtlStats) []WebhookEvent {
+	if previous.etlTime.IsZero() || previous.year != current.year {
+		return nil
+	}
+	newEvent := func(kind, title string) WebhookEvent {
+		return WebhookEvent{
+			Kind:            kind,
+			ApplicationName: applicationName,
+			SportType:       current.sportTypeName,
+			Year:            current.year,
+			Title:           fmt.Sprintf("%s %s %d: %s", applicationName, current.sportTypeName, current.year, title),
+		}
+	}
+	var events []WebhookEvent
+	rankEvent := newEvent("rank", "standings changed")
+	for _, sc := range current.scoreCategories {
+		previousSC, _, _ := previous.scoreCategory(sc.PlayerType)
+		if len(sc.FriendScores) == 0 || len(previousSC.FriendScores) == 0 {
+			continue
+		}
+		dc := newDigestCategory(previousSC, sc)
+		var leaders, previousLeaders []string
+		for _, s := range dc.Standings {
+			if s.Rank == 1 {
+				leaders = append(leaders, s.Name)
+			}
+			if s.PreviousRank == 1 {
+				previousLeaders = append(previousLeaders, s.Name)
+			}
+			if s.PreviousRank != 0 && s.Rank != s.PreviousRank {
+				line := fmt.Sprintf("%s moved %s to #%d in %s with %d", s.Name, s.Change(), s.Rank, sc.Name, s.Score)
+				rankEvent.Lines = append(rankEvent.Lines, line)
+			}
+		}
+		if len(previousLeaders) != 0 && strings.Join(leaders, ",") != strings.Join(previousLeaders, ",") {
+			leaderEvent := newEvent("leader", "new "+sc.Name+" leader")
+			line := fmt.Sprintf("%s leads %s with %d", strings.Join(leaders, " and "), sc.Name, dc.Standings[0].Score)
+			leaderEvent.Lines = append(leaderEvent.Lines, line)
+			events = append(events, leaderEvent)
+		}
+	}
+	if len(rankEvent.Lines) != 0 {
+		events = append(events, rankEvent)
+	}
+	return events
+}
+
+// newRosterWebhookEvent creates an event for an admin change to the rosters, such as "edited the friends".
+func newRosterWebhookEvent(applicationName, sportTypeName string, year int, change string) WebhookEvent {
+	return WebhookEvent{
+		Kind:            "roster",
+		ApplicationName: applicationName,
+		SportType:       sportTypeName,
+		Year:            year,
+		Title:           fmt.Sprintf("%s %s %d: rosters edited", applicationName, sportTypeName, year),
+		Lines:           []string{"An admin " + change},
+	}
+}
+
+// notify delivers the events to each webhook, logging the result of each delivery at the time from the function.
+func (n *webhookNotifier) notify(ctx context.Context, events []WebhookEvent, now func() time.Time) {
+	for _, e := range events {
+		for _, w := range n.webhooks {
+			d := n.deliver(ctx, w, e)
+			d.Time = now()
+			n.log(d)
+		}
+	}
+}
+
+// deliver posts the event to the webhook, retrying if the webhook cannot be reached, is overloaded, or has a server error.
+func (n *webhookNotifier) deliver(ctx context.Context, w webhook, e WebhookEvent) WebhookDelivery {
+	d := WebhookDelivery{
+		Kind:        e.Kind,
+		Format:      w.format,
+		Destination: w.destination(),
+	}
+	var payload bytes.Buffer
+	if err := webhookTemplates[w.format].Execute(&payload, e); err != nil {
+		d.Error = fmt.Sprintf("creating payload: %v", err)
+		return d
+	}
+	backoff := n.backoff
+	for {
+		d.Attempts++
+		retry, err := n.post(ctx, w, payload.Bytes(), &d)
+		if err == nil {
+			d.Error = ""
+			return d
+		}
+		d.Error = err.Error()
+		if !retry || d.Attempts > n.retries {
+			return d
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			d.Error = ctx.Err().Error()
+			return d
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the payload to the webhook once, setting the status code of the delivery.
+// The returned bool is true if the post failed in a way that might succeed if it is retried.
+func (n *webhookNotifier) post(ctx context.Context, w webhook, payload []byte, d *WebhookDelivery) (bool, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if len(n.secret) != 0 {
+		r.Header.Set(webhookSignatureHeader, "sha256="+signWebhookPayload(n.secret, payload))
+	}
+	response, err := n.httpClient.Do(r)
+	if err != nil {
+		return true, fmt.Errorf("posting payload: %w", err)
+	}
+	response.Body.Close()
+	d.StatusCode = response.StatusCode
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %v", response.Status)
+	case response.StatusCode >= 300:
+		return false, fmt.Errorf("webhook returned %v", response.Status)
+	}
+	return false, nil
+}
+
+// signWebhookPayload creates the hex-encoded HMAC-SHA256 signature of the payload with the secret.
+func signWebhookPayload(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// destination is the scheme and host of the webhook.  The path is not shown because it often contains a token.
+func (w webhook) destination() string {
+	u, err := url.Parse(w.url)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// log keeps the delivery, dropping the oldest deliveries when the log is full.
+func (n *webhookNotifier) log(d WebhookDelivery) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.deliveries = append(n.deliveries, d)
+	if len(n.deliveries) > maxWebhookDeliveries {
+		n.deliveries = n.deliveries[len(n.deliveries)-maxWebhookDeliveries:]
+	}
+}
+
+// recentDeliveries gets the logged deliveries, most recent first.  No deliveries are logged if the webhookNotifier is nil.
+func (n *webhookNotifier) recentDeliveries() []WebhookDelivery {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	deliveries := make([]WebhookDelivery, len(n.deliveries))
+	for i, d := range n.deliveries {
+		deliveries[len(deliveries)-1-i] = d
+	}
+	return deliveries
+}
+
+// notifyWebhooks delivers the events to the webhooks if any are configured.
+func (s Server) notifyWebhooks(ctx context.Context, events []WebhookEvent) {
+	if s.webhooks == nil || len(events) == 0 {
+		return
+	}
+	s.webhooks.notify(ctx, events, s.ds.GetUtcTime)
+}
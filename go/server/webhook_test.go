@@ -0,0 +1,311 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
+)
+
+func TestNewWebhookNotifier(t *testing.T) {
+	newWebhookNotifierTests := []struct {
+		webhooks     string
+		want         []webhook
+		wantNil      bool
+		wantErr      bool
+		requestRetry int
+	}{
+		{
+			wantNil: true,
+		},
+		{
+			webhooks: " ; ",
+			wantNil:  true,
+		},
+		{
+			webhooks: "https://hooks.slack.com/services/T0/B0/X",
+			wantErr:  true, // no format
+		},
+		{
+			webhooks: "teams=https://example.com/hook",
+			wantErr:  true,
+		},
+		{
+			webhooks: "slack=hooks.slack.com/services/T0/B0/X",
+			wantErr:  true, // no scheme
+		},
+		{
+			webhooks:     "slack=https://hooks.slack.com/services/T0/B0/X; discord = https://discord.com/api/webhooks/1/Y;json=http://localhost:8080/hook",
+			requestRetry: 3,
+			want: []webhook{
+				{format: "slack", url: "https://hooks.slack.com/services/T0/B0/X"},
+				{format: "discord", url: "https://discord.com/api/webhooks/1/Y"},
+				{format: "json", url: "http://localhost:8080/hook"},
+			},
+		},
+	}
+	for i, test := range newWebhookNotifierTests {
+		cfg := Config{
+			RequestRetries: test.requestRetry,
+			WebhookConfig: WebhookConfig{
+				Webhooks:      test.webhooks,
+				WebhookSecret: "s3cr3t",
+			},
+		}
+		got, err := cfg.newWebhookNotifier()
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantNil:
+			if got != nil {
+				t.Errorf("Test %v: wanted no webhook notifier, got %v", i, got)
+			}
+		case got == nil:
+			t.Errorf("Test %v: wanted webhook notifier", i)
+		case !reflect.DeepEqual(test.want, got.webhooks), string(got.secret) != "s3cr3t", got.retries != test.requestRetry:
+			t.Errorf("Test %v: unwanted webhook notifier: %v", i, got)
+		default:
+			if httpClient, ok := got.httpClient.(*http.Client); !ok || httpClient.Timeout != webhookTimeout {
+				t.Errorf("Test %v: wanted webhooks to be posted to with a client of their own, got %v", i, got.httpClient)
+			}
+		}
+	}
+}
+
+func TestNewStandingsWebhookEvents(t *testing.T) {
+	previous := EtlStats{
+		etlTime: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{Name: "Hitting", PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 10}, {ID: "2", Name: "Bert", Score: 8}}},
+		},
+	}
+	current := EtlStats{
+		etlTime:       time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		sportTypeName: "mlb",
+		year:          2019,
+		scoreCategories: []request.ScoreCategory{
+			{Name: "Hitting", PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 11}, {ID: "2", Name: "Bert", Score: 14}, {ID: "3", Name: "Carl", Score: 0}}},
+			{Name: "Pitching", PlayerType: 3},
+		},
+	}
+	want := []WebhookEvent{
+		{
+			Kind:            "leader",
+			ApplicationName: "nate",
+			SportType:       "mlb",
+			Year:            2019,
+			Title:           "nate mlb 2019: new Hitting leader",
+			Lines:           []string{"Bert leads Hitting with 14"},
+		},
+		{
+			Kind:            "rank",
+			ApplicationName: "nate",
+			SportType:       "mlb",
+			Year:            2019,
+			Title:           "nate mlb 2019: standings changed",
+			Lines: []string{
+				"Bert moved up 1 to #1 in Hitting with 14",
+				"Arnold moved down 1 to #2 in Hitting with 11",
+			},
+		},
+	}
+	got := newStandingsWebhookEvents("nate", previous, current)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("events not equal:\nwanted: %v\ngot:    %v", want, got)
+	}
+}
+
+func TestNewStandingsWebhookEvents_unchanged(t *testing.T) {
+	previous := EtlStats{
+		etlTime: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 10}, {ID: "2", Name: "Bert", Score: 8}}},
+		},
+	}
+	current := EtlStats{
+		etlTime: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC),
+		year:    2019,
+		scoreCategories: []request.ScoreCategory{
+			{PlayerType: 2, FriendScores: []request.FriendScore{{ID: "1", Name: "Arnold", Score: 11}, {ID: "2", Name: "Bert", Score: 14}}},
+		},
+	}
+	newYear := current
+	newYear.year = 2020
+	newStandingsWebhookEventsTests := []struct {
+		previous EtlStats
+		current  EtlStats
+	}{
+		{ // no previous stats
+			current: current,
+		},
+		{ // the standings of the previous year are not compared
+			previous: previous,
+			current:  newYear,
+		},
+		{ // no changes
+			previous: current,
+			current:  current,
+		},
+	}
+	for i, test := range newStandingsWebhookEventsTests {
+		if got := newStandingsWebhookEvents("nate", test.previous, test.current); len(got) != 0 {
+			t.Errorf("Test %v: wanted no events, got %v", i, got)
+		}
+	}
+}
+
+func TestWebhookPayloads(t *testing.T) {
+	e := newRosterWebhookEvent("nate", "nfl", 2019, `edited the "friends"`)
+	webhookPayloadTests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "slack",
+			want:   `{"text":"nate nfl 2019: rosters edited","blocks":[{"type":"header","text":{"type":"plain_text","text":"nate nfl 2019: rosters edited"}},{"type":"section","text":{"type":"mrkdwn","text":"An admin edited the \"friends\""}}]}`,
+		},
+		{
+			format: "discord",
+			want:   `{"username":"nate","embeds":[{"title":"nate nfl 2019: rosters edited","description":"An admin edited the \"friends\""}]}`,
+		},
+		{
+			format: "json",
+			want:   `{"kind":"roster","applicationName":"nate","sportType":"nfl","year":2019,"title":"nate nfl 2019: rosters edited","lines":["An admin edited the \"friends\""]}`,
+		},
+	}
+	for i, test := range webhookPayloadTests {
+		var sb strings.Builder
+		if err := webhookTemplates[test.format].Execute(&sb, e); err != nil {
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+			continue
+		}
+		got := sb.String()
+		switch {
+		case !json.Valid([]byte(got)):
+			t.Errorf("Test %v: invalid json: %v", i, got)
+		case test.want != got:
+			t.Errorf("Test %v: payloads not equal:\nwanted: %v\ngot:    %v", i, test.want, got)
+		}
+	}
+}
+
+func TestWebhookNotifierNotify(t *testing.T) {
+	notifyTests := []struct {
+		statusCodes  []int
+		doErr        error
+		retries      int
+		wantAttempts int
+		wantStatus   int
+		wantErr      bool
+	}{
+		{
+			statusCodes:  []int{204},
+			wantAttempts: 1,
+			wantStatus:   204,
+		},
+		{
+			statusCodes:  []int{503, 429, 200},
+			retries:      2,
+			wantAttempts: 3,
+			wantStatus:   200,
+		},
+		{
+			statusCodes:  []int{500, 500, 500},
+			retries:      1,
+			wantAttempts: 2,
+			wantStatus:   500,
+			wantErr:      true,
+		},
+		{ // client errors are not retried
+			statusCodes:  []int{404},
+			retries:      2,
+			wantAttempts: 1,
+			wantStatus:   404,
+			wantErr:      true,
+		},
+		{
+			doErr:        errors.New("connection refused"),
+			retries:      1,
+			wantAttempts: 2,
+			wantErr:      true,
+		},
+	}
+	e := newRosterWebhookEvent("nate", "mlb", 2019, "imported rosters")
+	deliveryTime := time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC)
+	for i, test := range notifyTests {
+		var payloads []string
+		httpClient := mockHTTPClient{
+			DoFunc: func(r *http.Request) (*http.Response, error) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("Test %v: reading request body: %v", i, err)
+				}
+				payloads = append(payloads, string(b))
+				if want, got := "sha256="+signWebhookPayload([]byte("s3cr3t"), b), r.Header.Get(webhookSignatureHeader); want != got {
+					t.Errorf("Test %v: signatures not equal: wanted %v, got %v", i, want, got)
+				}
+				if test.doErr != nil {
+					return nil, test.doErr
+				}
+				statusCode := test.statusCodes[len(payloads)-1]
+				return &http.Response{StatusCode: statusCode, Status: http.StatusText(statusCode), Body: io.NopCloser(strings.NewReader(""))}, nil
+			},
+		}
+		n := webhookNotifier{
+			webhooks:   []webhook{{format: "discord", url: "https://discord.com/api/webhooks/1/Y"}},
+			secret:     []byte("s3cr3t"),
+			httpClient: httpClient,
+			retries:    test.retries,
+		}
+		n.notify(context.Background(), []WebhookEvent{e}, func() time.Time { return deliveryTime })
+		deliveries := n.recentDeliveries()
+		if len(deliveries) != 1 {
+			t.Errorf("Test %v: wanted 1 delivery, got %v", i, len(deliveries))
+			continue
+		}
+		d := deliveries[0]
+		switch {
+		case len(payloads) != test.wantAttempts, d.Attempts != test.wantAttempts:
+			t.Errorf("Test %v: wanted %v attempts, got %v (%v logged)", i, test.wantAttempts, len(payloads), d.Attempts)
+		case d.StatusCode != test.wantStatus:
+			t.Errorf("Test %v: wanted status %v, got %v", i, test.wantStatus, d.StatusCode)
+		case test.wantErr != (len(d.Error) != 0):
+			t.Errorf("Test %v: wanted error: %v, got %q", i, test.wantErr, d.Error)
+		case d.Time != deliveryTime, d.Kind != "roster", d.Format != "discord":
+			t.Errorf("Test %v: unwanted delivery: %v", i, d)
+		case d.Destination != "https://discord.com":
+			t.Errorf("Test %v: wanted the destination to not have the token of the url, got %v", i, d.Destination)
+		}
+	}
+}
+
+func TestWebhookNotifierRecentDeliveries(t *testing.T) {
+	var n *webhookNotifier
+	if got := n.recentDeliveries(); got != nil {
+		t.Errorf("wanted no deliveries when webhooks are not configured, got %v", got)
+	}
+	n = new(webhookNotifier)
+	for i := 0; i < maxWebhookDeliveries+2; i++ {
+		n.log(WebhookDelivery{Attempts: i})
+	}
+	got := n.recentDeliveries()
+	switch {
+	case len(got) != maxWebhookDeliveries:
+		t.Errorf("wanted %v deliveries, got %v", maxWebhookDeliveries, len(got))
+	case got[0].Attempts != maxWebhookDeliveries+1, got[len(got)-1].Attempts != 2:
+		t.Errorf("wanted most recent deliveries first, got %v...%v", got[0], got[len(got)-1])
+	}
+}
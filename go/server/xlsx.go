@@ -0,0 +1,156 @@
+package server
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	// xlsxSheet is a worksheet of a workbook.
+	// Cells that are ints are numbers.  Other cells are text.
+	xlsxSheet struct {
+		name string
+		rows [][]interface{}
+	}
+
+	// xlsxPart is a file in the zip archive of a workbook.
+	xlsxPart struct {
+		name    string
+		content string
+	}
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	// xlsxInvalidSheetNameChars cannot be in the names of sheets
+	xlsxInvalidSheetNameChars = `[]:*?/\`
+	xlsxMaxSheetNameLength    = 31
+	xlsxHeader                = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+)
+
+// writeXlsx writes the sheets as an Office Open XML workbook, which can be opened by Excel and other spreadsheet programs.
+// Only the parts of a workbook that are required are written, so cells have no styles.
+func writeXlsx(w io.Writer, sheets ...xlsxSheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("workbook requires a sheet")
+	}
+	sheetNames := make(map[string]bool, len(sheets))
+	for _, sheet := range sheets {
+		switch {
+		case len(sheet.name) == 0, len([]rune(sheet.name)) > xlsxMaxSheetNameLength:
+			return fmt.Errorf("sheet name must have 1 to %v characters: %q", xlsxMaxSheetNameLength, sheet.name)
+		case strings.ContainsAny(sheet.name, xlsxInvalidSheetNameChars):
+			return fmt.Errorf("sheet name cannot contain any of %v: %q", xlsxInvalidSheetNameChars, sheet.name)
+		case sheetNames[strings.ToLower(sheet.name)]:
+			return fmt.Errorf("duplicate sheet name: %q", sheet.name)
+		}
+		sheetNames[strings.ToLower(sheet.name)] = true
+	}
+	parts := []xlsxPart{
+		{name: "[Content_Types].xml", content: xlsxContentTypes(len(sheets))},
+		{name: "_rels/.rels", content: xlsxRels()},
+		{name: "xl/workbook.xml", content: xlsxWorkbook(sheets)},
+		{name: "xl/_rels/workbook.xml.rels", content: xlsxWorkbookRels(len(sheets))},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, xlsxPart{name: fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), content: xlsxWorksheet(sheet)})
+	}
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err == nil {
+			_, err = io.WriteString(pw, part.content)
+		}
+		if err != nil {
+			return fmt.Errorf("writing xlsx part %v: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("writing xlsx: %w", err)
+	}
+	return nil
+}
+
+func xlsxContentTypes(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func xlsxRels() string {
+	return xlsxHeader +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+}
+
+func xlsxWorkbook(sheets []xlsxSheet) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&sb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.name), i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func xlsxWorkbookRels(sheetCount int) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// xlsxWorksheet is the xml of the cells of the sheet.  Text is written inline so the workbook does not need a table of shared strings.
+func xlsxWorksheet(sheet xlsxSheet) string {
+	var sb strings.Builder
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range sheet.rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			switch v := cell.(type) {
+			case int:
+				fmt.Fprintf(&sb, `<c r="%s"><v>%d</v></c>`, ref, v)
+			default:
+				fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxEscape(fmt.Sprint(v)))
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+// xlsxColumn is the name of the zero-based column, such as "A" for 0 and "AA" for 26
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xlsxEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// readXlsxSheets reads the cells of the sheets of the workbook as text, checking that its parts are valid xml.
+func readXlsxSheets(t *testing.T, workbook []byte) [][][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatalf("reading workbook zip: %v", err)
+	}
+	parts := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %v: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %v: %v", f.Name, err)
+		}
+		var v struct{}
+		if err := xml.Unmarshal(b, &v); err != nil {
+			t.Fatalf("%v is not valid xml: %v", f.Name, err)
+		}
+		parts[f.Name] = b
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing workbook part %v", name)
+		}
+	}
+	var sheets [][][]string
+	for i := 1; ; i++ {
+		b, ok := parts[fmt.Sprintf("xl/worksheets/sheet%d.xml", i)]
+		if !ok {
+			return sheets
+		}
+		var ws struct {
+			Rows []struct {
+				Cells []struct {
+					Ref    string `xml:"r,attr"`
+					Value  string `xml:"v"`
+					Inline string `xml:"is>t"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		if err := xml.Unmarshal(b, &ws); err != nil {
+			t.Fatalf("reading sheet %v: %v", i, err)
+		}
+		sheet := make([][]string, len(ws.Rows))
+		for j, row := range ws.Rows {
+			sheet[j] = make([]string, len(row.Cells))
+			for k, c := range row.Cells {
+				if want := fmt.Sprintf("%s%d", xlsxColumn(k), j+1); want != c.Ref {
+					t.Errorf("wanted cell reference %v, got %v", want, c.Ref)
+				}
+				sheet[j][k] = c.Value + c.Inline
+			}
+		}
+		sheets = append(sheets, sheet)
+	}
+}
+
+func TestWriteXlsx(t *testing.T) {
+	sheets := []xlsxSheet{
+		{name: "A & B", rows: [][]interface{}{{"name", "score"}, {"<Smith>", 3}, {" spaced ", -1}}},
+		{name: "Empty"},
+	}
+	var w bytes.Buffer
+	if err := writeXlsx(&w, sheets...); err != nil {
+		t.Fatalf("did not expect error: %v", err)
+	}
+	want := [][][]string{
+		{{"name", "score"}, {"<Smith>", "3"}, {" spaced ", "-1"}},
+		{},
+	}
+	got := readXlsxSheets(t, w.Bytes())
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("sheets not equal:\nwanted: %q\ngot:    %q", want, got)
+	}
+}
+
+func TestWriteXlsx_invalid(t *testing.T) {
+	writeXlsxTests := [][]xlsxSheet{
+		{},
+		{{name: ""}},
+		{{name: "this name is much too long for a sheet"}},
+		{{name: "a/b"}},
+		{{name: "Sheet"}, {name: "sheet"}},
+	}
+	for i, sheets := range writeXlsxTests {
+		if err := writeXlsx(io.Discard, sheets...); err == nil {
+			t.Errorf("Test %v: wanted error", i)
+		}
+	}
+}
+
+func TestWriteXlsx_writeError(t *testing.T) {
+	err := errors.New("write failed")
+	w := errWriter{err: err}
+	got := writeXlsx(w, xlsxSheet{name: "Sheet1", rows: [][]interface{}{{1}}})
+	if !errors.Is(got, err) {
+		t.Errorf("did not get expected write error: wanted: %v, got: %v", err, got)
+	}
+}
+
+func TestXlsxColumn(t *testing.T) {
+	xlsxColumnTests := map[int]string{
+		0:   "A",
+		1:   "B",
+		25:  "Z",
+		26:  "AA",
+		27:  "AB",
+		51:  "AZ",
+		52:  "BA",
+		701: "ZZ",
+		702: "AAA",
+	}
+	for i, want := range xlsxColumnTests {
+		if got := xlsxColumn(i); want != got {
+			t.Errorf("wanted column %v to be %v, got %v", i, want, got)
+		}
+	}
+}
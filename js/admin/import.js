@@ -0,0 +1,129 @@
+var importForm = {
+    load: function (event) {
+        var file = event.target.files[0];
+        if (file == null) {
+            return;
+        }
+        file.text().then(text => {
+            document.getElementById('import-roster').value = text;
+            importForm.clear();
+        });
+    },
+
+    preview: function () {
+        importForm.clear();
+        var formData = new FormData(document.getElementById('import-form'));
+        formData.set('action', 'preview');
+        var info = document.getElementById('import-review-info');
+        info.innerText = 'Searching for players...';
+        fetch(window.location.pathname + '/import', {
+            method: 'POST',
+            body: new URLSearchParams(formData),
+            credentials: 'include'
+        }).then(async res => {
+            if (res.status == 200) {
+                return res.json();
+            } else {
+                var message = await res.text();
+                return Promise.reject(message);
+            }
+        }).then(importRows => {
+            importForm.render(importRows);
+        }).catch(message => {
+            info.classList.add('bg-danger');
+            info.innerText = message;
+        });
+    },
+
+    render: function (importRows) {
+        var template = document.getElementById('import-row-template');
+        var rows = document.getElementById('import-rows');
+        var errorCount = 0;
+        var ambiguousCount = 0;
+        importRows.forEach((importRow, i) => {
+            var clone = document.importNode(template.content, true);
+            var row = clone.querySelector('.import-row');
+            row.querySelector('.import-row-line').innerText = importRow.Line || '';
+            row.querySelector('.import-row-friend').innerText = importRow.FriendName + (importRow.NewFriend ? ' [NEW]' : '');
+            row.querySelector('.import-row-player-type').innerText = importRow.PlayerTypeName;
+            row.querySelector('.import-row-player').innerText = importRow.Player;
+            var select = row.querySelector('.import-row-matches');
+            if (importRow.Error) {
+                errorCount++;
+                select.classList.add('d-none');
+                select.disabled = true;
+                var error = row.querySelector('.import-row-error');
+                error.classList.remove('d-none');
+                error.innerText = importRow.Error;
+                rows.appendChild(clone);
+                return;
+            }
+            if (!importRow.Resolved) {
+                ambiguousCount++;
+                var placeholder = document.createElement('option');
+                placeholder.value = '';
+                placeholder.innerText = 'Choose one of ' + importRow.Matches.length + ' players';
+                select.appendChild(placeholder);
+            }
+            for (var match of importRow.Matches) {
+                var option = document.createElement('option');
+                option.value = match.SourceID;
+                option.innerText = match.Name ? match.Name + ' (' + match.Details + ')' : 'Source ID ' + match.SourceID;
+                option.dataset.name = match.Name;
+                option.dataset.position = match.Position;
+                option.dataset.team = match.Team;
+                select.appendChild(option);
+            }
+            select.name = 'import-' + i + '-source-id';
+            select.required = true;
+            row.querySelector('.import-row-friend-name').name = 'import-' + i + '-friend-name';
+            row.querySelector('.import-row-friend-name').value = importRow.FriendName;
+            row.querySelector('.import-row-player-type-id').name = 'import-' + i + '-player-type';
+            row.querySelector('.import-row-player-type-id').value = importRow.PlayerType;
+            row.querySelector('.import-row-name').name = 'import-' + i + '-name';
+            row.querySelector('.import-row-position').name = 'import-' + i + '-position';
+            row.querySelector('.import-row-team').name = 'import-' + i + '-team';
+            importForm.setMatch(row, select.selectedOptions[0]);
+            rows.appendChild(clone);
+        });
+        document.getElementById('import-review').classList.remove('d-none');
+        var info = document.getElementById('import-review-info');
+        if (errorCount > 0) {
+            info.classList.add('bg-danger');
+            info.innerText = errorCount + ' rows have errors.  Fix the roster and review it again.';
+            return;
+        }
+        info.innerText = ambiguousCount > 0
+            ? 'Choose the players of ' + ambiguousCount + ' rows before submitting.'
+            : 'All players were found.';
+        document.getElementById('import-form-submit-button').disabled = false;
+    },
+
+    choose: function (event) {
+        var select = event.target;
+        importForm.setMatch(select.closest('.import-row'), select.selectedOptions[0]);
+    },
+
+    setMatch: function (row, option) {
+        row.querySelector('.import-row-name').value = option.dataset.name || '';
+        row.querySelector('.import-row-position').value = option.dataset.position || '';
+        row.querySelector('.import-row-team').value = option.dataset.team || '';
+    },
+
+    clear: function () {
+        document.getElementById('import-rows').innerHTML = '';
+        document.getElementById('import-review').classList.add('d-none');
+        var info = document.getElementById('import-review-info');
+        info.classList.remove('bg-danger');
+        info.innerText = '';
+        document.getElementById('import-form-submit-button').disabled = true;
+    },
+
+    init: function () {
+        document.getElementById('import-roster').oninput = importForm.clear;
+        importForm.clear();
+    },
+};
+
+// the submit button is after the import fieldset in the form
+document.addEventListener('DOMContentLoaded', importForm.init);
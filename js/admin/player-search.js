@@ -6,6 +6,7 @@ var playerSearch = {
         playerSearchModalOpenButton.classList.toggle('d-none', show);
         if (show) {
             playerSearch.initActivePlayersCB();
+            playerSearch.initFilters();
             var searchInput = document.getElementById('player-search');
             searchInput.focus();
         } else {
@@ -20,6 +21,17 @@ var playerSearch = {
         activePlayersOnlyGroup.classList.toggle('d-none', !isMlbPlayerType);
     },
 
+    initFilters: function () {
+        var playerType = document.getElementById('select-player-type').value;
+        var isTeamPlayerType = [1, 4].includes(parseInt(playerType)); // PlayerTypeMlbTeam, PlayerTypeNflTeam
+        var filtersGroup = document.getElementById('player-search-filters');
+        filtersGroup.classList.toggle('d-none', isTeamPlayerType);
+        if (isTeamPlayerType) {
+            document.getElementById('player-search-team').value = '';
+            document.getElementById('player-search-position').value = '';
+        }
+    },
+
     add: function () {
         var playerSearchResults = document.getElementById('player-search-results');
         playerSearchResults = playerSearchResults.getElementsByClassName('form-group');
@@ -27,7 +39,9 @@ var playerSearch = {
             if (psr.querySelector('.psr-radio').checked) {
                 var sourceID = psr.querySelector('.psr-source-id').value;
                 var playerName = psr.querySelector('.psr-player-name').value;
-                var newPlayer = playersForm.add(playerName, sourceID);
+                var position = psr.querySelector('.psr-player-position').value;
+                var team = psr.querySelector('.psr-player-team').value;
+                var newPlayer = playersForm.add(playerName, sourceID, position, team);
                 newPlayer.focus();
                 playerSearch.showModal(false);
                 playerSearch.clear();
@@ -87,6 +101,8 @@ var playerSearch = {
             psr.querySelector('.psr-label-details').innerText = playerSearchResult.Details;
             psr.querySelector('.psr-source-id').value = playerSearchResult.SourceID;
             psr.querySelector('.psr-player-name').value = playerSearchResult.Name;
+            psr.querySelector('.psr-player-position').value = playerSearchResult.Position;
+            psr.querySelector('.psr-player-team').value = playerSearchResult.Team;
             playerSearchResultsFieldSet.appendChild(psr);
             if (!firstChecked) {
                 psr.querySelector('.psr-radio').checked = true;
@@ -1,5 +1,5 @@
 var playersForm = {
-    add: function (playerName, sourceID) {
+    add: function (playerName, sourceID, position, team) {
         var playerType = document.getElementById('select-player-type').value;
         var friendID = document.getElementById('select-friend').value;
         var maxID = 0;
@@ -19,15 +19,27 @@ var playersForm = {
                 }
             }
         }
-        return playersForm.create(maxID + 1, playerName, sourceID, maxDisplayOrder + 1, playerType, friendID);
+        var id = maxID + 1;
+        var player = playersForm.create(id, playerName, sourceID, maxDisplayOrder + 1, playerType, friendID, '', '', '');
+        // the info of added players is saved so their names are known without requesting them
+        player.querySelector('.player-info-name').name = 'player-' + id + '-name';
+        player.querySelector('.player-info-name').value = playerName;
+        player.querySelector('.player-info-position').name = 'player-' + id + '-position';
+        player.querySelector('.player-info-position').value = position;
+        player.querySelector('.player-info-team').name = 'player-' + id + '-team';
+        player.querySelector('.player-info-team').value = team;
+        return player;
     },
 
-    create: function (id, playerName, sourceID, displayOrder, playerType, friendID) {
+    create: function (id, playerName, sourceID, displayOrder, playerType, friendID, addDate, dropDate, infoRefreshTime) {
         var template = document.getElementById('player-template');
         var clone = document.importNode(template.content, true);
         var player = clone.querySelector('.form-group');
         player.id = 'player-' + id;
         player.querySelector('.player-name-label').innerText = playerName;
+        if (infoRefreshTime) {
+            player.querySelector('.player-info-refresh-time').innerText = 'name saved ' + infoRefreshTime + ' UTC';
+        }
         player.querySelector('.player-source-id').name = 'player-' + id + '-source-id';
         player.querySelector('.player-source-id').value = sourceID;
         player.querySelector('.player-display-order').name = 'player-' + id + '-display-order';
@@ -37,6 +49,10 @@ var playersForm = {
         player.querySelector('.player-friend-id').name = 'player-' + id + '-friend-id';
         player.querySelector('.player-friend-id').value = friendID;
         player.querySelector('.player-id').value = id;
+        player.querySelector('.player-add-date').name = 'player-' + id + '-add-date';
+        player.querySelector('.player-add-date').value = addDate;
+        player.querySelector('.player-drop-date').name = 'player-' + id + '-drop-date';
+        player.querySelector('.player-drop-date').value = dropDate;
         var scoreCategories = document.getElementById('players');
         var scoreCategory = scoreCategories.querySelector('.player-type-' + playerType);
         var friendScore = scoreCategory.querySelector('.friend-id-' + friendID);
@@ -59,6 +75,40 @@ var playersForm = {
             }
         }
         playerSearch.clear();
+        playersForm.refreshTransactions();
+    },
+
+    refreshTransactions: function () {
+        var optionText = function (selectID, value) {
+            var option = document.querySelector('#' + selectID + ' option[value="' + value + '"]');
+            return option == null ? value : option.innerText;
+        };
+        var transactions = [];
+        var players = document.getElementById('player-form-items');
+        var playerElements = players.getElementsByClassName('form-group');
+        for (var player of playerElements) {
+            var playerName = player.querySelector('.player-name-label').innerText;
+            var friendName = optionText('select-friend', player.querySelector('.player-friend-id').value);
+            var playerTypeName = optionText('select-player-type', player.querySelector('.player-player-type').value);
+            var addDate = player.querySelector('.player-add-date').value;
+            var dropDate = player.querySelector('.player-drop-date').value;
+            if (addDate) {
+                transactions.push({ date: addDate, text: friendName + ' added ' + playerName + ' (' + playerTypeName + ')' });
+            }
+            if (dropDate) {
+                transactions.push({ date: dropDate, text: friendName + ' dropped ' + playerName + ' (' + playerTypeName + ')' });
+            }
+        }
+        transactions.sort(function (a, b) {
+            return a.date.localeCompare(b.date);
+        });
+        var transactionList = document.getElementById('player-transactions');
+        transactionList.innerHTML = '';
+        for (var transaction of transactions) {
+            var item = document.createElement('li');
+            item.innerText = transaction.date + ': ' + transaction.text;
+            transactionList.appendChild(item);
+        }
     },
 
     init: function () {
@@ -83,7 +133,10 @@ var playersForm = {
                     var displayOrder = playerScore.querySelector('.display-order').innerText;
                     var pt = playerScore.querySelector('.player-type').innerText;
                     var friendID = playerScore.querySelector('.friend-id').innerText;
-                    var newPlayer = playersForm.create(id, playerName, sourceID, displayOrder, pt, friendID);
+                    var addDate = playerScore.querySelector('.add-date').innerText;
+                    var dropDate = playerScore.querySelector('.drop-date').innerText;
+                    var infoRefreshTime = playerScore.querySelector('.info-refresh-time').innerText;
+                    var newPlayer = playersForm.create(id, playerName, sourceID, displayOrder, pt, friendID, addDate, dropDate, infoRefreshTime);
                     playerScore.replaceWith(newPlayer);
                 }
             }
@@ -1,7 +1,7 @@
 var adminTab = {
     submit: function (event) {
         event.preventDefault();
-        var pathname = window.location.pathname;
+        var pathname = window.location.pathname + (event.target.getAttribute('data-path') || '');
         var data = new URLSearchParams(new FormData(event.target));
         fetch(pathname, {
             method: 'POST',
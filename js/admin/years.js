@@ -15,6 +15,7 @@ var yearsForm = {
             }
         }
         var newYear = yearsForm.createYear(year, 'true');
+        yearsForm.initRollover(newYear, year);
         newYear.querySelector('.year-radio').focus();
     },
 
@@ -36,10 +37,38 @@ var yearsForm = {
         return newYear;
     },
 
+    initRollover: function (newYear, yearNum) {
+        var rollover = newYear.querySelector('.year-rollover');
+        var select = rollover.querySelector('.year-rollover-select');
+        select.name = 'year-' + yearNum + '-rollover';
+        for (var savedYear of yearsForm.savedYears) {
+            var option = document.createElement('option');
+            option.value = savedYear;
+            option.innerText = 'Copy rosters from ' + savedYear;
+            select.appendChild(option);
+        }
+        var keepers = rollover.querySelector('.year-keepers');
+        keepers.id = 'year-' + yearNum + '-keepers';
+        keepers.name = 'year-' + yearNum + '-keepers';
+        rollover.querySelector('.year-keepers-label').htmlFor = keepers.id;
+        rollover.classList.remove('d-none');
+    },
+
+    changeRollover: function (event) {
+        var keepers = event.target.parentNode.querySelector('.year-keepers');
+        keepers.disabled = !event.target.value;
+        if (keepers.disabled) {
+            keepers.checked = false;
+        }
+    },
+
+    savedYears: [],
+
     initYears: function () {
         var years = document.getElementById('year-form-items').children;
         for (var year of years) {
             var value = year.querySelector('.value').innerText;
+            yearsForm.savedYears.push(value);
             var active = year.querySelector('.active').innerText;
             var newYear = yearsForm.createYear(value, active);
             year.replaceWith(newYear);
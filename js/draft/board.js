@@ -0,0 +1,139 @@
+var draftBoard = {
+    pollMillis: 2000,
+
+    boardURL: function () {
+        return window.location.pathname + '/board';
+    },
+
+    refresh: function () {
+        fetch(draftBoard.boardURL(), {
+            method: 'GET',
+        }).then(async res => {
+            if (res.status == 200) {
+                return res.json();
+            } else {
+                var message = await res.text();
+                return Promise.reject(message);
+            }
+        }).then(board => {
+            draftBoard.render(board);
+        }).catch(message => {
+            document.getElementById('draft-status').innerText = message;
+        });
+    },
+
+    render: function (board) {
+        document.getElementById('draft-start-fieldset').classList.toggle('d-none', board.Started);
+        document.getElementById('draft-start-button').classList.toggle('d-none', board.Started);
+        for (var buttonID of ['draft-finalize-button', 'draft-cancel-button']) {
+            document.getElementById(buttonID).classList.toggle('d-none', !board.Started);
+        }
+        var picking = board.Started && !board.Done;
+        document.getElementById('draft-pick-fieldset').classList.toggle('d-none', !picking);
+        document.getElementById('draft-pick-button').classList.toggle('d-none', !picking);
+        document.getElementById('draft-rounds').required = !board.Started;
+        document.getElementById('draft-source-id').required = picking;
+        var status = document.getElementById('draft-status');
+        var timer = document.getElementById('draft-timer');
+        var picksBody = document.getElementById('draft-picks');
+        picksBody.innerHTML = '';
+        if (!board.Started) {
+            status.innerText = 'No draft in progress';
+            timer.innerText = '';
+            return;
+        }
+        status.innerText = board.Done
+            ? 'All ' + board.Rounds + ' rounds picked: finalize the draft to save the picks'
+            : 'Round ' + board.Round + ' of ' + board.Rounds + ': ' + board.CurrentFriendName + ' is picking';
+        timer.innerText = board.Paused
+            ? 'Timer paused after every friend skipped (' + board.Order + ' order: ' + board.FriendNames.join(', ') + ')'
+            : board.SecondsLeft > 0
+            ? board.SecondsLeft + ' seconds left (' + board.Order + ' order: ' + board.FriendNames.join(', ') + ')'
+            : board.Order + ' order: ' + board.FriendNames.join(', ');
+        for (var pick of board.Picks) {
+            var row = picksBody.insertRow(0);
+            row.insertCell().innerText = pick.Round;
+            row.insertCell().innerText = pick.FriendName;
+            row.insertCell().innerText = pick.Skipped ? '(skipped)' : pick.PlayerName;
+        }
+    },
+
+    search: function () {
+        var playerType = document.getElementById('draft-player-type').value;
+        var query = document.getElementById('draft-player-search').value;
+        var params = new URLSearchParams({ q: query, pt: playerType, apo: 'on' });
+        var results = document.getElementById('draft-search-results');
+        results.innerHTML = '';
+        fetch(window.location.pathname + '/search?' + params, {
+            method: 'GET',
+        }).then(async res => {
+            if (res.status == 200) {
+                return res.json();
+            } else {
+                var message = await res.text();
+                return Promise.reject(message);
+            }
+        }).then(playerSearchResults => {
+            if (playerSearchResults == null || playerSearchResults.length == 0) {
+                return Promise.reject('No results');
+            }
+            for (var psr of playerSearchResults) {
+                var option = document.createElement('option');
+                option.value = psr.SourceID;
+                option.innerText = psr.Name;
+                option.title = psr.Details;
+                option.dataset.position = psr.Position || '';
+                option.dataset.team = psr.Team || '';
+                results.appendChild(option);
+            }
+            draftBoard.selectPlayer();
+        }).catch(message => {
+            document.getElementById('draft-info').innerText = message;
+        });
+    },
+
+    selectPlayer: function () {
+        var results = document.getElementById('draft-search-results');
+        var option = results.options[results.selectedIndex];
+        document.getElementById('draft-source-id').value = option == null ? '' : option.value;
+        document.getElementById('draft-player-name').value = option == null ? '' : option.innerText;
+        document.getElementById('draft-player-position').value = option == null ? '' : option.dataset.position;
+        document.getElementById('draft-player-team').value = option == null ? '' : option.dataset.team;
+    },
+
+    submit: function (event) {
+        event.preventDefault();
+        document.getElementById('draft-action').value = event.submitter.value;
+        var data = new URLSearchParams(new FormData(event.target));
+        fetch(window.location.pathname, {
+            method: 'POST',
+            body: data,
+            credentials: 'include'
+        }).then(async res => {
+            if (res.status == 200) {
+                return res.json();
+            } else {
+                var message = await res.text();
+                return Promise.reject(message);
+            }
+        }).then(board => {
+            var draftInfo = document.getElementById('draft-info');
+            draftInfo.classList.remove('bg-danger');
+            draftInfo.innerText = 'Saved.';
+            document.getElementById('draft-search-results').innerHTML = '';
+            draftBoard.selectPlayer();
+            draftBoard.render(board);
+        }).catch(message => {
+            var draftInfo = document.getElementById('draft-info');
+            draftInfo.classList.add('bg-danger');
+            draftInfo.innerText = message;
+        });
+    },
+
+    init: function () {
+        draftBoard.refresh();
+        setInterval(draftBoard.refresh, draftBoard.pollMillis);
+    },
+};
+
+draftBoard.init();
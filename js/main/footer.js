@@ -39,6 +39,13 @@ var footerTemplate = {
         timesMessageElement.innerText = formattedTimesMessage;
     },
 
+    initLocalTimes: function () {
+        var localTimeElements = document.querySelectorAll('.local-time');
+        for (var localTimeElement of localTimeElements) {
+            localTimeElement.innerText = footerTemplate.formatDate(localTimeElement.innerText);
+        }
+    },
+
     initPageLoadMessage: function () {
         var pageLoadMessageElement = document.getElementById('page-load-message');
         var pageLoadTime = pageLoadMessageElement.innerText;
@@ -47,6 +54,7 @@ var footerTemplate = {
 
     init: function () {
         footerTemplate.initTimesMessage();
+        footerTemplate.initLocalTimes();
         footerTemplate.initPageLoadMessage();
     },
 };
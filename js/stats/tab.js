@@ -4,7 +4,53 @@ var statsTab = {
         for (var statsAdminLink of statsAdminLinks) {
             statsAdminLink.href = location.pathname + statsAdminLink.getAttribute('data-relative-path');
         }
+        if (window.statsEventSource == null && window.EventSource) {
+            // the script is included for each tab, but only one event source is needed
+            window.statsEventSource = new EventSource(location.pathname + '/events');
+            window.statsEventSource.addEventListener('stats', statsTab.update);
+        }
+    },
+
+    update: function (event) {
+        var statsEvent = JSON.parse(event.data);
+        for (var status of statsEvent.Statuses || []) {
+            var staleElement = document.querySelector('.stats-stale[data-player-type="' + status.PlayerType + '"]');
+            if (!!status.Error != (staleElement != null)) {
+                location.reload(); // stale banners are only rendered on the server
+                return;
+            }
+        }
+        for (var scoreCategory of statsEvent.ScoreCategories) {
+            var scoreCategoryElement = document.querySelector('.score-category[data-player-type="' + scoreCategory.PlayerType + '"]');
+            if (scoreCategoryElement == null) {
+                location.reload(); // a new score category cannot be patched in place
+                return;
+            }
+            for (var friendScore of scoreCategory.FriendScores) {
+                var friendScoreElement = scoreCategoryElement.querySelector('.friend-score[data-friend-id="' + friendScore.ID + '"]');
+                if (friendScoreElement == null) {
+                    location.reload(); // a new friend cannot be patched in place
+                    return;
+                }
+                statsTab.updateFriendScore(friendScoreElement, friendScore);
+            }
+        }
+        var pageLoadMessageElement = document.getElementById('page-load-message');
+        if (pageLoadMessageElement != null) {
+            pageLoadMessageElement.innerText = 'Stats updated at ' + footerTemplate.formatDate(statsEvent.EtlTime);
+        }
+    },
+
+    updateFriendScore: function (friendScoreElement, friendScore) {
+        var playerScoresElement = friendScoreElement.querySelector('.player-scores');
+        playerScoresElement.innerHTML = '';
+        for (var playerScore of friendScore.PlayerScores || []) {
+            var row = playerScoresElement.insertRow();
+            row.insertCell().innerText = playerScore.Name;
+            row.insertCell().innerText = playerScore.Score;
+        }
+        friendScoreElement.querySelector('.friend-score-total').innerText = friendScore.Score;
     },
 };
 
-statsTab.init();
\ No newline at end of file
+statsTab.init();
@@ -3,28 +3,64 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata" // etl schedule time zones for servers without zoneinfo
 
 	"github.com/jacobpatterson1549/nate-mlb/go/db"
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
 	"github.com/jacobpatterson1549/nate-mlb/go/server"
 	_ "github.com/lib/pq"
 )
 
 const (
-	environmentVariableAdminPassword   = "ADMIN_PASSWORD"
-	environmentVariableApplicationName = "APPLICATION_NAME"
-	environmentVariableDatabaseURL     = "DATABASE_URL"
-	environmentVariablePort            = "PORT"
-	environmentVariablePlayerTypesCsv  = "PLAYER_TYPES"
-	environmentVariableNflAppKey       = "NFL_APP_KEY"
-	environmentVariableLogRequestURIs  = "LOG_REQUEST_URIS"
+	environmentVariableAdminPassword      = "ADMIN_PASSWORD"
+	environmentVariableApplicationName    = "APPLICATION_NAME"
+	environmentVariableDatabaseURL        = "DATABASE_URL"
+	environmentVariablePort               = "PORT"
+	environmentVariablePlayerTypesCsv     = "PLAYER_TYPES"
+	environmentVariableNflAppKey          = "NFL_APP_KEY"
+	environmentVariableNflProviders       = "NFL_PROVIDERS"
+	environmentVariableLogRequestURIs     = "LOG_REQUEST_URIS"
+	environmentVariableEtlSchedules       = "ETL_SCHEDULES"
+	environmentVariableEtlTimeZones       = "ETL_TIME_ZONES"
+	environmentVariableRequestRetries     = "REQUEST_RETRIES"
+	environmentVariableRequestConcurrency = "REQUEST_CONCURRENCY"
+	environmentVariableRequestRate        = "REQUEST_RATE"
+	environmentVariableCacheFile          = "CACHE_FILE"
+	environmentVariableReplayMode         = "REPLAY_MODE"
+	environmentVariableReplayDir          = "REPLAY_DIR"
+	environmentVariableUpstreamURL        = "UPSTREAM_URL"
+	environmentVariableSMTPAddr           = "SMTP_ADDR"
+	environmentVariableSMTPUsername       = "SMTP_USERNAME"
+	environmentVariableSMTPPassword       = "SMTP_PASSWORD"
+	environmentVariableDigestFrom         = "DIGEST_FROM"
+	environmentVariableWebhooks           = "WEBHOOKS"
+	environmentVariableWebhookSecret      = "WEBHOOK_SECRET"
+)
+
+const (
+	// defaultRequestRetries is the number of times failed requests are retried if it is not specified.
+	defaultRequestRetries = 2
+	// defaultRequestConcurrency is the most requests made to each external host at once if it is not specified.
+	defaultRequestConcurrency = 4
+	// defaultRequestRate is the most requests started to each external host each second if it is not specified.
+	defaultRequestRate = 10
+)
+
+const (
+	// replayModeRecord records the responses of requests to external sources for data in the replay directory.
+	replayModeRecord = "record"
+	// replayModeReplay replays responses recorded in the replay directory instead of making requests to external sources for data.
+	replayModeReplay = "replay"
 )
 
 var (
@@ -39,13 +75,29 @@ var (
 )
 
 type mainFlags struct {
-	adminPassword   string
-	applicationName string
-	dataSourceName  string
-	port            string
-	playerTypesCsv  string
-	nflAppKey       string
-	logRequestURIs  bool
+	adminPassword      string
+	applicationName    string
+	dataSourceName     string
+	port               string
+	playerTypesCsv     string
+	nflAppKey          string
+	nflProviders       string
+	logRequestURIs     bool
+	etlSchedules       string
+	etlTimeZones       string
+	requestRetries     int
+	requestConcurrency int
+	requestRate        float64
+	cacheFile          string
+	replayMode         string
+	replayDir          string
+	upstreamURL        string
+	smtpAddr           string
+	smtpUsername       string
+	smtpPassword       string
+	digestFrom         string
+	webhooks           string
+	webhookSecret      string
 }
 
 func main() {
@@ -73,6 +125,22 @@ func flagUsage(fs *flag.FlagSet) {
 		environmentVariableAdminPassword,
 		environmentVariablePlayerTypesCsv,
 		environmentVariableNflAppKey,
+		environmentVariableNflProviders,
+		environmentVariableEtlSchedules,
+		environmentVariableEtlTimeZones,
+		environmentVariableRequestRetries,
+		environmentVariableRequestConcurrency,
+		environmentVariableRequestRate,
+		environmentVariableCacheFile,
+		environmentVariableReplayMode,
+		environmentVariableReplayDir,
+		environmentVariableUpstreamURL,
+		environmentVariableSMTPAddr,
+		environmentVariableSMTPUsername,
+		environmentVariableSMTPPassword,
+		environmentVariableDigestFrom,
+		environmentVariableWebhooks,
+		environmentVariableWebhookSecret,
 	}
 	fmt.Fprintln(fs.Output(), "Starts the server")
 	fmt.Fprintln(fs.Output(), "Reads environment variables when possible:", fmt.Sprintf("[%s]", strings.Join(envVars, ",")))
@@ -96,17 +164,46 @@ func initFlags(programName string) (*flag.FlagSet, *mainFlags) {
 	fs.StringVar(&mainFlags.port, "p", os.Getenv(environmentVariablePort), "The port number to run the server on.")
 	fs.StringVar(&mainFlags.playerTypesCsv, "pt", os.Getenv(environmentVariablePlayerTypesCsv), "A csv whitelist of player types to use. Must not contain spaces.")
 	fs.StringVar(&mainFlags.nflAppKey, "ak", os.Getenv(environmentVariableNflAppKey), "The application key used to make nfl requests")
+	fs.StringVar(&mainFlags.nflProviders, "np", os.Getenv(environmentVariableNflProviders), "The comma-separated sources of nfl data, primary first, such as \"fantasy,espn\".  Defaults to fantasy with espn as a fallback.")
 	_, logRequestURIs := os.LookupEnv(environmentVariableLogRequestURIs)
 	fs.BoolVar(&mainFlags.logRequestURIs, "logRequestURIs", logRequestURIs, "logs the uris of requests to external sources for data when set")
+	fs.StringVar(&mainFlags.etlSchedules, "es", os.Getenv(environmentVariableEtlSchedules), `Semicolon-separated cron schedules to refresh stats of sports at.  Multiple cron expressions for a sport are separated by pipes.  Sports without schedules are refreshed daily at midnight.  Example: "mlb=0 0 * * *;nfl=0 13-23 * 9-12 0|0 0 * * *"`)
+	requestRetries, err := strconv.Atoi(os.Getenv(environmentVariableRequestRetries))
+	if err != nil {
+		requestRetries = defaultRequestRetries
+	}
+	fs.IntVar(&mainFlags.requestRetries, "rr", requestRetries, "The number of times to retry requests to external sources for data that fail with server errors or timeouts.")
+	requestConcurrency, err := strconv.Atoi(os.Getenv(environmentVariableRequestConcurrency))
+	if err != nil {
+		requestConcurrency = defaultRequestConcurrency
+	}
+	fs.IntVar(&mainFlags.requestConcurrency, "rc", requestConcurrency, "The most requests to make to each external source for data at once.  Zero allows any number of requests.")
+	requestRate, err := strconv.ParseFloat(os.Getenv(environmentVariableRequestRate), 64)
+	if err != nil {
+		requestRate = defaultRequestRate
+	}
+	fs.Float64Var(&mainFlags.requestRate, "rps", requestRate, "The most requests to start to each external source for data each second.  Zero allows any rate.")
+	fs.StringVar(&mainFlags.cacheFile, "cf", os.Getenv(environmentVariableCacheFile), "The file to save responses of requests to external sources for data in so they are kept when the server restarts.  Responses are not saved if it is empty.")
+	fs.StringVar(&mainFlags.replayMode, "rm", os.Getenv(environmentVariableReplayMode), `Set to "record" to save responses of requests to external sources for data in the replay directory or "replay" to use the saved responses without making requests.  Requests are made normally if it is empty.`)
+	fs.StringVar(&mainFlags.replayDir, "rd", os.Getenv(environmentVariableReplayDir), "The directory responses of requests to external sources for data are recorded in and replayed from.")
+	fs.StringVar(&mainFlags.upstreamURL, "uu", os.Getenv(environmentVariableUpstreamURL), "The url of a server to request all data from instead of the external sources, such as the mock server.  The external sources are used if it is empty.")
+	fs.StringVar(&mainFlags.smtpAddr, "sa", os.Getenv(environmentVariableSMTPAddr), "The host and port of the mail server to email digests of the standings to subscribed friends with, such as \"smtp.example.com:587\".  Digests are not sent if it is empty.")
+	fs.StringVar(&mainFlags.smtpUsername, "su", os.Getenv(environmentVariableSMTPUsername), "The user to authenticate to the mail server as.  No authentication is used if it is empty.")
+	fs.StringVar(&mainFlags.smtpPassword, "sp", os.Getenv(environmentVariableSMTPPassword), "The password of the user of the mail server.")
+	fs.StringVar(&mainFlags.digestFrom, "df", os.Getenv(environmentVariableDigestFrom), "The email address digests of the standings are sent from.")
+	fs.StringVar(&mainFlags.webhooks, "wh", os.Getenv(environmentVariableWebhooks), `Semicolon-separated formats and urls of webhooks to notify when the standings or rosters change.  The formats are slack, discord, and json.  Example: "slack=https://hooks.slack.com/services/T0/B0/X"`)
+	fs.StringVar(&mainFlags.webhookSecret, "whs", os.Getenv(environmentVariableWebhookSecret), "The key to sign the payloads sent to webhooks with.  Payloads are not signed if it is empty.")
+	fs.StringVar(&mainFlags.etlTimeZones, "etz", os.Getenv(environmentVariableEtlTimeZones), `Semicolon-separated time zones of the schedules to refresh stats of sports at.  Sports without time zones use Pacific/Honolulu.  Example: "nfl=America/New_York"`)
 	return fs, mainFlags
 }
 
 func startupFuncs(mainFlags *mainFlags, log *log.Logger) []func() error {
+	ctx := context.Background()
 	var ds *db.Datastore
 	startupFuncs := make([]func() error, 0, 2)
 	startupFuncs = append(startupFuncs, func() error {
 		var err error
-		ds, err = db.NewDatastore(mainFlags.dataSourceName, log, sqlFS)
+		ds, err = db.NewDatastore(ctx, mainFlags.dataSourceName, log, sqlFS)
 		return err
 	})
 	if len(mainFlags.playerTypesCsv) != 0 {
@@ -116,20 +213,40 @@ func startupFuncs(mainFlags *mainFlags, log *log.Logger) []func() error {
 	}
 	if len(mainFlags.adminPassword) != 0 {
 		startupFuncs = append(startupFuncs, func() error {
-			return ds.SetAdminPassword(db.Password(mainFlags.adminPassword))
+			return ds.SetAdminPassword(ctx, db.Password(mainFlags.adminPassword))
 		})
 	}
 	return append(startupFuncs, func() error {
-		httpClient := &http.Client{
-			Timeout: 5 * time.Second,
+		httpClient, err := newHTTPClient(mainFlags)
+		if err != nil {
+			return err
 		}
 		cfg := server.Config{
-			DisplayName:  mainFlags.applicationName,
-			NflAppKey:    mainFlags.nflAppKey,
-			Port:         mainFlags.port,
-			HTMLFS:       htmlFS,
-			JavascriptFS: jsFS,
-			StaticFS:     staticFS,
+			DisplayName:        mainFlags.applicationName,
+			NflAppKey:          mainFlags.nflAppKey,
+			NflProviders:       mainFlags.nflProviders,
+			Port:               mainFlags.port,
+			EtlSchedules:       mainFlags.etlSchedules,
+			EtlTimeZones:       mainFlags.etlTimeZones,
+			LogRequestURIs:     mainFlags.logRequestURIs,
+			RequestRetries:     mainFlags.requestRetries,
+			RequestConcurrency: mainFlags.requestConcurrency,
+			RequestRate:        mainFlags.requestRate,
+			CacheFile:          mainFlags.cacheFile,
+			UpstreamURL:        mainFlags.upstreamURL,
+			HTMLFS:             htmlFS,
+			JavascriptFS:       jsFS,
+			StaticFS:           staticFS,
+			DigestConfig: server.DigestConfig{
+				SMTPAddr:     mainFlags.smtpAddr,
+				SMTPUsername: mainFlags.smtpUsername,
+				SMTPPassword: mainFlags.smtpPassword,
+				DigestFrom:   mainFlags.digestFrom,
+			},
+			WebhookConfig: server.WebhookConfig{
+				Webhooks:      mainFlags.webhooks,
+				WebhookSecret: mainFlags.webhookSecret,
+			},
 		}
 		server, err := cfg.New(log, ds, httpClient)
 		if err != nil {
@@ -138,3 +255,26 @@ func startupFuncs(mainFlags *mainFlags, log *log.Logger) []func() error {
 		return server.Run()
 	})
 }
+
+// newHTTPClient creates the client to make requests to external sources for data with, which might record or replay responses.
+func newHTTPClient(mainFlags *mainFlags) (request.HTTPClient, error) {
+	httpClient := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+	var record bool
+	switch mainFlags.replayMode {
+	case "":
+		return httpClient, nil
+	case replayModeRecord:
+		record = true
+	case replayModeReplay:
+		record = false
+	default:
+		return nil, fmt.Errorf("invalid replay mode: %q", mainFlags.replayMode)
+	}
+	replayClient, err := request.NewReplayClient(httpClient, mainFlags.replayDir, record)
+	if err != nil {
+		return nil, err
+	}
+	return replayClient, nil
+}
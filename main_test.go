@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/jacobpatterson1549/nate-mlb/go/request"
 )
 
 func TestStartupFuncs_initialCap(t *testing.T) {
@@ -98,3 +100,36 @@ func TestFlagUsage(t *testing.T) {
 		t.Error("flag defaults not included in help message")
 	}
 }
+
+func TestNewHTTPClient(t *testing.T) {
+	newHTTPClientTests := []struct {
+		replayMode string
+		replayDir  string
+		wantReplay bool
+		wantErr    bool
+	}{
+		{},
+		{replayMode: "record", replayDir: t.TempDir(), wantReplay: true},
+		{replayMode: "replay", replayDir: t.TempDir(), wantReplay: true},
+		{replayMode: "replay", wantErr: true}, // no replayDir
+		{replayMode: "rewind", replayDir: t.TempDir(), wantErr: true},
+	}
+	for i, test := range newHTTPClientTests {
+		mainFlags := mainFlags{
+			replayMode: test.replayMode,
+			replayDir:  test.replayDir,
+		}
+		httpClient, err := newHTTPClient(&mainFlags)
+		_, gotReplay := httpClient.(*request.ReplayClient)
+		switch {
+		case test.wantErr:
+			if err == nil {
+				t.Errorf("Test %v: wanted error", i)
+			}
+		case err != nil:
+			t.Errorf("Test %v: unexpected error: %v", i, err)
+		case test.wantReplay != gotReplay:
+			t.Errorf("Test %v: wanted replay client: %v, got %T", i, test.wantReplay, httpClient)
+		}
+	}
+}